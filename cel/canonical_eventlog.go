@@ -54,6 +54,9 @@ func (t TLV) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary unmarshal a byte slice to a TLV.
 func (t *TLV) UnmarshalBinary(data []byte) error {
+	if len(data) < tlvTypeFieldLength+tlvLengthFieldLength {
+		return fmt.Errorf("TLV is shorter than its type and length fields")
+	}
 	valueLength := binary.BigEndian.Uint32(data[tlvTypeFieldLength : tlvTypeFieldLength+tlvLengthFieldLength])
 
 	if valueLength != uint32(len(data[tlvTypeFieldLength+tlvLengthFieldLength:])) {
@@ -65,42 +68,69 @@ func (t *TLV) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// DecodeLimits bounds the resources used to decode a Canonical Eventlog from
+// untrusted input. A zero field means no limit.
+type DecodeLimits struct {
+	// The maximum number of records in the log.
+	MaxRecords int
+	// The maximum encoded size of a single record, including all its fields.
+	MaxRecordSize int
+	// The maximum encoded size of the whole log.
+	MaxTotalSize int64
+}
+
+// DefaultDecodeLimits returns limits which are far above the size of any
+// legitimate COS event log. They are suggested for decoding untrusted input.
+func DefaultDecodeLimits() DecodeLimits {
+	return DecodeLimits{
+		MaxRecords:    1 << 16,
+		MaxRecordSize: 1 << 20,
+		MaxTotalSize:  1 << 26,
+	}
+}
+
 // UnmarshalFirstTLV reads and parse the first TLV from the bytes buffer. The function will
 // return io.EOF if the buf ends unexpectedly or cannot fill the TLV.
 func UnmarshalFirstTLV(buf *bytes.Buffer) (tlv TLV, err error) {
-	typeByte, err := buf.ReadByte()
-	if err != nil {
-		return tlv, err
-	}
-	var data []byte
-	data = append(data, typeByte)
+	return UnmarshalFirstTLVWithLimits(buf, DecodeLimits{})
+}
 
-	// get the length
-	lengthBytes := make([]byte, tlvLengthFieldLength)
-	bytesRead, err := buf.Read(lengthBytes)
-	if err != nil {
-		return TLV{}, err
+// UnmarshalFirstTLVWithLimits is like UnmarshalFirstTLV, but rejects a TLV
+// larger than limits.MaxRecordSize.
+func UnmarshalFirstTLVWithLimits(buf *bytes.Buffer, limits DecodeLimits) (tlv TLV, err error) {
+	maxValueLength := -1
+	if limits.MaxRecordSize > 0 {
+		maxValueLength = max(limits.MaxRecordSize-tlvTypeFieldLength-tlvLengthFieldLength, 0)
 	}
-	if bytesRead != tlvLengthFieldLength {
+	tlv, err = readTLV(buf, maxValueLength)
+	if err == io.ErrUnexpectedEOF {
 		return TLV{}, io.EOF
 	}
-	valueLength := binary.BigEndian.Uint32(lengthBytes)
-	data = append(data, lengthBytes...)
+	return tlv, err
+}
 
-	valueBytes := make([]byte, valueLength)
-	bytesRead, err = buf.Read(valueBytes)
-	if err != nil {
+// readTLV reads a TLV from the reader. If maxValueLength is not negative, TLVs
+// with a longer value are rejected. It returns io.EOF if the reader is empty,
+// and io.ErrUnexpectedEOF if it ends within the TLV.
+func readTLV(r io.Reader, maxValueLength int) (TLV, error) {
+	header := make([]byte, tlvTypeFieldLength+tlvLengthFieldLength)
+	if _, err := io.ReadFull(r, header); err != nil {
 		return TLV{}, err
 	}
-	if uint32(bytesRead) != valueLength {
-		return TLV{}, io.EOF
+	valueLength := binary.BigEndian.Uint32(header[tlvTypeFieldLength:])
+	if maxValueLength >= 0 && uint64(valueLength) > uint64(maxValueLength) {
+		return TLV{}, fmt.Errorf("TLV length [%d] exceeds the limit [%d]", valueLength, maxValueLength)
 	}
-	data = append(data, valueBytes...)
-
-	if err = (&tlv).UnmarshalBinary(data); err != nil {
+	// Grow the value as it is read, so that a bogus length in a truncated
+	// input does not cause a large allocation.
+	var value bytes.Buffer
+	if _, err := io.CopyN(&value, r, int64(valueLength)); err != nil {
+		if err == io.EOF {
+			return TLV{}, io.ErrUnexpectedEOF
+		}
 		return TLV{}, err
 	}
-	return tlv, nil
+	return TLV{Type: header[0], Value: value.Bytes()}, nil
 }
 
 // Record represents a Canonical Eventlog Record.
@@ -123,6 +153,7 @@ type Content interface {
 // CEL represents a Canonical Eventlog, which contains a list of Records.
 type CEL struct {
 	Records []Record
+	journal *Journal
 }
 
 // generateDigestMap computes hashes with the given hash algos and the given event
@@ -151,11 +182,6 @@ func (c *CEL) AppendEventRTMR(client configfsi.Client, rtmrIndex int, event Cont
 		return err
 	}
 
-	err = rtmr.ExtendDigestClient(client, rtmrIndex, digestsMap[crypto.SHA384])
-	if err != nil {
-		return err
-	}
-
 	celrRTMR := Record{
 		RecNum:    uint64(len(c.Records)),
 		Index:     uint8(rtmrIndex) + 1, // CCMR conversion from RTMR
@@ -163,6 +189,17 @@ func (c *CEL) AppendEventRTMR(client configfsi.Client, rtmrIndex int, event Cont
 		Content:   eventTlv,
 		IndexType: CCMRTypeValue,
 	}
+	if err := c.journalRecord(celrRTMR); err != nil {
+		return err
+	}
+
+	err = rtmr.ExtendDigestClient(client, rtmrIndex, digestsMap[crypto.SHA384])
+	if err != nil {
+		if jErr := c.unjournalRecord(); jErr != nil {
+			return fmt.Errorf("%v (and failed to remove the record from the journal: %v)", err, jErr)
+		}
+		return err
+	}
 
 	c.Records = append(c.Records, celrRTMR)
 	return nil
@@ -182,6 +219,34 @@ func (c *CEL) AppendEventPCR(tpm io.ReadWriteCloser, pcr int, hashAlgos []crypto
 		return err
 	}
 
+	eventTlv, err := event.GetTLV()
+	if err != nil {
+		return err
+	}
+
+	celrPCR := Record{
+		RecNum:    uint64(len(c.Records)),
+		Index:     uint8(pcr),
+		Digests:   digestsMap,
+		Content:   eventTlv,
+		IndexType: PCRTypeValue,
+	}
+	if err := c.journalRecord(celrPCR); err != nil {
+		return err
+	}
+
+	if err := extendPCR(tpm, pcr, digestsMap); err != nil {
+		if jErr := c.unjournalRecord(); jErr != nil {
+			return fmt.Errorf("%v (and failed to remove the record from the journal: %v)", err, jErr)
+		}
+		return err
+	}
+
+	c.Records = append(c.Records, celrPCR)
+	return nil
+}
+
+func extendPCR(tpm io.ReadWriteCloser, pcr int, digestsMap map[crypto.Hash][]byte) error {
 	for hs, dgst := range digestsMap {
 		tpm2Alg, err := tpm2.HashToAlgorithm(hs)
 		if err != nil {
@@ -191,21 +256,38 @@ func (c *CEL) AppendEventPCR(tpm io.ReadWriteCloser, pcr int, hashAlgos []crypto
 			return fmt.Errorf("failed to extend event to PCR%d: %v", pcr, err)
 		}
 	}
+	return nil
+}
+
+// AppendCelMgtPCR appends a non-measured CEL management record to a CEL of
+// PCR records. The record has the given PCR index, but no digests.
+func (c *CEL) AppendCelMgtPCR(pcr int, event CelMgt) error {
+	return c.appendCelMgt(PCRTypeValue, uint8(pcr), event)
+}
 
+// AppendCelMgtRTMR appends a non-measured CEL management record to a CEL of
+// RTMR records. As with AppendEventRTMR, the index showing up in the record
+// will be rtmrIndex + 1.
+func (c *CEL) AppendCelMgtRTMR(rtmrIndex int, event CelMgt) error {
+	return c.appendCelMgt(CCMRTypeValue, uint8(rtmrIndex)+1, event)
+}
+
+func (c *CEL) appendCelMgt(indexType uint8, index uint8, event CelMgt) error {
 	eventTlv, err := event.GetTLV()
 	if err != nil {
 		return err
 	}
-
-	celrPCR := Record{
+	record := Record{
 		RecNum:    uint64(len(c.Records)),
-		Index:     uint8(pcr),
-		Digests:   digestsMap,
+		Index:     index,
+		IndexType: indexType,
+		Digests:   map[crypto.Hash][]byte{},
 		Content:   eventTlv,
-		IndexType: PCRTypeValue,
 	}
-
-	c.Records = append(c.Records, celrPCR)
+	if err := c.journalRecord(record); err != nil {
+		return err
+	}
+	c.Records = append(c.Records, record)
 	return nil
 }
 
@@ -355,26 +437,125 @@ func (c *CEL) EncodeCEL(buf *bytes.Buffer) error {
 }
 
 // DecodeToCEL will read the buf for CEL, will return err if the buffer
-// is not complete.
+// is not complete. Use DecodeToCELWithLimits to decode untrusted input.
 func DecodeToCEL(buf *bytes.Buffer) (CEL, error) {
+	return DecodeToCELWithLimits(buf, DecodeLimits{})
+}
+
+// DecodeToCELWithLimits reads a whole CEL from the reader, returning an error
+// as soon as the log exceeds the limits.
+func DecodeToCELWithLimits(r io.Reader, limits DecodeLimits) (CEL, error) {
 	var cel CEL
-	for buf.Len() > 0 {
-		celr, err := DecodeToCELR(buf)
+	decoder := NewDecoder(r, limits)
+	for {
+		celr, err := decoder.Decode()
 		if err == io.EOF {
-			return CEL{}, fmt.Errorf("buffer ends unexpectedly")
+			return cel, nil
 		}
 		if err != nil {
 			return CEL{}, err
 		}
 		cel.Records = append(cel.Records, celr)
 	}
-	return cel, nil
 }
 
 // DecodeToCELR will read the buf for the next CELR, will return err if
 // failed to unmarshal a correct CELR TLV from the buffer.
 func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
-	recnum, err := UnmarshalFirstTLV(buf)
+	r, err = decodeRecord(buf, 0)
+	if err == io.ErrUnexpectedEOF {
+		return Record{}, io.EOF
+	}
+	return r, err
+}
+
+// Decoder reads CEL records one at a time from a stream (e.g., a file or a
+// network connection), so that records can be processed without holding the
+// whole log in memory.
+type Decoder struct {
+	r       io.Reader
+	limits  DecodeLimits
+	records int
+	total   int64
+}
+
+// NewDecoder returns a Decoder reading records from r, enforcing the limits.
+func NewDecoder(r io.Reader, limits DecodeLimits) *Decoder {
+	d := &Decoder{limits: limits}
+	d.r = &countingReader{r: r, n: &d.total}
+	return d
+}
+
+// Decode returns the next record of the log. It returns io.EOF at the end of
+// the log, and an error if the log ends within a record or exceeds the limits.
+func (d *Decoder) Decode() (Record, error) {
+	var limitErr error
+	maxRecordSize := d.limits.MaxRecordSize
+	if d.limits.MaxTotalSize > 0 {
+		remaining := d.limits.MaxTotalSize - d.total
+		if remaining == 0 {
+			limitErr = fmt.Errorf("CEL exceeds the total size limit [%d]", d.limits.MaxTotalSize)
+		} else if maxRecordSize <= 0 || remaining < int64(maxRecordSize) {
+			maxRecordSize = int(remaining)
+		}
+	}
+	if d.limits.MaxRecords > 0 && d.records >= d.limits.MaxRecords {
+		limitErr = fmt.Errorf("CEL has more than the limit of %d records", d.limits.MaxRecords)
+	}
+	if limitErr != nil {
+		// Only fail if there actually is another record.
+		if _, err := io.ReadFull(d.r, make([]byte, 1)); err == io.EOF {
+			return Record{}, io.EOF
+		}
+		return Record{}, limitErr
+	}
+	r, err := decodeRecord(d.r, maxRecordSize)
+	if err == io.ErrUnexpectedEOF {
+		return Record{}, fmt.Errorf("buffer ends unexpectedly")
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	d.records++
+	return r, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// decodeRecord reads the four TLVs of a record, which together must not be
+// larger than maxRecordSize (if positive). It returns io.EOF if the reader is
+// empty and io.ErrUnexpectedEOF if it ends within the record.
+func decodeRecord(reader io.Reader, maxRecordSize int) (r Record, err error) {
+	size := 0
+	next := func() (TLV, error) {
+		maxValueLength := -1
+		if maxRecordSize > 0 {
+			maxValueLength = maxRecordSize - size - tlvTypeFieldLength - tlvLengthFieldLength
+			if maxValueLength < 0 {
+				return TLV{}, fmt.Errorf("CEL record exceeds the size limit [%d]", maxRecordSize)
+			}
+		}
+		tlv, err := readTLV(reader, maxValueLength)
+		if err != nil {
+			if err == io.EOF && size > 0 {
+				return TLV{}, io.ErrUnexpectedEOF
+			}
+			return TLV{}, err
+		}
+		size += tlvTypeFieldLength + tlvLengthFieldLength + len(tlv.Value)
+		return tlv, nil
+	}
+
+	recnum, err := next()
 	if err != nil {
 		return Record{}, err
 	}
@@ -383,7 +564,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	regIndex, err := UnmarshalFirstTLV(buf)
+	regIndex, err := next()
 	if err != nil {
 		return Record{}, err
 	}
@@ -392,7 +573,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	digests, err := UnmarshalFirstTLV(buf)
+	digests, err := next()
 	if err != nil {
 		return Record{}, err
 	}
@@ -401,7 +582,7 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 		return Record{}, err
 	}
 
-	r.Content, err = UnmarshalFirstTLV(buf)
+	r.Content, err = next()
 	if err != nil {
 		return Record{}, err
 	}
@@ -412,49 +593,14 @@ func DecodeToCELR(buf *bytes.Buffer) (r Record, err error) {
 // extend sequence for each register (PCR, RTMR) in the log. It then compares
 // the final digests against a bank of register values to see if they match.
 // make sure CEL has only one indexType event
+// CEL management records are not measured, so they are skipped.
+// Use ReplayReport to diagnose which records a register failed to replay.
 func (c *CEL) Replay(regs register.MRBank) error {
-	cryptoHash, err := regs.CryptoHash()
+	report, err := c.ReplayReport(regs)
 	if err != nil {
 		return err
 	}
-	replayed := make(map[uint8][]byte)
-	for _, record := range c.Records {
-		if _, ok := replayed[record.Index]; !ok {
-			replayed[record.Index] = make([]byte, cryptoHash.Size())
-		}
-		hasher := cryptoHash.New()
-		digestsMap := record.Digests
-		digest, ok := digestsMap[cryptoHash]
-		if !ok {
-			return fmt.Errorf("the CEL record did not contain a %v digest", cryptoHash)
-		}
-		hasher.Write(replayed[record.Index])
-		hasher.Write(digest)
-		replayed[record.Index] = hasher.Sum(nil)
-	}
-
-	// to a map for easy matching
-	registers := make(map[int][]byte)
-	for _, r := range regs.MRs() {
-		registers[r.Idx()] = r.Dgst()
-	}
-
-	var failedReplayRegs []uint8
-	for replayReg, replayDigest := range replayed {
-		bankDigest, ok := registers[int(replayReg)]
-		if !ok {
-			return fmt.Errorf("the CEL contains record(s) for register %d without a matching register in the given bank to verify", replayReg)
-		}
-		if !bytes.Equal(bankDigest, replayDigest) {
-			failedReplayRegs = append(failedReplayRegs, replayReg)
-		}
-	}
-
-	if len(failedReplayRegs) == 0 {
-		return nil
-	}
-
-	return fmt.Errorf("CEL replay failed for these registers in bank %v: %v", cryptoHash, failedReplayRegs)
+	return report.Err()
 }
 
 // VerifyDigests checks the digest generated by the given record's content to make sure they are equal to
@@ -7,6 +7,7 @@ import (
 	"io"
 	"reflect"
 	"testing"
+	"testing/iotest"
 
 	"github.com/google/go-configfs-tsm/configfs/configfsi"
 	"github.com/google/go-configfs-tsm/configfs/fakertmr"
@@ -228,3 +229,148 @@ func appendRtmrEventOrFatal(t *testing.T, cel *CEL, rtmrClient configfsi.Client,
 		t.Fatalf("failed to append RTMR event: %v", err)
 	}
 }
+
+func encodeTestCEL(t *testing.T, numRecords int, contentSize int) []byte {
+	t.Helper()
+	c := &CEL{}
+	for i := 0; i < numRecords; i++ {
+		c.Records = append(c.Records, Record{
+			RecNum:    uint64(i),
+			Index:     uint8(test.DebugPCR),
+			IndexType: PCRTypeValue,
+			Digests:   map[crypto.Hash][]byte{crypto.SHA256: make([]byte, crypto.SHA256.Size())},
+			Content:   TLV{Type: uint8(ImageRefType), Value: bytes.Repeat([]byte{'a'}, contentSize)},
+		})
+	}
+	var buf bytes.Buffer
+	if err := c.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecoderStreaming(t *testing.T) {
+	encoded := encodeTestCEL(t, 3, 100)
+	decoder := NewDecoder(iotest.OneByteReader(bytes.NewReader(encoded)), DefaultDecodeLimits())
+	for i := 0; i < 3; i++ {
+		record, err := decoder.Decode()
+		if err != nil {
+			t.Fatalf("Decode() #%d failed: %v", i, err)
+		}
+		if record.RecNum != uint64(i) || len(record.Content.Value) != 100 {
+			t.Errorf("Decode() #%d got record %d with content size %d", i, record.RecNum, len(record.Content.Value))
+		}
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("Decode() at the end got err = %v, want io.EOF", err)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	// Each record has 5+8 + 5+1 + 5+5+32 + 5+100 = 166 bytes.
+	encoded := encodeTestCEL(t, 4, 100)
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  DecodeLimits
+		wantErr bool
+	}{
+		{"NoLimits", encoded, DecodeLimits{}, false},
+		{"Defaults", encoded, DefaultDecodeLimits(), false},
+		{"ExactLimits", encoded, DecodeLimits{MaxRecords: 4, MaxRecordSize: 166, MaxTotalSize: 4 * 166}, false},
+		{"TooManyRecords", encoded, DecodeLimits{MaxRecords: 3}, true},
+		{"RecordTooLarge", encoded, DecodeLimits{MaxRecordSize: 165}, true},
+		{"TotalTooLarge", encoded, DecodeLimits{MaxTotalSize: 4*166 - 1}, true},
+		{"TotalLimitAtRecordBoundary", encoded, DecodeLimits{MaxTotalSize: 3 * 166}, true},
+		{"Truncated", encoded[:len(encoded)-1], DecodeLimits{}, true},
+		{"TruncatedHeader", encoded[:166+3], DecodeLimits{}, true},
+		// A single TLV claiming a 4 GiB value.
+		{"HugeLength", []byte{0, 0xff, 0xff, 0xff, 0xff, 0}, DecodeLimits{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := DecodeToCELWithLimits(bytes.NewReader(tc.data), tc.limits)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DecodeToCELWithLimits() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && len(decoded.Records) != 4 {
+				t.Errorf("DecodeToCELWithLimits() got %d records, want 4", len(decoded.Records))
+			}
+		})
+	}
+}
+
+func TestUnmarshalFirstTLVLimit(t *testing.T) {
+	// The length is checked before reading the value.
+	huge := []byte{1, 0x7f, 0xff, 0xff, 0xff}
+	if _, err := UnmarshalFirstTLVWithLimits(bytes.NewBuffer(huge), DefaultDecodeLimits()); err == nil || err == io.EOF {
+		t.Errorf("UnmarshalFirstTLVWithLimits() got err = %v, want a limit error", err)
+	}
+	truncated := []byte{1, 0, 0, 0, 4, 'a'}
+	if _, err := UnmarshalFirstTLVWithLimits(bytes.NewBuffer(truncated), DefaultDecodeLimits()); err != io.EOF {
+		t.Errorf("UnmarshalFirstTLVWithLimits() got err = %v, want io.EOF", err)
+	}
+
+	// Without limits, a TLV larger than the default limits is accepted.
+	large := bytes.Repeat([]byte{'a'}, DefaultDecodeLimits().MaxRecordSize)
+	encoded, err := TLV{Type: 1, Value: large}.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalFirstTLVWithLimits(bytes.NewBuffer(encoded), DefaultDecodeLimits()); err == nil {
+		t.Error("UnmarshalFirstTLVWithLimits() got nil error, want a limit error")
+	}
+	tlv, err := UnmarshalFirstTLV(bytes.NewBuffer(encoded))
+	if err != nil {
+		t.Fatalf("UnmarshalFirstTLV() got err = %v, want nil", err)
+	}
+	if !bytes.Equal(tlv.Value, large) {
+		t.Errorf("UnmarshalFirstTLV() got a value of %d bytes, want %d", len(tlv.Value), len(large))
+	}
+}
+
+func TestReplayReport(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	for _, pcr := range []int{test.DebugPCR, test.ApplicationPCR} {
+		if err := tpm2.PCRReset(tpm, tpmutil.Handle(pcr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cel := &CEL{}
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	appendPcrEventOrFatal(t, cel, tpm, test.ApplicationPCR, measuredHashes, CosTlv{ArgType, []byte("--x")})
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ArgType, []byte("--y")})
+	appendPcrEventOrFatal(t, cel, tpm, test.ApplicationPCR, measuredHashes, CosTlv{LaunchSeparatorType, []byte{}})
+	// Tamper with the digest of the second record of the application PCR.
+	cel.Records[3].Digests[crypto.SHA256] = make([]byte, crypto.SHA256.Size())
+
+	pcrMap, err := tpm2.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{test.DebugPCR, test.ApplicationPCR}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank := register.PCRBank{TCGHashAlgo: state.HashAlgo_SHA256}
+	for index, val := range pcrMap {
+		bank.PCRs = append(bank.PCRs, register.PCR{Index: index, Digest: val, DigestAlg: crypto.SHA256})
+	}
+	report, err := cel.ReplayReport(bank)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Registers) != 2 {
+		t.Fatalf("ReplayReport() got %d registers, want 2", len(report.Registers))
+	}
+	debug, application := report.Registers[0], report.Registers[1]
+	if test.DebugPCR > test.ApplicationPCR {
+		debug, application = application, debug
+	}
+	if !debug.Match() || debug.Records != 2 || debug.FirstDivergentRecord != -1 || debug.ExtraExtends {
+		t.Errorf("ReplayReport() got %+v for the debug PCR, want a match", debug)
+	}
+	if application.Match() || application.Records != 2 || application.FirstDivergentRecord != 3 || !application.ExtraExtends {
+		t.Errorf("ReplayReport() got %+v for the application PCR, want a mismatch at record 3", application)
+	}
+	if err := report.Err(); err == nil || err.Error() != cel.Replay(bank).Error() {
+		t.Errorf("ReplayReport.Err() got %v, want the Replay() error", err)
+	}
+}
@@ -0,0 +1,205 @@
+package cel
+
+import (
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// CelMgtEventType represents a CEL management event type in a CelMgt content.
+type CelMgtEventType uint8
+
+// Types of CEL management events, from the CEL spec.
+const (
+	// CelVersionType records the version of the CEL spec used by the log.
+	CelVersionType CelMgtEventType = 1
+	// FirmwareEndType marks the end of the firmware measurements.
+	FirmwareEndType CelMgtEventType = 2
+	// TransferChangeType records the handoff of the log to another
+	// measurement agent.
+	TransferChangeType CelMgtEventType = 3
+	// CelTimestampType records the time at which the following records were
+	// measured.
+	CelTimestampType CelMgtEventType = 80
+	// StateTransType records a platform state transition.
+	StateTransType CelMgtEventType = 81
+)
+
+// StateTransition is the platform state transition of a state_trans event.
+type StateTransition uint8
+
+// Platform state transitions, from the CEL spec.
+const (
+	Suspend StateTransition = iota
+	Hibernate
+	Kexec
+)
+
+func (t StateTransition) String() string {
+	switch t {
+	case Suspend:
+		return "suspend"
+	case Hibernate:
+		return "hibernate"
+	case Kexec:
+		return "kexec"
+	}
+	return fmt.Sprintf("StateTransition(%d)", uint8(t))
+}
+
+// Nested TLV types of the cel_version management event.
+const (
+	celVersionMajorTLV uint8 = 0
+	celVersionMinorTLV uint8 = 1
+)
+
+var celMgtTypeNames = map[CelMgtEventType]string{
+	CelVersionType:     "cel_version",
+	FirmwareEndType:    "firmware_end",
+	TransferChangeType: "transfer_change",
+	CelTimestampType:   "cel_timestamp",
+	StateTransType:     "state_trans",
+}
+
+func (t CelMgtEventType) String() string {
+	if name, ok := celMgtTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("CelMgtEventType(%d)", uint8(t))
+}
+
+// CelMgt is a CEL management event (e.g., the version of the CEL spec used by
+// the log), used as a CEL content. CEL management records are not measured
+// into a register, so their content cannot be verified by replaying the log.
+type CelMgt struct {
+	EventType    CelMgtEventType
+	EventContent []byte
+}
+
+// NewCelVersion returns a cel_version management event.
+func NewCelVersion(major, minor uint32) (CelMgt, error) {
+	content, err := marshalNestedTLVs(
+		TLV{celVersionMajorTLV, marshalUint(major)},
+		TLV{celVersionMinorTLV, marshalUint(minor)},
+	)
+	if err != nil {
+		return CelMgt{}, err
+	}
+	return CelMgt{EventType: CelVersionType, EventContent: content}, nil
+}
+
+// CelVersion returns the version of a cel_version management event.
+func (c CelMgt) CelVersion() (major, minor uint32, err error) {
+	if c.EventType != CelVersionType {
+		return 0, 0, fmt.Errorf("CEL management event %v is not a cel_version", c.EventType)
+	}
+	nested, err := unmarshalNestedTLVs(c.EventContent, celVersionMajorTLV, celVersionMinorTLV)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cel_version: %v", err)
+	}
+	majorVal, err := unmarshalUint(nested[0].Value, math.MaxUint32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cel_version major: %v", err)
+	}
+	minorVal, err := unmarshalUint(nested[1].Value, math.MaxUint32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cel_version minor: %v", err)
+	}
+	return uint32(majorVal), uint32(minorVal), nil
+}
+
+// NewFirmwareEnd returns a firmware_end management event.
+func NewFirmwareEnd() CelMgt {
+	return CelMgt{EventType: FirmwareEndType, EventContent: []byte{}}
+}
+
+// NewTransferChange returns a transfer_change management event, recording
+// that the named measurement agent now appends to the log.
+func NewTransferChange(agent string) CelMgt {
+	return CelMgt{EventType: TransferChangeType, EventContent: []byte(agent)}
+}
+
+// TransferChange returns the measurement agent of a transfer_change event.
+func (c CelMgt) TransferChange() (string, error) {
+	if c.EventType != TransferChangeType {
+		return "", fmt.Errorf("CEL management event %v is not a transfer_change", c.EventType)
+	}
+	if !utf8.Valid(c.EventContent) {
+		return "", fmt.Errorf("transfer_change agent is not valid UTF-8")
+	}
+	return string(c.EventContent), nil
+}
+
+// NewCelTimestamp returns a cel_timestamp management event. The timestamp is
+// encoded as the big-endian number of nanoseconds since the Unix epoch.
+func NewCelTimestamp(t time.Time) CelMgt {
+	return CelMgt{EventType: CelTimestampType, EventContent: binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))}
+}
+
+// CelTimestamp returns the time of a cel_timestamp event.
+func (c CelMgt) CelTimestamp() (time.Time, error) {
+	if c.EventType != CelTimestampType {
+		return time.Time{}, fmt.Errorf("CEL management event %v is not a cel_timestamp", c.EventType)
+	}
+	nanos, err := unmarshalUint(c.EventContent, math.MaxInt64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cel_timestamp: %v", err)
+	}
+	return time.Unix(0, int64(nanos)), nil
+}
+
+// NewStateTrans returns a state_trans management event.
+func NewStateTrans(transition StateTransition) CelMgt {
+	return CelMgt{EventType: StateTransType, EventContent: []byte{uint8(transition)}}
+}
+
+// StateTrans returns the platform state transition of a state_trans event.
+func (c CelMgt) StateTrans() (StateTransition, error) {
+	if c.EventType != StateTransType {
+		return 0, fmt.Errorf("CEL management event %v is not a state_trans", c.EventType)
+	}
+	transition, err := unmarshalUint(c.EventContent, uint64(Kexec))
+	if err != nil {
+		return 0, fmt.Errorf("invalid state_trans: %v", err)
+	}
+	return StateTransition(transition), nil
+}
+
+// GetTLV returns the TLV representation of the CEL management event.
+func (c CelMgt) GetTLV() (TLV, error) {
+	data, err := TLV{uint8(c.EventType), c.EventContent}.MarshalBinary()
+	if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: CelMgtType, Value: data}, nil
+}
+
+// GenerateDigest generates the digest of the whole TLV of the CEL management
+// event, as for a COS TLV.
+func (c CelMgt) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	contentTLV, err := c.GetTLV()
+	if err != nil {
+		return nil, err
+	}
+	b, err := contentTLV.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return hashBytes(hashAlgo, b)
+}
+
+// ParseToCelMgt constructs a CelMgt from a TLV. It will check for the correct
+// content type, and unmarshal the nested event.
+func (t TLV) ParseToCelMgt() (CelMgt, error) {
+	if t.Type != CelMgtType {
+		return CelMgt{}, fmt.Errorf("TLV type %v is not a CEL management event", t.Type)
+	}
+	nestedEvent := TLV{}
+	if err := nestedEvent.UnmarshalBinary(t.Value); err != nil {
+		return CelMgt{}, err
+	}
+	return CelMgt{CelMgtEventType(nestedEvent.Type), nestedEvent.Value}, nil
+}
@@ -0,0 +1,132 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Content types defined in the CEL spec, used as the type of a record's
+// content TLV. COS content uses CosEventType.
+const (
+	// CelMgtType indicates the CELR content is CEL management information.
+	CelMgtType uint8 = 4
+	// PCClientStdType indicates the CELR content is a TCG PC Client event.
+	PCClientStdType uint8 = 5
+	// ImaTemplateType indicates the CELR content is a Linux IMA template entry.
+	ImaTemplateType uint8 = 7
+)
+
+// ContentDecoder decodes the content TLV of a record into a Content.
+type ContentDecoder func(TLV) (Content, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[uint8]ContentDecoder{
+		CosEventType:    func(t TLV) (Content, error) { return t.ParseToCosTlv() },
+		CelMgtType:      func(t TLV) (Content, error) { return t.ParseToCelMgt() },
+		PCClientStdType: func(t TLV) (Content, error) { return t.ParseToPCClientStd() },
+		ImaTemplateType: func(t TLV) (Content, error) { return t.ParseToImaTemplate() },
+	}
+)
+
+// RegisterContentDecoder makes a decoder for a custom content type available
+// to DecodeContent, so that agents can log their own events in a CEL. It
+// panics if the content type conflicts with a record field or already has a
+// decoder, including the ones of the content types defined in this package.
+func RegisterContentDecoder(contentType uint8, decoder ContentDecoder) {
+	switch contentType {
+	case recnumTypeValue, PCRTypeValue, digestsTypeValue, CCMRTypeValue:
+		panic(fmt.Sprintf("cel: content type %d is a record field type", contentType))
+	}
+	if decoder == nil {
+		panic("cel: RegisterContentDecoder decoder is nil")
+	}
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	if _, dup := contentDecoders[contentType]; dup {
+		panic(fmt.Sprintf("cel: RegisterContentDecoder called twice for content type %d", contentType))
+	}
+	contentDecoders[contentType] = decoder
+}
+
+// DecodeContent decodes the content TLV of a record, using the decoder
+// registered for its type.
+func DecodeContent(t TLV) (Content, error) {
+	contentDecodersMu.RLock()
+	decoder, ok := contentDecoders[t.Type]
+	contentDecodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no decoder registered for content type %d", t.Type)
+	}
+	return decoder(t)
+}
+
+// marshalNestedTLVs concatenates the encodings of the TLVs.
+func marshalNestedTLVs(tlvs ...TLV) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, tlv := range tlvs {
+		data, err := tlv.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalNestedTLVs parses the value of a TLV as a sequence of TLVs with
+// the given types, in order.
+func unmarshalNestedTLVs(value []byte, types ...uint8) ([]TLV, error) {
+	buf := bytes.NewBuffer(value)
+	tlvs := make([]TLV, 0, len(types))
+	for _, typ := range types {
+		tlv, err := UnmarshalFirstTLV(buf)
+		if err == io.EOF {
+			return nil, fmt.Errorf("nested TLV of type [%d] is missing or truncated", typ)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if tlv.Type != typ {
+			return nil, fmt.Errorf("nested TLV has type [%d], expected [%d]", tlv.Type, typ)
+		}
+		tlvs = append(tlvs, tlv)
+	}
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after nested TLVs", buf.Len())
+	}
+	return tlvs, nil
+}
+
+// Integers in nested TLVs are big-endian. They are encoded in 4 bytes, but
+// any length up to 8 bytes is accepted.
+func marshalUint(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func unmarshalUint(value []byte, max uint64) (uint64, error) {
+	if len(value) == 0 || len(value) > 8 {
+		return 0, fmt.Errorf("invalid integer length [%d]", len(value))
+	}
+	var v uint64
+	for _, b := range value {
+		v = v<<8 | uint64(b)
+	}
+	if v > max {
+		return 0, fmt.Errorf("integer %d exceeds the maximum %d", v, max)
+	}
+	return v, nil
+}
+
+func hashBytes(hashAlgo crypto.Hash, data []byte) ([]byte, error) {
+	if !hashAlgo.Available() {
+		return nil, fmt.Errorf("hash algorithm %v is not available", hashAlgo)
+	}
+	hash := hashAlgo.New()
+	hash.Write(data)
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,247 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestContentRoundTrip(t *testing.T) {
+	celVersion, err := NewCelVersion(1, 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []Content{
+		CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")},
+		PCClientStd{EventType: 0x4, EventData: []byte{0, 0, 0, 0}},
+		PCClientStd{EventType: 0x80000008, EventData: nil},
+		ImaTemplate{TemplateName: "ima-ng", TemplateData: []byte{0x24, 0, 0, 0, 's', 'h', 'a'}},
+		celVersion,
+		CelMgt{EventType: FirmwareEndType, EventContent: []byte{}},
+	} {
+		tlv, err := content.GetTLV()
+		if err != nil {
+			t.Fatalf("%T.GetTLV() failed: %v", content, err)
+		}
+		decoded, err := DecodeContent(tlv)
+		if err != nil {
+			t.Fatalf("DecodeContent(%T) failed: %v", content, err)
+		}
+		if diff := cmp.Diff(content, decoded, cmp.Comparer(bytes.Equal)); diff != "" {
+			t.Errorf("DecodeContent() returned unexpected diff (-want +got):\n%s", diff)
+		}
+	}
+
+	major, minor, err := celVersion.CelVersion()
+	if err != nil || major != 1 || minor != 41 {
+		t.Errorf("CelVersion() got %d.%d, err = %v, want 1.41", major, minor, err)
+	}
+}
+
+func TestContentDigests(t *testing.T) {
+	data := []byte("template data")
+	want := sha256.Sum256(data)
+	for _, content := range []Content{
+		PCClientStd{EventType: 0xd, EventData: data},
+		ImaTemplate{TemplateName: "ima-ng", TemplateData: data},
+	} {
+		got, err := content.GenerateDigest(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want[:]) {
+			t.Errorf("%T.GenerateDigest() got %x, want %x", content, got, want)
+		}
+	}
+}
+
+func TestDecodeContentFailures(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tlv  TLV
+	}{
+		{"Unregistered", TLV{Type: 201, Value: []byte{1}}},
+		{"PCClientStdTruncated", TLV{Type: PCClientStdType, Value: []byte{0, 0, 0, 0, 4, 0}}},
+		{"PCClientStdMissingData", TLV{Type: PCClientStdType, Value: []byte{0, 0, 0, 0, 1, 4}}},
+		{"PCClientStdLargeEventType", TLV{Type: PCClientStdType, Value: []byte{0, 0, 0, 0, 5, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0}}},
+		{"ImaTemplateWrongOrder", TLV{Type: ImaTemplateType, Value: []byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0}}},
+		{"ImaTemplateTrailingBytes", TLV{Type: ImaTemplateType, Value: []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 9}}},
+		{"CelMgtTruncated", TLV{Type: CelMgtType, Value: []byte{1, 0}}},
+		{"CosTruncated", TLV{Type: CosEventType, Value: []byte{1}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DecodeContent(tc.tlv); err == nil {
+				t.Error("DecodeContent() got nil error, want error")
+			}
+		})
+	}
+}
+
+type testAgentContent struct{ message string }
+
+func (c testAgentContent) GetTLV() (TLV, error) {
+	return TLV{Type: 200, Value: []byte(c.message)}, nil
+}
+
+func (c testAgentContent) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	return hashBytes(hashAlgo, []byte(c.message))
+}
+
+func TestRegisterContentDecoder(t *testing.T) {
+	RegisterContentDecoder(200, func(t TLV) (Content, error) {
+		return testAgentContent{string(t.Value)}, nil
+	})
+	defer func() {
+		contentDecodersMu.Lock()
+		delete(contentDecoders, 200)
+		contentDecodersMu.Unlock()
+	}()
+
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	cel := &CEL{}
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, testAgentContent{"agent started"})
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, PCClientStd{EventType: 0xd, EventData: []byte("agent config")})
+	replay(t, cel, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+	for _, record := range cel.Records {
+		content, err := DecodeContent(record.Content)
+		if err != nil {
+			t.Fatalf("DecodeContent() failed: %v", err)
+		}
+		if err := VerifyDigests(content, record.Digests); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for _, contentType := range []uint8{200, CosEventType, PCRTypeValue} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("RegisterContentDecoder(%d) did not panic", contentType)
+				}
+			}()
+			RegisterContentDecoder(contentType, func(t TLV) (Content, error) { return nil, nil })
+		}()
+	}
+}
+
+func TestEncodeJSONContentTypes(t *testing.T) {
+	celVersion, err := NewCelVersion(1, 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cel := &CEL{}
+	for i, content := range []Content{
+		celVersion,
+		PCClientStd{EventType: 0x4, EventData: []byte{0, 0, 0, 0}},
+		ImaTemplate{TemplateName: "ima-ng", TemplateData: []byte{1, 2}},
+	} {
+		digests, err := generateDigestMap([]crypto.Hash{crypto.SHA256}, content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tlv, err := content.GetTLV()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cel.Records = append(cel.Records, Record{RecNum: uint64(i), Index: 10, IndexType: PCRTypeValue, Digests: digests, Content: tlv})
+	}
+	var buf bytes.Buffer
+	if err := cel.EncodeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"content_type":"cel","content":{"cel_version":{"major":1,"minor":41}}`, `"content_type":"pcclient_std"`, `"content_type":"ima_template","content":{"template_data":"0102","template_name":"ima-ng"}`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("EncodeJSON() got %s, want it to contain %s", buf.String(), want)
+		}
+	}
+	decoded, err := DecodeJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(cel.Records, decoded.Records); diff != "" {
+		t.Errorf("DecodeJSON() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCelMgtEvents(t *testing.T) {
+	agent, err := NewTransferChange("cos-launcher").TransferChange()
+	if err != nil || agent != "cos-launcher" {
+		t.Errorf("TransferChange() got %q, err = %v, want cos-launcher", agent, err)
+	}
+	now := time.Unix(1700000000, 42)
+	ts, err := NewCelTimestamp(now).CelTimestamp()
+	if err != nil || !ts.Equal(now) {
+		t.Errorf("CelTimestamp() got %v, err = %v, want %v", ts, err, now)
+	}
+	transition, err := NewStateTrans(Kexec).StateTrans()
+	if err != nil || transition != Kexec {
+		t.Errorf("StateTrans() got %v, err = %v, want kexec", transition, err)
+	}
+	if _, err := NewFirmwareEnd().CelTimestamp(); err == nil {
+		t.Error("CelTimestamp() of a firmware_end got nil error, want error")
+	}
+	if _, err := (CelMgt{EventType: StateTransType, EventContent: []byte{9}}).StateTrans(); err == nil {
+		t.Error("StateTrans() of an unknown transition got nil error, want error")
+	}
+}
+
+func TestReplayCelMgt(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	celVersion, err := NewCelVersion(1, 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cel := &CEL{}
+	if err := cel.AppendCelMgtPCR(test.DebugPCR, celVersion); err != nil {
+		t.Fatal(err)
+	}
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	for _, event := range []CelMgt{NewCelTimestamp(time.Now()), NewTransferChange("workload"), NewStateTrans(Suspend), NewFirmwareEnd()} {
+		if err := cel.AppendCelMgtPCR(test.DebugPCR, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{LaunchSeparatorType, []byte{}})
+	replay(t, cel, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+	for name, encoding := range map[string]struct {
+		encode func(*CEL, *bytes.Buffer) error
+		decode func(*bytes.Buffer) (CEL, error)
+	}{
+		"TLV":  {(*CEL).EncodeCEL, DecodeToCEL},
+		"JSON": {(*CEL).EncodeJSON, DecodeJSON},
+		"CBOR": {(*CEL).EncodeCBOR, DecodeCBOR},
+	} {
+		var buf bytes.Buffer
+		if err := encoding.encode(cel, &buf); err != nil {
+			t.Fatalf("%s: failed to encode CEL: %v", name, err)
+		}
+		decoded, err := encoding.decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: failed to decode CEL: %v", name, err)
+		}
+		if diff := cmp.Diff(cel.Records, decoded.Records, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("%s: decoded CEL has unexpected diff (-want +got):\n%s", name, diff)
+		}
+		replay(t, &decoded, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+	}
+}
@@ -45,8 +45,71 @@ const (
 	LaunchSeparatorType
 	MemoryMonitorType
 	GpuCCModeType
+	// EventContent is a Linux capability added to the container.
+	AddedCapabilityType
+	// EventContent is 1 if the container has its own cgroup namespace, 0 otherwise.
+	CgroupNamespaceType
+	// EventContent is a mount added to the container, see ContainerMount.
+	MountType
+	// EventContent is the name of the attest.LogRedirectLocation of the container logs.
+	LogRedirectType
+	// EventContent is a service account impersonated by the launcher.
+	ImpersonatedServiceAccountType
+	// EventContent is 1 for a hardened launcher image, 0 for a debug one.
+	HardenedImageType
+	// EventContent is the name of a sidecar container. The container events
+	// (e.g., ImageRefType) following it describe the sidecar, not the main
+	// container.
+	SidecarType
+	// EventContent is the name of an init container. The container events
+	// following it describe the init container, like for SidecarType.
+	InitContainerType
+	// EventContent is the name of the signal stopping the workload (e.g.,
+	// "SIGTERM"). It is the only event measured after LaunchSeparatorType.
+	ShutdownType
+	// EventContent is the DER-encoded PKIX public key with which the local
+	// verifier, generated in the launcher, signs the tokens. It is only measured
+	// when the launch spec selects the local verifier.
+	LocalVerifierKeyType
+	// EventContent is 1 if the init container or sidecar described by the
+	// preceding SidecarType or InitContainerType event can read the
+	// attestation token and reach the TEE server, 0 otherwise. The main
+	// container always can.
+	AttestationAccessType
 )
 
+var cosTypeNames = map[CosType]string{
+	ImageRefType:                   "ImageRef",
+	ImageDigestType:                "ImageDigest",
+	RestartPolicyType:              "RestartPolicy",
+	ImageIDType:                    "ImageID",
+	ArgType:                        "Arg",
+	EnvVarType:                     "EnvVar",
+	OverrideArgType:                "OverrideArg",
+	OverrideEnvType:                "OverrideEnv",
+	LaunchSeparatorType:            "LaunchSeparator",
+	MemoryMonitorType:              "MemoryMonitor",
+	GpuCCModeType:                  "GpuCCMode",
+	AddedCapabilityType:            "AddedCapability",
+	CgroupNamespaceType:            "CgroupNamespace",
+	MountType:                      "Mount",
+	LogRedirectType:                "LogRedirect",
+	ImpersonatedServiceAccountType: "ImpersonatedServiceAccount",
+	HardenedImageType:              "HardenedImage",
+	SidecarType:                    "Sidecar",
+	InitContainerType:              "InitContainer",
+	ShutdownType:                   "Shutdown",
+	LocalVerifierKeyType:           "LocalVerifierKey",
+	AttestationAccessType:          "AttestationAccess",
+}
+
+func (t CosType) String() string {
+	if name, ok := cosTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("CosType(%d)", uint8(t))
+}
+
 // CosTlv is a specific event type created for the COS (Google Container-Optimized OS),
 // used as a CEL content.
 type CosTlv struct {
@@ -138,3 +201,61 @@ func ParseEnvVar(envvar string) (string, string, error) {
 
 	return e[0], e[1], nil
 }
+
+// Keys of the ContainerMount fields in a MountType event.
+const (
+	mountTypeKey        = "type"
+	mountSourceKey      = "source"
+	mountDestinationKey = "destination"
+)
+
+// ContainerMount is a mount added to the container, as measured in a
+// MountType event.
+type ContainerMount struct {
+	Type        string
+	Source      string
+	Destination string
+	// The mount options, either flags (e.g., nosuid) or key-values (e.g., size=1024).
+	Options []string
+}
+
+// Format returns the MountType event content of the mount: the comma-separated
+// type, source and destination key-values, followed by the mount options
+// (e.g., "type=tmpfs,source=tmpfs,destination=/tmp,nosuid,size=1024").
+func (m ContainerMount) Format() (string, error) {
+	items := append([]string{mountTypeKey + "=" + m.Type, mountSourceKey + "=" + m.Source, mountDestinationKey + "=" + m.Destination}, m.Options...)
+	for _, item := range items {
+		if !utf8.ValidString(item) {
+			return "", fmt.Errorf("malformed mount, contains non-utf8 character: [%s]", item)
+		}
+		if strings.Contains(item, ",") {
+			return "", fmt.Errorf("malformed mount, contains ',': [%s]", item)
+		}
+	}
+	if m.Type == "" || m.Destination == "" {
+		return "", fmt.Errorf("malformed mount, missing type or destination: %+v", m)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// ParseContainerMount parses the content of a MountType event, or returns an
+// error if it fails the validation check.
+func ParseContainerMount(mount string) (ContainerMount, error) {
+	items := strings.Split(mount, ",")
+	if len(items) < 3 {
+		return ContainerMount{}, fmt.Errorf("malformed mount, expect type, source and destination: [%s]", mount)
+	}
+	var values [3]string
+	for i, key := range []string{mountTypeKey, mountSourceKey, mountDestinationKey} {
+		value, ok := strings.CutPrefix(items[i], key+"=")
+		if !ok {
+			return ContainerMount{}, fmt.Errorf("malformed mount, expect %s at position %d: [%s]", key, i, mount)
+		}
+		values[i] = value
+	}
+	m := ContainerMount{Type: values[0], Source: values[1], Destination: values[2], Options: items[3:]}
+	if _, err := m.Format(); err != nil {
+		return ContainerMount{}, err
+	}
+	return m, nil
+}
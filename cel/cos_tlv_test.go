@@ -126,3 +126,46 @@ func TestParseEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParseContainerMount(t *testing.T) {
+	tests := []struct {
+		testName             string
+		mount                string
+		want                 ContainerMount
+		expectedErrSubstring string
+	}{
+		{"tmpfs", "type=tmpfs,source=tmpfs,destination=/tmpmount", ContainerMount{"tmpfs", "tmpfs", "/tmpmount", []string{}}, ""},
+		{"options", "type=tmpfs,source=tmpfs,destination=/tmp,nosuid,size=1024", ContainerMount{"tmpfs", "tmpfs", "/tmp", []string{"nosuid", "size=1024"}}, ""},
+		{"empty source", "type=bind,source=,destination=/data", ContainerMount{"bind", "", "/data", []string{}}, ""},
+		{"wrong order", "source=tmpfs,type=tmpfs,destination=/tmp", ContainerMount{}, "expect type at position 0"},
+		{"missing destination", "type=tmpfs,source=tmpfs", ContainerMount{}, "expect type, source and destination"},
+		{"empty destination", "type=tmpfs,source=tmpfs,destination=", ContainerMount{}, "missing type or destination"},
+		{"non utf-8", string([]byte{'t', 'y', 'p', 'e', '=', 0xC0, ',', 's', 'o', 'u', 'r', 'c', 'e', '=', ',', 'd', 'e', 's', 't', 'i', 'n', 'a', 't', 'i', 'o', 'n', '=', '/'}), ContainerMount{}, "contains non-utf8 character"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			got, err := ParseContainerMount(test.mount)
+			if test.expectedErrSubstring != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErrSubstring) {
+					t.Errorf("expected error substring [%s], but got [%v]", test.expectedErrSubstring, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got [%s]", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("ParseContainerMount() got %+v, want %+v", got, test.want)
+			}
+			formatted, err := got.Format()
+			if err != nil || formatted != test.mount {
+				t.Errorf("Format() got [%s], err = %v, want [%s]", formatted, err, test.mount)
+			}
+		})
+	}
+
+	if _, err := (ContainerMount{Type: "tmpfs", Destination: "/a,b"}).Format(); err == nil {
+		t.Error("Format() of a destination containing ',' got nil error, want error")
+	}
+}
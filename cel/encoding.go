@@ -0,0 +1,622 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal/cbor"
+)
+
+// This file implements the JSON and CBOR encodings of a CEL, defined next to
+// the TLV encoding in the CEL spec. Both encode the same Record type, so a
+// decoded CEL can be replayed and verified regardless of its encoding.
+
+// Content type names used in the "content_type" field of CEL-JSON and
+// CEL-CBOR, from the CEL spec. COS events are not defined by the spec, so
+// their content type name is namespaced by vendor.
+const (
+	celMgtContentType      = "cel"
+	pcClientStdContentType = "pcclient_std"
+	imaTemplateContentType = "ima_template"
+	cosContentType         = "com.google.cos"
+)
+
+// Hash algorithm names used in the "hashAlg" field of CEL-JSON and CEL-CBOR.
+var jsonHashAlgs = map[crypto.Hash]string{
+	crypto.SHA1:   "sha1",
+	crypto.SHA256: "sha256",
+	crypto.SHA384: "sha384",
+	crypto.SHA512: "sha512",
+}
+
+// sortedHashes returns the hash algorithms of the digests, in the order of
+// their TPM algorithm IDs.
+func sortedHashes(digests map[crypto.Hash][]byte) ([]crypto.Hash, error) {
+	hashes := make([]crypto.Hash, 0, len(digests))
+	for hash, digest := range digests {
+		if len(digest) != hash.Size() {
+			return nil, fmt.Errorf("digest length [%d] doesn't match the expected length [%d] for the hash algorithm",
+				len(digest), hash.Size())
+		}
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return hashes, nil
+}
+
+// hexBytes is a byte string, which CEL-JSON encodes as a hex string.
+type hexBytes []byte
+
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+type celDigest struct {
+	HashAlg string   `json:"hashAlg"`
+	Digest  hexBytes `json:"digest"`
+}
+
+// celRecord is a record in the data model shared by CEL-JSON and CEL-CBOR.
+// The fields of its content are unsigned integers, strings, hexBytes, nested
+// content fields or nil.
+type celRecord struct {
+	RecNum uint64  `json:"recnum"`
+	PCR    *uint64 `json:"pcr,omitempty"`
+	// CCMR indexes are not part of the CEL spec, as in the TLV encoding.
+	CCMR        *uint64        `json:"ccmr,omitempty"`
+	Digests     []celDigest    `json:"digests"`
+	ContentType string         `json:"content_type"`
+	Content     map[string]any `json:"content"`
+}
+
+func newCelRecord(r Record) (celRecord, error) {
+	index := uint64(r.Index)
+	record := celRecord{RecNum: r.RecNum, Digests: []celDigest{}}
+	switch r.IndexType {
+	case PCRTypeValue:
+		record.PCR = &index
+	case CCMRTypeValue:
+		record.CCMR = &index
+	default:
+		return celRecord{}, fmt.Errorf("unsupported register index type [%d]", r.IndexType)
+	}
+
+	hashes, err := sortedHashes(r.Digests)
+	if err != nil {
+		return celRecord{}, err
+	}
+	for _, hash := range hashes {
+		name, ok := jsonHashAlgs[hash]
+		if !ok {
+			return celRecord{}, fmt.Errorf("unsupported hash algorithm %v", hash)
+		}
+		record.Digests = append(record.Digests, celDigest{name, r.Digests[hash]})
+	}
+
+	if record.ContentType, record.Content, err = contentToFields(r.Content); err != nil {
+		return celRecord{}, err
+	}
+	return record, nil
+}
+
+// cborValue returns the CBOR data item of a celRecord or its content.
+func (record celRecord) cborValue() any {
+	digests := make([]any, 0, len(record.Digests))
+	for _, d := range record.Digests {
+		digests = append(digests, map[any]any{"hashAlg": d.HashAlg, "digest": []byte(d.Digest)})
+	}
+	m := map[any]any{
+		"recnum":       record.RecNum,
+		"digests":      digests,
+		"content_type": record.ContentType,
+		"content":      contentCBORValue(record.Content),
+	}
+	if record.PCR != nil {
+		m["pcr"] = *record.PCR
+	}
+	if record.CCMR != nil {
+		m["ccmr"] = *record.CCMR
+	}
+	return m
+}
+
+func contentCBORValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[any]any, len(v))
+		for key, field := range v {
+			m[key] = contentCBORValue(field)
+		}
+		return m
+	case hexBytes:
+		return []byte(v)
+	}
+	return value
+}
+
+// contentToFields returns the content type name and the content fields of a
+// content TLV, following the CEL-JSON encoding of the CEL spec.
+func contentToFields(t TLV) (string, map[string]any, error) {
+	switch t.Type {
+	case CelMgtType:
+		c, err := t.ParseToCelMgt()
+		if err != nil {
+			return "", nil, err
+		}
+		content, err := celMgtToFields(c)
+		return celMgtContentType, content, err
+	case PCClientStdType:
+		c, err := t.ParseToPCClientStd()
+		if err != nil {
+			return "", nil, err
+		}
+		return pcClientStdContentType, map[string]any{
+			"event_type": uint64(c.EventType),
+			"event_data": hexBytes(c.EventData),
+		}, nil
+	case ImaTemplateType:
+		c, err := t.ParseToImaTemplate()
+		if err != nil {
+			return "", nil, err
+		}
+		return imaTemplateContentType, map[string]any{
+			"template_name": c.TemplateName,
+			"template_data": hexBytes(c.TemplateData),
+		}, nil
+	case CosEventType:
+		c, err := t.ParseToCosTlv()
+		if err != nil {
+			return "", nil, err
+		}
+		return cosContentType, map[string]any{
+			"event_type":    uint64(c.EventType),
+			"event_content": hexBytes(c.EventContent),
+		}, nil
+	}
+	return "", nil, fmt.Errorf("content type [%d] has no CEL-JSON or CEL-CBOR encoding", t.Type)
+}
+
+// celMgtToFields returns the content fields of a CEL management event: a
+// single field named by the event type.
+func celMgtToFields(c CelMgt) (map[string]any, error) {
+	var value any
+	switch c.EventType {
+	case CelVersionType:
+		major, minor, err := c.CelVersion()
+		if err != nil {
+			return nil, err
+		}
+		value = map[string]any{"major": uint64(major), "minor": uint64(minor)}
+	case FirmwareEndType:
+		if len(c.EventContent) != 0 {
+			return nil, fmt.Errorf("firmware_end has content")
+		}
+	case TransferChangeType:
+		agent, err := c.TransferChange()
+		if err != nil {
+			return nil, err
+		}
+		value = agent
+	case CelTimestampType:
+		ts, err := c.CelTimestamp()
+		if err != nil {
+			return nil, err
+		}
+		value = uint64(ts.UnixNano())
+	case StateTransType:
+		transition, err := c.StateTrans()
+		if err != nil {
+			return nil, err
+		}
+		value = transition.String()
+	default:
+		return nil, fmt.Errorf("unsupported CEL management event %v", c.EventType)
+	}
+	return map[string]any{c.EventType.String(): value}, nil
+}
+
+// EncodeJSON encodes the CEL as a CEL-JSON array of records, and writes it to
+// the bytes buffer.
+func (c *CEL) EncodeJSON(buf *bytes.Buffer) error {
+	records := make([]celRecord, 0, len(c.Records))
+	for i, r := range c.Records {
+		record, err := newCelRecord(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode record #%d: %v", i, err)
+		}
+		records = append(records, record)
+	}
+	out, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	_, err = buf.Write(out)
+	return err
+}
+
+// DecodeJSON reads a CEL-JSON array of records from the buffer. A JSON-SEQ
+// (RFC 7464) or newline-separated stream of records is also accepted.
+func DecodeJSON(buf *bytes.Buffer) (CEL, error) {
+	limits := DefaultDecodeLimits()
+	if int64(buf.Len()) > limits.MaxTotalSize {
+		return CEL{}, fmt.Errorf("CEL exceeds the total size limit [%d]", limits.MaxTotalSize)
+	}
+	data := bytes.TrimSpace(buf.Next(buf.Len()))
+	var items []any
+	if bytes.HasPrefix(data, []byte("[")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&items); err != nil {
+			return CEL{}, fmt.Errorf("failed to decode CEL-JSON: %v", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(bytes.ReplaceAll(data, []byte{0x1e}, []byte{' '})))
+		decoder.UseNumber()
+		for {
+			var item any
+			if err := decoder.Decode(&item); err == io.EOF {
+				break
+			} else if err != nil {
+				return CEL{}, fmt.Errorf("failed to decode CEL-JSON record #%d: %v", len(items), err)
+			}
+			items = append(items, item)
+		}
+	}
+	if len(items) > limits.MaxRecords {
+		return CEL{}, fmt.Errorf("CEL has more than the limit of %d records", limits.MaxRecords)
+	}
+
+	var cel CEL
+	for i, item := range items {
+		r, err := recordFromItem(item, true)
+		if err != nil {
+			return CEL{}, fmt.Errorf("invalid CEL-JSON record #%d: %v", i, err)
+		}
+		cel.Records = append(cel.Records, r)
+	}
+	return cel, nil
+}
+
+// EncodeCBOR encodes the CEL as a CEL-CBOR array of records, and writes it to
+// the bytes buffer. Records have the same fields as in CEL-JSON, with byte
+// strings in place of hex strings.
+func (c *CEL) EncodeCBOR(buf *bytes.Buffer) error {
+	records := make([]any, 0, len(c.Records))
+	for i, r := range c.Records {
+		record, err := newCelRecord(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode record #%d: %v", i, err)
+		}
+		records = append(records, record.cborValue())
+	}
+	out, err := cbor.Encode(records)
+	if err != nil {
+		return err
+	}
+	_, err = buf.Write(out)
+	return err
+}
+
+// DecodeCBOR reads a CEL-CBOR array of records from the buffer.
+func DecodeCBOR(buf *bytes.Buffer) (CEL, error) {
+	limits := DefaultDecodeLimits()
+	if int64(buf.Len()) > limits.MaxTotalSize {
+		return CEL{}, fmt.Errorf("CEL exceeds the total size limit [%d]", limits.MaxTotalSize)
+	}
+	item, err := cbor.Decode(buf.Next(buf.Len()))
+	if err != nil {
+		return CEL{}, fmt.Errorf("failed to decode CEL-CBOR: %v", err)
+	}
+	items, ok := item.([]any)
+	if !ok {
+		return CEL{}, fmt.Errorf("CEL-CBOR is not an array of records")
+	}
+	if len(items) > limits.MaxRecords {
+		return CEL{}, fmt.Errorf("CEL has more than the limit of %d records", limits.MaxRecords)
+	}
+	var cel CEL
+	for i, item := range items {
+		r, err := recordFromItem(item, false)
+		if err != nil {
+			return CEL{}, fmt.Errorf("invalid CEL-CBOR record #%d: %v", i, err)
+		}
+		cel.Records = append(cel.Records, r)
+	}
+	return cel, nil
+}
+
+// fields is a decoded CEL-JSON or CEL-CBOR map, such as a record or its
+// content.
+type fields struct {
+	m map[string]any
+	// isJSON is set for CEL-JSON, whose numbers are json.Numbers and whose
+	// byte strings are hex strings.
+	isJSON bool
+}
+
+func newFields(item any, isJSON bool) (fields, error) {
+	switch m := item.(type) {
+	case map[string]any:
+		return fields{m, isJSON}, nil
+	case map[any]any:
+		f := fields{make(map[string]any, len(m)), isJSON}
+		for key, value := range m {
+			name, ok := key.(string)
+			if !ok {
+				return fields{}, fmt.Errorf("field name %v is not a string", key)
+			}
+			f.m[name] = value
+		}
+		return f, nil
+	}
+	return fields{}, fmt.Errorf("not a map")
+}
+
+func (f fields) has(name string) bool {
+	_, ok := f.m[name]
+	return ok
+}
+
+func (f fields) get(name string) (any, error) {
+	value, ok := f.m[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	return value, nil
+}
+
+func (f fields) uint(name string, max uint64) (uint64, error) {
+	value, err := f.get(name)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch v := value.(type) {
+	case json.Number:
+		if n, err = strconv.ParseUint(v.String(), 10, 64); err != nil {
+			return 0, fmt.Errorf("%s is not an unsigned integer", name)
+		}
+	case uint64:
+		n = v
+	default:
+		return 0, fmt.Errorf("%s is not an unsigned integer", name)
+	}
+	if n > max {
+		return 0, fmt.Errorf("%s [%d] exceeds the maximum [%d]", name, n, max)
+	}
+	return n, nil
+}
+
+func (f fields) string(name string) (string, error) {
+	value, err := f.get(name)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s is not a string", name)
+	}
+	return s, nil
+}
+
+func (f fields) bytes(name string) ([]byte, error) {
+	value, err := f.get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.isJSON {
+		b, ok := value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a byte string", name)
+		}
+		return b, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a hex string", name)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return b, nil
+}
+
+func (f fields) fields(name string) (fields, error) {
+	value, err := f.get(name)
+	if err != nil {
+		return fields{}, err
+	}
+	nested, err := newFields(value, f.isJSON)
+	if err != nil {
+		return fields{}, fmt.Errorf("%s is %v", name, err)
+	}
+	return nested, nil
+}
+
+func recordFromItem(item any, isJSON bool) (Record, error) {
+	f, err := newFields(item, isJSON)
+	if err != nil {
+		return Record{}, fmt.Errorf("record is %v", err)
+	}
+	var r Record
+	if r.RecNum, err = f.uint("recnum", math.MaxUint64); err != nil {
+		return Record{}, err
+	}
+	var index uint64
+	switch {
+	case f.has("pcr") && !f.has("ccmr"):
+		r.IndexType = PCRTypeValue
+		index, err = f.uint("pcr", math.MaxUint8)
+	case f.has("ccmr") && !f.has("pcr"):
+		r.IndexType = CCMRTypeValue
+		index, err = f.uint("ccmr", math.MaxUint8)
+	default:
+		return Record{}, fmt.Errorf("record must have exactly one of pcr or ccmr")
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	r.Index = uint8(index)
+
+	value, err := f.get("digests")
+	if err != nil {
+		return Record{}, err
+	}
+	digests, ok := value.([]any)
+	if !ok {
+		return Record{}, fmt.Errorf("digests is not an array")
+	}
+	r.Digests = make(map[crypto.Hash][]byte, len(digests))
+	for _, item := range digests {
+		d, err := newFields(item, isJSON)
+		if err != nil {
+			return Record{}, fmt.Errorf("digest is %v", err)
+		}
+		name, err := d.string("hashAlg")
+		if err != nil {
+			return Record{}, err
+		}
+		hash, ok := hashFromJSONName(name)
+		if !ok {
+			return Record{}, fmt.Errorf("unsupported hash algorithm %q", name)
+		}
+		digest, err := d.bytes("digest")
+		if err != nil {
+			return Record{}, err
+		}
+		if len(digest) != hash.Size() {
+			return Record{}, fmt.Errorf("digest length [%d] doesn't match the expected length [%d] for %v", len(digest), hash.Size(), hash)
+		}
+		r.Digests[hash] = digest
+	}
+
+	contentType, err := f.string("content_type")
+	if err != nil {
+		return Record{}, err
+	}
+	content, err := f.fields("content")
+	if err != nil {
+		return Record{}, err
+	}
+	if r.Content, err = contentFromFields(contentType, content); err != nil {
+		return Record{}, fmt.Errorf("invalid %s content: %v", contentType, err)
+	}
+	return r, nil
+}
+
+func contentFromFields(contentType string, f fields) (TLV, error) {
+	var content Content
+	switch contentType {
+	case celMgtContentType:
+		c, err := celMgtFromFields(f)
+		if err != nil {
+			return TLV{}, err
+		}
+		content = c
+	case pcClientStdContentType:
+		eventType, err := f.uint("event_type", math.MaxUint32)
+		if err != nil {
+			return TLV{}, err
+		}
+		eventData, err := f.bytes("event_data")
+		if err != nil {
+			return TLV{}, err
+		}
+		content = PCClientStd{uint32(eventType), eventData}
+	case imaTemplateContentType:
+		templateName, err := f.string("template_name")
+		if err != nil {
+			return TLV{}, err
+		}
+		templateData, err := f.bytes("template_data")
+		if err != nil {
+			return TLV{}, err
+		}
+		content = ImaTemplate{templateName, templateData}
+	case cosContentType:
+		eventType, err := f.uint("event_type", math.MaxUint8)
+		if err != nil {
+			return TLV{}, err
+		}
+		eventContent, err := f.bytes("event_content")
+		if err != nil {
+			return TLV{}, err
+		}
+		content = CosTlv{CosType(eventType), eventContent}
+	default:
+		return TLV{}, fmt.Errorf("unsupported content type")
+	}
+	return content.GetTLV()
+}
+
+func celMgtFromFields(f fields) (CelMgt, error) {
+	if len(f.m) != 1 {
+		return CelMgt{}, fmt.Errorf("content must have exactly one CEL management event")
+	}
+	for name, value := range f.m {
+		switch name {
+		case CelVersionType.String():
+			version, err := f.fields(name)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			major, err := version.uint("major", math.MaxUint32)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			minor, err := version.uint("minor", math.MaxUint32)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			return NewCelVersion(uint32(major), uint32(minor))
+		case FirmwareEndType.String():
+			if value != nil {
+				return CelMgt{}, fmt.Errorf("%s is not null", name)
+			}
+			return NewFirmwareEnd(), nil
+		case TransferChangeType.String():
+			agent, err := f.string(name)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			return NewTransferChange(agent), nil
+		case CelTimestampType.String():
+			nanos, err := f.uint(name, math.MaxInt64)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			return NewCelTimestamp(time.Unix(0, int64(nanos))), nil
+		case StateTransType.String():
+			transition, err := f.string(name)
+			if err != nil {
+				return CelMgt{}, err
+			}
+			for t := Suspend; t <= Kexec; t++ {
+				if t.String() == transition {
+					return NewStateTrans(t), nil
+				}
+			}
+			return CelMgt{}, fmt.Errorf("unsupported state transition %q", transition)
+		}
+		return CelMgt{}, fmt.Errorf("unsupported CEL management event %q", name)
+	}
+	return CelMgt{}, nil
+}
+
+func hashFromJSONName(name string) (crypto.Hash, bool) {
+	for hash, hashName := range jsonHashAlgs {
+		if hashName == name {
+			return hash, true
+		}
+	}
+	return 0, false
+}
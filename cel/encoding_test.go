@@ -0,0 +1,220 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-configfs-tsm/configfs/fakertmr"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestJSONAndCBORRoundTrip(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	fakeRTMR := fakertmr.CreateRtmrSubsystem(t.TempDir())
+
+	cel := &CEL{}
+	celRTMR := &CEL{}
+	events := []CosTlv{
+		{ImageRefType, []byte("docker.io/bazel/experimental/test:latest")},
+		{EnvVarType, []byte("foo=bar")},
+		{LaunchSeparatorType, []byte{}},
+	}
+	for _, event := range events {
+		appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, event)
+		appendRtmrEventOrFatal(t, celRTMR, fakeRTMR, CosRTMR, event)
+	}
+	for _, encoding := range []struct {
+		name   string
+		encode func(*CEL, *bytes.Buffer) error
+		decode func(*bytes.Buffer) (CEL, error)
+	}{
+		{"JSON", (*CEL).EncodeJSON, DecodeJSON},
+		{"CBOR", (*CEL).EncodeCBOR, DecodeCBOR},
+	} {
+		t.Run(encoding.name, func(t *testing.T) {
+			for _, c := range []*CEL{cel, celRTMR} {
+				var buf bytes.Buffer
+				if err := encoding.encode(c, &buf); err != nil {
+					t.Fatalf("failed to encode CEL: %v", err)
+				}
+				decoded, err := encoding.decode(&buf)
+				if err != nil {
+					t.Fatalf("failed to decode CEL: %v", err)
+				}
+				if !reflect.DeepEqual(decoded.Records, c.Records) {
+					t.Errorf("decoded CEL doesn't equal to the original one:\ngot  %+v\nwant %+v", decoded.Records, c.Records)
+				}
+			}
+
+			var buf bytes.Buffer
+			if err := encoding.encode(cel, &buf); err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := encoding.decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			replay(t, &decoded, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+			for _, record := range decoded.Records {
+				cosTlv, err := record.Content.ParseToCosTlv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := VerifyDigests(cosTlv, record.Digests); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	// A JSON-SEQ stream, with records written by hand.
+	log := "\x1e" + `{"recnum":0,"pcr":13,"digests":[{"hashAlg":"sha256","digest":"` + strings.Repeat("ab", 32) + `"}],` +
+		`"content_type":"com.google.cos","content":{"event_type":0,"event_content":"696d616765"}}` + "\n" +
+		"\x1e" + `{"recnum":1,"ccmr":4,"digests":[{"hashAlg":"sha384","digest":"` + strings.Repeat("cd", 48) + `"}],` +
+		`"content_type":"cel","content":{"state_trans":"kexec"}}` + "\n"
+	decoded, err := DecodeJSON(bytes.NewBufferString(log))
+	if err != nil {
+		t.Fatalf("DecodeJSON() failed: %v", err)
+	}
+	imageRef, _ := CosTlv{ImageRefType, []byte("image")}.GetTLV()
+	kexec, _ := NewStateTrans(Kexec).GetTLV()
+	want := []Record{
+		{
+			RecNum:    0,
+			Index:     13,
+			IndexType: PCRTypeValue,
+			Digests:   map[crypto.Hash][]byte{crypto.SHA256: bytes.Repeat([]byte{0xab}, 32)},
+			Content:   imageRef,
+		},
+		{
+			RecNum:    1,
+			Index:     4,
+			IndexType: CCMRTypeValue,
+			Digests:   map[crypto.Hash][]byte{crypto.SHA384: bytes.Repeat([]byte{0xcd}, 48)},
+			Content:   kexec,
+		},
+	}
+	if !reflect.DeepEqual(decoded.Records, want) {
+		t.Errorf("DecodeJSON() got %+v, want %+v", decoded.Records, want)
+	}
+}
+
+func TestDecodeJSONFailures(t *testing.T) {
+	digest := `[{"hashAlg":"sha256","digest":"` + strings.Repeat("ab", 32) + `"}]`
+	content := `"content_type":"com.google.cos","content":{"event_type":0,"event_content":""}`
+	for _, tc := range []struct {
+		name string
+		log  string
+	}{
+		{"NotJSON", "[{"},
+		{"NoIndex", `[{"recnum":0,"digests":` + digest + `,` + content + `}]`},
+		{"TwoIndexes", `[{"recnum":0,"pcr":1,"ccmr":1,"digests":` + digest + `,` + content + `}]`},
+		{"UnknownHash", `[{"recnum":0,"pcr":1,"digests":[{"hashAlg":"md5","digest":"00"}],` + content + `}]`},
+		{"ShortDigest", `[{"recnum":0,"pcr":1,"digests":[{"hashAlg":"sha256","digest":"00"}],` + content + `}]`},
+		{"UnknownContentType", `[{"recnum":0,"pcr":1,"digests":` + digest + `,"content_type":"systemd","content":{}}]`},
+		{"Base64Content", `[{"recnum":0,"pcr":1,"digests":` + digest + `,"content_type":"com.google.cos","content":{"event_type":0,"event_content":"aW1hZ2U="}}]`},
+		{"NegativeEventType", `[{"recnum":0,"pcr":1,"digests":` + digest + `,"content_type":"pcclient_std","content":{"event_type":-1,"event_data":""}}]`},
+		{"TwoCelMgtEvents", `[{"recnum":0,"pcr":1,"digests":` + digest + `,"content_type":"cel","content":{"firmware_end":null,"state_trans":"kexec"}}]`},
+		{"UnknownStateTrans", `[{"recnum":0,"pcr":1,"digests":` + digest + `,"content_type":"cel","content":{"state_trans":"reboot"}}]`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DecodeJSON(bytes.NewBufferString(tc.log)); err == nil {
+				t.Error("DecodeJSON() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestDecodeCBORFailures(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		encoded []byte
+	}{
+		{"NotCBOR", []byte{0x83, 0x01}},
+		{"NotArray", []byte{0xa0}},
+		{"EmptyRecord", []byte{0x81, 0xa0}},
+		// [{0: 0, 1: 1, 3: {}}]: a record keyed by TLV types.
+		{"IntegerKeys", []byte{0x81, 0xa3, 0x00, 0x00, 0x01, 0x01, 0x03, 0xa0}},
+		// [{"recnum": 0, "pcr": "1"}]: a register index which is not an integer.
+		{"StringIndex", []byte{0x81, 0xa2, 0x66, 'r', 'e', 'c', 'n', 'u', 'm', 0x00, 0x63, 'p', 'c', 'r', 0x61, '1'}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := DecodeCBOR(bytes.NewBuffer(tc.encoded)); err == nil {
+				t.Error("DecodeCBOR() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestEncodeUnsupportedContent(t *testing.T) {
+	cel := &CEL{Records: []Record{{
+		RecNum:    0,
+		Index:     uint8(test.ApplicationPCR),
+		IndexType: PCRTypeValue,
+		Digests:   map[crypto.Hash][]byte{crypto.SHA256: make([]byte, crypto.SHA256.Size())},
+		Content:   TLV{Type: 200, Value: []byte{0, 1}},
+	}}}
+	var buf bytes.Buffer
+	if err := cel.EncodeJSON(&buf); err == nil {
+		t.Error("EncodeJSON() got nil error, want error")
+	}
+	if err := cel.EncodeCBOR(&buf); err == nil {
+		t.Error("EncodeCBOR() got nil error, want error")
+	}
+}
+
+// A CEL-JSON log in the format written by systemd-pcrlock, with the
+// separators and the EFI boot option action measured by firmware.
+const pcrlockCELJSON = `[
+{"recnum":0,"pcr":4,"digests":[{"hashAlg":"sha1","digest":"cd0fdb4531a6ec41be2753ba042637d6e5f7f256"},{"hashAlg":"sha256","digest":"3d6772b4f84ed47595d72a2c4c5ffd15f5bb72c7507fe26f2aaee2c69d5633ba"}],"content_type":"pcclient_std","content":{"event_type":2147483655,"event_data":"43616c6c696e6720454649204170706c69636174696f6e2066726f6d20426f6f74204f7074696f6e"}},
+{"recnum":1,"pcr":0,"digests":[{"hashAlg":"sha1","digest":"9069ca78e7450a285173431b3e52c5c25299e473"},{"hashAlg":"sha256","digest":"df3f619804a92fdb4057192dc43dd748ea778adc52bc498ce80524c014b81119"}],"content_type":"pcclient_std","content":{"event_type":4,"event_data":"00000000"}},
+{"recnum":2,"pcr":7,"digests":[{"hashAlg":"sha1","digest":"9069ca78e7450a285173431b3e52c5c25299e473"},{"hashAlg":"sha256","digest":"df3f619804a92fdb4057192dc43dd748ea778adc52bc498ce80524c014b81119"}],"content_type":"pcclient_std","content":{"event_type":4,"event_data":"00000000"}}
+]`
+
+func TestDecodePcrlockJSON(t *testing.T) {
+	decoded, err := DecodeJSON(bytes.NewBufferString(pcrlockCELJSON))
+	if err != nil {
+		t.Fatalf("DecodeJSON() failed: %v", err)
+	}
+	for i, wantIndex := range []uint8{4, 0, 7} {
+		record := decoded.Records[i]
+		if record.RecNum != uint64(i) || record.IndexType != PCRTypeValue || record.Index != wantIndex {
+			t.Errorf("record #%d got recnum %d, PCR %d, want recnum %d, PCR %d", i, record.RecNum, record.Index, i, wantIndex)
+		}
+		content, err := record.Content.ParseToPCClientStd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyDigests(content, record.Digests); err != nil {
+			t.Errorf("record #%d: %v", i, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := decoded.EncodeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got, want any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(pcrlockCELJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeJSON() got %s, want %s", buf.String(), pcrlockCELJSON)
+	}
+}
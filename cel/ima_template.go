@@ -0,0 +1,55 @@
+package cel
+
+import (
+	"crypto"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Nested TLV types of ImaTemplate content.
+const (
+	imaTemplateNameTLV uint8 = 0
+	imaTemplateDataTLV uint8 = 1
+)
+
+// ImaTemplate is a Linux IMA measurement list entry, used as a CEL content.
+type ImaTemplate struct {
+	// The template name, such as "ima-ng" or "ima-sig".
+	TemplateName string
+	// The template data, as in the binary runtime measurements list.
+	TemplateData []byte
+}
+
+// GetTLV returns the TLV representation of the IMA template entry.
+func (i ImaTemplate) GetTLV() (TLV, error) {
+	data, err := marshalNestedTLVs(
+		TLV{imaTemplateNameTLV, []byte(i.TemplateName)},
+		TLV{imaTemplateDataTLV, i.TemplateData},
+	)
+	if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: ImaTemplateType, Value: data}, nil
+}
+
+// GenerateDigest returns the IMA template hash, which is the digest of the
+// template data.
+func (i ImaTemplate) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	return hashBytes(hashAlgo, i.TemplateData)
+}
+
+// ParseToImaTemplate constructs an ImaTemplate from a TLV. It will check for
+// the correct content type, and unmarshal the nested template name and data.
+func (t TLV) ParseToImaTemplate() (ImaTemplate, error) {
+	if t.Type != ImaTemplateType {
+		return ImaTemplate{}, fmt.Errorf("TLV type %v is not an IMA template entry", t.Type)
+	}
+	nested, err := unmarshalNestedTLVs(t.Value, imaTemplateNameTLV, imaTemplateDataTLV)
+	if err != nil {
+		return ImaTemplate{}, fmt.Errorf("invalid IMA template entry: %v", err)
+	}
+	if !utf8.Valid(nested[0].Value) {
+		return ImaTemplate{}, fmt.Errorf("IMA template name is not valid UTF-8")
+	}
+	return ImaTemplate{TemplateName: string(nested[0].Value), TemplateData: nested[1].Value}, nil
+}
@@ -0,0 +1,354 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/google/go-eventlog/register"
+)
+
+// The journal starts with a header of journalMagic followed by the big-endian
+// journalVersion. Each record follows as a frame: the big-endian uint32 length
+// of the encoded record, the big-endian uint32 CRC-32C of the encoded record,
+// then the record itself as encoded by EncodeCELR.
+const (
+	journalMagic       = "CELJ"
+	journalVersion     = uint32(1)
+	journalHeaderSize  = 8
+	journalFrameHeader = 8
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Journal is an append-only on-disk copy of a CEL, which lets a measurement
+// agent recover its CEL after a restart. Every record is checksummed and
+// synced to disk before it is measured, so the journal never lacks a record
+// whose digest was extended into a register.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	// ends[i] is the file offset at the end of record i.
+	ends []int64
+}
+
+// OpenJournal opens the journal at path, creating it if it does not exist,
+// and returns it with the CEL it holds. A record torn by a crash while it was
+// being written can only be the last one, and is removed from the journal.
+// Any other damage to the journal is returned as an error.
+func OpenJournal(path string, limits DecodeLimits) (*Journal, CEL, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, CEL{}, fmt.Errorf("failed to open CEL journal: %v", err)
+	}
+	j := &Journal{file: file}
+	cel, err := j.load(limits)
+	if err != nil {
+		file.Close()
+		return nil, CEL{}, fmt.Errorf("failed to load CEL journal %v: %v", path, err)
+	}
+	if len(cel.Records) == 0 {
+		// Make sure a newly created journal survives a crash.
+		if err := syncDir(filepath.Dir(path)); err != nil {
+			file.Close()
+			return nil, CEL{}, err
+		}
+	}
+	return j, cel, nil
+}
+
+func (j *Journal) load(limits DecodeLimits) (CEL, error) {
+	info, err := j.file.Stat()
+	if err != nil {
+		return CEL{}, err
+	}
+	size := info.Size()
+	if size < journalHeaderSize {
+		// A new journal, or a crash before the header was synced.
+		return CEL{}, j.writeHeader()
+	}
+	header := make([]byte, journalHeaderSize)
+	if _, err := io.ReadFull(j.file, header); err != nil {
+		return CEL{}, err
+	}
+	if string(header[:len(journalMagic)]) != journalMagic {
+		return CEL{}, errors.New("not a CEL journal")
+	}
+	if version := binary.BigEndian.Uint32(header[len(journalMagic):]); version != journalVersion {
+		return CEL{}, fmt.Errorf("unsupported CEL journal version [%d]", version)
+	}
+
+	var cel CEL
+	offset := int64(journalHeaderSize)
+	reader := io.NewSectionReader(j.file, offset, size-offset)
+	for offset < size {
+		if limits.MaxRecords > 0 && len(cel.Records) >= limits.MaxRecords {
+			return CEL{}, fmt.Errorf("CEL journal exceeds the record limit [%d]", limits.MaxRecords)
+		}
+		frame := make([]byte, journalFrameHeader)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return cel, j.truncateTorn(offset)
+		}
+		length := binary.BigEndian.Uint32(frame[:4])
+		if limits.MaxRecordSize > 0 && int64(length) > int64(limits.MaxRecordSize) {
+			return CEL{}, fmt.Errorf("CEL journal record [%d] exceeds the record size limit [%d]", len(cel.Records), limits.MaxRecordSize)
+		}
+		end := offset + journalFrameHeader + int64(length)
+		if end > size {
+			return cel, j.truncateTorn(offset)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return CEL{}, err
+		}
+		if crc32.Checksum(data, castagnoli) != binary.BigEndian.Uint32(frame[4:]) {
+			if end == size {
+				return cel, j.truncateTorn(offset)
+			}
+			return CEL{}, fmt.Errorf("CEL journal record [%d] has a bad checksum", len(cel.Records))
+		}
+		record, err := decodeRecord(bytes.NewReader(data), int(length))
+		if err != nil {
+			return CEL{}, fmt.Errorf("failed to decode CEL journal record [%d]: %v", len(cel.Records), err)
+		}
+		if record.RecNum != uint64(len(cel.Records)) {
+			return CEL{}, fmt.Errorf("CEL journal record [%d] has record number [%d]", len(cel.Records), record.RecNum)
+		}
+		cel.Records = append(cel.Records, record)
+		j.ends = append(j.ends, end)
+		offset = end
+	}
+	if _, err := j.file.Seek(offset, io.SeekStart); err != nil {
+		return CEL{}, err
+	}
+	return cel, nil
+}
+
+func (j *Journal) writeHeader() error {
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	header := binary.BigEndian.AppendUint32([]byte(journalMagic), journalVersion)
+	if _, err := j.file.WriteAt(header, 0); err != nil {
+		return err
+	}
+	if _, err := j.file.Seek(journalHeaderSize, io.SeekStart); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// truncateTorn removes everything from offset on (e.g., a partially written
+// record), and syncs the journal.
+func (j *Journal) truncateTorn(offset int64) error {
+	if err := j.file.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := j.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Append writes the record to the end of the journal, and syncs it to disk.
+func (j *Journal) Append(r Record) error {
+	var buf bytes.Buffer
+	if err := r.EncodeCELR(&buf); err != nil {
+		return err
+	}
+	frame := binary.BigEndian.AppendUint32(nil, uint32(buf.Len()))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.Checksum(buf.Bytes(), castagnoli))
+	frame = append(frame, buf.Bytes()...)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if r.RecNum != uint64(len(j.ends)) {
+		return fmt.Errorf("CEL journal has %d records, cannot append record [%d]", len(j.ends), r.RecNum)
+	}
+	offset := int64(journalHeaderSize)
+	if len(j.ends) > 0 {
+		offset = j.ends[len(j.ends)-1]
+	}
+	_, err := j.file.Write(frame)
+	if err == nil {
+		err = j.file.Sync()
+	}
+	if err != nil {
+		// Drop what was written of the frame, so the next append follows the
+		// last complete record.
+		j.truncateTorn(offset)
+		return fmt.Errorf("failed to write CEL journal record [%d]: %v", r.RecNum, err)
+	}
+	j.ends = append(j.ends, offset+int64(len(frame)))
+	return nil
+}
+
+// Truncate removes all but the first n records from the journal.
+func (j *Journal) Truncate(n int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if n < 0 || n > len(j.ends) {
+		return fmt.Errorf("cannot truncate CEL journal of %d records to %d records", len(j.ends), n)
+	}
+	if n == len(j.ends) {
+		return nil
+	}
+	offset := int64(journalHeaderSize)
+	if n > 0 {
+		offset = j.ends[n-1]
+	}
+	if err := j.truncateTorn(offset); err != nil {
+		return fmt.Errorf("failed to truncate CEL journal: %v", err)
+	}
+	j.ends = j.ends[:n]
+	return nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// UseJournal makes the CEL write ahead each appended record to the journal,
+// before its digest is extended into a register. The journal must hold the
+// records of the CEL, as returned by OpenJournal.
+func (c *CEL) UseJournal(j *Journal) {
+	c.journal = j
+}
+
+// ResumeJournal checks the CEL loaded from a journal against the current
+// values of the registers, given in one bank for each hash algorithm the
+// records were extended with. As records are journaled before they are
+// extended, the last record may never have been extended if the agent
+// stopped in between: such a record is removed from the CEL and the journal.
+//
+// A PCR record is extended into its banks with one command per bank, so an
+// agent stopping in between leaves the last record extended into only some
+// of the banks. The banks then replay different CELs, and extending the
+// missing digests later cannot be told apart from extending them outside the
+// CEL, so the journal cannot be resumed and an error is returned.
+func (c *CEL) ResumeJournal(banks ...register.MRBank) error {
+	hashes, err := c.measuredHashes()
+	if err != nil {
+		return err
+	}
+	last := len(c.Records) - 1
+	var extended, unextended []crypto.Hash
+	for _, hash := range hashes {
+		bank, err := bankForHash(banks, hash)
+		if err != nil {
+			return err
+		}
+		report, err := c.ReplayReport(bank)
+		if err != nil {
+			return err
+		}
+		lastExtended, err := lastRecordExtended(report, last)
+		if err != nil {
+			return err
+		}
+		if lastExtended {
+			extended = append(extended, hash)
+		} else {
+			unextended = append(unextended, hash)
+		}
+	}
+	if len(unextended) == 0 {
+		return nil
+	}
+	if len(extended) != 0 {
+		return fmt.Errorf("CEL journal record [%d] was extended into the %v banks but not into the %v banks", last, extended, unextended)
+	}
+	if c.journal != nil {
+		if err := c.journal.Truncate(last); err != nil {
+			return err
+		}
+	}
+	c.Records = c.Records[:last]
+	return nil
+}
+
+// measuredHashes returns the sorted hash algorithms of the digests of the
+// measured records. All measured records must have the same digests.
+func (c *CEL) measuredHashes() ([]crypto.Hash, error) {
+	var hashes []crypto.Hash
+	for i, record := range c.Records {
+		if record.Content.Type == CelMgtType {
+			continue
+		}
+		var recordHashes []crypto.Hash
+		for hash := range record.Digests {
+			recordHashes = append(recordHashes, hash)
+		}
+		sort.Slice(recordHashes, func(i, j int) bool { return recordHashes[i] < recordHashes[j] })
+		if hashes == nil {
+			hashes = recordHashes
+		} else if !reflect.DeepEqual(hashes, recordHashes) {
+			return nil, fmt.Errorf("CEL record [%d] has %v digests, but earlier records have %v digests", i, recordHashes, hashes)
+		}
+	}
+	return hashes, nil
+}
+
+func bankForHash(banks []register.MRBank, hash crypto.Hash) (register.MRBank, error) {
+	for _, bank := range banks {
+		if bankHash, err := bank.CryptoHash(); err == nil && bankHash == hash {
+			return bank, nil
+		}
+	}
+	return nil, fmt.Errorf("the CEL records were extended with %v, but no %v bank was given to resume the journal", hash, hash)
+}
+
+// lastRecordExtended returns whether the last record was extended into the
+// bank of the report, or an error if the bank does not match the CEL with or
+// without the last record.
+func lastRecordExtended(report *ReplayReport, last int) (bool, error) {
+	replayErr := report.Err()
+	if replayErr == nil {
+		return true, nil
+	}
+	for _, reg := range report.Registers {
+		if reg.Quoted == nil {
+			return false, replayErr
+		}
+		if !reg.Match() && (reg.ExtraExtends || reg.FirstDivergentRecord != last) {
+			return false, fmt.Errorf("CEL journal does not match the current register values: %v", replayErr)
+		}
+	}
+	return false, nil
+}
+
+// journalRecord writes ahead the record to the journal of the CEL, if any.
+func (c *CEL) journalRecord(r Record) error {
+	if c.journal == nil {
+		return nil
+	}
+	return c.journal.Append(r)
+}
+
+// unjournalRecord removes the last record from the journal of the CEL, after
+// failing to extend it.
+func (c *CEL) unjournalRecord() error {
+	if c.journal == nil {
+		return nil
+	}
+	return c.journal.Truncate(len(c.Records))
+}
@@ -0,0 +1,280 @@
+package cel
+
+import (
+	"crypto"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-configfs-tsm/configfs/fakertmr"
+	configfstsmrtmr "github.com/google/go-configfs-tsm/rtmr"
+	"github.com/google/go-eventlog/proto/state"
+	"github.com/google/go-eventlog/register"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// readPCRBanks reads the pcr in the bank of each of the measured hashes.
+func readPCRBanks(t *testing.T, tpm io.ReadWriteCloser, pcr int) []register.MRBank {
+	t.Helper()
+	var banks []register.MRBank
+	for _, hash := range measuredHashes {
+		alg, err := tpm2.HashToAlgorithm(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcrMap, err := tpm2.ReadPCRs(tpm, tpm2.PCRSelection{Hash: alg, PCRs: []int{pcr}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		banks = append(banks, register.PCRBank{
+			TCGHashAlgo: state.HashAlgo(alg),
+			PCRs:        []register.PCR{{Index: pcr, Digest: pcrMap[pcr], DigestAlg: hash}},
+		})
+	}
+	return banks
+}
+
+// openJournalCEL opens the journal at path, and returns the CEL it holds
+// writing ahead to it.
+func openJournalCEL(t *testing.T, path string) *CEL {
+	t.Helper()
+	journal, cel, err := OpenJournal(path, DefaultDecodeLimits())
+	if err != nil {
+		t.Fatalf("OpenJournal() failed: %v", err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	cel.UseJournal(journal)
+	return &cel
+}
+
+func TestJournalResume(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cel_journal")
+
+	cel := openJournalCEL(t, path)
+	if len(cel.Records) != 0 {
+		t.Fatalf("new journal has %d records, want 0", len(cel.Records))
+	}
+	if err := cel.AppendCelMgtPCR(test.DebugPCR, NewFirmwareEnd()); err != nil {
+		t.Fatal(err)
+	}
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ArgType, []byte("--x")})
+
+	// Restart: the CEL is reloaded from the journal, and appends continue.
+	resumed := openJournalCEL(t, path)
+	if !reflect.DeepEqual(resumed.Records, cel.Records) {
+		t.Fatalf("reloaded CEL got %+v, want %+v", resumed.Records, cel.Records)
+	}
+	if err := resumed.ResumeJournal(readPCRBanks(t, tpm, test.DebugPCR)...); err != nil {
+		t.Fatalf("ResumeJournal() failed: %v", err)
+	}
+	appendPcrEventOrFatal(t, resumed, tpm, test.DebugPCR, measuredHashes, CosTlv{LaunchSeparatorType, []byte{}})
+	replay(t, resumed, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+
+	reloaded := openJournalCEL(t, path)
+	if len(reloaded.Records) != 4 {
+		t.Errorf("reloaded CEL has %d records, want 4", len(reloaded.Records))
+	}
+	replay(t, reloaded, tpm, measuredHashes, []int{test.DebugPCR}, true /*shouldSucceed*/)
+}
+
+func TestJournalResumeUnextendedRecord(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cel_journal")
+
+	cel := openJournalCEL(t, path)
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	// Simulate a crash after the record was journaled, but before it was
+	// extended.
+	digests, err := generateDigestMap(measuredHashes, CosTlv{ArgType, []byte("--x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := CosTlv{ArgType, []byte("--x")}.GetTLV()
+	if err := cel.journal.Append(Record{RecNum: 1, Index: uint8(test.DebugPCR), IndexType: PCRTypeValue, Digests: digests, Content: content}); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := openJournalCEL(t, path)
+	if len(resumed.Records) != 2 {
+		t.Fatalf("reloaded CEL has %d records, want 2", len(resumed.Records))
+	}
+	if err := resumed.ResumeJournal(readPCRBanks(t, tpm, test.DebugPCR)...); err != nil {
+		t.Fatalf("ResumeJournal() failed: %v", err)
+	}
+	if len(resumed.Records) != 1 {
+		t.Errorf("resumed CEL has %d records, want 1", len(resumed.Records))
+	}
+	appendPcrEventOrFatal(t, resumed, tpm, test.DebugPCR, measuredHashes, CosTlv{ArgType, []byte("--y")})
+	if reloaded := openJournalCEL(t, path); !reflect.DeepEqual(reloaded.Records, resumed.Records) {
+		t.Errorf("reloaded CEL got %+v, want %+v", reloaded.Records, resumed.Records)
+	}
+
+	// An extension missing from the journal cannot be recovered.
+	if err := tpm2.PCRExtend(tpm, tpmutil.Handle(test.DebugPCR), tpm2.AlgSHA256, make([]byte, crypto.SHA256.Size()), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := openJournalCEL(t, path).ResumeJournal(readPCRBanks(t, tpm, test.DebugPCR)...); err == nil {
+		t.Error("ResumeJournal() got nil error, want error")
+	}
+}
+
+func TestJournalResumePartiallyExtendedRecord(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	if err := tpm2.PCRReset(tpm, tpmutil.Handle(test.DebugPCR)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cel_journal")
+
+	cel := openJournalCEL(t, path)
+	appendPcrEventOrFatal(t, cel, tpm, test.DebugPCR, measuredHashes, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	// Simulate a crash after the record was extended into the SHA256 bank,
+	// but before it was extended into the SHA1 bank.
+	digests, err := generateDigestMap(measuredHashes, CosTlv{ArgType, []byte("--x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := CosTlv{ArgType, []byte("--x")}.GetTLV()
+	if err := cel.journal.Append(Record{RecNum: 1, Index: uint8(test.DebugPCR), IndexType: PCRTypeValue, Digests: digests, Content: content}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpm2.PCRExtend(tpm, tpmutil.Handle(test.DebugPCR), tpm2.AlgSHA256, digests[crypto.SHA256], ""); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := openJournalCEL(t, path)
+	banks := readPCRBanks(t, tpm, test.DebugPCR)
+	if err := resumed.ResumeJournal(banks...); err == nil {
+		t.Error("ResumeJournal() with a record missing from the SHA1 bank got nil error, want error")
+	}
+	if len(resumed.Records) != 2 {
+		t.Errorf("resumed CEL has %d records, want 2", len(resumed.Records))
+	}
+	// Checking only the bank the record was extended into is not enough.
+	for _, bank := range banks {
+		if hash, _ := bank.CryptoHash(); hash == crypto.SHA256 {
+			if err := resumed.ResumeJournal(bank); err == nil {
+				t.Error("ResumeJournal() with only the SHA256 bank got nil error, want error")
+			}
+		}
+	}
+}
+
+func TestJournalRTMR(t *testing.T) {
+	fakeRTMR := fakertmr.CreateRtmrSubsystem(t.TempDir())
+	path := filepath.Join(t.TempDir(), "cel_journal")
+
+	cel := openJournalCEL(t, path)
+	appendRtmrEventOrFatal(t, cel, fakeRTMR, CosRTMR, CosTlv{ImageRefType, []byte("docker.io/library/nginx:latest")})
+	if err := cel.AppendCelMgtRTMR(CosRTMR, NewCelTimestamp(time.Unix(0, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := openJournalCEL(t, path)
+	digest, err := configfstsmrtmr.GetDigest(fakeRTMR, CosRTMR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.ResumeJournal(register.RTMRBank{RTMRs: []register.RTMR{{Index: CosRTMR, Digest: digest.Digest}}}); err != nil {
+		t.Errorf("ResumeJournal() failed: %v", err)
+	}
+	if !reflect.DeepEqual(resumed.Records, cel.Records) {
+		t.Errorf("reloaded CEL got %+v, want %+v", resumed.Records, cel.Records)
+	}
+}
+
+func TestJournalTornAndCorrupted(t *testing.T) {
+	writeJournal := func(t *testing.T) (string, []byte) {
+		path := filepath.Join(t.TempDir(), "cel_journal")
+		cel := openJournalCEL(t, path)
+		for _, event := range []CelMgt{NewFirmwareEnd(), NewTransferChange("workload")} {
+			if err := cel.AppendCelMgtPCR(test.DebugPCR, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path, data
+	}
+	// The frame of the second record starts after the header and the first
+	// frame.
+	_, data := writeJournal(t)
+	firstFrameEnd := journalHeaderSize + journalFrameHeader + int(binary.BigEndian.Uint32(data[journalHeaderSize:]))
+
+	for _, tc := range []struct {
+		name        string
+		modify      func([]byte) []byte
+		wantRecords int
+		wantErr     bool
+	}{
+		{"Intact", func(b []byte) []byte { return b }, 2, false},
+		{"TornFrameHeader", func(b []byte) []byte { return b[:firstFrameEnd+3] }, 1, false},
+		{"TornRecord", func(b []byte) []byte { return b[:len(b)-1] }, 1, false},
+		{"TornHeader", func(b []byte) []byte { return b[:journalHeaderSize-1] }, 0, false},
+		{"LastRecordChecksum", func(b []byte) []byte { b[len(b)-1] ^= 1; return b }, 1, false},
+		{"FirstRecordChecksum", func(b []byte) []byte { b[firstFrameEnd-1] ^= 1; return b }, 0, true},
+		{"BadMagic", func(b []byte) []byte { b[0] = 'X'; return b }, 0, true},
+		{"BadVersion", func(b []byte) []byte { b[7] = 2; return b }, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, data := writeJournal(t)
+			if err := os.WriteFile(path, tc.modify(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			journal, cel, err := OpenJournal(path, DefaultDecodeLimits())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("OpenJournal() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer journal.Close()
+			if len(cel.Records) != tc.wantRecords {
+				t.Fatalf("OpenJournal() got %d records, want %d", len(cel.Records), tc.wantRecords)
+			}
+			// The journal accepts the next record after the recovered ones.
+			cel.UseJournal(journal)
+			if err := cel.AppendCelMgtPCR(test.DebugPCR, NewStateTrans(Suspend)); err != nil {
+				t.Fatal(err)
+			}
+			if reloaded := openJournalCEL(t, path); len(reloaded.Records) != tc.wantRecords+1 {
+				t.Errorf("OpenJournal() got %d records, want %d", len(reloaded.Records), tc.wantRecords+1)
+			}
+		})
+	}
+}
+
+func TestJournalLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cel_journal")
+	cel := openJournalCEL(t, path)
+	for i := 0; i < 3; i++ {
+		if err := cel.AppendCelMgtPCR(test.DebugPCR, NewFirmwareEnd()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := OpenJournal(path, DecodeLimits{MaxRecords: 2}); err == nil {
+		t.Error("OpenJournal() got nil error, want error")
+	}
+	if _, _, err := OpenJournal(path, DecodeLimits{MaxRecordSize: 8}); err == nil {
+		t.Error("OpenJournal() got nil error, want error")
+	}
+}
@@ -0,0 +1,57 @@
+package cel
+
+import (
+	"crypto"
+	"fmt"
+	"math"
+)
+
+// Nested TLV types of PCClientStd content.
+const (
+	pcClientEventTypeTLV uint8 = 0
+	pcClientEventDataTLV uint8 = 1
+)
+
+// PCClientStd is an event of the TCG PC Client Platform Firmware Profile
+// (i.e., an event from a TCG event log), used as a CEL content.
+type PCClientStd struct {
+	EventType uint32
+	EventData []byte
+}
+
+// GetTLV returns the TLV representation of the PC Client event.
+func (p PCClientStd) GetTLV() (TLV, error) {
+	data, err := marshalNestedTLVs(
+		TLV{pcClientEventTypeTLV, marshalUint(p.EventType)},
+		TLV{pcClientEventDataTLV, p.EventData},
+	)
+	if err != nil {
+		return TLV{}, err
+	}
+	return TLV{Type: PCClientStdType, Value: data}, nil
+}
+
+// GenerateDigest returns the digest of the event data. As in a TCG event log,
+// the digest of some event types (e.g., EV_EFI_BOOT_SERVICES_APPLICATION) is
+// not over the event data, so this can only be used to measure events whose
+// data is the measured content.
+func (p PCClientStd) GenerateDigest(hashAlgo crypto.Hash) ([]byte, error) {
+	return hashBytes(hashAlgo, p.EventData)
+}
+
+// ParseToPCClientStd constructs a PCClientStd from a TLV. It will check for the
+// correct content type, and unmarshal the nested event type and data.
+func (t TLV) ParseToPCClientStd() (PCClientStd, error) {
+	if t.Type != PCClientStdType {
+		return PCClientStd{}, fmt.Errorf("TLV type %v is not a PC Client event", t.Type)
+	}
+	nested, err := unmarshalNestedTLVs(t.Value, pcClientEventTypeTLV, pcClientEventDataTLV)
+	if err != nil {
+		return PCClientStd{}, fmt.Errorf("invalid PC Client event: %v", err)
+	}
+	eventType, err := unmarshalUint(nested[0].Value, math.MaxUint32)
+	if err != nil {
+		return PCClientStd{}, fmt.Errorf("invalid PC Client event type: %v", err)
+	}
+	return PCClientStd{EventType: uint32(eventType), EventData: nested[1].Value}, nil
+}
@@ -0,0 +1,179 @@
+package cel
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-eventlog/register"
+)
+
+// RegisterReplay is the result of replaying the records of a CEL for one
+// register.
+type RegisterReplay struct {
+	// The register index, as in the CEL records.
+	Index uint8
+	// The register value computed from all the records for the register.
+	Replayed []byte
+	// The register value in the bank, or nil if the bank lacks the register.
+	Quoted []byte
+	// The number of measured records for the register.
+	Records int
+	// The position in the CEL of the first record for the register that is not
+	// reflected in the quoted value, or -1. If the quoted value is the replay of
+	// only some of the records (e.g., the agent stopped before extending the
+	// rest), this is the first record left out. Otherwise, it is the first
+	// record whose digest does not match its content, if any.
+	FirstDivergentRecord int
+	// ExtraExtends is true if the quoted value is not the replay of any prefix
+	// of the records: the register was extended with digests that are not in
+	// the CEL (or some record digests were altered).
+	ExtraExtends bool
+}
+
+// Match returns true if the register value in the bank is the replay of all
+// the records for the register.
+func (r RegisterReplay) Match() bool {
+	return r.Quoted != nil && bytes.Equal(r.Replayed, r.Quoted)
+}
+
+func (r RegisterReplay) String() string {
+	if r.Quoted == nil {
+		return fmt.Sprintf("register %d: no quoted value for %d record(s)", r.Index, r.Records)
+	}
+	if r.Match() {
+		return fmt.Sprintf("register %d: %d record(s) match %s", r.Index, r.Records, hex.EncodeToString(r.Quoted))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "register %d: replayed %s from %d record(s), quoted %s", r.Index, hex.EncodeToString(r.Replayed), r.Records, hex.EncodeToString(r.Quoted))
+	if r.FirstDivergentRecord >= 0 {
+		fmt.Fprintf(&b, ", first divergent record [%d]", r.FirstDivergentRecord)
+	}
+	if r.ExtraExtends {
+		b.WriteString(", extended outside the CEL")
+	}
+	return b.String()
+}
+
+// ReplayReport is the per-register result of replaying a CEL against a bank
+// of registers, meant to diagnose replay failures.
+type ReplayReport struct {
+	// The hash algorithm of the bank.
+	Hash crypto.Hash
+	// The replay of each register with records in the CEL, by index.
+	Registers []RegisterReplay
+}
+
+// Err returns an error describing the registers which failed to replay, or
+// nil if all of them match.
+func (r *ReplayReport) Err() error {
+	var failed []uint8
+	var details []string
+	for _, reg := range r.Registers {
+		if reg.Quoted == nil {
+			return fmt.Errorf("the CEL contains record(s) for register %d without a matching register in the given bank to verify", reg.Index)
+		}
+		if !reg.Match() {
+			failed = append(failed, reg.Index)
+			details = append(details, reg.String())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("CEL replay failed for these registers in bank %v: %v (%s)", r.Hash, failed, strings.Join(details, "; "))
+}
+
+// ReplayReport replays the CEL against the bank like Replay, but reports the
+// result for each register instead of failing on the first mismatch. It
+// returns an error only if the CEL cannot be replayed with the bank's hash
+// algorithm.
+func (c *CEL) ReplayReport(regs register.MRBank) (*ReplayReport, error) {
+	cryptoHash, err := regs.CryptoHash()
+	if err != nil {
+		return nil, err
+	}
+
+	type replayState struct {
+		// The register value after each measured record, starting from the
+		// initial value.
+		values [][]byte
+		// The position of each measured record.
+		positions []int
+	}
+	replayed := make(map[uint8]*replayState)
+	for i, record := range c.Records {
+		if record.Content.Type == CelMgtType {
+			continue
+		}
+		state, ok := replayed[record.Index]
+		if !ok {
+			state = &replayState{values: [][]byte{make([]byte, cryptoHash.Size())}}
+			replayed[record.Index] = state
+		}
+		digest, ok := record.Digests[cryptoHash]
+		if !ok {
+			return nil, fmt.Errorf("the CEL record did not contain a %v digest", cryptoHash)
+		}
+		hasher := cryptoHash.New()
+		hasher.Write(state.values[len(state.values)-1])
+		hasher.Write(digest)
+		state.values = append(state.values, hasher.Sum(nil))
+		state.positions = append(state.positions, i)
+	}
+
+	registers := make(map[int][]byte)
+	for _, r := range regs.MRs() {
+		registers[r.Idx()] = r.Dgst()
+	}
+
+	report := &ReplayReport{Hash: cryptoHash}
+	for index, state := range replayed {
+		reg := RegisterReplay{
+			Index:                index,
+			Replayed:             state.values[len(state.values)-1],
+			Quoted:               registers[int(index)],
+			Records:              len(state.positions),
+			FirstDivergentRecord: -1,
+		}
+		if reg.Quoted != nil && !reg.Match() {
+			reg.ExtraExtends = true
+			// Look for the last prefix of the records matching the quoted value.
+			for n := len(state.values) - 2; n >= 0; n-- {
+				if bytes.Equal(state.values[n], reg.Quoted) {
+					reg.FirstDivergentRecord = state.positions[n]
+					reg.ExtraExtends = false
+					break
+				}
+			}
+			if reg.ExtraExtends {
+				reg.FirstDivergentRecord = c.firstUnverifiedRecord(state.positions, cryptoHash)
+			}
+		}
+		report.Registers = append(report.Registers, reg)
+	}
+	sort.Slice(report.Registers, func(i, j int) bool {
+		return report.Registers[i].Index < report.Registers[j].Index
+	})
+	return report, nil
+}
+
+// firstUnverifiedRecord returns the first of the records at the positions
+// whose digest does not match its content, or -1. Records with content types
+// without a registered decoder cannot be verified, and are skipped.
+func (c *CEL) firstUnverifiedRecord(positions []int, hash crypto.Hash) int {
+	for _, i := range positions {
+		record := c.Records[i]
+		content, err := DecodeContent(record.Content)
+		if err != nil {
+			continue
+		}
+		if VerifyDigests(content, map[crypto.Hash][]byte{hash: record.Digests[hash]}) != nil {
+			return i
+		}
+	}
+	return -1
+}
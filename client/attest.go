@@ -45,6 +45,14 @@ type AttestOpts struct {
 	// logs overlap, server-side verification using this library may fail.
 	// Deprecated: Manually populate the pb.Attestation instead.
 	CanonicalEventLog []byte
+	// systemd userspace TPM measurement log (CEL-JSON) to add to the
+	// attestation. This is usually read from SystemdEventLogPath.
+	// Optional.
+	SystemdEventLog []byte
+	// Linux IMA runtime measurement list (ascii or binary) to add to the
+	// attestation. This is usually read from ImaEventLogPath.
+	// Optional.
+	ImaEventLog []byte
 	// If non-nil, will be used to fetch the AK certificate chain for validation.
 	// Key.Attest() will construct the certificate chain by making GET requests to
 	// the contents of Key.cert.IssuingCertificateURL using this client.
@@ -303,6 +311,12 @@ func (k *Key) Attest(opts AttestOpts) (*pb.Attestation, error) {
 	if len(opts.CanonicalEventLog) != 0 {
 		attestation.CanonicalEventLog = opts.CanonicalEventLog
 	}
+	if len(opts.SystemdEventLog) != 0 {
+		attestation.SystemdEventLog = opts.SystemdEventLog
+	}
+	if len(opts.ImaEventLog) != 0 {
+		attestation.ImaEventLog = opts.ImaEventLog
+	}
 
 	// Attempt to construct certificate chain. fetchIssuingCertificate checks if
 	// AK cert is present and contains intermediate cert URLs.
@@ -2,6 +2,15 @@ package client
 
 import "io"
 
+// SystemdEventLogPath is the default location of the systemd userspace TPM
+// measurement log, recording the PCR11 and PCR15 measurements made by
+// systemd-pcrphase, systemd-pcrmachine and systemd-pcrfs.
+const SystemdEventLogPath = "/run/log/systemd/tpm2-measure.log"
+
+// ImaEventLogPath is the default location of the binary Linux IMA runtime
+// measurement list, recording the PCR10 measurements made by IMA.
+const ImaEventLogPath = "/sys/kernel/security/ima/binary_runtime_measurements"
+
 // GetEventLog grabs the crypto-agile TCG event log for the system. The TPM can
 // override this implementation by implementing EventLogGetter.
 func GetEventLog(rw io.ReadWriter) ([]byte, error) {
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-tpm-tools/client"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+)
+
+var eventlogCmd = &cobra.Command{
+	Use:   "eventlog",
+	Short: "Inspect TCG and Canonical event logs",
+	Args:  cobra.NoArgs,
+}
+
+var (
+	eventlogHashAlgo = tpm2.AlgSHA256
+	eventlogCEL      bool
+	eventlogJSON     bool
+	pcrValues        string
+)
+
+var eventlogDiffCmd = &cobra.Command{
+	Use:   "diff <base> <other>",
+	Short: "Compare two event logs",
+	Long: `Compare two event logs event by event and per PCR
+
+Reads two raw event logs (e.g., from a known-good boot and from a boot which
+failed attestation) and prints the events that were added, removed or modified,
+grouped by PCR and classified by the component that measured them (firmware,
+bootloader, kernel, secure boot or COS).
+
+By default, the files are TCG PC Client event logs. If --cel is provided, the
+files are COS Canonical Event Logs instead. The logs are not verified against
+any PCR values.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		base, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		other, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		var diff *server.EventLogDiff
+		if eventlogCEL {
+			diff, err = server.DiffCanonicalEventLogs(base, other, pb.HashAlgo(eventlogHashAlgo))
+		} else {
+			diff, err = server.DiffEventLogs(base, other, pb.HashAlgo(eventlogHashAlgo))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to diff event logs: %v", err)
+		}
+		return diff.WriteReport(dataOutput())
+	},
+}
+
+var eventlogDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Decode and print an event log",
+	Long: `Decode and print the events of an event log
+
+Prints the index, PCR, type, digest and decoded data (e.g., UEFI variable
+names, device paths or COS events) of every event in the log, using the
+digests of --hash-algo.
+
+By default, the TCG PC Client event log is read from the TPM. If --input is
+provided, the log is read from that file instead. If --cel is provided, the
+input is a COS Canonical Event Log. The log is not verified against any PCR
+values, use "gotpm eventlog replay" for that.`,
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		var rwc io.ReadWriteCloser
+		if readsEventLogFromTPM() {
+			var err error
+			if rwc, err = openTpm(); err != nil {
+				return err
+			}
+			defer rwc.Close()
+		}
+		rawLog, err := readEventLog(rwc)
+		if err != nil {
+			return err
+		}
+
+		var events []server.DecodedEvent
+		if eventlogCEL {
+			events, err = server.DecodeCanonicalEventLog(rawLog, pb.HashAlgo(eventlogHashAlgo))
+		} else {
+			events, err = server.DecodeEventLog(rawLog, pb.HashAlgo(eventlogHashAlgo))
+		}
+		if err != nil {
+			return err
+		}
+		return writeDecodedEvents(dataOutput(), events)
+	},
+}
+
+var eventlogReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay an event log against PCR values",
+	Long: `Replay an event log against live or supplied PCR values
+
+Replays the event log (read as in "gotpm eventlog dump") against the PCRs of
+--hash-algo, and prints the decoded events if the replay succeeds.
+
+By default, the PCR values are read from the TPM. If --pcr-values is provided,
+they are read from a file containing a PCRs proto in the --format encoding
+(e.g., the PCRs of a quote from an attestation report) instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(*cobra.Command, []string) error {
+		var rwc io.ReadWriteCloser
+		if readsEventLogFromTPM() || pcrValues == "" {
+			var err error
+			if rwc, err = openTpm(); err != nil {
+				return err
+			}
+			defer rwc.Close()
+		}
+		rawLog, err := readEventLog(rwc)
+		if err != nil {
+			return err
+		}
+		pcrs, err := readPCRValues(rwc)
+		if err != nil {
+			return err
+		}
+
+		var events []server.DecodedEvent
+		if eventlogCEL {
+			events, err = server.ReplayCanonicalEventLog(rawLog, pcrs)
+		} else {
+			events, err = server.ReplayEventLog(rawLog, pcrs)
+		}
+		if err != nil {
+			if eventlogCEL {
+				printReplayReport(rawLog, pcrs)
+			}
+			return fmt.Errorf("failed to replay event log: %v", err)
+		}
+		fmt.Fprintf(messageOutput(), "Successfully replayed %d events against %v PCRs\n", len(events), tpm2.Algorithm(pcrs.GetHash()))
+		return writeDecodedEvents(dataOutput(), events)
+	},
+}
+
+// printReplayReport prints the replay of each PCR of a CEL which failed to
+// replay, to help find the records which diverge from the PCR values.
+func printReplayReport(rawCEL []byte, pcrs *pb.PCRs) {
+	report, err := server.CanonicalEventLogReplayReport(rawCEL, pcrs)
+	if err != nil {
+		return
+	}
+	for _, reg := range report.Registers {
+		fmt.Fprintln(messageOutput(), reg)
+	}
+}
+
+// readsEventLogFromTPM returns true if the event log is not read from a file.
+func readsEventLogFromTPM() bool {
+	return !eventlogCEL && input == ""
+}
+
+func readEventLog(rw io.ReadWriter) ([]byte, error) {
+	if !readsEventLogFromTPM() {
+		return io.ReadAll(dataInput())
+	}
+	rawLog, err := client.GetEventLog(rw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event log: %v", err)
+	}
+	return rawLog, nil
+}
+
+func readPCRValues(rw io.ReadWriter) (*pb.PCRs, error) {
+	if pcrValues == "" {
+		fmt.Fprintf(debugOutput(), "Reading %v PCRs from the TPM\n", eventlogHashAlgo)
+		return client.ReadPCRs(rw, client.FullPcrSel(eventlogHashAlgo))
+	}
+	data, err := os.ReadFile(pcrValues)
+	if err != nil {
+		return nil, err
+	}
+	pcrs := &pb.PCRs{}
+	switch format {
+	case "binarypb":
+		err = proto.Unmarshal(data, pcrs)
+	case "textproto":
+		err = unmarshalOptions.Unmarshal(data, pcrs)
+	default:
+		return nil, errors.New("format should be either binarypb or textproto")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal PCR values: %v", err)
+	}
+	return pcrs, nil
+}
+
+func writeDecodedEvents(w io.Writer, events []server.DecodedEvent) error {
+	if eventlogJSON {
+		out, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(out, '\n'))
+		return err
+	}
+
+	var b strings.Builder
+	for _, event := range events {
+		fmt.Fprintf(&b, "#%d PCR%d %s (0x%x)\n", event.Index, event.PCR, event.Type, event.TypeValue)
+		verified := ""
+		if event.DigestVerified {
+			verified = " (verified)"
+		}
+		fmt.Fprintf(&b, "  digest: %s%s\n", event.Digest, verified)
+		if event.Description != "" {
+			fmt.Fprintf(&b, "  data:   %s\n", event.Description)
+		} else if event.Data != "" {
+			fmt.Fprintf(&b, "  data:   %d bytes\n", len(event.Data)/2)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func init() {
+	RootCmd.AddCommand(eventlogCmd)
+	eventlogCmd.AddCommand(eventlogDiffCmd)
+	eventlogCmd.AddCommand(eventlogDumpCmd)
+	eventlogCmd.AddCommand(eventlogReplayCmd)
+	hideHelp(eventlogCmd)
+
+	addOutputFlag(eventlogCmd)
+	addHashAlgoFlag(eventlogCmd, &eventlogHashAlgo)
+	eventlogCmd.PersistentFlags().BoolVar(&eventlogCEL, "cel", false, "read COS Canonical Event Logs instead of TCG event logs")
+
+	for _, cmd := range []*cobra.Command{eventlogDumpCmd, eventlogReplayCmd} {
+		addInputFlag(cmd)
+		cmd.Flags().BoolVar(&eventlogJSON, "json", false, "print the decoded events as JSON")
+	}
+	eventlogReplayCmd.Flags().StringVar(&pcrValues, "pcr-values", "", "file containing the PCR values to replay against (defaults to reading the TPM)")
+	addFormatFlag(eventlogReplayCmd)
+}
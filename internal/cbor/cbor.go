@@ -0,0 +1,413 @@
+// Package cbor implements the subset of CBOR (RFC 8949) needed to process
+// COSE, CoRIM and CoSWID documents and to encode Canonical Event Logs.
+//
+// Values are decoded into generic Go types:
+//   - unsigned integers as uint64, negative integers as int64
+//   - byte strings as []byte, text strings as string
+//   - arrays as []any, maps as map[any]any
+//   - tags as Tag
+//   - false/true as bool, null and undefined as nil
+//   - floating-point numbers as float64
+//
+// Encode accepts the same types (along with the other Go integer types) and
+// produces the deterministic encoding of RFC 8949 Section 4.2.1.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// MaxDepth is the maximum nesting depth of arrays, maps and tags accepted by
+// Decode.
+const MaxDepth = 32
+
+// CBOR major types.
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Tag is a tagged CBOR data item.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// Decode decodes a single CBOR data item, which must span all of data.
+func Decode(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, fmt.Errorf("cbor: %d trailing bytes after data item", len(d.data)-d.off)
+	}
+	return v, nil
+}
+
+// DecodeFirst decodes the first CBOR data item in data, returning the item
+// and the remaining bytes.
+func DecodeFirst(data []byte) (any, []byte, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, d.data[d.off:], nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+var errTruncated = errors.New("cbor: unexpected end of data")
+
+func (d *decoder) readByte() (byte, error) {
+	if d.off >= len(d.data) {
+		return 0, errTruncated
+	}
+	b := d.data[d.off]
+	d.off++
+	return b, nil
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errTruncated
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// readHead reads the initial byte and argument of a data item. For
+// indefinite-length items, indefinite is true and arg is zero.
+func (d *decoder) readHead() (major byte, info byte, arg uint64, indefinite bool, err error) {
+	b, err := d.readByte()
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		size := uint64(1) << (info - 24)
+		buf, err := d.read(size)
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		for _, c := range buf {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, info, arg, false, nil
+	case info == 31 && major >= majorBytes && major <= majorMap:
+		return major, info, 0, true, nil
+	case info == 31 && major == majorSimple:
+		return 0, 0, 0, false, errors.New("cbor: unexpected break")
+	}
+	return 0, 0, 0, false, fmt.Errorf("cbor: invalid additional information %d for major type %d", info, major)
+}
+
+// isBreak consumes the break stop code, if it is the next byte.
+func (d *decoder) isBreak() (bool, error) {
+	if d.off >= len(d.data) {
+		return false, errTruncated
+	}
+	if d.data[d.off] == 0xff {
+		d.off++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("cbor: exceeded maximum nesting depth %d", MaxDepth)
+	}
+	major, info, arg, indefinite, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return arg, nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		s, err := d.decodeString(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorBytes {
+			return s, nil
+		}
+		if !utf8.Valid(s) {
+			return nil, errors.New("cbor: invalid UTF-8 in text string")
+		}
+		return string(s), nil
+	case majorArray:
+		// Every item takes at least one byte, which bounds the allocation.
+		if !indefinite && arg > uint64(len(d.data)-d.off) {
+			return nil, errTruncated
+		}
+		arr := make([]any, 0, arg)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite {
+				if done, err := d.isBreak(); err != nil {
+					return nil, err
+				} else if done {
+					break
+				}
+			}
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	case majorMap:
+		if !indefinite && arg > uint64(len(d.data)-d.off)/2 {
+			return nil, errTruncated
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite {
+				if done, err := d.isBreak(); err != nil {
+					return nil, err
+				} else if done {
+					break
+				}
+			}
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case uint64, int64, string, bool:
+			default:
+				return nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+			if _, ok := m[key]; ok {
+				return nil, fmt.Errorf("cbor: duplicate map key %v", key)
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		}
+		return m, nil
+	case majorTag:
+		content, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: content}, nil
+	}
+
+	// Major type 7: simple values and floats.
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float64(halfToFloat32(uint16(arg))), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+}
+
+func (d *decoder) decodeString(major byte, length uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		s, err := d.read(length)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Clone(s), nil
+	}
+	// Indefinite-length strings are a sequence of definite-length chunks of
+	// the same major type.
+	var buf []byte
+	for {
+		if done, err := d.isBreak(); err != nil {
+			return nil, err
+		} else if done {
+			return buf, nil
+		}
+		chunkMajor, _, chunkLen, chunkIndefinite, err := d.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkIndefinite {
+			return nil, errors.New("cbor: invalid indefinite-length string chunk")
+		}
+		chunk, err := d.read(chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+}
+
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h & 0x3ff)
+	switch {
+	case exp == 0:
+		// Subnormal or zero.
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
+
+// Encode encodes a value using the deterministic CBOR encoding.
+func Encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{major<<5 | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func encodeInt(buf *bytes.Buffer, i int64) {
+	if i < 0 {
+		writeHead(buf, majorNegInt, uint64(-1-i))
+	} else {
+		writeHead(buf, majorUint, uint64(i))
+	}
+}
+
+func encode(buf *bytes.Buffer, v any, depth int) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("cbor: exceeded maximum nesting depth %d", MaxDepth)
+	}
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case uint64:
+		writeHead(buf, majorUint, v)
+	case uint32:
+		writeHead(buf, majorUint, uint64(v))
+	case uint16:
+		writeHead(buf, majorUint, uint64(v))
+	case uint8:
+		writeHead(buf, majorUint, uint64(v))
+	case uint:
+		writeHead(buf, majorUint, uint64(v))
+	case int64:
+		encodeInt(buf, v)
+	case int32:
+		encodeInt(buf, int64(v))
+	case int:
+		encodeInt(buf, int64(v))
+	case float64:
+		buf.WriteByte(majorSimple<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		writeHead(buf, majorText, uint64(len(v)))
+		buf.WriteString(v)
+	case []any:
+		writeHead(buf, majorArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encode(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		return encodeMap(buf, v, depth)
+	case Tag:
+		writeHead(buf, majorTag, v.Number)
+		return encode(buf, v.Content, depth+1)
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+	return nil
+}
+
+// encodeMap encodes a map with its keys sorted in the bytewise lexicographic
+// order of their encodings.
+func encodeMap(buf *bytes.Buffer, m map[any]any, depth int) error {
+	type entry struct {
+		key   []byte
+		value any
+	}
+	entries := make([]entry, 0, len(m))
+	for k, v := range m {
+		var keyBuf bytes.Buffer
+		if err := encode(&keyBuf, k, depth+1); err != nil {
+			return err
+		}
+		entries = append(entries, entry{keyBuf.Bytes(), v})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	writeHead(buf, majorMap, uint64(len(entries)))
+	for i, e := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, e.key) {
+			return errors.New("cbor: duplicate map key")
+		}
+		buf.Write(e.key)
+		if err := encode(buf, e.value, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Examples from RFC 8949 Appendix A.
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    any
+	}{
+		{"00", uint64(0)},
+		{"17", uint64(23)},
+		{"1818", uint64(24)},
+		{"1903e8", uint64(1000)},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"20", int64(-1)},
+		{"3903e7", int64(-1000)},
+		{"f90000", float64(0)},
+		{"f93c00", float64(1)},
+		{"f97bff", float64(65504)},
+		{"fa47c35000", float64(100000)},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"40", []byte{}},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"62c3bc", "ü"},
+		{"83010203", []any{uint64(1), uint64(2), uint64(3)}},
+		{"8301820203820405", []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}}},
+		{"a201020304", map[any]any{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		{"a26161016162820203", map[any]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}}},
+		{"c074323031332d30332d32315432303a30343a30305a", Tag{0, "2013-03-21T20:04:00Z"}},
+		{"d82076687474703a2f2f7777772e6578616d706c652e636f6d", Tag{32, "http://www.example.com"}},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9f018202039f0405ffff", []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}}},
+		{"bf61610161629f0203ffff", map[any]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.encoded, func(t *testing.T) {
+			got, err := Decode(mustHex(t, tc.encoded))
+			if err != nil {
+				t.Fatalf("Decode() got err = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Decode() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"Empty", ""},
+		{"TruncatedUint", "19e8"},
+		{"TruncatedBytes", "4401"},
+		{"TruncatedArray", "830102"},
+		{"HugeArray", "9bffffffffffffffff"},
+		{"HugeMap", "bb7fffffffffffffff"},
+		{"TrailingBytes", "0000"},
+		{"ReservedInfo", "1c"},
+		{"UnexpectedBreak", "ff"},
+		{"InvalidUTF8", "62c328"},
+		{"DuplicateKey", "a201020103"},
+		{"ByteStringKey", "a1410102"},
+		{"BadChunk", "5f6161ff"},
+		{"NegativeOverflow", "3bffffffffffffffff"},
+		{"TooDeep", "8181818181818181818181818181818181818181818181818181818181818181818100"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Decode(mustHex(t, tc.encoded)); err == nil {
+				t.Error("Decode() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{uint64(0), "00"},
+		{23, "17"},
+		{24, "1818"},
+		{uint32(1000), "1903e8"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{-1, "20"},
+		{int64(-1000), "3903e7"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{[]any{1, []any{2, 3}, []any{4, 5}}, "8301820203820405"},
+		// Map keys are sorted by their encoding.
+		{map[any]any{"b": 2, 10: 1, -1: 3, "a": 4}, "a40a012003616104616202"},
+		{Tag{1, 1363896240}, "c11a514b67b0"},
+	}
+	for _, tc := range tests {
+		got, err := Encode(tc.value)
+		if err != nil {
+			t.Fatalf("Encode(%v) got err = %v, want nil", tc.value, err)
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("Encode(%v) got %x, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	for _, v := range []any{
+		struct{}{},
+		map[any]any{1: 1, uint64(1): 2},
+		[]any{float32(1)},
+	} {
+		if _, err := Encode(v); err == nil {
+			t.Errorf("Encode(%v) got nil error, want error", v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	value := map[any]any{
+		uint64(0): "id",
+		uint64(1): []any{Tag{506, []byte{0xa0}}},
+		int64(-7): map[any]any{"digests": []any{[]any{uint64(1), []byte{0xde, 0xad}}}},
+	}
+	encoded, err := Encode(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(value, decoded); diff != "" {
+		t.Errorf("round trip returned unexpected diff (-want +got):\n%s", diff)
+	}
+	first, rest, err := DecodeFirst(append(encoded, 0x01))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(value, first); diff != "" || len(rest) != 1 {
+		t.Errorf("DecodeFirst() got rest %x, unexpected diff (-want +got):\n%s", rest, diff)
+	}
+}
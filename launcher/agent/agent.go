@@ -14,11 +14,15 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/google/go-configfs-tsm/configfs/configfsi"
+	"github.com/google/go-configfs-tsm/rtmr"
+	"github.com/google/go-eventlog/proto/state"
+	"github.com/google/go-eventlog/register"
 
 	"github.com/google/go-configfs-tsm/configfs/linuxtsm"
 	tg "github.com/google/go-tdx-guest/client"
@@ -28,17 +32,28 @@ import (
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/metrics"
 	"github.com/google/go-tpm-tools/launcher/internal/signaturediscovery"
 	"github.com/google/go-tpm-tools/launcher/spec"
 	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm-tools/verifier"
 	"github.com/google/go-tpm-tools/verifier/models"
 	"github.com/google/go-tpm-tools/verifier/oci"
 	"github.com/google/go-tpm-tools/verifier/util"
+	"github.com/google/go-tpm/legacy/tpm2"
 )
 
 var defaultCELHashAlgo = []crypto.Hash{crypto.SHA256, crypto.SHA1}
 
+// The version of the CEL spec recorded at the start of the CEL. The CEL
+// follows version 1 of the spec (TCG_IWG_CEL_v1_r0p41 is version 1, revision
+// 0.41), whose cel_version is major 1, minor 0.
+const (
+	celVersionMajor = 1
+	celVersionMinor = 0
+)
+
 type principalIDTokenFetcher func(audience string) ([][]byte, error)
 
 // AttestationAgent is an agent that interacts with GCE's Attestation Service
@@ -47,14 +62,40 @@ type principalIDTokenFetcher func(audience string) ([][]byte, error)
 type AttestationAgent interface {
 	MeasureEvent(cel.Content) error
 	Attest(context.Context, AttestAgentOpts) ([]byte, error)
-	AttestWithClient(ctx context.Context, opts AttestAgentOpts, client verifier.Client) ([]byte, error)
+	// AttestWithClient attests like Attest, with the client of the verifier
+	// type instead of the default client.
+	AttestWithClient(ctx context.Context, opts AttestAgentOpts, verifierType spec.VerifierType, client verifier.Client) ([]byte, error)
 	Refresh(context.Context) error
+	// AttestationEvidence returns the raw vTPM attestation over the nonce,
+	// with the COS CEL and any TEE attestation report, for a relying party
+	// verifying it itself (e.g., with server.VerifyAttestation).
+	AttestationEvidence(nonce []byte) (*pb.Attestation, error)
+	// Seal seals data with the vTPM, bound to the current values of the boot
+	// chain PCRs and of the COS event PCR, which holds the measured launch of
+	// the workload.
+	Seal(data []byte) (*tpmpb.SealedBytes, error)
+	// Unseal unseals data sealed by Seal, if these PCRs still have the values
+	// they had when the data was sealed. Measuring any later event (e.g., the
+	// shutdown of the workload) changes the COS event PCR, after which the
+	// workload can no longer unseal the data.
+	Unseal(*tpmpb.SealedBytes) ([]byte, error)
+	// ResumedEvents returns the contents of the measured records of the CEL
+	// resumed from the journal, in order, or nil if the CEL was not resumed.
+	ResumedEvents() []cel.TLV
 	Close() error
 }
 
 type attestRoot interface {
 	// Extend measures the cel content into a measurement register and appends to the CEL.
 	Extend(cel.Content) error
+	// AppendCelMgt appends a non-measured CEL management record to the CEL.
+	AppendCelMgt(cel.CelMgt) error
+	// ResumeCEL loads the CEL from the journal at journalPath, checks it against
+	// the current register values, and journals all later records. It returns
+	// whether the journal held any records.
+	ResumeCEL(journalPath string) (bool, error)
+	// Close releases the resources (e.g., the CEL journal) of the attestation root.
+	Close() error
 	// GetCEL fetches the CEL with events corresponding to the sequence of Extended measurements
 	// to this attestation root
 	GetCEL() *cel.CEL
@@ -71,6 +112,7 @@ type AttestAgentOpts struct {
 type agent struct {
 	measuredRots     []attestRoot
 	avRot            attestRoot
+	tpmRot           *tpmAttestRoot
 	fetchedAK        *client.Key
 	client           verifier.Client
 	principalFetcher principalIDTokenFetcher
@@ -78,6 +120,10 @@ type agent struct {
 	launchSpec       spec.LaunchSpec
 	logger           logging.Logger
 	sigsCache        *sigsCache
+	resumedEvents    []cel.TLV
+	// teeDevice adds the attestation report of the Confidential VM to the raw
+	// evidence. It is nil outside of a SEV-SNP or TDX VM.
+	teeDevice client.TEEDevice
 }
 
 // CreateAttestationAgent returns an agent capable of performing remote
@@ -87,7 +133,9 @@ type agent struct {
 // - principalFetcher is a func to fetch GCE principal tokens for a given audience.
 // - signaturesFetcher is a func to fetch container image signatures associated with the running workload.
 // - logger will log any partial errors returned by VerifyAttestation.
-func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher util.TpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, sigsFetcher signaturediscovery.Fetcher, launchSpec spec.LaunchSpec, logger logging.Logger) (AttestationAgent, error) {
+// - celJournalDir is the directory of the on-disk CEL journals, which let the
+// agent resume its CELs after a restart. CELs are only kept in memory if empty.
+func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher util.TpmKeyFetcher, verifierClient verifier.Client, principalFetcher principalIDTokenFetcher, sigsFetcher signaturediscovery.Fetcher, launchSpec spec.LaunchSpec, logger logging.Logger, celJournalDir string) (AttestationAgent, error) {
 	// Fetched the AK and save it, so the agent doesn't need to create a new key everytime
 	ak, err := akFetcher(tpm)
 	if err != nil {
@@ -111,6 +159,7 @@ func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher util.TpmKeyFetcher
 		tpm:       tpm,
 	}
 	attestAgent.measuredRots = append(attestAgent.measuredRots, tpmAR)
+	attestAgent.tpmRot = tpmAR
 
 	// check if is a TDX machine
 	qp, err := tg.GetQuoteProvider()
@@ -133,9 +182,55 @@ func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher util.TpmKeyFetcher
 
 		logger.Info("Using TDX RTMR as attestation root.")
 		attestAgent.avRot = tdxAR
+		attestAgent.teeDevice = &client.TdxQuoteProvider{QuoteProvider: qp}
 	} else {
 		logger.Info("Using TPM PCR as attestation root.")
 		attestAgent.avRot = tpmAR
+		attestAgent.teeDevice = newTEEDevice()
+	}
+
+	resumed := false
+	if celJournalDir != "" {
+		if err := os.MkdirAll(celJournalDir, 0700); err != nil {
+			attestAgent.Close()
+			return nil, fmt.Errorf("failed to create the CEL journal directory: %v", err)
+		}
+		for i, rot := range attestAgent.measuredRots {
+			hasRecords, err := rot.ResumeCEL(path.Join(celJournalDir, fmt.Sprintf("cel_journal_%d", i)))
+			if err != nil {
+				attestAgent.Close()
+				return nil, fmt.Errorf("failed to resume the CEL: %v", err)
+			}
+			resumed = resumed || hasRecords
+		}
+	}
+	if resumed {
+		logger.Info("Resumed the CEL from the journal.")
+		for _, record := range attestAgent.avRot.GetCEL().Records {
+			if record.Content.Type != cel.CelMgtType {
+				attestAgent.resumedEvents = append(attestAgent.resumedEvents, record.Content)
+			}
+		}
+		return attestAgent, nil
+	}
+
+	// Start every CEL with the version of the CEL spec it follows. Verifiers
+	// which predate CEL management records reject them, as they have no
+	// SHA256 digest, so this is behind an experiment until verifiers accept
+	// them.
+	if !launchSpec.Experiments.EnableCelVersionEvent {
+		return attestAgent, nil
+	}
+	celVersion, err := cel.NewCelVersion(celVersionMajor, celVersionMinor)
+	if err != nil {
+		attestAgent.Close()
+		return nil, err
+	}
+	for _, rot := range attestAgent.measuredRots {
+		if err := rot.AppendCelMgt(celVersion); err != nil {
+			attestAgent.Close()
+			return nil, fmt.Errorf("failed to append the CEL version: %v", err)
+		}
 	}
 
 	return attestAgent, nil
@@ -144,6 +239,14 @@ func CreateAttestationAgent(tpm io.ReadWriteCloser, akFetcher util.TpmKeyFetcher
 // Close cleans up the agent
 func (a *agent) Close() error {
 	a.fetchedAK.Close()
+	if a.teeDevice != nil {
+		a.teeDevice.Close()
+	}
+	for _, rot := range a.measuredRots {
+		if err := rot.Close(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -159,6 +262,23 @@ func (a *agent) MeasureEvent(event cel.Content) error {
 	return nil
 }
 
+// ResumedEvents returns the contents of the measured records of the CEL
+// resumed from the journal.
+func (a *agent) ResumedEvents() []cel.TLV {
+	return a.resumedEvents
+}
+
+// Seal seals data with the vTPM to the current boot chain and COS event PCR
+// values.
+func (a *agent) Seal(data []byte) (*tpmpb.SealedBytes, error) {
+	return a.tpmRot.Seal(data)
+}
+
+// Unseal unseals data sealed by Seal.
+func (a *agent) Unseal(sealed *tpmpb.SealedBytes) ([]byte, error) {
+	return a.tpmRot.Unseal(sealed)
+}
+
 // Attest fetches the nonce and connection ID from the Attestation Service,
 // creates an attestation message, and returns the resultant
 // principalIDTokens and Metadata Server-generated ID tokens for the instance.
@@ -169,7 +289,7 @@ func (a *agent) Attest(ctx context.Context, opts AttestAgentOpts) ([]byte, error
 		return nil, fmt.Errorf("attest agent does not have initialized verifier client")
 	}
 
-	return a.AttestWithClient(ctx, opts, a.client)
+	return a.AttestWithClient(ctx, opts, a.launchSpec.Verifier, a.client)
 }
 
 // AttestWithClient fetches the nonce and connection ID from the Attestation Service via the provided client,
@@ -177,7 +297,17 @@ func (a *agent) Attest(ctx context.Context, opts AttestAgentOpts) ([]byte, error
 // principalIDTokens and Metadata Server-generated ID tokens for the instance.
 // When possible, Attest uses the technology-specific attestation root-of-trust
 // (TDX RTMR), otherwise falls back to the vTPM.
-func (a *agent) AttestWithClient(ctx context.Context, opts AttestAgentOpts, client verifier.Client) ([]byte, error) {
+// The verifier type the client was created for names the verifier in the
+// metrics.
+func (a *agent) AttestWithClient(ctx context.Context, opts AttestAgentOpts, verifierType spec.VerifierType, client verifier.Client) ([]byte, error) {
+	start := time.Now()
+	token, err := a.attestWithClient(ctx, opts, client)
+	metrics.AttestTotal.Inc(string(verifierType), metrics.Result(err))
+	metrics.AttestDuration.Observe(metrics.Since(start), string(verifierType))
+	return token, err
+}
+
+func (a *agent) attestWithClient(ctx context.Context, opts AttestAgentOpts, client verifier.Client) ([]byte, error) {
 	challenge, err := client.CreateChallenge(ctx)
 	if err != nil {
 		return nil, err
@@ -250,6 +380,27 @@ func (a *agent) AttestWithClient(ctx context.Context, opts AttestAgentOpts, clie
 	return resp.ClaimsToken, nil
 }
 
+// AttestationEvidence quotes the vTPM over the nonce, and returns the quotes
+// with the AK certificate chain and the COS CEL. On a Confidential VM, the
+// evidence holds the SEV-SNP or TDX attestation report of the TEE device as
+// well, whose report data is the nonce (zero-padded or truncated). It fails if
+// the TEE device cannot produce the report. The evidence never uses the TDX
+// RTMRs as attestation root, so that server.VerifyAttestation can replay the
+// CEL against the PCRs.
+func (a *agent) AttestationEvidence(nonce []byte) (*pb.Attestation, error) {
+	attestation, err := a.tpmRot.AttestWithTEE(nonce, a.teeDevice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attest: %v", err)
+	}
+
+	var cosCel bytes.Buffer
+	if err := a.tpmRot.GetCEL().EncodeCEL(&cosCel); err != nil {
+		return nil, err
+	}
+	attestation.CanonicalEventLog = cosCel.Bytes()
+	return attestation, nil
+}
+
 func convertOCIToContainerSignature(ociSig oci.Signature) (*verifier.ContainerSignature, error) {
 	payload, err := ociSig.Payload()
 	if err != nil {
@@ -274,6 +425,48 @@ type tpmAttestRoot struct {
 	fetchedAK *client.Key
 	tpm       io.ReadWriteCloser
 	cosCel    cel.CEL
+	journal   *cel.Journal
+}
+
+func (t *tpmAttestRoot) ResumeCEL(journalPath string) (bool, error) {
+	journal, cosCel, err := cel.OpenJournal(journalPath, cel.DefaultDecodeLimits())
+	if err != nil {
+		return false, err
+	}
+	cosCel.UseJournal(journal)
+	// Every bank the CEL is extended into is checked, so that a record
+	// extended into only some of the banks is detected.
+	var banks []register.MRBank
+	for _, hash := range defaultCELHashAlgo {
+		alg, err := tpm2.HashToAlgorithm(hash)
+		if err != nil {
+			journal.Close()
+			return false, err
+		}
+		pcrs, err := tpm2.ReadPCRs(t.tpm, tpm2.PCRSelection{Hash: alg, PCRs: []int{cel.CosEventPCR}})
+		if err != nil {
+			journal.Close()
+			return false, err
+		}
+		banks = append(banks, register.PCRBank{
+			TCGHashAlgo: state.HashAlgo(alg),
+			PCRs:        []register.PCR{{Index: cel.CosEventPCR, Digest: pcrs[cel.CosEventPCR], DigestAlg: hash}},
+		})
+	}
+	if err := cosCel.ResumeJournal(banks...); err != nil {
+		journal.Close()
+		return false, err
+	}
+	t.cosCel = cosCel
+	t.journal = journal
+	return len(cosCel.Records) > 0, nil
+}
+
+func (t *tpmAttestRoot) Close() error {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.Close()
 }
 
 func (t *tpmAttestRoot) GetCEL() *cel.CEL {
@@ -284,6 +477,10 @@ func (t *tpmAttestRoot) Extend(c cel.Content) error {
 	return t.cosCel.AppendEventPCR(t.tpm, cel.CosEventPCR, defaultCELHashAlgo, c)
 }
 
+func (t *tpmAttestRoot) AppendCelMgt(c cel.CelMgt) error {
+	return t.cosCel.AppendCelMgtPCR(cel.CosEventPCR, c)
+}
+
 func (t *tpmAttestRoot) Attest(nonce []byte) (any, error) {
 	t.tpmMu.Lock()
 	defer t.tpmMu.Unlock()
@@ -294,11 +491,99 @@ func (t *tpmAttestRoot) Attest(nonce []byte) (any, error) {
 	})
 }
 
+// AttestWithTEE quotes the vTPM over the nonce like Attest, and adds the
+// attestation report of teeDevice over the same nonce, if not nil.
+func (t *tpmAttestRoot) AttestWithTEE(nonce []byte, teeDevice client.TEEDevice) (*pb.Attestation, error) {
+	t.tpmMu.Lock()
+	defer t.tpmMu.Unlock()
+
+	return t.fetchedAK.Attest(client.AttestOpts{
+		Nonce:            nonce,
+		CertChainFetcher: http.DefaultClient,
+		TEEDevice:        teeDevice,
+	})
+}
+
+// newTEEDevice returns the SEV-SNP or TDX device of the Confidential VM, or nil
+// outside of a Confidential VM.
+func newTEEDevice() client.TEEDevice {
+	if device, err := client.CreateSevSnpQuoteProvider(); err == nil {
+		return device
+	}
+	if device, err := client.CreateTdxQuoteProvider(); err == nil {
+		return device
+	}
+	return nil
+}
+
+// sealPCRSel selects the PCRs data is sealed to: the firmware (0), boot loader
+// (4), Secure Boot policy (7), kernel command line (8) and kernel (9) PCRs,
+// which pin the COS image, and the COS event PCR, which pins the launch of the
+// workload.
+var sealPCRSel = tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0, 4, 7, 8, 9, cel.CosEventPCR}}
+
+// Seal seals data to the current values of the sealPCRSel PCRs, under the ECC
+// SRK, which the vTPM derives again after a reboot.
+func (t *tpmAttestRoot) Seal(data []byte) (*tpmpb.SealedBytes, error) {
+	t.tpmMu.Lock()
+	defer t.tpmMu.Unlock()
+
+	srk, err := client.StorageRootKeyECC(t.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the SRK: %v", err)
+	}
+	defer srk.Close()
+	return srk.Seal(data, client.SealOpts{Current: sealPCRSel})
+}
+
+// Unseal unseals data sealed by Seal. The vTPM enforces that the sealPCRSel
+// PCRs have the values they had when the data was sealed.
+func (t *tpmAttestRoot) Unseal(sealed *tpmpb.SealedBytes) ([]byte, error) {
+	t.tpmMu.Lock()
+	defer t.tpmMu.Unlock()
+
+	srk, err := client.StorageRootKeyECC(t.tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the SRK: %v", err)
+	}
+	defer srk.Close()
+	return srk.Unseal(sealed, client.UnsealOpts{})
+}
+
 type tdxAttestRoot struct {
 	tdxMu     sync.Mutex
 	qp        *tg.LinuxConfigFsQuoteProvider
 	tsmClient configfsi.Client
 	cosCel    cel.CEL
+	journal   *cel.Journal
+}
+
+func (t *tdxAttestRoot) ResumeCEL(journalPath string) (bool, error) {
+	journal, cosCel, err := cel.OpenJournal(journalPath, cel.DefaultDecodeLimits())
+	if err != nil {
+		return false, err
+	}
+	cosCel.UseJournal(journal)
+	digest, err := rtmr.GetDigest(t.tsmClient, cel.CosRTMR)
+	if err != nil {
+		journal.Close()
+		return false, err
+	}
+	bank := register.RTMRBank{RTMRs: []register.RTMR{{Index: cel.CosRTMR, Digest: digest.Digest}}}
+	if err := cosCel.ResumeJournal(bank); err != nil {
+		journal.Close()
+		return false, err
+	}
+	t.cosCel = cosCel
+	t.journal = journal
+	return len(cosCel.Records) > 0, nil
+}
+
+func (t *tdxAttestRoot) Close() error {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.Close()
 }
 
 func (t *tdxAttestRoot) GetCEL() *cel.CEL {
@@ -309,6 +594,10 @@ func (t *tdxAttestRoot) Extend(c cel.Content) error {
 	return t.cosCel.AppendEventRTMR(t.tsmClient, cel.CosRTMR, c)
 }
 
+func (t *tdxAttestRoot) AppendCelMgt(c cel.CelMgt) error {
+	return t.cosCel.AppendCelMgtRTMR(cel.CosRTMR, c)
+}
+
 func (t *tdxAttestRoot) Attest(nonce []byte) (any, error) {
 	t.tdxMu.Lock()
 	defer t.tdxMu.Unlock()
@@ -357,6 +646,7 @@ func fetchContainerImageSignatures(ctx context.Context, fetcher signaturediscove
 
 			// backoff independently per repo
 			var sigs []oci.Signature
+			start := time.Now()
 			err := backoff.RetryNotify(
 				func() error {
 					s, err := fetcher.FetchImageSignatures(ctx, targetRepo)
@@ -367,6 +657,8 @@ func fetchContainerImageSignatures(ctx context.Context, fetcher signaturediscove
 				func(err error, _ time.Duration) {
 					logger.Error(fmt.Sprintf("Failed to fetch container image signatures from repo: %v", err.Error()), "repo", targetRepo)
 				})
+			metrics.SignatureDiscoveryTotal.Inc(metrics.Result(err))
+			metrics.SignatureDiscoveryDuration.Observe(metrics.Since(start))
 			if err != nil {
 				logger.Error(fmt.Sprintf("Failed all attempts to refresh container signatures from repo: %v", err.Error()), "repo", targetRepo)
 			} else {
@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/base64"
@@ -18,15 +20,19 @@ import (
 	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/internal/experiments"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
 	"github.com/google/go-tpm-tools/launcher/internal/signaturediscovery"
 	"github.com/google/go-tpm-tools/launcher/spec"
 	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
 	"github.com/google/go-tpm-tools/verifier"
 	"github.com/google/go-tpm-tools/verifier/fake"
 	"github.com/google/go-tpm-tools/verifier/oci"
 	"github.com/google/go-tpm-tools/verifier/oci/cosign"
 	"github.com/google/go-tpm-tools/verifier/rest"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -59,7 +65,7 @@ func TestAttestRacing(t *testing.T) {
 	}
 
 	verifierClient := fake.NewClient(fakeSigner)
-	agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger())
+	agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -78,6 +84,255 @@ func TestAttestRacing(t *testing.T) {
 	agent.Close()
 }
 
+func TestResumeCEL(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	journalDir := t.TempDir()
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	verifierClient := fake.NewClient(fakeSigner)
+	launchSpec := spec.LaunchSpec{Experiments: experiments.Experiments{EnableCelVersionEvent: true}}
+	createAgent := func() *agent {
+		a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), launchSpec, logging.SimpleLogger(), journalDir)
+		if err != nil {
+			t.Fatalf("CreateAttestationAgent() failed: %v", err)
+		}
+		return a.(*agent)
+	}
+
+	first := createAgent()
+	if err := first.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(imageRef)}); err != nil {
+		t.Fatal(err)
+	}
+	want := first.avRot.GetCEL().Records
+	first.Close()
+
+	// The restarted agent resumes the CEL, and does not stamp the version again.
+	second := createAgent()
+	defer second.Close()
+	if diff := cmp.Diff(want, second.avRot.GetCEL().Records); diff != "" {
+		t.Errorf("resumed CEL has unexpected diff (-want +got):\n%s", diff)
+	}
+	if got := second.ResumedEvents(); len(got) != 1 || got[0].Type != cel.CosEventType {
+		t.Errorf("ResumedEvents() got %+v, want the image reference event", got)
+	}
+	if err := second.MeasureEvent(cel.CosTlv{EventType: cel.LaunchSeparatorType, EventContent: []byte{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Attest(context.Background(), AttestAgentOpts{}); err != nil {
+		t.Errorf("Attest() with the resumed CEL failed: %v", err)
+	}
+}
+
+func TestCelVersionExperiment(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	for _, enabled := range []bool{false, true} {
+		launchSpec := spec.LaunchSpec{Experiments: experiments.Experiments{EnableCelVersionEvent: enabled}}
+		a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), launchSpec, logging.SimpleLogger(), "")
+		if err != nil {
+			t.Fatalf("CreateAttestationAgent() failed: %v", err)
+		}
+		records := a.(*agent).avRot.GetCEL().Records
+		a.Close()
+		if !enabled {
+			if len(records) != 0 {
+				t.Errorf("CEL with the experiment disabled got %d records, want none", len(records))
+			}
+			continue
+		}
+		if len(records) != 1 {
+			t.Fatalf("CEL with the experiment enabled got %d records, want 1", len(records))
+		}
+		celMgt, err := records[0].Content.ParseToCelMgt()
+		if err != nil {
+			t.Fatal(err)
+		}
+		major, minor, err := celMgt.CelVersion()
+		if err != nil || major != 1 || minor != 0 {
+			t.Errorf("CelVersion() got %d.%d, err = %v, want 1.0", major, minor, err)
+		}
+	}
+}
+
+func TestSealUnseal(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
+	if err != nil {
+		t.Fatalf("CreateAttestationAgent() failed: %v", err)
+	}
+	defer a.Close()
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(imageRef)}); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("workload secret")
+	sealed, err := a.Seal(secret)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	got, err := a.Unseal(sealed)
+	if err != nil {
+		t.Fatalf("Unseal() failed: %v", err)
+	}
+	if !cmp.Equal(got, secret) {
+		t.Errorf("Unseal() got %q, want %q", got, secret)
+	}
+
+	// Another workload launch cannot unseal the secret.
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ArgType, EventContent: []byte(arg)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Unseal(sealed); err == nil {
+		t.Error("Unseal() after another measurement got nil error, want error")
+	}
+}
+
+func TestUnsealAfterPCRChange(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
+	if err != nil {
+		t.Fatalf("CreateAttestationAgent() failed: %v", err)
+	}
+	defer a.Close()
+	for _, event := range []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte(imageRef)},
+		{EventType: cel.LaunchSeparatorType, EventContent: []byte{}},
+	} {
+		if err := a.MeasureEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret := []byte("workload secret")
+	sealed, err := a.Seal(secret)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	if _, err := a.Unseal(sealed); err != nil {
+		t.Fatalf("Unseal() failed: %v", err)
+	}
+	// The workload cannot unseal after the launcher measured its shutdown.
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ShutdownType, EventContent: []byte("SIGTERM")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Unseal(sealed); err == nil {
+		t.Error("Unseal() after the shutdown event got nil error, want error")
+	}
+
+	// The data is also bound to the boot chain.
+	sealed, err = a.Seal(secret)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	if err := tpm2.PCRExtend(tpm, tpmutil.Handle(9), tpm2.AlgSHA256, make([]byte, crypto.SHA256.Size()), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Unseal(sealed); err == nil {
+		t.Error("Unseal() after extending the kernel PCR got nil error, want error")
+	}
+}
+
+func TestAttestationEvidence(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
+	if err != nil {
+		t.Fatalf("CreateAttestationAgent() failed: %v", err)
+	}
+	defer a.Close()
+	if err := a.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(imageRef)}); err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := []byte("relying party nonce")
+	evidence, err := a.AttestationEvidence(nonce)
+	if err != nil {
+		t.Fatalf("AttestationEvidence() failed: %v", err)
+	}
+	ms, err := server.VerifyAttestation(evidence, server.VerifyOpts{Nonce: nonce, TrustedAKs: []crypto.PublicKey{a.(*agent).fetchedAK.PublicKey()}})
+	if err != nil {
+		t.Fatalf("VerifyAttestation() failed: %v", err)
+	}
+	if got := ms.GetCos().GetContainer().GetImageReference(); got != imageRef {
+		t.Errorf("VerifyAttestation() got image reference %q, want %q", got, imageRef)
+	}
+
+	if _, err := server.VerifyAttestation(evidence, server.VerifyOpts{Nonce: []byte("another nonce"), TrustedAKs: []crypto.PublicKey{a.(*agent).fetchedAK.PublicKey()}}); err == nil {
+		t.Error("VerifyAttestation() with another nonce got nil error, want error")
+	}
+}
+
+// fakeTEEDevice adds an empty SEV-SNP attestation report over the nonce.
+type fakeTEEDevice struct {
+	nonce []byte
+}
+
+func (d *fakeTEEDevice) AddAttestation(attestation *attestpb.Attestation, opts client.AttestOpts) error {
+	d.nonce = opts.Nonce
+	attestation.TeeAttestation = &attestpb.Attestation_SevSnpAttestation{}
+	return nil
+}
+
+func (d *fakeTEEDevice) Close() error {
+	return nil
+}
+
+func TestAttestationEvidenceTEE(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	fakeSigner, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key %v", err)
+	}
+	a, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, fake.NewClient(fakeSigner), placeholderPrincipalFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
+	if err != nil {
+		t.Fatalf("CreateAttestationAgent() failed: %v", err)
+	}
+	defer a.Close()
+	device := &fakeTEEDevice{}
+	a.(*agent).teeDevice = device
+
+	nonce := []byte("relying party nonce")
+	evidence, err := a.AttestationEvidence(nonce)
+	if err != nil {
+		t.Fatalf("AttestationEvidence() failed: %v", err)
+	}
+	if evidence.GetTeeAttestation() == nil {
+		t.Error("AttestationEvidence() got no TEE attestation report")
+	}
+	if !bytes.Equal(device.nonce, nonce) {
+		t.Errorf("AttestationEvidence() got TEE report over nonce %q, want %q", device.nonce, nonce)
+	}
+}
+
 func TestAttest(t *testing.T) {
 	ctx := context.Background()
 	testCases := []struct {
@@ -111,7 +366,7 @@ func TestAttest(t *testing.T) {
 
 			verifierClient := fake.NewClient(fakeSigner)
 
-			agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, tc.principalIDTokenFetcher, tc.containerSignaturesFetcher, tc.launchSpec, logging.SimpleLogger())
+			agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, tc.principalIDTokenFetcher, tc.containerSignaturesFetcher, tc.launchSpec, logging.SimpleLogger(), "")
 			if err != nil {
 				t.Fatalf("failed to create an attestation agent %v", err)
 			}
@@ -601,7 +856,7 @@ func TestWithAgent(t *testing.T) {
 	tpm := test.GetTPM(t)
 	defer client.CheckedClose(t, tpm)
 
-	agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, vClient, testPrincipalIDTokenFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger())
+	agent, err := CreateAttestationAgent(tpm, client.AttestationKeyECC, vClient, testPrincipalIDTokenFetcher, signaturediscovery.NewFakeClient(), spec.LaunchSpec{}, logging.SimpleLogger(), "")
 	if err != nil {
 		t.Fatalf("failed to create an attestation agent %v", err)
 	}
@@ -2,7 +2,10 @@
 package launcher
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,9 +13,11 @@ import (
 	"math/rand"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
@@ -30,13 +35,14 @@ import (
 	"github.com/google/go-tpm-tools/launcher/agent"
 	"github.com/google/go-tpm-tools/launcher/internal/healthmonitoring/nodeproblemdetector"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/metrics"
 	"github.com/google/go-tpm-tools/launcher/internal/signaturediscovery"
 	"github.com/google/go-tpm-tools/launcher/launcherfile"
 	"github.com/google/go-tpm-tools/launcher/registryauth"
 	"github.com/google/go-tpm-tools/launcher/spec"
 	"github.com/google/go-tpm-tools/launcher/teeserver"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
 	"github.com/google/go-tpm-tools/verifier"
-	"github.com/google/go-tpm-tools/verifier/ita"
 	"github.com/google/go-tpm-tools/verifier/util"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
@@ -45,18 +51,26 @@ import (
 
 // ContainerRunner contains information about the container settings
 type ContainerRunner struct {
-	container     containerd.Container
-	launchSpec    spec.LaunchSpec
-	attestAgent   agent.AttestationAgent
-	logger        logging.Logger
-	serialConsole *os.File
+	container   containerd.Container
+	launchSpec  spec.LaunchSpec
+	attestAgent agent.AttestationAgent
+	// verifierClient is the client of the verifier selected in the launch
+	// spec, shared by the attestation agent (except for ITA) and the TEE server.
+	verifierClient verifier.Client
+	logger         logging.Logger
+	serialConsole  *os.File
+	// The init containers, in the order they run, and the sidecars.
+	initContainers []workloadContainer
+	sidecars       []workloadContainer
+	// tokens publishes the refreshed default tokens to the TEE server.
+	tokens *teeserver.TokenNotifier
 }
 
 const tokenFileTmp = ".token.tmp"
 
 const teeServerSocket = "teeserver.sock"
 
-// Since we only allow one container on a VM, using a deterministic id is probably fine
+// Since we only allow one workload on a VM, using a deterministic id is probably fine
 const (
 	containerID = "tee-container"
 	snapshotID  = "tee-snapshot"
@@ -82,7 +96,7 @@ const defaultOOMScore = 1000
 
 // NewRunner returns a runner.
 func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, launchSpec spec.LaunchSpec, mdsClient *metadata.Client, tpm io.ReadWriteCloser, logger logging.Logger, serialConsole *os.File) (*ContainerRunner, error) {
-	image, err := initImage(ctx, cdClient, launchSpec, token)
+	image, err := initImage(ctx, cdClient, launchSpec.ImageRef, token)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +105,20 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 	for _, lsMnt := range launchSpec.Mounts {
 		mounts = append(mounts, lsMnt.SpecsMount())
 	}
+	// The init containers and sidecars share the mounts of the main
+	// container, except for the cgroup filesystem and, unless their launch
+	// spec opts in, the attestation token and TEE server socket mount.
+	var workloadMounts []specs.Mount
+	if len(launchSpec.InitContainers) != 0 || len(launchSpec.Sidecars) != 0 {
+		if err := createSharedVolume(); err != nil {
+			return nil, fmt.Errorf("failed to create the volume shared by the workload containers: %v", err)
+		}
+		workloadMounts = appendSharedVolumeMount(append([]specs.Mount{}, mounts...))
+	}
 	mounts = appendTokenMounts(mounts)
+	if len(workloadMounts) != 0 {
+		mounts = appendSharedVolumeMount(mounts)
+	}
 	var cgroupOpts []oci.SpecOpts
 	if launchSpec.CgroupNamespace {
 		mounts = appendCgroupRw(mounts)
@@ -219,6 +246,16 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 				len(containerSpec.Process.Args), len(launchSpec.Cmd))
 	}
 
+	initContainers, err := newWorkloadContainers(ctx, cdClient, token, launchSpec.InitContainers, workloadMounts, hostname, logger)
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := newWorkloadContainers(ctx, cdClient, token, launchSpec.Sidecars, workloadMounts, hostname, logger)
+	if err != nil {
+		deleteWorkloadContainers(ctx, initContainers)
+		return nil, err
+	}
+
 	principalFetcherWithImpersonate := func(audience string) ([][]byte, error) {
 		tokens, err := util.PrincipalFetcher(audience, mdsClient)
 		if err != nil {
@@ -237,31 +274,43 @@ func NewRunner(ctx context.Context, cdClient *containerd.Client, token oauth2.To
 		return tokens, nil
 	}
 
-	asAddr := launchSpec.AttestationServiceAddr
-
-	var verifierClient verifier.Client
-	if launchSpec.ITARegion == "" {
-		gcaClient, err := util.NewRESTClient(ctx, asAddr, launchSpec.ProjectID, launchSpec.Region)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create REST verifier client: %v", err)
-		}
-
-		verifierClient = gcaClient
+	verifierClient, err := newVerifierClient(ctx, launchSpec, tpm)
+	if err != nil {
+		deleteWorkloadContainers(ctx, initContainers)
+		deleteWorkloadContainers(ctx, sidecars)
+		return nil, err
+	}
+	// ITA tokens are only fetched on demand through the TEE server.
+	agentVerifierClient := verifierClient
+	if launchSpec.Verifier == spec.ITAVerifier {
+		agentVerifierClient = nil
 	}
 
-	// Create a new signaturediscovery client to fetch signatures.
-	sdClient := getSignatureDiscoveryClient(cdClient, mdsClient, image.Target())
+	// Create a new signaturediscovery client to fetch the signatures of all
+	// the workload images.
+	sdClient, err := workloadSignatureFetcher(ctx, cdClient, mdsClient, image, initContainers, sidecars)
+	if err != nil {
+		deleteWorkloadContainers(ctx, initContainers)
+		deleteWorkloadContainers(ctx, sidecars)
+		return nil, err
+	}
 
-	attestAgent, err := agent.CreateAttestationAgent(tpm, client.GceAttestationKeyECC, verifierClient, principalFetcherWithImpersonate, sdClient, launchSpec, logger)
+	attestAgent, err := agent.CreateAttestationAgent(tpm, client.GceAttestationKeyECC, agentVerifierClient, principalFetcherWithImpersonate, sdClient, launchSpec, logger, launcherfile.CELJournalPath)
 	if err != nil {
+		deleteWorkloadContainers(ctx, initContainers)
+		deleteWorkloadContainers(ctx, sidecars)
 		return nil, err
 	}
 	return &ContainerRunner{
 		container,
 		launchSpec,
 		attestAgent,
+		verifierClient,
 		logger,
 		serialConsole,
+		initContainers,
+		sidecars,
+		teeserver.NewTokenNotifier(),
 	}, nil
 }
 
@@ -333,13 +382,31 @@ func appendTokenMounts(mounts []specs.Mount) []specs.Mount {
 	return append(mounts, m)
 }
 
+// measureCELEvents measures the launch of the workload into the COS eventlog,
+// ending with the launch separator. If the launcher restarted and the CEL was
+// resumed from the journal, the events already in it are checked against the
+// ones being measured instead of being measured again.
 func (r *ContainerRunner) measureCELEvents(ctx context.Context) error {
+	if resumed := r.attestAgent.ResumedEvents(); len(resumed) > 0 {
+		attestAgent := r.attestAgent
+		r.attestAgent = &resumedAttestationAgent{AttestationAgent: attestAgent, resumed: resumed}
+		defer func() { r.attestAgent = attestAgent }()
+	}
 	if err := r.measureContainerClaims(ctx); err != nil {
 		return fmt.Errorf("failed to measure container claims: %v", err)
 	}
 	if err := r.measureMemoryMonitor(); err != nil {
 		return fmt.Errorf("failed to measure memory monitoring state: %v", err)
 	}
+	if err := r.measureLaunchPosture(); err != nil {
+		return fmt.Errorf("failed to measure launch posture: %v", err)
+	}
+	if err := r.measureWorkloadContainerClaims(ctx, cel.InitContainerType, r.initContainers); err != nil {
+		return fmt.Errorf("failed to measure init container claims: %v", err)
+	}
+	if err := r.measureWorkloadContainerClaims(ctx, cel.SidecarType, r.sidecars); err != nil {
+		return fmt.Errorf("failed to measure sidecar claims: %v", err)
+	}
 
 	separator := cel.CosTlv{
 		EventType:    cel.LaunchSeparatorType,
@@ -348,6 +415,29 @@ func (r *ContainerRunner) measureCELEvents(ctx context.Context) error {
 	return r.attestAgent.MeasureEvent(separator)
 }
 
+// resumedAttestationAgent skips measuring the events already in the CEL
+// resumed from the journal, after checking that they are the ones being
+// measured, and measures the following events.
+type resumedAttestationAgent struct {
+	agent.AttestationAgent
+	resumed []cel.TLV
+}
+
+func (a *resumedAttestationAgent) MeasureEvent(event cel.Content) error {
+	if len(a.resumed) == 0 {
+		return a.AttestationAgent.MeasureEvent(event)
+	}
+	tlv, err := event.GetTLV()
+	if err != nil {
+		return err
+	}
+	if tlv.Type != a.resumed[0].Type || !bytes.Equal(tlv.Value, a.resumed[0].Value) {
+		return fmt.Errorf("the CEL resumed from the journal measured a different launch")
+	}
+	a.resumed = a.resumed[1:]
+	return nil
+}
+
 // measureContainerClaims will measure various container claims into the COS
 // eventlog in the AttestationAgent.
 func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
@@ -370,7 +460,14 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 		}
 	}
 
-	containerSpec, err := r.container.Spec(ctx)
+	return r.measureProcessClaims(ctx, r.container, r.launchSpec.Envs, r.launchSpec.Cmd)
+}
+
+// measureProcessClaims will measure the args and env vars of the container
+// process, followed by the given operator overrides, into the COS eventlog in
+// the AttestationAgent.
+func (r *ContainerRunner) measureProcessClaims(ctx context.Context, container containerd.Container, overrideEnvs []spec.EnvVar, overrideCmd []string) error {
+	containerSpec, err := container.Spec(ctx)
 	if err != nil {
 		return err
 	}
@@ -386,7 +483,7 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 	}
 
 	// Measure the input overridden Env Vars and Args separately, these should be subsets of the Env Vars and Args above.
-	envs, err := formatEnvVars(r.launchSpec.Envs)
+	envs, err := formatEnvVars(overrideEnvs)
 	if err != nil {
 		return err
 	}
@@ -395,7 +492,7 @@ func (r *ContainerRunner) measureContainerClaims(ctx context.Context) error {
 			return err
 		}
 	}
-	for _, arg := range r.launchSpec.Cmd {
+	for _, arg := range overrideCmd {
 		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.OverrideArgType, EventContent: []byte(arg)}); err != nil {
 			return err
 		}
@@ -418,10 +515,102 @@ func (r *ContainerRunner) measureMemoryMonitor() error {
 	return nil
 }
 
+// logRedirectLocations maps the launch spec log redirect locations to the
+// attest.LogRedirectLocation values, whose names are measured in the COS
+// eventlog.
+var logRedirectLocations = map[spec.LogRedirectLocation]attestpb.LogRedirectLocation{
+	spec.Nowhere:      attestpb.LogRedirectLocation_LOG_REDIRECT_NOWHERE,
+	spec.CloudLogging: attestpb.LogRedirectLocation_LOG_REDIRECT_CLOUD_LOGGING,
+	spec.Serial:       attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL,
+	spec.Everywhere:   attestpb.LogRedirectLocation_LOG_REDIRECT_EVERYWHERE,
+}
+
+// measureLaunchPosture will measure the launch decisions which change the
+// trust posture of the workload into the COS eventlog in the AttestationAgent.
+func (r *ContainerRunner) measureLaunchPosture() error {
+	for _, capability := range r.launchSpec.AddedCapabilities {
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.AddedCapabilityType, EventContent: []byte(capability)}); err != nil {
+			return err
+		}
+	}
+	var cgroupNamespace uint8
+	if r.launchSpec.CgroupNamespace {
+		cgroupNamespace = 1
+	}
+	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.CgroupNamespaceType, EventContent: []byte{cgroupNamespace}}); err != nil {
+		return err
+	}
+	if err := r.measureMounts(); err != nil {
+		return err
+	}
+	if location, ok := logRedirectLocations[r.launchSpec.LogRedirect]; ok {
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.LogRedirectType, EventContent: []byte(location.String())}); err != nil {
+			return err
+		}
+	}
+	for _, sa := range r.launchSpec.ImpersonateServiceAccounts {
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImpersonatedServiceAccountType, EventContent: []byte(sa)}); err != nil {
+			return err
+		}
+	}
+	var hardened uint8
+	if r.launchSpec.Hardened {
+		hardened = 1
+	}
+	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.HardenedImageType, EventContent: []byte{hardened}}); err != nil {
+		return err
+	}
+	if r.launchSpec.Verifier == spec.LocalVerifier {
+		if err := r.measureLocalVerifierKey(); err != nil {
+			return err
+		}
+	}
+	r.logger.Info("Successfully measured launch posture events")
+	return nil
+}
+
+// measureLocalVerifierKey measures the public key with which the local
+// verifier signs the tokens, so that relying parties can trust it.
+func (r *ContainerRunner) measureLocalVerifierKey() error {
+	signer, ok := r.verifierClient.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return fmt.Errorf("the %s verifier client has no public key", r.launchSpec.Verifier)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return fmt.Errorf("failed to marshal the local verifier key: %v", err)
+	}
+	return r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.LocalVerifierKeyType, EventContent: der})
+}
+
+// measureMounts will measure the mounts added by the operator into the COS
+// eventlog in the AttestationAgent.
+func (r *ContainerRunner) measureMounts() error {
+	for _, lsMnt := range r.launchSpec.Mounts {
+		specsMnt := lsMnt.SpecsMount()
+		mount, err := cel.ContainerMount{Type: specsMnt.Type, Source: specsMnt.Source, Destination: specsMnt.Destination, Options: specsMnt.Options}.Format()
+		if err != nil {
+			return err
+		}
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.MountType, EventContent: []byte(mount)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Retrieves the default OIDC token from the attestation service, and returns how long
 // to wait before attemping to refresh it.
 // The token file will be written to a tmp file and then renamed.
 func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	duration, err := r.writeToken(ctx)
+	metrics.TokenRefreshTotal.Inc(metrics.Result(err))
+	metrics.TokenRefreshDuration.Observe(metrics.Since(start))
+	return duration, err
+}
+
+func (r *ContainerRunner) writeToken(ctx context.Context) (time.Duration, error) {
 	if err := r.attestAgent.Refresh(ctx); err != nil {
 		return 0, fmt.Errorf("failed to refresh attestation agent: %v", err)
 	}
@@ -463,6 +652,8 @@ func (r *ContainerRunner) refreshToken(ctx context.Context) (time.Duration, erro
 	}
 
 	r.logger.Info("successfully refreshed attestation token", "token", mapClaims)
+	r.tokens.SetToken(token, claims.ExpiresAt.Time)
+	metrics.TokenExpiry.Set(float64(claims.ExpiresAt.Unix()))
 
 	return getNextRefreshFromExpiration(time.Until(claims.ExpiresAt.Time), rand.Float64()), nil
 }
@@ -486,6 +677,7 @@ func (r *ContainerRunner) fetchAndWriteTokenWithRetry(ctx context.Context,
 
 	// Set a timer to refresh the token before it expires.
 	timer := time.NewTimer(duration)
+	scheduled := time.Now().Add(duration)
 	go func() {
 		for {
 			select {
@@ -505,13 +697,17 @@ func (r *ContainerRunner) fetchAndWriteTokenWithRetry(ctx context.Context,
 					retry(),
 					func(err error, t time.Duration) {
 						r.logger.Error(fmt.Sprintf("failed to refresh attestation service token at time %v: %v", t, err))
+						r.tokens.SetRefreshError(err, true)
 					})
 				if err != nil {
 					r.logger.Error(fmt.Sprintf("failed all attempts to refresh attestation service token, stopping refresher: %v", err))
+					r.tokens.SetRefreshError(err, false)
 					return
 				}
+				metrics.TokenRefreshLag.Observe(metrics.Since(scheduled))
 
 				timer.Reset(duration)
+				scheduled = time.Now().Add(duration)
 			}
 		}
 	}()
@@ -581,8 +777,8 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to measure CEL events: %v", err)
 	}
 
-	// Only refresh token if agent has a default GCA client (not ITA use case).
-	if r.launchSpec.ITARegion == "" {
+	// Only refresh token if agent has a default client (not ITA use case).
+	if r.launchSpec.Verifier != spec.ITAVerifier {
 		if err := r.fetchAndWriteToken(ctx); err != nil {
 			return fmt.Errorf("failed to fetch and write OIDC token: %v", err)
 		}
@@ -591,24 +787,14 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	// create and start the TEE server
 	r.logger.Info("EnableOnDemandAttestation is enabled: initializing TEE server.")
 
-	attestClients := &teeserver.AttestClients{}
-	if r.launchSpec.ITARegion != "" {
-		itaClient, err := ita.NewClient(r.launchSpec.ITARegion, r.launchSpec.ITAKey)
-		if err != nil {
-			return fmt.Errorf("failed to create ITA client: %v", err)
-		}
-
-		attestClients.ITA = itaClient
-	} else {
-		gcaClient, err := util.NewRESTClient(ctx, r.launchSpec.AttestationServiceAddr, r.launchSpec.ProjectID, r.launchSpec.Region)
-		if err != nil {
-			return fmt.Errorf("failed to create REST verifier client: %v", err)
-		}
-
-		attestClients.GCA = gcaClient
+	attestClients := teeserver.AttestClients{r.launchSpec.Verifier: r.verifierClient}
+	// No default token is refreshed for ITA.
+	var tokens *teeserver.TokenNotifier
+	if r.launchSpec.Verifier != spec.ITAVerifier {
+		tokens = r.tokens
 	}
 
-	teeServer, err := teeserver.New(ctx, path.Join(launcherfile.HostTmpPath, teeServerSocket), r.attestAgent, r.logger, r.launchSpec, attestClients)
+	teeServer, err := teeserver.New(ctx, path.Join(launcherfile.HostTmpPath, teeServerSocket), r.attestAgent, r.logger, r.launchSpec, attestClients, tokens)
 	if err != nil {
 		return fmt.Errorf("failed to create the TEE server: %v", err)
 	}
@@ -639,12 +825,28 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 		return fmt.Errorf("unknown logging redirect location: %v", r.launchSpec.LogRedirect)
 	}
 
+	// The workload starts in phases: the init containers run to completion
+	// one after the other, then the sidecars start, then the main container.
+	if err := r.runInitContainers(ctx, streamOpt); err != nil {
+		return err
+	}
+
 	task, err := r.container.NewTask(ctx, cio.NewCreator(streamOpt))
 	if err != nil {
 		return &RetryableError{err}
 	}
 	defer task.Delete(ctx)
 
+	workloadEnded := make(chan struct{})
+	sidecarTasks, err := r.startSidecars(ctx, streamOpt, workloadEnded)
+	defer func() {
+		close(workloadEnded)
+		deleteSidecarTasks(ctx, sidecarTasks)
+	}()
+	if err != nil {
+		return err
+	}
+
 	setupDuration := time.Since(start)
 	r.logger.Info("Workload setup completed",
 		"setup_sec", setupDuration.Seconds(),
@@ -654,6 +856,12 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	if err != nil {
 		r.logger.Error(err.Error())
 	}
+	// The VM shutdown stops the launcher, which forwards the stop signals to
+	// the workload task.
+	stopC := make(chan os.Signal, 1)
+	signal.Notify(stopC, stopSignals...)
+	defer signal.Stop(stopC)
+
 	// Start timer for workload execution.
 	start = time.Now()
 	r.logger.Info("workload task started")
@@ -661,10 +869,8 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	if err := task.Start(ctx); err != nil {
 		return &RetryableError{err}
 	}
-	status := <-exitStatusC
+	code, err := r.waitTask(ctx, task, exitStatusC, stopC)
 	workloadDuration := time.Since(start)
-
-	code, _, err := status.Result()
 	if err != nil {
 		return err
 	}
@@ -681,25 +887,104 @@ func (r *ContainerRunner) Run(ctx context.Context) error {
 	return nil
 }
 
+// stopSignals are the signals forwarded to the workload task.
+var stopSignals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+
+// signalName returns the name of a stop signal, as measured in the shutdown
+// event.
+func signalName(sig os.Signal) string {
+	switch sig {
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	case syscall.SIGINT:
+		return "SIGINT"
+	}
+	return sig.String()
+}
+
+// taskKiller sends signals to a task.
+type taskKiller interface {
+	Kill(context.Context, syscall.Signal, ...containerd.KillOpts) error
+}
+
+// waitTask waits for the task to exit, and returns its return code. If the
+// launcher receives a stop signal first, it measures a shutdown event, and
+// forwards the signal to the task. The task is killed if it does not exit
+// within the stop grace period. A StoppedError is returned in this case.
+func (r *ContainerRunner) waitTask(ctx context.Context, task taskKiller, exitStatusC <-chan containerd.ExitStatus, stopC <-chan os.Signal) (uint32, error) {
+	var sig os.Signal
+	select {
+	case status := <-exitStatusC:
+		code, _, err := status.Result()
+		return code, err
+	case sig = <-stopC:
+	}
+
+	stopped := &StoppedError{Signal: signalName(sig)}
+	r.logger.Info("launcher received a stop signal, forwarding it to the workload task",
+		"signal", stopped.Signal,
+		"grace_period_sec", r.launchSpec.StopGracePeriod.Seconds(),
+	)
+	// The shutdown event is measured before the task is signaled, so the
+	// attestations of the stopping workload include it.
+	if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ShutdownType, EventContent: []byte(stopped.Signal)}); err != nil {
+		r.logger.Error(fmt.Sprintf("failed to measure the shutdown event: %v", err))
+	}
+	if err := task.Kill(ctx, sig.(syscall.Signal)); err != nil {
+		r.logger.Error(fmt.Sprintf("failed to forward %s to the workload task: %v", stopped.Signal, err))
+	}
+
+	timer := time.NewTimer(r.launchSpec.StopGracePeriod)
+	defer timer.Stop()
+	var status containerd.ExitStatus
+	select {
+	case status = <-exitStatusC:
+	case <-timer.C:
+		r.logger.Warn("workload task did not exit within the grace period, killing it")
+		stopped.Killed = true
+		if err := task.Kill(ctx, syscall.SIGKILL); err != nil {
+			return 0, fmt.Errorf("failed to kill the workload task: %v", err)
+		}
+		status = <-exitStatusC
+	}
+	code, _, err := status.Result()
+	if err != nil {
+		return 0, err
+	}
+	stopped.ReturnCode = code
+	r.logger.Info("workload task stopped",
+		"signal", stopped.Signal,
+		"return_code", code,
+		"killed", stopped.Killed,
+	)
+	return code, stopped
+}
+
 func pullImageWithRetries(f func() (containerd.Image, error), retry func() backoff.BackOff) (containerd.Image, error) {
 	var err error
 	var image containerd.Image
+	start := time.Now()
+	attempts := 0
 	err = backoff.Retry(func() error {
+		attempts++
 		image, err = f()
 		return err
 	}, retry())
+	metrics.ImagePullTotal.Inc(metrics.Result(err))
+	metrics.ImagePullRetries.Add(float64(attempts - 1))
+	metrics.ImagePullDuration.Observe(metrics.Since(start))
 	if err != nil {
 		return nil, fmt.Errorf("failed to pull image with retries, the last error is: %w", err)
 	}
 	return image, nil
 }
 
-func initImage(ctx context.Context, cdClient *containerd.Client, launchSpec spec.LaunchSpec, token oauth2.Token) (containerd.Image, error) {
+func initImage(ctx context.Context, cdClient *containerd.Client, imageRef string, token oauth2.Token) (containerd.Image, error) {
 	if token.Valid() {
 		remoteOpt := containerd.WithResolver(registryauth.Resolver(token.AccessToken))
 		image, err := pullImageWithRetries(
 			func() (containerd.Image, error) {
-				return cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack, remoteOpt)
+				return cdClient.Pull(ctx, imageRef, containerd.WithPullUnpack, remoteOpt)
 			},
 			pullImageBackoffPolicy,
 		)
@@ -710,7 +995,7 @@ func initImage(ctx context.Context, cdClient *containerd.Client, launchSpec spec
 	}
 	image, err := pullImageWithRetries(
 		func() (containerd.Image, error) {
-			return cdClient.Pull(ctx, launchSpec.ImageRef, containerd.WithPullUnpack)
+			return cdClient.Pull(ctx, imageRef, containerd.WithPullUnpack)
 		},
 		pullImageBackoffPolicy,
 	)
@@ -782,7 +1067,9 @@ func (r *ContainerRunner) Close(ctx context.Context) {
 	r.attestAgent.Close()
 
 	// Exit gracefully:
-	// Delete container and close connection to attestation service.
+	// Delete containers and close connection to attestation service.
+	deleteWorkloadContainers(ctx, r.initContainers)
+	deleteWorkloadContainers(ctx, r.sidecars)
 	r.container.Delete(ctx, containerd.WithSnapshotCleanup)
 }
 
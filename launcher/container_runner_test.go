@@ -3,6 +3,8 @@ package launcher
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
@@ -11,6 +13,7 @@ import (
 	"path"
 	"strconv"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -22,10 +25,16 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
 	"github.com/google/go-tpm-tools/launcher/agent"
+	"github.com/google/go-tpm-tools/launcher/internal/launchermount"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/signaturediscovery"
 	"github.com/google/go-tpm-tools/launcher/launcherfile"
 	"github.com/google/go-tpm-tools/launcher/spec"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm-tools/verifier"
 	"github.com/opencontainers/go-digest"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
@@ -43,6 +52,7 @@ type fakeAttestationAgent struct {
 	attestFunc       func(context.Context, agent.AttestAgentOpts) ([]byte, error)
 	sigsCache        []string
 	sigsFetcherFunc  func(context.Context) []string
+	resumedEvents    []cel.TLV
 
 	// attMu sits on top of attempts field and protects attempts.
 	attMu    sync.Mutex
@@ -65,7 +75,7 @@ func (f *fakeAttestationAgent) Attest(ctx context.Context, _ agent.AttestAgentOp
 	return nil, fmt.Errorf("unimplemented")
 }
 
-func (f *fakeAttestationAgent) AttestWithClient(_ context.Context, _ agent.AttestAgentOpts, _ verifier.Client) ([]byte, error) {
+func (f *fakeAttestationAgent) AttestWithClient(_ context.Context, _ agent.AttestAgentOpts, _ spec.VerifierType, _ verifier.Client) ([]byte, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
 
@@ -77,6 +87,22 @@ func (f *fakeAttestationAgent) Refresh(ctx context.Context) error {
 	return nil
 }
 
+func (f *fakeAttestationAgent) Seal(_ []byte) (*tpmpb.SealedBytes, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) Unseal(_ *tpmpb.SealedBytes) ([]byte, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) AttestationEvidence(_ []byte) (*attestpb.Attestation, error) {
+	return nil, fmt.Errorf("unimplemented")
+}
+
+func (f *fakeAttestationAgent) ResumedEvents() []cel.TLV {
+	return f.resumedEvents
+}
+
 func (f *fakeAttestationAgent) Close() error {
 	return nil
 }
@@ -542,7 +568,7 @@ func TestInitImageDockerPublic(t *testing.T) {
 	ctx := namespaces.WithNamespace(context.Background(), "test")
 	// This is a "valid" token (formatwise)
 	validToken := oauth2.Token{AccessToken: "000000", Expiry: time.Now().Add(time.Hour)}
-	if _, err := initImage(ctx, containerdClient, spec.LaunchSpec{ImageRef: "docker.io/library/hello-world:latest"}, validToken); err != nil {
+	if _, err := initImage(ctx, containerdClient, "docker.io/library/hello-world:latest", validToken); err != nil {
 		t.Error(err)
 	} else {
 		if err := containerdClient.ImageService().Delete(ctx, "docker.io/library/hello-world:latest"); err != nil {
@@ -551,7 +577,7 @@ func TestInitImageDockerPublic(t *testing.T) {
 	}
 
 	invalidToken := oauth2.Token{}
-	if _, err := initImage(ctx, containerdClient, spec.LaunchSpec{ImageRef: "docker.io/library/hello-world:latest"}, invalidToken); err != nil {
+	if _, err := initImage(ctx, containerdClient, "docker.io/library/hello-world:latest", invalidToken); err != nil {
 		t.Error(err)
 	} else {
 		if err := containerdClient.ImageService().Delete(ctx, "docker.io/library/hello-world:latest"); err != nil {
@@ -571,11 +597,23 @@ func TestMeasureCELEvents(t *testing.T) {
 		args: []string{"fake args"},
 		env:  []string{"fake env"},
 	}
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	defer func(keyPath string) { localVerifierKeyPath = keyPath }(localVerifierKeyPath)
+	localVerifierKeyPath = path.Join(t.TempDir(), "local_verifier_key")
+	localSpec := spec.LaunchSpec{Verifier: spec.LocalVerifier, VerifierConfig: `{"issuer":"https://verifier.example.com"}`}
+	localClient, err := newVerifierClient(ctx, localSpec, tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	testCases := []struct {
-		name          string
-		wantCELEvents []cel.CosType
-		launchSpec    spec.LaunchSpec
+		name           string
+		wantCELEvents  []cel.CosType
+		launchSpec     spec.LaunchSpec
+		verifierClient verifier.Client
+		initContainers []workloadContainer
+		sidecars       []workloadContainer
 	}{
 		{
 			name: "measure full container events and launch separator event",
@@ -589,11 +627,23 @@ func TestMeasureCELEvents(t *testing.T) {
 				cel.OverrideEnvType,
 				cel.OverrideArgType,
 				cel.MemoryMonitorType,
+				cel.AddedCapabilityType,
+				cel.CgroupNamespaceType,
+				cel.MountType,
+				cel.LogRedirectType,
+				cel.ImpersonatedServiceAccountType,
+				cel.HardenedImageType,
 				cel.LaunchSeparatorType,
 			},
 			launchSpec: spec.LaunchSpec{
-				Envs: []spec.EnvVar{{Name: "hello", Value: "world"}},
-				Cmd:  []string{"hello world"},
+				Envs:                       []spec.EnvVar{{Name: "hello", Value: "world"}},
+				Cmd:                        []string{"hello world"},
+				AddedCapabilities:          []string{"CAP_SYS_ADMIN"},
+				CgroupNamespace:            true,
+				Mounts:                     []launchermount.Mount{launchermount.TmpfsMount{Destination: "/tmpmount", Size: 1024}},
+				LogRedirect:                spec.Serial,
+				ImpersonateServiceAccounts: []string{"sa@project.iam.gserviceaccount.com"},
+				Hardened:                   true,
 			},
 		},
 		{
@@ -606,8 +656,77 @@ func TestMeasureCELEvents(t *testing.T) {
 				cel.ArgType,
 				cel.EnvVarType,
 				cel.MemoryMonitorType,
+				cel.CgroupNamespaceType,
+				cel.HardenedImageType,
+				cel.LaunchSeparatorType,
+			},
+		},
+		{
+			name: "measure init container and sidecar events after the main container events",
+			wantCELEvents: []cel.CosType{
+				cel.ImageRefType,
+				cel.ImageDigestType,
+				cel.RestartPolicyType,
+				cel.ImageIDType,
+				cel.ArgType,
+				cel.EnvVarType,
+				cel.MemoryMonitorType,
+				cel.CgroupNamespaceType,
+				cel.MountType,
+				cel.HardenedImageType,
+				cel.InitContainerType,
+				cel.ImageRefType,
+				cel.ImageDigestType,
+				cel.ImageIDType,
+				cel.ArgType,
+				cel.EnvVarType,
+				cel.MountType,
+				cel.AttestationAccessType,
+				cel.SidecarType,
+				cel.ImageRefType,
+				cel.ImageDigestType,
+				cel.ImageIDType,
+				cel.ArgType,
+				cel.EnvVarType,
+				cel.OverrideEnvType,
+				cel.OverrideArgType,
+				cel.MountType,
+				cel.AttestationAccessType,
+				cel.LaunchSeparatorType,
+			},
+			launchSpec: spec.LaunchSpec{
+				Mounts: []launchermount.Mount{launchermount.TmpfsMount{Destination: "/tmpmount", Size: 1024}},
+			},
+			initContainers: []workloadContainer{{
+				spec:      spec.Container{Name: "weights"},
+				container: fakeContainer,
+			}},
+			sidecars: []workloadContainer{{
+				spec: spec.Container{
+					Name: "proxy",
+					Cmd:  []string{"--port"},
+					Envs: []spec.EnvVar{{Name: "hello", Value: "world"}},
+				},
+				container: fakeContainer,
+			}},
+		},
+		{
+			name: "measure the local verifier key after the launch posture events",
+			wantCELEvents: []cel.CosType{
+				cel.ImageRefType,
+				cel.ImageDigestType,
+				cel.RestartPolicyType,
+				cel.ImageIDType,
+				cel.ArgType,
+				cel.EnvVarType,
+				cel.MemoryMonitorType,
+				cel.CgroupNamespaceType,
+				cel.HardenedImageType,
+				cel.LocalVerifierKeyType,
 				cel.LaunchSeparatorType,
 			},
+			launchSpec:     localSpec,
+			verifierClient: localClient,
 		},
 	}
 
@@ -626,10 +745,13 @@ func TestMeasureCELEvents(t *testing.T) {
 			}
 
 			r := ContainerRunner{
-				attestAgent: fakeAgent,
-				container:   fakeContainer,
-				launchSpec:  tc.launchSpec,
-				logger:      logging.SimpleLogger(),
+				attestAgent:    fakeAgent,
+				container:      fakeContainer,
+				launchSpec:     tc.launchSpec,
+				verifierClient: tc.verifierClient,
+				logger:         logging.SimpleLogger(),
+				initContainers: tc.initContainers,
+				sidecars:       tc.sidecars,
 			}
 
 			if err := r.measureCELEvents(ctx); err != nil {
@@ -643,6 +765,119 @@ func TestMeasureCELEvents(t *testing.T) {
 	}
 }
 
+func TestRestartWithLocalVerifier(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	ctx := context.Background()
+	journalDir := t.TempDir()
+	defer func(keyPath string) { localVerifierKeyPath = keyPath }(localVerifierKeyPath)
+	localVerifierKeyPath = path.Join(journalDir, "local_verifier_key")
+
+	launchSpec := spec.LaunchSpec{Verifier: spec.LocalVerifier, VerifierConfig: `{"issuer":"https://verifier.example.com"}`}
+	start := func() *ContainerRunner {
+		verifierClient, err := newVerifierClient(ctx, launchSpec, tpm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		attestAgent, err := agent.CreateAttestationAgent(tpm, client.AttestationKeyECC, verifierClient, func(string) ([][]byte, error) { return nil, nil }, signaturediscovery.NewFakeClient(), launchSpec, logging.SimpleLogger(), journalDir)
+		if err != nil {
+			t.Fatalf("CreateAttestationAgent() failed: %v", err)
+		}
+		r := &ContainerRunner{
+			container: &fakeContainer{
+				image: &fakeImage{name: "fake image name", digest: "fake digest", id: "fake id"},
+			},
+			launchSpec:     launchSpec,
+			attestAgent:    attestAgent,
+			verifierClient: verifierClient,
+			logger:         logging.SimpleLogger(),
+		}
+		if err := r.measureCELEvents(ctx); err != nil {
+			t.Fatalf("failed to measureCELEvents: %v", err)
+		}
+		return r
+	}
+
+	first := start()
+	sealed, err := first.attestAgent.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	firstKey := first.verifierClient.(interface{ Public() crypto.PublicKey }).Public()
+	first.attestAgent.Close()
+
+	// The restarted launcher signs with the same key, so it resumes the CEL
+	// measuring it, and the COS event PCR keeps the value data was sealed to.
+	second := start()
+	defer second.attestAgent.Close()
+	if len(second.attestAgent.ResumedEvents()) == 0 {
+		t.Error("the restarted launcher did not resume the CEL")
+	}
+	if secondKey := second.verifierClient.(interface{ Public() crypto.PublicKey }).Public(); !firstKey.(*ecdsa.PublicKey).Equal(secondKey) {
+		t.Error("the restarted launcher signs with another local verifier key")
+	}
+	if got, err := second.attestAgent.Unseal(sealed); err != nil || string(got) != "secret" {
+		t.Errorf("Unseal() after the restart got %q, %v, want %q", got, err, "secret")
+	}
+}
+
+func TestMeasureCELEventsResumed(t *testing.T) {
+	ctx := context.Background()
+	fakeContainer := &fakeContainer{
+		image: &fakeImage{
+			name:   "fake image name",
+			digest: "fake digest",
+			id:     "fake id",
+		},
+		args: []string{"fake args"},
+		env:  []string{"fake env"},
+	}
+	measure := func(resumed []cel.TLV) ([]cel.TLV, error) {
+		var measured []cel.TLV
+		r := ContainerRunner{
+			attestAgent: &fakeAttestationAgent{
+				measureEventFunc: func(content cel.Content) error {
+					tlv, err := content.GetTLV()
+					measured = append(measured, tlv)
+					return err
+				},
+				resumedEvents: resumed,
+			},
+			container: fakeContainer,
+			logger:    logging.SimpleLogger(),
+		}
+		err := r.measureCELEvents(ctx)
+		return measured, err
+	}
+
+	launch, err := measure(nil)
+	if err != nil {
+		t.Fatalf("failed to measureCELEvents: %v", err)
+	}
+	shutdown, _ := cel.CosTlv{EventType: cel.ShutdownType, EventContent: []byte("SIGTERM")}.GetTLV()
+
+	// The launch was fully measured before the restart, so nothing is measured
+	// again, whatever was measured after the launch separator.
+	if got, err := measure(append(append([]cel.TLV{}, launch...), shutdown)); err != nil || len(got) != 0 {
+		t.Errorf("measureCELEvents() with a resumed launch measured %d events, err = %v, want none", len(got), err)
+	}
+	// The launcher restarted while measuring the launch, so the rest of it is
+	// measured.
+	got, err := measure(launch[:3])
+	if err != nil {
+		t.Fatalf("failed to measureCELEvents: %v", err)
+	}
+	if diff := cmp.Diff(launch[3:], got); diff != "" {
+		t.Errorf("measureCELEvents() with a partially resumed launch returned unexpected diff (-want +got):\n%s", diff)
+	}
+	// The resumed CEL measured a different launch.
+	other, _ := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("other image")}.GetTLV()
+	if _, err := measure([]cel.TLV{other}); err == nil {
+		t.Error("measureCELEvents() with a different resumed launch got nil error, want error")
+	}
+}
+
 func TestPullImageWithRetries(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -733,3 +968,122 @@ func (i *fakeImage) Target() v1.Descriptor {
 func (i *fakeImage) Config(_ context.Context) (v1.Descriptor, error) {
 	return v1.Descriptor{Digest: i.id}, nil
 }
+
+// fakeTask sends the exit status of a task when it gets one of exitOn.
+type fakeTask struct {
+	exitStatusC chan containerd.ExitStatus
+	exitOn      map[syscall.Signal]uint32
+	signals     []syscall.Signal
+}
+
+func (f *fakeTask) Kill(_ context.Context, sig syscall.Signal, _ ...containerd.KillOpts) error {
+	f.signals = append(f.signals, sig)
+	if code, ok := f.exitOn[sig]; ok {
+		f.exitStatusC <- *containerd.NewExitStatus(code, time.Now(), nil)
+	}
+	return nil
+}
+
+func TestWaitTask(t *testing.T) {
+	testCases := []struct {
+		name string
+		// exited is true if the task exits with wantCode before any signal.
+		exited      bool
+		stop        bool
+		exitOn      map[syscall.Signal]uint32
+		wantCode    uint32
+		wantErr     error
+		wantSignals []syscall.Signal
+		wantEvents  []cel.CosTlv
+	}{
+		{
+			name:     "task exits",
+			exited:   true,
+			wantCode: 3,
+		},
+		{
+			name:        "task exits on the forwarded signal",
+			stop:        true,
+			exitOn:      map[syscall.Signal]uint32{syscall.SIGTERM: 143},
+			wantCode:    143,
+			wantErr:     &StoppedError{Signal: "SIGTERM", ReturnCode: 143},
+			wantSignals: []syscall.Signal{syscall.SIGTERM},
+			wantEvents:  []cel.CosTlv{{EventType: cel.ShutdownType, EventContent: []byte("SIGTERM")}},
+		},
+		{
+			name:        "task killed after the grace period",
+			stop:        true,
+			exitOn:      map[syscall.Signal]uint32{syscall.SIGKILL: 137},
+			wantCode:    137,
+			wantErr:     &StoppedError{Signal: "SIGTERM", Killed: true, ReturnCode: 137},
+			wantSignals: []syscall.Signal{syscall.SIGTERM, syscall.SIGKILL},
+			wantEvents:  []cel.CosTlv{{EventType: cel.ShutdownType, EventContent: []byte("SIGTERM")}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotEvents []cel.CosTlv
+			runner := ContainerRunner{
+				attestAgent: &fakeAttestationAgent{
+					measureEventFunc: func(content cel.Content) error {
+						gotEvents = append(gotEvents, content.(cel.CosTlv))
+						return nil
+					},
+				},
+				launchSpec: spec.LaunchSpec{StopGracePeriod: 10 * time.Millisecond},
+				logger:     logging.SimpleLogger(),
+			}
+			task := &fakeTask{exitStatusC: make(chan containerd.ExitStatus, 1), exitOn: tc.exitOn}
+			stopC := make(chan os.Signal, 1)
+			if tc.exited {
+				task.exitStatusC <- *containerd.NewExitStatus(tc.wantCode, time.Now(), nil)
+			}
+			if tc.stop {
+				stopC <- syscall.SIGTERM
+			}
+
+			code, err := runner.waitTask(context.Background(), task, task.exitStatusC, stopC)
+			if code != tc.wantCode {
+				t.Errorf("waitTask() got code %d, want %d", code, tc.wantCode)
+			}
+			if !cmp.Equal(err, tc.wantErr) {
+				t.Errorf("waitTask() got error %v, want %v", err, tc.wantErr)
+			}
+			if !cmp.Equal(task.signals, tc.wantSignals) {
+				t.Errorf("waitTask() sent signals %v, want %v", task.signals, tc.wantSignals)
+			}
+			if !cmp.Equal(gotEvents, tc.wantEvents) {
+				t.Errorf("waitTask() measured events %v, want %v", gotEvents, tc.wantEvents)
+			}
+		})
+	}
+}
+
+func TestWorkloadContainerMounts(t *testing.T) {
+	shared := appendSharedVolumeMount(nil)
+	for _, tc := range []struct {
+		name          string
+		containerSpec spec.Container
+		wantToken     bool
+	}{
+		{"NoAttestationAccess", spec.Container{Name: "proxy"}, false},
+		{"AttestationAccess", spec.Container{Name: "proxy", AttestationAccess: true}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mounts := workloadContainerMounts(tc.containerSpec, shared)
+			gotToken := false
+			for _, m := range mounts {
+				if m.Source == launcherfile.HostTmpPath {
+					gotToken = true
+				}
+			}
+			if gotToken != tc.wantToken {
+				t.Errorf("workloadContainerMounts() got the attestation token mount %v, want %v", gotToken, tc.wantToken)
+			}
+			if len(shared) != 1 {
+				t.Errorf("workloadContainerMounts() modified the shared mounts: %v", shared)
+			}
+		})
+	}
+}
@@ -12,6 +12,17 @@ type WorkloadError struct {
 	ReturnCode uint32
 }
 
+// StoppedError means the launcher was stopped by a signal (e.g., on VM
+// shutdown or preemption), which it forwarded to the workload task.
+type StoppedError struct {
+	// Signal is the name of the signal, e.g., "SIGTERM".
+	Signal string
+	// Killed is true if the task did not exit within the grace period, and was
+	// killed.
+	Killed     bool
+	ReturnCode uint32
+}
+
 func (e *RetryableError) Error() string {
 	return fmt.Sprintf("failed with retryable error: %v", e.Err.Error())
 }
@@ -19,3 +30,10 @@ func (e *RetryableError) Error() string {
 func (e *WorkloadError) Error() string {
 	return "workload finished with a non-zero return code"
 }
+
+func (e *StoppedError) Error() string {
+	if e.Killed {
+		return fmt.Sprintf("workload stopped by %v: killed after the grace period", e.Signal)
+	}
+	return fmt.Sprintf("workload stopped by %v: exited with return code %d", e.Signal, e.ReturnCode)
+}
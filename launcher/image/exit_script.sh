@@ -1,5 +1,16 @@
 #! /bin/bash
 
+# EXIT_STATUS is the return code of the launcher, see launcher/launcher/main.go.
+case $EXIT_STATUS in
+	0) echo "exit_script: workload finished successfully" ;;
+	1) echo "exit_script: workload or launcher error" ;;
+	2) echo "exit_script: launcher panicked" ;;
+	3) echo "exit_script: rebooting the VM" ;;
+	4) echo "exit_script: VM remains running" ;;
+	5) echo "exit_script: workload stopped by a signal" ;;
+	*) echo "exit_script: unknown exit status: $EXIT_STATUS" ;;
+esac
+
 if [[ $EXIT_STATUS -eq 3 ]]
 then
 	# reboot after 2 min
@@ -11,3 +22,11 @@ then
 	# poweroff after 2 min
 	shutdown --poweroff +2
 fi
+
+if [[ $EXIT_STATUS -eq 5 ]] && [[ "$(systemctl is-system-running)" != "stopping" ]]
+then
+	# The launcher of a hardened image was stopped while the VM keeps running,
+	# which must not keep running without its workload: poweroff after 2 min.
+	# If the VM is already shutting down, it is left to finish.
+	shutdown --poweroff +2
+fi
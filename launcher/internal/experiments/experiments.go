@@ -16,6 +16,7 @@ type Experiments struct {
 	EnableHealthMonitoring    bool
 	EnableItaVerifier         bool
 	EnablePrivilegedCS        bool
+	EnableCelVersionEvent     bool
 }
 
 // New takes a filepath, opens the file, and calls ReadJsonInput with the contents
@@ -0,0 +1,57 @@
+package metrics
+
+import "time"
+
+// Default is the registry of the launcher metrics, served by the TEE server.
+var Default = NewRegistry()
+
+// DurationBuckets are the buckets of the latency histograms, in seconds.
+var DurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Values of the "result" label.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Result returns the "result" label value of an operation returning err.
+func Result(err error) string {
+	if err != nil {
+		return Failure
+	}
+	return Success
+}
+
+// Since returns the seconds elapsed since start, for a latency histogram.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// The launcher metrics.
+var (
+	AttestTotal = Default.NewCounter("launcher_attest_total",
+		"Attestations to a verifier, by verifier and result.", "verifier", "result")
+	AttestDuration = Default.NewHistogram("launcher_attest_duration_seconds",
+		"Latency of the attestations to a verifier, by verifier.", DurationBuckets, "verifier")
+
+	TokenRefreshTotal = Default.NewCounter("launcher_token_refresh_total",
+		"Refreshes of the default token, by result.", "result")
+	TokenRefreshDuration = Default.NewHistogram("launcher_token_refresh_duration_seconds",
+		"Latency of the refreshes of the default token.", DurationBuckets)
+	TokenRefreshLag = Default.NewHistogram("launcher_token_refresh_lag_seconds",
+		"Delay of the successful refreshes of the default token after their schedule, retries included.", DurationBuckets)
+	TokenExpiry = Default.NewGauge("launcher_token_expiry_timestamp_seconds",
+		"Expiry of the default token, in seconds since the Unix epoch.")
+
+	ImagePullTotal = Default.NewCounter("launcher_image_pull_total",
+		"Image pulls, retries included, by result.", "result")
+	ImagePullRetries = Default.NewCounter("launcher_image_pull_retries_total",
+		"Retried image pull attempts.")
+	ImagePullDuration = Default.NewHistogram("launcher_image_pull_duration_seconds",
+		"Latency of the image pulls, retries included.", DurationBuckets)
+
+	SignatureDiscoveryTotal = Default.NewCounter("launcher_signature_discovery_total",
+		"Container image signature fetches from a repository, retries included, by result.", "result")
+	SignatureDiscoveryDuration = Default.NewHistogram("launcher_signature_discovery_duration_seconds",
+		"Latency of the container image signature fetches from a repository, retries included.", DurationBuckets)
+)
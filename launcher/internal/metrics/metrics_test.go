@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	attests := r.NewCounter("test_attest_total", "Attestations, by verifier and result.", "verifier", "result")
+	expiry := r.NewGauge("test_expiry_seconds", "Token expiry.")
+	latency := r.NewHistogram("test_latency_seconds", "Latency,\nin seconds.", []float64{0.5, 1}, "verifier")
+	r.NewCounter("test_unused_total", "Never incremented.")
+
+	attests.Inc("gca", Result(nil))
+	attests.Inc("gca", Result(nil))
+	attests.Add(3, "ita", Result(errors.New("failed")))
+	attests.Inc(`a"b\c`, Success)
+	expiry.Set(1700000000)
+	latency.Observe(0.25, "gca")
+	latency.Observe(0.5, "gca")
+	latency.Observe(2, "gca")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_attest_total Attestations, by verifier and result.
+# TYPE test_attest_total counter
+test_attest_total{verifier="a\"b\\c",result="success"} 1
+test_attest_total{verifier="gca",result="success"} 2
+test_attest_total{verifier="ita",result="failure"} 3
+# HELP test_expiry_seconds Token expiry.
+# TYPE test_expiry_seconds gauge
+test_expiry_seconds 1.7e+09
+# HELP test_latency_seconds Latency,\nin seconds.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{verifier="gca",le="0.5"} 2
+test_latency_seconds_bucket{verifier="gca",le="1"} 2
+test_latency_seconds_bucket{verifier="gca",le="+Inf"} 3
+test_latency_seconds_sum{verifier="gca"} 2.75
+test_latency_seconds_count{verifier="gca"} 3
+# HELP test_unused_total Never incremented.
+# TYPE test_unused_total counter
+`
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("Write() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "Test.").Inc()
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("got content type %q, want %q", got, ContentType)
+	}
+	if !strings.Contains(w.Body.String(), "test_total 1\n") {
+		t.Errorf("got metrics %q, want test_total 1", w.Body.String())
+	}
+}
+
+func TestMisuse(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		f    func(r *Registry)
+	}{
+		{"RegisteredTwice", func(r *Registry) {
+			r.NewCounter("test_total", "Test.")
+			r.NewGauge("test_total", "Test.")
+		}},
+		{"WrongLabelValues", func(r *Registry) {
+			r.NewCounter("test_total", "Test.", "result").Inc()
+		}},
+		{"DecreasingCounter", func(r *Registry) {
+			r.NewCounter("test_total", "Test.").Add(-1)
+		}},
+		{"UnsortedBuckets", func(r *Registry) {
+			r.NewHistogram("test_seconds", "Test.", []float64{1, 0.5})
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("got no panic, want panic")
+				}
+			}()
+			tc.f(NewRegistry())
+		})
+	}
+}
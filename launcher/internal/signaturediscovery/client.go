@@ -42,6 +42,36 @@ func New(originalImageDesc v1.Descriptor, resolverFetcher remoteResolverFetcher,
 	}
 }
 
+type multiFetcher []Fetcher
+
+// NewMulti returns a Fetcher which fetches the signatures of the images of all
+// the fetchers (e.g., of all the containers of a workload) from the target
+// repository. It only fails if all the fetchers fail, so that the signatures
+// of one image are still found if there are none for another image.
+func NewMulti(fetchers ...Fetcher) Fetcher {
+	return multiFetcher(fetchers)
+}
+
+// FetchImageSignatures returns the signatures found by all the fetchers.
+func (m multiFetcher) FetchImageSignatures(ctx context.Context, targetRepository string) ([]oci.Signature, error) {
+	var signatures []oci.Signature
+	var lastErr error
+	succeeded := false
+	for _, f := range m {
+		sigs, err := f.FetchImageSignatures(ctx, targetRepository)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded = true
+		signatures = append(signatures, sigs...)
+	}
+	if !succeeded && lastErr != nil {
+		return nil, lastErr
+	}
+	return signatures, nil
+}
+
 // FetchSignedImageManifest fetches a signed image manifest using a tag-based discovery mechanism.
 func (c *Client) FetchSignedImageManifest(ctx context.Context, targetRepository string) (v1.Manifest, error) {
 	image, err := c.pullSignatureImage(ctx, targetRepository)
@@ -11,6 +11,7 @@ import (
 	"github.com/containerd/containerd/remotes"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/launcher/registryauth"
+	"github.com/google/go-tpm-tools/verifier/oci"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -166,3 +167,35 @@ func createTestClient(t *testing.T, originalImageDesc v1.Descriptor) *Client {
 		imageFetcher:      imageFetcher,
 	}
 }
+
+type failingFetcher struct{}
+
+func (failingFetcher) FetchImageSignatures(_ context.Context, targetRepository string) ([]oci.Signature, error) {
+	return nil, fmt.Errorf("no signatures in %v", targetRepository)
+}
+
+func TestMultiFetcher(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		fetchers []Fetcher
+		repo     string
+		wantSigs int
+		wantErr  bool
+	}{
+		{"all images signed", []Fetcher{NewFakeClient(), NewFakeClient()}, FakeRepoWithSignatures, 4, false},
+		{"one image unsigned", []Fetcher{NewFakeClient(), failingFetcher{}}, FakeRepoWithSignatures, 2, false},
+		{"no image signed", []Fetcher{NewFakeClient(), failingFetcher{}}, FakeRepoWithNoSignatures, 0, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sigs, err := NewMulti(tc.fetchers...).FetchImageSignatures(ctx, tc.repo)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("FetchImageSignatures() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(sigs) != tc.wantSigs {
+				t.Errorf("FetchImageSignatures() got %d signatures, want %d", len(sigs), tc.wantSigs)
+			}
+		})
+	}
+}
@@ -30,8 +30,9 @@ const (
 	successRC = 0 // workload successful (no reboot)
 	failRC    = 1 // workload or launcher internal failed (no reboot)
 	// panic() returns 2
-	rebootRC = 3 // reboot
-	holdRC   = 4 // hold
+	rebootRC  = 3 // reboot
+	holdRC    = 4 // hold
+	stoppedRC = 5 // workload stopped by a signal (no restart)
 )
 
 var expectedTPMDAParams = launcher.TPMDAParams{
@@ -45,6 +46,7 @@ var rcMessage = map[int]string{
 	failRC:    "workload or launcher error, shutting down the VM",
 	rebootRC:  "rebooting VM",
 	holdRC:    "VM remains running",
+	stoppedRC: "workload stopped by a signal, shutting down the VM unless it already is",
 }
 
 // BuildCommit shows the commit when building the binary, set by -ldflags when building
@@ -133,6 +135,12 @@ func main() {
 	exitCode = getExitCode(launchSpec.Hardened, launchSpec.RestartPolicy, err)
 }
 
+// getExitCode returns the exit code of the launcher, which exit_script.sh acts
+// on. A debug image always holds the VM. On a hardened image, a workload
+// stopped by a signal is exempt from the restart policy: the stop was
+// requested, by the VM shutting down or by stopping the launcher, so the
+// workload is not restarted, and the VM is powered off unless it is already
+// shutting down.
 func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) int {
 	exitCode := 0
 
@@ -141,6 +149,11 @@ func getExitCode(isHardened bool, restartPolicy spec.RestartPolicy, err error) i
 		return holdRC
 	}
 
+	// the VM is shutting down, or the launcher was stopped on purpose
+	if _, ok := err.(*launcher.StoppedError); ok {
+		return stoppedRC
+	}
+
 	if err != nil {
 		switch err.(type) {
 		default:
@@ -120,6 +120,24 @@ func TestGetExitCode(t *testing.T) {
 			"hardened, onfailure restart, non-retryable error",
 			true, spec.OnFailure, errors.New(""), failRC,
 		},
+		// stopped error, held on a debug image
+		{
+			"debug, always restart, stopped error",
+			false, spec.Always, &launcher.StoppedError{}, holdRC,
+		},
+		// stopped error, on a hardened image regardless of the restart policy
+		{
+			"hardened, on failure restart, stopped error",
+			true, spec.OnFailure, &launcher.StoppedError{}, stoppedRC,
+		},
+		{
+			"hardened, always restart, stopped error",
+			true, spec.Always, &launcher.StoppedError{}, stoppedRC,
+		},
+		{
+			"hardened, never restart, stopped error",
+			true, spec.Never, &launcher.StoppedError{Killed: true}, stoppedRC,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -9,4 +9,13 @@ const (
 	ContainerRuntimeMountPath = "/run/container_launcher/"
 	// AttestationVerifierTokenFilename defines the name of the file the attestation token is stored in.
 	AttestationVerifierTokenFilename = "attestation_verifier_claims_token"
+	// CELJournalPath defined the directory in the host that stores the CEL journals of the
+	// attestation agent. It is not shared with the container, and does not outlive a reboot.
+	CELJournalPath = "/run/container_launcher_cel/"
+	// HostSharedVolumePath defined the directory in the host backing the volume shared by
+	// the main container, its init containers and its sidecars.
+	HostSharedVolumePath = "/tmp/container_launcher_shared/"
+	// ContainerSharedVolumePath defined the directory in the containers where the volume
+	// shared by the main container, its init containers and its sidecars is mounted.
+	ContainerSharedVolumePath = "/run/container_launcher_shared/"
 )
@@ -21,6 +21,9 @@ type LaunchPolicy struct {
 	DebugImageMonitoring     MonitoringType
 	PrivilegedCaps           bool
 	AllowCgroups             bool
+	AllowInitContainers      bool
+	AllowSidecars            bool
+	AllowLocalVerifier       bool
 }
 
 type policy int
@@ -110,9 +113,12 @@ const (
 	// Empty paths will be ignored and relative paths will be interpreted as
 	// relative to "/".
 	// Paths will be cleaned using filepath.Clean.
-	mountDestinations = "tee.launch_policy.allow_mount_destinations"
-	privilegedCaps    = "tee.launch_policy.allow_capabilities"
-	allowCgroups      = "tee.launch_policy.allow_cgroups"
+	mountDestinations   = "tee.launch_policy.allow_mount_destinations"
+	privilegedCaps      = "tee.launch_policy.allow_capabilities"
+	allowCgroups        = "tee.launch_policy.allow_cgroups"
+	allowInitContainers = "tee.launch_policy.allow_init_containers"
+	allowSidecars       = "tee.launch_policy.allow_sidecars"
+	allowLocalVerifier  = "tee.launch_policy.allow_local_verifier"
 )
 
 func configureMonitoringPolicy(imageLabels map[string]string, launchPolicy *LaunchPolicy, logger logging.Logger) error {
@@ -231,6 +237,24 @@ func GetLaunchPolicy(imageLabels map[string]string, logger logging.Logger) (Laun
 		}
 	}
 
+	if v, ok := imageLabels[allowInitContainers]; ok {
+		if launchPolicy.AllowInitContainers, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean)", allowInitContainers)
+		}
+	}
+
+	if v, ok := imageLabels[allowSidecars]; ok {
+		if launchPolicy.AllowSidecars, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean)", allowSidecars)
+		}
+	}
+
+	if v, ok := imageLabels[allowLocalVerifier]; ok {
+		if launchPolicy.AllowLocalVerifier, err = strconv.ParseBool(v); err != nil {
+			return LaunchPolicy{}, fmt.Errorf("invalid image LABEL '%s' (not a boolean)", allowLocalVerifier)
+		}
+	}
+
 	return launchPolicy, nil
 }
 
@@ -257,13 +281,8 @@ func verifyMonitoringConfig(policy MonitoringType, spec MonitoringType) error {
 // Verify will use the LaunchPolicy to verify the given LaunchSpec. If the verification passed, will return nil.
 // If there are multiple violations, the function will return the first error.
 func (p LaunchPolicy) Verify(ls LaunchSpec) error {
-	for _, e := range ls.Envs {
-		if !contains(p.AllowedEnvOverride, e.Name) {
-			return fmt.Errorf("env var %s is not allowed to be overridden on this image; allowed envs to be overridden: %v", e, p.AllowedEnvOverride)
-		}
-	}
-	if !p.AllowedCmdOverride && len(ls.Cmd) > 0 {
-		return fmt.Errorf("CMD is not allowed to be overridden on this image")
+	if err := p.verifyOverrides(ls.Envs, ls.Cmd); err != nil {
+		return err
 	}
 
 	if p.AllowedLogRedirect == never && ls.LogRedirect.enabled() {
@@ -299,6 +318,40 @@ func (p LaunchPolicy) Verify(ls LaunchSpec) error {
 		return errors.New("cgroups usage is not allowed")
 	}
 
+	if len(ls.InitContainers) != 0 && !p.AllowInitContainers {
+		return errors.New("init containers are not allowed")
+	}
+
+	if len(ls.Sidecars) != 0 && !p.AllowSidecars {
+		return errors.New("sidecars are not allowed")
+	}
+
+	if ls.Verifier == LocalVerifier && !p.AllowLocalVerifier {
+		return errors.New("the local verifier is not allowed")
+	}
+
+	return nil
+}
+
+// VerifyContainer will use the LaunchPolicy of the image of an init container
+// or a sidecar to verify its overrides. If the verification passed, will
+// return nil.
+func (p LaunchPolicy) VerifyContainer(c Container) error {
+	if err := p.verifyOverrides(c.Envs, c.Cmd); err != nil {
+		return fmt.Errorf("container %q: %v", c.Name, err)
+	}
+	return nil
+}
+
+func (p LaunchPolicy) verifyOverrides(envs []EnvVar, cmd []string) error {
+	for _, e := range envs {
+		if !contains(p.AllowedEnvOverride, e.Name) {
+			return fmt.Errorf("env var %s is not allowed to be overridden on this image; allowed envs to be overridden: %v", e, p.AllowedEnvOverride)
+		}
+	}
+	if !p.AllowedCmdOverride && len(cmd) > 0 {
+		return fmt.Errorf("CMD is not allowed to be overridden on this image")
+	}
 	return nil
 }
 
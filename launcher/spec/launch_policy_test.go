@@ -43,6 +43,26 @@ func TestLaunchPolicy(t *testing.T) {
 				AllowedCmdOverride: false,
 			},
 		},
+		{
+			"init containers and sidecars allowed",
+			map[string]string{
+				allowInitContainers: "true",
+				allowSidecars:       "true",
+			},
+			LaunchPolicy{
+				AllowInitContainers: true,
+				AllowSidecars:       true,
+			},
+		},
+		{
+			"local verifier allowed",
+			map[string]string{
+				allowLocalVerifier: "true",
+			},
+			LaunchPolicy{
+				AllowLocalVerifier: true,
+			},
+		},
 		{
 			"empty string in ENV override",
 			map[string]string{
@@ -564,6 +584,62 @@ func TestVerify(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"sidecars allowed and used",
+			LaunchPolicy{
+				AllowSidecars: true,
+			},
+			LaunchSpec{
+				Sidecars: []Container{{Name: "proxy", ImageRef: "docker.io/library/envoy:latest"}},
+			},
+			false,
+		},
+		{
+			"init containers allowed and used",
+			LaunchPolicy{
+				AllowInitContainers: true,
+			},
+			LaunchSpec{
+				InitContainers: []Container{{Name: "weights", ImageRef: "gcr.io/project/fetch-weights:latest"}},
+			},
+			false,
+		},
+		{
+			"init containers not allowed but used",
+			LaunchPolicy{
+				AllowSidecars: true,
+			},
+			LaunchSpec{
+				InitContainers: []Container{{Name: "weights", ImageRef: "gcr.io/project/fetch-weights:latest"}},
+			},
+			true,
+		},
+		{
+			"sidecars not allowed but used",
+			LaunchPolicy{},
+			LaunchSpec{
+				Sidecars: []Container{{Name: "proxy", ImageRef: "docker.io/library/envoy:latest"}},
+			},
+			true,
+		},
+		{
+			"local verifier allowed and used",
+			LaunchPolicy{
+				AllowLocalVerifier: true,
+			},
+			LaunchSpec{
+				Verifier: LocalVerifier,
+			},
+			false,
+		},
+		{
+			"local verifier not allowed but used",
+			LaunchPolicy{},
+			LaunchSpec{
+				Verifier: LocalVerifier,
+			},
+			true,
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.testName, func(t *testing.T) {
@@ -581,6 +657,53 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyContainer(t *testing.T) {
+	testCases := []struct {
+		testName  string
+		policy    LaunchPolicy
+		container Container
+		expectErr bool
+	}{
+		{
+			"no overrides",
+			LaunchPolicy{},
+			Container{Name: "proxy"},
+			false,
+		},
+		{
+			"allowed overrides",
+			LaunchPolicy{
+				AllowedEnvOverride: []string{"foo"},
+				AllowedCmdOverride: true,
+			},
+			Container{Name: "proxy", Envs: []EnvVar{{Name: "foo", Value: "bar"}}, Cmd: []string{"--foo"}},
+			false,
+		},
+		{
+			"env override violation",
+			LaunchPolicy{
+				AllowedEnvOverride: []string{"foo"},
+			},
+			Container{Name: "proxy", Envs: []EnvVar{{Name: "bar", Value: ""}}},
+			true,
+		},
+		{
+			"cmd violation",
+			LaunchPolicy{},
+			Container{Name: "proxy", Cmd: []string{"--foo"}},
+			true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			err := testCase.policy.VerifyContainer(testCase.container)
+			if gotErr := err != nil; gotErr != testCase.expectErr {
+				t.Errorf("VerifyContainer() got error %v, want error %v", err, testCase.expectErr)
+			}
+		})
+	}
+}
+
 func TestVerifyMonitoringSettings(t *testing.T) {
 	testCases := []struct {
 		testName   string
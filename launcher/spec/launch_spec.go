@@ -10,8 +10,10 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
 
@@ -73,6 +75,32 @@ const (
 	Nowhere      LogRedirectLocation = "false"
 )
 
+// VerifierType names the verifier service which mints the attestation tokens
+// of the workload.
+type VerifierType string
+
+func (v VerifierType) isValid() error {
+	switch v {
+	case GCAVerifier, ITAVerifier, LocalVerifier:
+		return nil
+	}
+	return fmt.Errorf("invalid verifier %s, expect one of %s", v,
+		[]VerifierType{GCAVerifier, ITAVerifier, LocalVerifier})
+}
+
+// VerifierType acceptable values.
+const (
+	// GCAVerifier is Google Cloud Attestation, the default verifier.
+	GCAVerifier VerifierType = "gca"
+	// ITAVerifier is Intel Trust Authority, configured by ita-region and
+	// ita-api-key.
+	ITAVerifier VerifierType = "ita"
+	// LocalVerifier verifies the attestations in the launcher, and mints tokens
+	// with a key generated in the VM. It is configured by tee-verifier-config,
+	// and requires the tee.launch_policy.allow_local_verifier image label.
+	LocalVerifier VerifierType = "local"
+)
+
 // Metadata variable names.
 const (
 	imageRefKey                = "tee-image-reference"
@@ -91,8 +119,19 @@ const (
 	itaKey                     = "ita-api-key"
 	addedCaps                  = "tee-added-capabilities"
 	cgroupNS                   = "tee-cgroup-ns"
+	initContainersKey          = "tee-init-containers"
+	sidecarsKey                = "tee-sidecars"
+	verifierKey                = "tee-verifier"
+	verifierConfigKey          = "tee-verifier-config"
+	tokenRateLimitKey          = "tee-token-rate-limit"
+	tokenMaxConcurrencyKey     = "tee-token-max-concurrency"
+	stopGracePeriodKey         = "tee-stop-grace-period-sec"
 )
 
+// defaultStopGracePeriod leaves the workload time to exit on a stop signal
+// within the 30 seconds of a Spot VM preemption.
+const defaultStopGracePeriod = 25 * time.Second
+
 const (
 	instanceAttributesQuery = "instance/attributes/?recursive=true"
 )
@@ -105,6 +144,54 @@ type EnvVar struct {
 	Value string
 }
 
+// Container describes a container of the workload other than the main
+// container: an init container, run to completion before the main container
+// starts (e.g., to fetch model weights), or a sidecar, run alongside the main
+// container (e.g., a proxy or a log shipper). These containers share the
+// network and the mounts of the main container, and sidecars are stopped when
+// the main container exits.
+type Container struct {
+	// Name identifies the container in the workload, and must be a DNS label.
+	Name     string   `json:"name"`
+	ImageRef string   `json:"image"`
+	Cmd      []string `json:"cmd,omitempty"`
+	Envs     []EnvVar `json:"env,omitempty"`
+	// AttestationAccess gives the container the attestation token and the TEE
+	// server socket of the main container, which it lacks by default.
+	AttestationAccess bool `json:"attestation_access,omitempty"`
+}
+
+var containerNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+func (c Container) isValid() error {
+	if !containerNameRegexp.MatchString(c.Name) {
+		return fmt.Errorf("invalid container name %q: must be a lowercase DNS label", c.Name)
+	}
+	if c.ImageRef == "" {
+		return fmt.Errorf("image is not specified for container %q", c.Name)
+	}
+	return nil
+}
+
+// unmarshalContainers parses a JSON list of Container, and checks that their
+// names are not in names, adding them to it.
+func unmarshalContainers(key, val string, names map[string]bool) ([]Container, error) {
+	var containers []Container
+	if err := json.Unmarshal([]byte(val), &containers); err != nil {
+		return nil, fmt.Errorf("invalid value for %v: %v", key, err)
+	}
+	for _, c := range containers {
+		if err := c.isValid(); err != nil {
+			return nil, err
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("found more than one container named %q", c.Name)
+		}
+		names[c.Name] = true
+	}
+	return containers, nil
+}
+
 // LaunchSpec contains specification set by the operator who wants to
 // launch a container.
 type LaunchSpec struct {
@@ -130,6 +217,21 @@ type LaunchSpec struct {
 	DevShmSize        int64
 	AddedCapabilities []string
 	CgroupNamespace   bool
+	InitContainers    []Container
+	Sidecars          []Container
+	Verifier          VerifierType
+	// VerifierConfig is the JSON configuration of the verifier, parsed by its
+	// backend in the launcher.
+	VerifierConfig string
+	// TokenRateLimit is the number of tokens per minute, and
+	// TokenMaxConcurrency the number of concurrent token requests, each token
+	// endpoint of the TEE server requests from the verifier. Zero, the
+	// default, disables the limit, until the operator opts in.
+	TokenRateLimit      int
+	TokenMaxConcurrency int
+	// StopGracePeriod is how long the workload task has to exit after the
+	// launcher forwards it a stop signal, before it is killed.
+	StopGracePeriod time.Duration
 }
 
 // UnmarshalJSON unmarshals an instance attributes list in JSON format from the metadata
@@ -207,6 +309,22 @@ func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
 		}
 	}
 
+	// Populate init containers and sidecars, as JSON lists of Container with
+	// unique names.
+	containerNames := make(map[string]bool)
+	if val, ok := unmarshaledMap[initContainersKey]; ok && val != "" {
+		var err error
+		if s.InitContainers, err = unmarshalContainers(initContainersKey, val, containerNames); err != nil {
+			return err
+		}
+	}
+	if val, ok := unmarshaledMap[sidecarsKey]; ok && val != "" {
+		var err error
+		if s.Sidecars, err = unmarshalContainers(sidecarsKey, val, containerNames); err != nil {
+			return err
+		}
+	}
+
 	// Populate all env vars.
 	for k, v := range unmarshaledMap {
 		if strings.HasPrefix(k, envKeyPrefix) {
@@ -267,6 +385,46 @@ func (s *LaunchSpec) UnmarshalJSON(b []byte) error {
 		}
 	}
 
+	// Before tee-verifier, ITA was selected by setting its region and key.
+	s.Verifier = VerifierType(unmarshaledMap[verifierKey])
+	if s.Verifier == "" {
+		s.Verifier = GCAVerifier
+		if s.ITARegion != "" {
+			s.Verifier = ITAVerifier
+		}
+	}
+	if err := s.Verifier.isValid(); err != nil {
+		return err
+	}
+	if s.Verifier == ITAVerifier && s.ITARegion == "" {
+		return fmt.Errorf("verifier %s requires %s and %s", ITAVerifier, itaRegion, itaKey)
+	}
+	s.VerifierConfig = unmarshaledMap[verifierConfigKey]
+
+	if val, ok := unmarshaledMap[tokenRateLimitKey]; ok && val != "" {
+		limit, err := strconv.ParseUint(val, 10, 31)
+		if err != nil {
+			return fmt.Errorf("failed to convert %v into a non-negative integer, got: %v", tokenRateLimitKey, val)
+		}
+		s.TokenRateLimit = int(limit)
+	}
+	if val, ok := unmarshaledMap[tokenMaxConcurrencyKey]; ok && val != "" {
+		limit, err := strconv.ParseUint(val, 10, 31)
+		if err != nil {
+			return fmt.Errorf("failed to convert %v into a non-negative integer, got: %v", tokenMaxConcurrencyKey, val)
+		}
+		s.TokenMaxConcurrency = int(limit)
+	}
+
+	s.StopGracePeriod = defaultStopGracePeriod
+	if val, ok := unmarshaledMap[stopGracePeriodKey]; ok && val != "" {
+		sec, err := strconv.ParseUint(val, 10, 32)
+		if err != nil {
+			return fmt.Errorf("failed to convert %v into a number of seconds, got: %v", stopGracePeriodKey, val)
+		}
+		s.StopGracePeriod = time.Duration(sec) * time.Second
+	}
+
 	if s.Experiments.EnablePrivilegedCS {
 		// Populate capabilities override.
 		if val, ok := unmarshaledMap[addedCaps]; ok && val != "" {
@@ -3,6 +3,7 @@ package spec
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/launcher/internal/experiments"
@@ -28,7 +29,12 @@ func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
 				"tee-dev-shm-size-kb":"234234",
 				"tee-mount":"type=tmpfs,source=tmpfs,destination=/tmpmount;type=tmpfs,source=tmpfs,destination=/sized,size=222",
 				"ita-region":"US",
-				"ita-api-key":"test-api-key"
+				"ita-api-key":"test-api-key",
+				"tee-init-containers":"[{\"name\":\"weights\",\"image\":\"gcr.io/project/fetch-weights:latest\"}]",
+				"tee-sidecars":"[{\"name\":\"proxy\",\"image\":\"docker.io/library/envoy:latest\",\"cmd\":[\"--port\",\"8080\"],\"env\":[{\"name\":\"foo\",\"value\":\"bar\"}],\"attestation_access\":true}]",
+				"tee-token-rate-limit":"120",
+				"tee-token-max-concurrency":"0",
+				"tee-stop-grace-period-sec":"60"
 			}`,
 		},
 		{
@@ -47,7 +53,12 @@ func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
 				"tee-dev-shm-size-kb":"234234",
 				"tee-mount":"type=tmpfs,source=tmpfs,destination=/tmpmount;type=tmpfs,source=tmpfs,destination=/sized,size=222",
 				"ita-region":"US",
-				"ita-api-key":"test-api-key"
+				"ita-api-key":"test-api-key",
+				"tee-init-containers":"[{\"name\":\"weights\",\"image\":\"gcr.io/project/fetch-weights:latest\"}]",
+				"tee-sidecars":"[{\"name\":\"proxy\",\"image\":\"docker.io/library/envoy:latest\",\"cmd\":[\"--port\",\"8080\"],\"env\":[{\"name\":\"foo\",\"value\":\"bar\"}],\"attestation_access\":true}]",
+				"tee-token-rate-limit":"120",
+				"tee-token-max-concurrency":"0",
+				"tee-stop-grace-period-sec":"60"
 			}`,
 		},
 	}
@@ -66,10 +77,25 @@ func TestLaunchSpecUnmarshalJSONHappyCases(t *testing.T) {
 			launchermount.TmpfsMount{Destination: "/sized", Size: 222}},
 		ITARegion: "US",
 		ITAKey:    "test-api-key",
+		Verifier:  ITAVerifier,
 		Experiments: experiments.Experiments{
 			EnableTempFSMount: true,
 			EnableItaVerifier: true,
 		},
+		InitContainers: []Container{{
+			Name:     "weights",
+			ImageRef: "gcr.io/project/fetch-weights:latest",
+		}},
+		Sidecars: []Container{{
+			Name:              "proxy",
+			ImageRef:          "docker.io/library/envoy:latest",
+			Cmd:               []string{"--port", "8080"},
+			Envs:              []EnvVar{{"foo", "bar"}},
+			AttestationAccess: true,
+		}},
+		TokenRateLimit:      120,
+		TokenMaxConcurrency: 0,
+		StopGracePeriod:     time.Minute,
 	}
 
 	for _, testcase := range testCases {
@@ -128,6 +154,77 @@ func TestLaunchSpecUnmarshalJSONBadInput(t *testing.T) {
 				"tee-container-log-redirect":"badideas",
 			}`,
 		},
+		{
+			"BadSidecarsJSON",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-sidecars":"{\"name\":\"proxy\"}"
+			}`,
+		},
+		{
+			"SidecarWithoutImage",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-sidecars":"[{\"name\":\"proxy\"}]"
+			}`,
+		},
+		{
+			"SidecarWithBadName",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-sidecars":"[{\"name\":\"Proxy_1\",\"image\":\"docker.io/library/envoy:latest\"}]"
+			}`,
+		},
+		{
+			"DuplicateSidecarNames",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-sidecars":"[{\"name\":\"proxy\",\"image\":\"docker.io/library/envoy:latest\"},{\"name\":\"proxy\",\"image\":\"docker.io/library/nginx:latest\"}]"
+			}`,
+		},
+		{
+			"InitContainerNamedLikeSidecar",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-init-containers":"[{\"name\":\"proxy\",\"image\":\"gcr.io/project/fetch-weights:latest\"}]",
+				"tee-sidecars":"[{\"name\":\"proxy\",\"image\":\"docker.io/library/envoy:latest\"}]"
+			}`,
+		},
+		{
+			"UnknownVerifier",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-verifier":"other"
+			}`,
+		},
+		{
+			"ITAVerifierWithoutRegion",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-verifier":"ita"
+			}`,
+		},
+		{
+			"NegativeTokenRateLimit",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-rate-limit":"-1"
+			}`,
+		},
+		{
+			"BadTokenMaxConcurrency",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-token-max-concurrency":"four"
+			}`,
+		},
+		{
+			"BadStopGracePeriod",
+			`{
+				"tee-image-reference":"docker.io/library/hello-world:latest",
+				"tee-stop-grace-period-sec":"30s"
+			}`,
+		},
 		{
 			"Memory and Health Monitoring both specified",
 			`{
@@ -168,6 +265,8 @@ func TestLaunchSpecUnmarshalJSONWithDefaultValue(t *testing.T) {
 		RestartPolicy:     Never,
 		LogRedirect:       Nowhere,
 		MonitoringEnabled: None,
+		Verifier:          GCAVerifier,
+		StopGracePeriod:   defaultStopGracePeriod,
 	}
 
 	if !cmp.Equal(spec, want) {
@@ -188,6 +287,25 @@ func TestLaunchSpecUnmarshalJSONWithoutImageReference(t *testing.T) {
 	}
 }
 
+func TestLaunchSpecUnmarshalJSONWithLocalVerifier(t *testing.T) {
+	mdsJSON := `{
+		"tee-image-reference":"docker.io/library/hello-world:latest",
+		"tee-verifier":"local",
+		"tee-verifier-config":"{\"issuer\":\"https://verifier.example.com\"}"
+		}`
+
+	spec := &LaunchSpec{}
+	if err := spec.UnmarshalJSON([]byte(mdsJSON)); err != nil {
+		t.Fatal(err)
+	}
+	if spec.Verifier != LocalVerifier {
+		t.Errorf("got verifier %q, want %q", spec.Verifier, LocalVerifier)
+	}
+	if want := `{"issuer":"https://verifier.example.com"}`; spec.VerifierConfig != want {
+		t.Errorf("got verifier config %q, want %q", spec.VerifierConfig, want)
+	}
+}
+
 func TestLaunchSpecUnmarshalJSONWithTmpfsMounts(t *testing.T) {
 	var testCases = []struct {
 		testName string
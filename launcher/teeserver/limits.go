@@ -0,0 +1,101 @@
+package teeserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedError is returned when the rate limit of an endpoint is
+// exceeded.
+type rateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("too many token requests, retry in %v", e.retryAfter)
+}
+
+// endpointLimiter limits the token requests of an endpoint of the TEE server
+// to the verifier.
+type endpointLimiter struct {
+	// rate is nil if the rate is not limited.
+	rate *rate.Limiter
+	// slots has a buffered value per running request, and is nil if the
+	// concurrency is not limited.
+	slots chan struct{}
+}
+
+// newEndpointLimiter creates a limiter of ratePerMinute requests per minute,
+// and of maxConcurrency concurrent requests. Zero disables a limit.
+func newEndpointLimiter(ratePerMinute, maxConcurrency int) *endpointLimiter {
+	l := &endpointLimiter{}
+	if ratePerMinute > 0 {
+		// Allow a minute of requests at once, then ratePerMinute per minute.
+		l.rate = rate.NewLimiter(rate.Every(time.Minute/time.Duration(ratePerMinute)), ratePerMinute)
+	}
+	if maxConcurrency > 0 {
+		l.slots = make(chan struct{}, maxConcurrency)
+	}
+	return l
+}
+
+// acquire returns a rateLimitedError if the rate limit is exceeded, and
+// otherwise waits for a slot for a request, until ctx is done. The caller must
+// call release when the request completes.
+func (l *endpointLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if l.rate != nil {
+		reservation := l.rate.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			return nil, &rateLimitedError{retryAfter: delay}
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limiter returns the limiter of the endpoint, configured by the launch spec.
+func (a *attestHandler) limiter(endpoint string) *endpointLimiter {
+	a.limitersMu.Lock()
+	defer a.limitersMu.Unlock()
+	if a.limiters == nil {
+		a.limiters = make(map[string]*endpointLimiter)
+	}
+	l, ok := a.limiters[endpoint]
+	if !ok {
+		l = newEndpointLimiter(a.launchSpec.TokenRateLimit, a.launchSpec.TokenMaxConcurrency)
+		a.limiters[endpoint] = l
+	}
+	return l
+}
+
+// acquireLimit acquires the limiter of the endpoint of the request, or writes
+// the error to the response if the request is over the limits.
+func (a *attestHandler) acquireLimit(w http.ResponseWriter, r *http.Request) (release func(), ok bool) {
+	release, err := a.limiter(r.URL.Path).acquire(r.Context())
+	var rateErr *rateLimitedError
+	switch {
+	case errors.As(err, &rateErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.retryAfter.Seconds()))))
+		a.logAndWriteHTTPError(w, http.StatusTooManyRequests, err)
+		return nil, false
+	case err != nil:
+		a.logAndWriteHTTPError(w, http.StatusServiceUnavailable, fmt.Errorf("failed to wait for a token request slot: %v", err))
+		return nil, false
+	}
+	return release, true
+}
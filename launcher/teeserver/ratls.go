@@ -0,0 +1,139 @@
+package teeserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/google/go-tpm-tools/server"
+)
+
+const (
+	// The limit on the size of a certificate request.
+	maxCSRSize = 16 * 1024
+	// The lifetime of an RA-TLS certificate, as of an attestation token: the
+	// workload should get a new certificate before it expires, attesting its
+	// current state.
+	ratlsCertLifetime = time.Hour
+)
+
+var ratlsIssuer = pkix.Name{CommonName: "Confidential Space launcher"}
+
+// getRATLSCertificate issues an RA-TLS certificate for the key of the PEM
+// certificate request in the POST body, with the attestation evidence over
+// the key, the validity period of the certificate and the optional hex
+// challenge query parameter of the verifier (see server.RATLSNonce) in an
+// extension. A peer verifies the certificate with
+// server.RATLSVerifyPeerCertificate.
+func (a *attestHandler) getRATLSCertificate(w http.ResponseWriter, r *http.Request) {
+	data, err := readPostBody(r, maxCSRSize)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to read the certificate request: %v", err))
+		return
+	}
+	csr, err := parseCSR(data)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	challenge, err := parseChallenge(r.URL.Query().Get("challenge"))
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	// The certificate encodes its validity period in seconds.
+	notBefore := time.Now().Truncate(time.Second)
+	notAfter := notBefore.Add(ratlsCertLifetime)
+	nonce, err := server.RATLSNonce(csr.PublicKey, notBefore, notAfter, challenge)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	attestation, err := a.attestAgent.AttestationEvidence(nonce)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to collect attestation evidence: %v", err))
+		return
+	}
+	ext, err := server.RATLSExtension(attestation)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cert, err := createRATLSCertificate(csr, ext, notBefore, notAfter)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to create the RA-TLS certificate: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.WriteHeader(http.StatusOK)
+	w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}))
+}
+
+// parseCSR parses a PEM certificate request, and checks its signature, which
+// proves that the workload holds the key.
+func parseCSR(data []byte) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("the request body is not a PEM certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the certificate request: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid certificate request signature: %v", err)
+	}
+	return csr, nil
+}
+
+// parseChallenge parses the hex challenge of the verifier of an RA-TLS
+// certificate, if any.
+func parseChallenge(s string) ([]byte, error) {
+	challenge, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the challenge: %v", err)
+	}
+	if len(challenge) > maxEvidenceNonceSize {
+		return nil, fmt.Errorf("challenge must be at most %d bytes, got %d bytes", maxEvidenceNonceSize, len(challenge))
+	}
+	return challenge, nil
+}
+
+// createRATLSCertificate creates a DER certificate for the subject, the names
+// and the key of the certificate request, valid for the attested period, with
+// the RA-TLS extension. As the peers trust the extension rather than the
+// issuer, the certificate is signed by a throwaway key.
+func createRATLSCertificate(csr *x509.CertificateRequest, ext pkix.Extension, notBefore, notAfter time.Time) ([]byte, error) {
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:    serial,
+		Subject:         csr.Subject,
+		DNSNames:        csr.DNSNames,
+		IPAddresses:     csr.IPAddresses,
+		URIs:            csr.URIs,
+		NotBefore:       notBefore,
+		NotAfter:        notAfter,
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: []pkix.Extension{ext},
+	}
+	parent := &x509.Certificate{Subject: ratlsIssuer}
+	return x509.CreateCertificate(rand.Reader, template, parent, csr.PublicKey, issuerKey)
+}
@@ -5,24 +5,28 @@ package teeserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"sync"
 
 	"github.com/google/go-tpm-tools/launcher/agent"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/metrics"
 	"github.com/google/go-tpm-tools/launcher/spec"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
 	"github.com/google/go-tpm-tools/verifier"
 	"github.com/google/go-tpm-tools/verifier/models"
 	"github.com/google/go-tpm-tools/verifier/util"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 // AttestClients contains clients for supported verifier services that can be used to
-// get attestation tokens.
-type AttestClients struct {
-	GCA verifier.Client
-	ITA verifier.Client
-}
+// get attestation tokens, by verifier type.
+type AttestClients map[spec.VerifierType]verifier.Client
 
 type attestHandler struct {
 	ctx         context.Context
@@ -30,7 +34,16 @@ type attestHandler struct {
 	// defaultTokenFile string
 	logger     logging.Logger
 	launchSpec spec.LaunchSpec
-	clients    *AttestClients
+	clientsMu  sync.Mutex
+	clients    AttestClients
+	// tokens is nil if the launcher refreshes no default token.
+	tokens *TokenNotifier
+	// shutdown is closed when the server shuts down, ending the token watches.
+	shutdown chan struct{}
+	// The custom tokens, and the limits of the token endpoints.
+	tokenCache tokenCache
+	limitersMu sync.Mutex
+	limiters   map[string]*endpointLimiter
 }
 
 // TeeServer is a server that can be called from a container through a unix
@@ -41,25 +54,29 @@ type TeeServer struct {
 }
 
 // New takes in a socket and start to listen to it, and create a server
-func New(ctx context.Context, unixSock string, a agent.AttestationAgent, logger logging.Logger, launchSpec spec.LaunchSpec, clients *AttestClients) (*TeeServer, error) {
+func New(ctx context.Context, unixSock string, a agent.AttestationAgent, logger logging.Logger, launchSpec spec.LaunchSpec, clients AttestClients, tokens *TokenNotifier) (*TeeServer, error) {
 	var err error
 	nl, err := net.Listen("unix", unixSock)
 	if err != nil {
 		return nil, fmt.Errorf("cannot listen to the socket [%s]: %v", unixSock, err)
 	}
 
+	handler := &attestHandler{
+		ctx:         ctx,
+		attestAgent: a,
+		logger:      logger,
+		launchSpec:  launchSpec,
+		clients:     clients,
+		tokens:      tokens,
+		shutdown:    make(chan struct{}),
+	}
 	teeServer := TeeServer{
 		netListener: nl,
-		server: &http.Server{
-			Handler: (&attestHandler{
-				ctx:         ctx,
-				attestAgent: a,
-				logger:      logger,
-				launchSpec:  launchSpec,
-				clients:     clients,
-			}).Handler(),
-		},
+		server:      &http.Server{Handler: handler.Handler()},
 	}
+	// Shutdown waits for the active connections, which the token watches
+	// would otherwise keep open.
+	teeServer.server.RegisterOnShutdown(func() { close(handler.shutdown) })
 	return &teeServer, nil
 }
 
@@ -73,34 +90,79 @@ func (a *attestHandler) Handler() http.Handler {
 
 	mux.HandleFunc("/v1/token", a.getToken)
 	mux.HandleFunc("/v1/intel/token", a.getITAToken)
+	// to watch the default token as it is refreshed (server-sent events):
+	// curl -N --unix-socket <socket> http://localhost/v1/token/watch
+	mux.HandleFunc("/v1/token/watch", a.watchToken)
+	// to seal a secret to the measured boot chain and workload (PCRs 0, 4, 7,
+	// 8, 9 and the COS event PCR), which it can unseal until the launcher
+	// measures the shutdown of the workload:
+	// curl --data-binary @secret -X POST --unix-socket <socket> http://localhost/v1/seal > sealed
+	// to unseal it:
+	// curl --data-binary @sealed -X POST --unix-socket <socket> http://localhost/v1/unseal
+	mux.HandleFunc("/v1/seal", a.seal)
+	mux.HandleFunc("/v1/unseal", a.unseal)
+	// to get the raw attestation evidence, for a relying party verifying it itself
+	// (e.g., to learn the key of the local verifier from its LocalVerifierKey event):
+	// curl -d '{"nonce":"<base64 nonce>"}' -H "Accept: application/json" -X POST
+	//   --unix-socket <socket> http://localhost/v1/evidence
+	mux.HandleFunc("/v1/evidence", a.getEvidence)
+	// to get an RA-TLS certificate for a workload key:
+	// openssl req -new -key key.pem -subj /CN=workload -addext subjectAltName=DNS:workload > csr.pem
+	// curl --data-binary @csr.pem -X POST --unix-socket <socket> http://localhost/v1/ratls/certificate
+	// optionally answering the hex challenge of a verifier:
+	// curl --data-binary @csr.pem -X POST --unix-socket <socket> "http://localhost/v1/ratls/certificate?challenge=<hex challenge>"
+	mux.HandleFunc("/v1/ratls/certificate", a.getRATLSCertificate)
+	// to get the launcher metrics, in the Prometheus text format:
+	// curl --unix-socket <socket> http://localhost/metrics
+	mux.Handle("/metrics", metrics.Default.Handler())
+	// to check the launcher health:
+	// curl --unix-socket <socket> http://localhost/healthz
+	mux.HandleFunc("/healthz", a.healthz)
 	return mux
 }
 
+// healthz responds "ok" if the launcher is healthy, and 503 with the reason
+// otherwise: if the default token refresher stopped, or let the token expire.
+func (a *attestHandler) healthz(w http.ResponseWriter, _ *http.Request) {
+	if err := a.tokens.healthy(); err != nil {
+		a.logAndWriteHTTPError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
 func (a *attestHandler) logAndWriteError(errStr string, status int, w http.ResponseWriter) {
 	a.logger.Error(errStr)
 	w.WriteHeader(status)
 	w.Write([]byte(errStr))
 }
 
-// getDefaultToken handles the request to get the default OIDC token.
+// getDefaultToken handles the request to get the default OIDC token, from
+// the verifier selected in the launch spec, or from GCA if ITA is selected.
 // For now this function will just read the content of the file and return.
 // Later, this function can use attestation agent to get a token directly.
 func (a *attestHandler) getToken(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 
-	// If the handler does not have a GCA client, create one.
-	if a.clients.GCA == nil {
-		gcaClient, err := util.NewRESTClient(a.ctx, a.launchSpec.AttestationServiceAddr, a.launchSpec.ProjectID, a.launchSpec.Region)
-		if err != nil {
-			errStr := fmt.Sprintf("failed to create REST verifier client: %v", err)
-			a.logAndWriteError(errStr, http.StatusInternalServerError, w)
-			return
-		}
-
-		a.clients.GCA = gcaClient
+	// ITA tokens are served by getITAToken.
+	verifierType := a.launchSpec.Verifier
+	if verifierType == "" || verifierType == spec.ITAVerifier {
+		verifierType = spec.GCAVerifier
+	}
+	client, err := a.verifierClient(verifierType)
+	if err != nil {
+		errStr := fmt.Sprintf("failed to create REST verifier client: %v", err)
+		a.logAndWriteError(errStr, http.StatusInternalServerError, w)
+		return
+	}
+	if client == nil {
+		errStr := fmt.Sprintf("no %s verifier client present - ensure the verifier is configured in metadata", verifierType)
+		a.logAndWriteError(errStr, http.StatusPreconditionFailed, w)
+		return
 	}
 
-	a.attest(w, r, a.clients.GCA)
+	a.attest(w, r, verifierType, client)
 }
 
 // getITAToken retrieves a attestation token signed by ITA.
@@ -108,25 +170,51 @@ func (a *attestHandler) getITAToken(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 
 	// If the handler does not have an ITA client, return error.
-	if a.clients.ITA == nil {
+	client, _ := a.verifierClient(spec.ITAVerifier)
+	if client == nil {
 		errStr := "no ITA verifier client present - ensure ITA Region and Key are defined in metadata"
 		a.logAndWriteError(errStr, http.StatusPreconditionFailed, w)
 		return
 	}
 
-	a.attest(w, r, a.clients.ITA)
+	a.attest(w, r, spec.ITAVerifier, client)
+}
+
+// verifierClient returns the client of the verifier type, or nil if there is
+// none. If the handler does not have a GCA client, it creates one.
+func (a *attestHandler) verifierClient(verifierType spec.VerifierType) (verifier.Client, error) {
+	a.clientsMu.Lock()
+	defer a.clientsMu.Unlock()
+	if client := a.clients[verifierType]; client != nil || verifierType != spec.GCAVerifier {
+		return client, nil
+	}
+	gcaClient, err := util.NewRESTClient(a.ctx, a.launchSpec.AttestationServiceAddr, a.launchSpec.ProjectID, a.launchSpec.Region)
+	if err != nil {
+		return nil, err
+	}
+	if a.clients == nil {
+		a.clients = make(AttestClients)
+	}
+	a.clients[spec.GCAVerifier] = gcaClient
+	return gcaClient, nil
 }
 
-func (a *attestHandler) attest(w http.ResponseWriter, r *http.Request, client verifier.Client) {
+func (a *attestHandler) attest(w http.ResponseWriter, r *http.Request, verifierType spec.VerifierType, client verifier.Client) {
 	switch r.Method {
 	case http.MethodGet:
+		release, ok := a.acquireLimit(w, r)
+		if !ok {
+			return
+		}
+		defer release()
+
 		if err := a.attestAgent.Refresh(a.ctx); err != nil {
 			errStr := fmt.Sprintf("failed to refresh attestation agent: %v", err)
 			a.logAndWriteError(errStr, http.StatusInternalServerError, w)
 			return
 		}
 
-		token, err := a.attestAgent.AttestWithClient(a.ctx, agent.AttestAgentOpts{}, client)
+		token, err := a.attestAgent.AttestWithClient(a.ctx, agent.AttestAgentOpts{}, verifierType, client)
 		if err != nil {
 			errStr := fmt.Sprintf("failed to retrieve attestation service token: %v", err)
 			a.logAndWriteError(errStr, http.StatusInternalServerError, w)
@@ -162,13 +250,31 @@ func (a *attestHandler) attest(w http.ResponseWriter, r *http.Request, client ve
 
 		// Do not check that TokenTypeOptions matches TokenType in the launcher.
 
+		cacheKey, err := tokenCacheKey(r.URL.Path, &tokenOptions)
+		if err != nil {
+			a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+			return
+		}
+		if tok, ok := a.tokenCache.get(cacheKey); ok {
+			w.WriteHeader(http.StatusOK)
+			w.Write(tok)
+			return
+		}
+
+		release, ok := a.acquireLimit(w, r)
+		if !ok {
+			return
+		}
+		defer release()
+
 		tok, err := a.attestAgent.AttestWithClient(a.ctx, agent.AttestAgentOpts{
 			TokenOptions: &tokenOptions,
-		}, client)
+		}, verifierType, client)
 		if err != nil {
 			a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
 			return
 		}
+		a.tokenCache.put(cacheKey, tok)
 
 		w.WriteHeader(http.StatusOK)
 		w.Write(tok)
@@ -180,6 +286,133 @@ func (a *attestHandler) attest(w http.ResponseWriter, r *http.Request, client ve
 	}
 }
 
+// The TPM seals at most MAX_SYM_DATA (128) bytes: a workload with larger
+// secrets should seal a key encrypting them.
+const maxSealDataSize = 128
+
+// The limit on the size of a sealed blob to unseal, well above the size of the
+// sealed blobs of maxSealDataSize bytes.
+const maxUnsealDataSize = 4096
+
+// seal seals the POST body with the vTPM, bound to the measured launch of the
+// workload, and returns the opaque sealed blob.
+func (a *attestHandler) seal(w http.ResponseWriter, r *http.Request) {
+	data, err := readPostBody(r, maxSealDataSize)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to read the data to seal: %v", err))
+		return
+	}
+	sealed, err := a.attestAgent.Seal(data)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to seal data: %v", err))
+		return
+	}
+	out, err := proto.Marshal(sealed)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to marshal sealed data: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+// unseal unseals the sealed blob in the POST body, returned by seal. It fails
+// if the workload launch measured since differs (e.g., another image digest).
+func (a *attestHandler) unseal(w http.ResponseWriter, r *http.Request) {
+	data, err := readPostBody(r, maxUnsealDataSize)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to read the sealed data: %v", err))
+		return
+	}
+	sealed := &tpmpb.SealedBytes{}
+	if err := proto.Unmarshal(data, sealed); err != nil {
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, fmt.Errorf("failed to parse the sealed data: %v", err))
+		return
+	}
+	unsealed, err := a.attestAgent.Unseal(sealed)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusForbidden, fmt.Errorf("failed to unseal data: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(unsealed)
+}
+
+// The TPM quotes over at most 64 bytes of qualifying data (the size of a
+// SHA-512 digest).
+const maxEvidenceNonceSize = 64
+
+// evidenceRequest is the POST body of an evidence request.
+type evidenceRequest struct {
+	// The nonce of the relying party, base64-encoded in JSON.
+	Nonce []byte `json:"nonce"`
+}
+
+// getEvidence returns the raw attestation evidence over the nonce of the
+// caller, as a serialized attest.Attestation, or as its JSON encoding if the
+// request accepts application/json. A relying party can verify it without a
+// cloud verifier, with server.VerifyAttestation.
+func (a *attestHandler) getEvidence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		err := fmt.Errorf("TEE server received an invalid HTTP method: %s", r.Method)
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	var req evidenceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		err = fmt.Errorf("failed to parse POST body as an evidence request: %v", err)
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Nonce) == 0 || len(req.Nonce) > maxEvidenceNonceSize {
+		err := fmt.Errorf("nonce must be between 1 and %d bytes, got %d bytes", maxEvidenceNonceSize, len(req.Nonce))
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	attestation, err := a.attestAgent.AttestationEvidence(req.Nonce)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to collect attestation evidence: %v", err))
+		return
+	}
+	contentType := "application/octet-stream"
+	marshal := proto.Marshal
+	if r.Header.Get("Accept") == "application/json" {
+		contentType = "application/json"
+		marshal = protojson.Marshal
+	}
+	out, err := marshal(attestation)
+	if err != nil {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("failed to marshal attestation evidence: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+// readPostBody reads the body of a POST request, of at most limit bytes.
+func readPostBody(r *http.Request, limit int64) ([]byte, error) {
+	if r.Method != http.MethodPost {
+		return nil, fmt.Errorf("TEE server received an invalid HTTP method: %s", r.Method)
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("the request body exceeds %d bytes", limit)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("the request body is empty")
+	}
+	return data, nil
+}
+
 func (a *attestHandler) logAndWriteHTTPError(w http.ResponseWriter, statusCode int, err error) {
 	a.logger.Error(err.Error())
 	w.WriteHeader(statusCode)
@@ -1,7 +1,18 @@
 package teeserver
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -9,13 +20,24 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/internal/cbor"
 	"github.com/google/go-tpm-tools/launcher/agent"
 	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/metrics"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/server"
 	"github.com/google/go-tpm-tools/verifier"
 	"github.com/google/go-tpm-tools/verifier/models"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
 )
 
 // Implements verifier.Client interface so it can be used to initialize test attestHandlers
@@ -32,15 +54,18 @@ func (f *fakeVerifierClient) VerifyAttestation(_ context.Context, _ verifier.Ver
 type fakeAttestationAgent struct {
 	measureEventFunc     func(cel.Content) error
 	attestFunc           func(context.Context, agent.AttestAgentOpts) ([]byte, error)
-	attestWithClientFunc func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error)
+	attestWithClientFunc func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error)
+	sealFunc             func([]byte) (*tpmpb.SealedBytes, error)
+	unsealFunc           func(*tpmpb.SealedBytes) ([]byte, error)
+	evidenceFunc         func([]byte) (*attestpb.Attestation, error)
 }
 
 func (f fakeAttestationAgent) Attest(c context.Context, a agent.AttestAgentOpts) ([]byte, error) {
 	return f.attestFunc(c, a)
 }
 
-func (f fakeAttestationAgent) AttestWithClient(c context.Context, a agent.AttestAgentOpts, v verifier.Client) ([]byte, error) {
-	return f.attestWithClientFunc(c, a, v)
+func (f fakeAttestationAgent) AttestWithClient(c context.Context, a agent.AttestAgentOpts, t spec.VerifierType, v verifier.Client) ([]byte, error) {
+	return f.attestWithClientFunc(c, a, t, v)
 }
 
 func (f fakeAttestationAgent) MeasureEvent(c cel.Content) error {
@@ -51,6 +76,22 @@ func (f fakeAttestationAgent) Refresh(_ context.Context) error {
 	return nil
 }
 
+func (f fakeAttestationAgent) Seal(data []byte) (*tpmpb.SealedBytes, error) {
+	return f.sealFunc(data)
+}
+
+func (f fakeAttestationAgent) Unseal(sealed *tpmpb.SealedBytes) ([]byte, error) {
+	return f.unsealFunc(sealed)
+}
+
+func (f fakeAttestationAgent) AttestationEvidence(nonce []byte) (*attestpb.Attestation, error) {
+	return f.evidenceFunc(nonce)
+}
+
+func (f fakeAttestationAgent) ResumedEvents() []cel.TLV {
+	return nil
+}
+
 func (f fakeAttestationAgent) Close() error {
 	return nil
 }
@@ -62,11 +103,11 @@ func TestGetDefaultToken(t *testing.T) {
 	// in the handler.
 	ah := attestHandler{
 		logger: logging.SimpleLogger(),
-		clients: &AttestClients{
-			GCA: &fakeVerifierClient{},
+		clients: AttestClients{
+			spec.GCAVerifier: &fakeVerifierClient{},
 		},
 		attestAgent: fakeAttestationAgent{
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				return []byte(testTokenContent), nil
 			},
 		}}
@@ -88,11 +129,62 @@ func TestGetDefaultToken(t *testing.T) {
 	}
 }
 
+// namedVerifierClient is a fakeVerifierClient told apart by its name.
+type namedVerifierClient struct {
+	fakeVerifierClient
+	name string
+}
+
+func TestGetTokenVerifierSelection(t *testing.T) {
+	gcaClient := &namedVerifierClient{name: "gca"}
+	itaClient := &namedVerifierClient{name: "ita"}
+	localClient := &namedVerifierClient{name: "local"}
+	for _, tc := range []struct {
+		testName   string
+		verifier   spec.VerifierType
+		clients    AttestClients
+		handler    func(*attestHandler, http.ResponseWriter, *http.Request)
+		wantClient verifier.Client
+		wantCode   int
+	}{
+		{"DefaultGCA", "", AttestClients{spec.GCAVerifier: gcaClient}, (*attestHandler).getToken, gcaClient, http.StatusOK},
+		{"Local", spec.LocalVerifier, AttestClients{spec.LocalVerifier: localClient}, (*attestHandler).getToken, localClient, http.StatusOK},
+		{"GCAWithITA", spec.ITAVerifier, AttestClients{spec.GCAVerifier: gcaClient, spec.ITAVerifier: itaClient}, (*attestHandler).getToken, gcaClient, http.StatusOK},
+		{"ITA", spec.ITAVerifier, AttestClients{spec.ITAVerifier: itaClient}, (*attestHandler).getITAToken, itaClient, http.StatusOK},
+		{"NoLocalClient", spec.LocalVerifier, AttestClients{}, (*attestHandler).getToken, nil, http.StatusPreconditionFailed},
+		{"NoITAClient", spec.GCAVerifier, AttestClients{spec.GCAVerifier: gcaClient}, (*attestHandler).getITAToken, nil, http.StatusPreconditionFailed},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			ah := &attestHandler{
+				logger:     logging.SimpleLogger(),
+				launchSpec: spec.LaunchSpec{Verifier: tc.verifier},
+				clients:    tc.clients,
+				attestAgent: fakeAttestationAgent{
+					attestWithClientFunc: func(_ context.Context, _ agent.AttestAgentOpts, verifierType spec.VerifierType, client verifier.Client) ([]byte, error) {
+						if client != tc.wantClient {
+							t.Errorf("got verifier client %v, want %v", client, tc.wantClient)
+						}
+						if tc.clients[verifierType] != client {
+							t.Errorf("got verifier type %q, which is not the type of client %v", verifierType, client)
+						}
+						return []byte("token"), nil
+					},
+				}}
+
+			w := httptest.NewRecorder()
+			tc.handler(ah, w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tc.wantCode {
+				t.Errorf("got return code %d, want %d", w.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
 func TestCustomToken(t *testing.T) {
 	tests := []struct {
 		testName             string
 		body                 string
-		attestWithClientFunc func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error)
+		attestWithClientFunc func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error)
 		want                 int
 	}{
 		{
@@ -102,7 +194,7 @@ func TestCustomToken(t *testing.T) {
 				"nonces": ["thisIsAcustomNonce"],
 				"token_type": "OIDC"
 				}`,
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				t.Errorf("This method should not be called")
 				return nil, nil
 			},
@@ -115,7 +207,7 @@ func TestCustomToken(t *testing.T) {
 				"nonces": ["thisIsAcustomNonce"],
 				"token_type": "OIDC"
 			}`,
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				return nil, errors.New("Error")
 			},
 			want: http.StatusBadRequest,
@@ -127,7 +219,7 @@ func TestCustomToken(t *testing.T) {
 				"nonces": ["thisIsAcustomNonce"],
 				"token_type": ""
 			}`,
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				t.Errorf("This method should not be called")
 				return nil, nil
 			},
@@ -140,7 +232,7 @@ func TestCustomToken(t *testing.T) {
 				"nonces": ["thisIsAcustomNonce"],
 				"token_type": "OIDC"
 			}`,
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				return []byte{}, nil
 			},
 			want: http.StatusOK,
@@ -159,7 +251,7 @@ func TestCustomToken(t *testing.T) {
 					}
 				}
 			}`,
-			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, verifier.Client) ([]byte, error) {
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
 				return []byte{}, nil
 			},
 			want: http.StatusOK,
@@ -171,8 +263,8 @@ func TestCustomToken(t *testing.T) {
 		// in the handler.
 		ah := attestHandler{
 			logger: logging.SimpleLogger(),
-			clients: &AttestClients{
-				GCA: &fakeVerifierClient{},
+			clients: AttestClients{
+				spec.GCAVerifier: &fakeVerifierClient{},
 			},
 			attestAgent: fakeAttestationAgent{
 				attestWithClientFunc: test.attestWithClientFunc,
@@ -302,11 +394,11 @@ func TestCustomTokenDataParsedSuccessfully(t *testing.T) {
 	for i, test := range tests {
 		ah := attestHandler{
 			logger: logging.SimpleLogger(),
-			clients: &AttestClients{
-				GCA: &fakeVerifierClient{},
+			clients: AttestClients{
+				spec.GCAVerifier: &fakeVerifierClient{},
 			},
 			attestAgent: fakeAttestationAgent{
-				attestWithClientFunc: func(_ context.Context, gotOpts agent.AttestAgentOpts, _ verifier.Client) ([]byte, error) {
+				attestWithClientFunc: func(_ context.Context, gotOpts agent.AttestAgentOpts, _ spec.VerifierType, _ verifier.Client) ([]byte, error) {
 					diff := cmp.Diff(test.wantOpts, gotOpts)
 					if diff != "" {
 						t.Errorf("%v: got unexpected agent.AttestAgentOpts. diff:\n%v", test.testName, diff)
@@ -330,3 +422,631 @@ func TestCustomTokenDataParsedSuccessfully(t *testing.T) {
 		}
 	}
 }
+
+func TestSealUnseal(t *testing.T) {
+	// The fake agent "seals" data in the clear, and unseals it if the sealed
+	// PCR values are unchanged.
+	sealedPCRs := &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{13: []byte("launch")}}
+	ah := attestHandler{
+		logger: logging.SimpleLogger(),
+		attestAgent: fakeAttestationAgent{
+			sealFunc: func(data []byte) (*tpmpb.SealedBytes, error) {
+				return &tpmpb.SealedBytes{Priv: data, Pcrs: []uint32{13}, Hash: tpmpb.HashAlgo_SHA256, CertifiedPcrs: sealedPCRs}, nil
+			},
+			unsealFunc: func(sealed *tpmpb.SealedBytes) ([]byte, error) {
+				if !proto.Equal(sealed.GetCertifiedPcrs(), sealedPCRs) {
+					return nil, errors.New("PCR policy check failed")
+				}
+				return sealed.GetPriv(), nil
+			},
+		},
+	}
+
+	secret := []byte("workload secret")
+	w := httptest.NewRecorder()
+	ah.seal(w, httptest.NewRequest(http.MethodPost, "/v1/seal", strings.NewReader(string(secret))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("seal got return code %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	sealed := w.Body.Bytes()
+
+	w = httptest.NewRecorder()
+	ah.unseal(w, httptest.NewRequest(http.MethodPost, "/v1/unseal", strings.NewReader(string(sealed))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unseal got return code %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Body.String(); got != string(secret) {
+		t.Errorf("unseal got %q, want %q", got, secret)
+	}
+
+	// Sealed data for another launch fails to unseal.
+	otherLaunch, err := proto.Marshal(&tpmpb.SealedBytes{Priv: secret, CertifiedPcrs: &tpmpb.PCRs{Hash: tpmpb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{13: []byte("other")}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	ah.unseal(w, httptest.NewRequest(http.MethodPost, "/v1/unseal", strings.NewReader(string(otherLaunch))))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unseal of another launch got return code %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestSealUnsealBadRequests(t *testing.T) {
+	ah := attestHandler{
+		logger: logging.SimpleLogger(),
+		attestAgent: fakeAttestationAgent{
+			sealFunc: func([]byte) (*tpmpb.SealedBytes, error) {
+				return &tpmpb.SealedBytes{}, nil
+			},
+			unsealFunc: func(*tpmpb.SealedBytes) ([]byte, error) {
+				return []byte{}, nil
+			},
+		},
+	}
+	for _, tc := range []struct {
+		testName string
+		handler  http.HandlerFunc
+		method   string
+		body     string
+	}{
+		{"SealGet", ah.seal, http.MethodGet, ""},
+		{"SealEmpty", ah.seal, http.MethodPost, ""},
+		{"SealTooLarge", ah.seal, http.MethodPost, strings.Repeat("a", maxSealDataSize+1)},
+		{"UnsealGet", ah.unseal, http.MethodGet, ""},
+		{"UnsealEmpty", ah.unseal, http.MethodPost, ""},
+		{"UnsealTooLarge", ah.unseal, http.MethodPost, strings.Repeat("a", maxUnsealDataSize+1)},
+		{"UnsealNotSealedBytes", ah.unseal, http.MethodPost, "not a proto"},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tc.handler(w, httptest.NewRequest(tc.method, "/", strings.NewReader(tc.body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("got return code %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetEvidence(t *testing.T) {
+	ah := attestHandler{
+		logger: logging.SimpleLogger(),
+		attestAgent: fakeAttestationAgent{
+			evidenceFunc: func(nonce []byte) (*attestpb.Attestation, error) {
+				return &attestpb.Attestation{AkPub: []byte("ak"), CanonicalEventLog: []byte("cel"), Quotes: []*tpmpb.Quote{{RawSig: nonce}}}, nil
+			},
+		},
+	}
+	want := &attestpb.Attestation{AkPub: []byte("ak"), CanonicalEventLog: []byte("cel"), Quotes: []*tpmpb.Quote{{RawSig: []byte("nonce")}}}
+	body := `{"nonce":"bm9uY2U="}`
+
+	for _, tc := range []struct {
+		testName        string
+		accept          string
+		wantContentType string
+		unmarshal       func([]byte, proto.Message) error
+	}{
+		{"Proto", "", "application/octet-stream", proto.Unmarshal},
+		{"JSON", "application/json", "application/json", protojson.Unmarshal},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/evidence", strings.NewReader(body))
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			ah.getEvidence(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got return code %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.wantContentType {
+				t.Errorf("got Content-Type %q, want %q", got, tc.wantContentType)
+			}
+			got := &attestpb.Attestation{}
+			if err := tc.unmarshal(w.Body.Bytes(), got); err != nil {
+				t.Fatalf("failed to unmarshal the evidence: %v", err)
+			}
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("got unexpected evidence (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetEvidenceErrors(t *testing.T) {
+	okAgent := fakeAttestationAgent{
+		evidenceFunc: func([]byte) (*attestpb.Attestation, error) {
+			return &attestpb.Attestation{}, nil
+		},
+	}
+	for _, tc := range []struct {
+		testName string
+		agent    fakeAttestationAgent
+		method   string
+		body     string
+		wantCode int
+	}{
+		{"Get", okAgent, http.MethodGet, "", http.StatusBadRequest},
+		{"NotJSON", okAgent, http.MethodPost, "nonce", http.StatusBadRequest},
+		{"UnknownField", okAgent, http.MethodPost, `{"nonce":"bm9uY2U=","audience":"aud"}`, http.StatusBadRequest},
+		{"NoNonce", okAgent, http.MethodPost, `{}`, http.StatusBadRequest},
+		{"NonceTooLarge", okAgent, http.MethodPost, fmt.Sprintf(`{"nonce":"%s"}`, base64.StdEncoding.EncodeToString(make([]byte, maxEvidenceNonceSize+1))), http.StatusBadRequest},
+		{"AgentError", fakeAttestationAgent{
+			evidenceFunc: func([]byte) (*attestpb.Attestation, error) {
+				return nil, errors.New("quote failed")
+			},
+		}, http.MethodPost, `{"nonce":"bm9uY2U="}`, http.StatusInternalServerError},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			ah := attestHandler{logger: logging.SimpleLogger(), attestAgent: tc.agent}
+			w := httptest.NewRecorder()
+			ah.getEvidence(w, httptest.NewRequest(tc.method, "/v1/evidence", strings.NewReader(tc.body)))
+			if w.Code != tc.wantCode {
+				t.Errorf("got return code %d, want %d", w.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
+// parseRATLSEvidence returns the attestation in the CMW of the RA-TLS
+// extension of the certificate.
+func parseRATLSEvidence(t *testing.T, cert *x509.Certificate) *attestpb.Attestation {
+	t.Helper()
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(server.RATLSEvidenceOID) {
+			continue
+		}
+		var cmw []byte
+		if _, err := asn1.Unmarshal(ext.Value, &cmw); err != nil {
+			t.Fatalf("failed to parse the RA-TLS extension: %v", err)
+		}
+		decoded, err := cbor.Decode(cmw)
+		if err != nil {
+			t.Fatalf("failed to decode the RA-TLS CMW: %v", err)
+		}
+		record, ok := decoded.([]any)
+		if !ok || len(record) != 2 || record[0] != server.RATLSMediaType {
+			t.Fatalf("got RA-TLS CMW %v, want a record of type %q", decoded, server.RATLSMediaType)
+		}
+		evidence, ok := record[1].([]byte)
+		if !ok {
+			t.Fatalf("got RA-TLS CMW value %v, want a byte string", record[1])
+		}
+		got := &attestpb.Attestation{}
+		if err := proto.Unmarshal(evidence, got); err != nil {
+			t.Fatalf("failed to parse the RA-TLS attestation: %v", err)
+		}
+		return got
+	}
+	return nil
+}
+
+func TestGetRATLSCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "workload"},
+		DNSNames: []string{"workload.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+	for _, tc := range []struct {
+		testName  string
+		target    string
+		challenge []byte
+	}{
+		{"NoChallenge", "/v1/ratls/certificate", nil},
+		{"Challenge", "/v1/ratls/certificate?challenge=6368616c6c656e6765", []byte("challenge")},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			var gotNonce []byte
+			want := &attestpb.Attestation{AkPub: []byte("ak"), CanonicalEventLog: []byte("cel")}
+			ah := attestHandler{
+				logger: logging.SimpleLogger(),
+				attestAgent: fakeAttestationAgent{
+					evidenceFunc: func(nonce []byte) (*attestpb.Attestation, error) {
+						gotNonce = nonce
+						return want, nil
+					},
+				},
+			}
+
+			w := httptest.NewRecorder()
+			ah.getRATLSCertificate(w, httptest.NewRequest(http.MethodPost, tc.target, bytes.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got return code %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+			block, _ := pem.Decode(w.Body.Bytes())
+			if block == nil {
+				t.Fatalf("got no PEM certificate: %s", w.Body.String())
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("failed to parse the certificate: %v", err)
+			}
+			if !key.PublicKey.Equal(cert.PublicKey) {
+				t.Error("got certificate for another key")
+			}
+			wantNonce, err := server.RATLSNonce(key.Public(), cert.NotBefore, cert.NotAfter, tc.challenge)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(gotNonce, wantNonce) {
+				t.Errorf("got evidence nonce %x, want the nonce of the key, validity period and challenge %x", gotNonce, wantNonce)
+			}
+			if diff := cmp.Diff([]string{"workload.example.com"}, cert.DNSNames); diff != "" {
+				t.Errorf("got unexpected DNS names (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want, parseRATLSEvidence(t, cert), protocmp.Transform()); diff != "" {
+				t.Errorf("got unexpected RA-TLS evidence (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRATLSCertificateBadRequests(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "workload"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSignature := bytes.Clone(csr)
+	badSignature[len(badSignature)-1] ^= 1
+	ah := attestHandler{
+		logger: logging.SimpleLogger(),
+		attestAgent: fakeAttestationAgent{
+			evidenceFunc: func([]byte) (*attestpb.Attestation, error) {
+				return &attestpb.Attestation{}, nil
+			},
+		},
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+	for _, tc := range []struct {
+		testName string
+		method   string
+		target   string
+		body     []byte
+	}{
+		{"Get", http.MethodGet, "/v1/ratls/certificate", nil},
+		{"NotPEM", http.MethodPost, "/v1/ratls/certificate", csr},
+		{"NotCSR", http.MethodPost, "/v1/ratls/certificate", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: csr})},
+		{"BadSignature", http.MethodPost, "/v1/ratls/certificate", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: badSignature})},
+		{"ChallengeNotHex", http.MethodPost, "/v1/ratls/certificate?challenge=xyz", csrPEM},
+		{"ChallengeTooLarge", http.MethodPost, "/v1/ratls/certificate?challenge=" + strings.Repeat("00", maxEvidenceNonceSize+1), csrPEM},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ah.getRATLSCertificate(w, httptest.NewRequest(tc.method, tc.target, bytes.NewReader(tc.body)))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("got return code %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+// readTokenEvent reads the next server-sent event of the token watch stream.
+func readTokenEvent(t *testing.T, r *bufio.Reader) (string, tokenEvent) {
+	t.Helper()
+	var name string
+	var event tokenEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the token event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return name, event
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatalf("failed to parse the token event data: %v", err)
+			}
+		}
+	}
+}
+
+func TestWatchToken(t *testing.T) {
+	tokens := NewTokenNotifier()
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	tokens.SetToken([]byte("token1"), expiry)
+
+	ah := &attestHandler{
+		logger:   logging.SimpleLogger(),
+		tokens:   tokens,
+		shutdown: make(chan struct{}),
+	}
+	s := httptest.NewServer(ah.Handler())
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/v1/token/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got return code %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got content type %q, want %q", got, "text/event-stream")
+	}
+	body := bufio.NewReader(resp.Body)
+
+	wantExpiry := "2030-01-02T03:04:05Z"
+	name, event := readTokenEvent(t, body)
+	if diff := cmp.Diff(tokenEvent{Token: "token1", ExpiresAt: wantExpiry}, event); name != tokenEventName || diff != "" {
+		t.Errorf("got %q event, want %q event; data diff (-want +got):\n%s", name, tokenEventName, diff)
+	}
+
+	tokens.SetRefreshError(errors.New("attest error"), true)
+	name, event = readTokenEvent(t, body)
+	if diff := cmp.Diff(tokenEvent{ExpiresAt: wantExpiry, Error: "attest error", Retrying: true}, event); name != refreshErrorEventName || diff != "" {
+		t.Errorf("got %q event, want %q event; data diff (-want +got):\n%s", name, refreshErrorEventName, diff)
+	}
+
+	newExpiry := expiry.Add(time.Hour)
+	tokens.SetToken([]byte("token2"), newExpiry)
+	name, event = readTokenEvent(t, body)
+	if diff := cmp.Diff(tokenEvent{Token: "token2", ExpiresAt: "2030-01-02T04:04:05Z"}, event); name != tokenEventName || diff != "" {
+		t.Errorf("got %q event, want %q event; data diff (-want +got):\n%s", name, tokenEventName, diff)
+	}
+
+	tokens.SetRefreshError(errors.New("attest error"), false)
+	name, event = readTokenEvent(t, body)
+	if diff := cmp.Diff(tokenEvent{ExpiresAt: "2030-01-02T04:04:05Z", Error: "attest error"}, event); name != refreshErrorEventName || diff != "" {
+		t.Errorf("got %q event, want %q event; data diff (-want +got):\n%s", name, refreshErrorEventName, diff)
+	}
+
+	// The stream ends when the server shuts down.
+	close(ah.shutdown)
+	if _, err := body.ReadString('\n'); err != io.EOF {
+		t.Errorf("got error %v after shutdown, want %v", err, io.EOF)
+	}
+}
+
+func TestWatchTokenErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		method   string
+		tokens   *TokenNotifier
+		wantCode int
+	}{
+		{"NoTokens", http.MethodGet, nil, http.StatusPreconditionFailed},
+		{"BadMethod", http.MethodPost, NewTokenNotifier(), http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ah := &attestHandler{
+				logger:     logging.SimpleLogger(),
+				launchSpec: spec.LaunchSpec{Verifier: spec.ITAVerifier},
+				tokens:     tc.tokens,
+			}
+			w := httptest.NewRecorder()
+			ah.watchToken(w, httptest.NewRequest(tc.method, "/v1/token/watch", nil))
+			if w.Code != tc.wantCode {
+				t.Errorf("got return code %d, want %d", w.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
+// createTestJWT creates a token expiring after ttl, with the audience.
+func createTestJWT(t *testing.T, audience string, ttl time.Duration) []byte {
+	t.Helper()
+	claims := jwt.RegisteredClaims{
+		Audience:  []string{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(token)
+}
+
+func TestCustomTokenCache(t *testing.T) {
+	var attests int
+	ttls := map[string]time.Duration{
+		"cached":      time.Hour,
+		"other":       time.Hour,
+		"short-lived": time.Minute,
+	}
+	ah := attestHandler{
+		logger: logging.SimpleLogger(),
+		clients: AttestClients{
+			spec.GCAVerifier: &fakeVerifierClient{},
+		},
+		attestAgent: fakeAttestationAgent{
+			attestWithClientFunc: func(_ context.Context, opts agent.AttestAgentOpts, _ spec.VerifierType, _ verifier.Client) ([]byte, error) {
+				attests++
+				return createTestJWT(t, opts.TokenOptions.Audience, ttls[opts.TokenOptions.Audience]), nil
+			},
+		}}
+
+	getToken := func(body string) []byte {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/token", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		ah.getToken(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got return code %d, want %d", w.Code, http.StatusOK)
+		}
+		return w.Body.Bytes()
+	}
+
+	for _, tc := range []struct {
+		testName    string
+		body        string
+		wantAttests int
+	}{
+		{"FirstRequest", `{"audience":"cached","token_type":"OIDC"}`, 1},
+		{"SameOptions", `{"audience":"cached","token_type":"OIDC"}`, 1},
+		{"OtherNonces", `{"audience":"cached","token_type":"OIDC","nonces":["thisIsAcustomNonce"]}`, 2},
+		{"SameNonces", `{"audience":"cached","token_type":"OIDC","nonces":["thisIsAcustomNonce"]}`, 2},
+		{"OtherTokenType", `{"audience":"cached","token_type":"PKI"}`, 3},
+		{"OtherAudience", `{"audience":"other","token_type":"OIDC"}`, 4},
+		{"ShortLivedToken", `{"audience":"short-lived","token_type":"OIDC"}`, 5},
+		{"ShortLivedTokenNotCached", `{"audience":"short-lived","token_type":"OIDC"}`, 6},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			getToken(tc.body)
+			if attests != tc.wantAttests {
+				t.Errorf("got %d attestations, want %d", attests, tc.wantAttests)
+			}
+		})
+	}
+
+	// A cached token is served on its endpoint only.
+	req := httptest.NewRequest(http.MethodPost, "/v1/intel/token", strings.NewReader(`{"audience":"cached","token_type":"OIDC"}`))
+	ah.attest(httptest.NewRecorder(), req, &fakeVerifierClient{})
+	if attests != 7 {
+		t.Errorf("got %d attestations, want 7", attests)
+	}
+}
+
+func TestTokenRateLimit(t *testing.T) {
+	ah := attestHandler{
+		logger:     logging.SimpleLogger(),
+		launchSpec: spec.LaunchSpec{TokenRateLimit: 2},
+		clients: AttestClients{
+			spec.GCAVerifier: &fakeVerifierClient{},
+		},
+		attestAgent: fakeAttestationAgent{
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
+				return []byte("test token"), nil
+			},
+		}}
+
+	for i, wantCode := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		ah.getToken(w, httptest.NewRequest(http.MethodGet, "/v1/token", nil))
+		if w.Code != wantCode {
+			t.Errorf("request %d: got return code %d, want %d", i, w.Code, wantCode)
+		}
+		if wantCode == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "30" {
+			t.Errorf("request %d: got Retry-After %q, want %q", i, w.Header().Get("Retry-After"), "30")
+		}
+	}
+
+	// The rate is limited per endpoint.
+	req := httptest.NewRequest(http.MethodPost, "/v1/intel/token", strings.NewReader(`{"audience":"aud","token_type":"OIDC"}`))
+	w := httptest.NewRecorder()
+	ah.attest(w, req, &fakeVerifierClient{})
+	if w.Code != http.StatusOK {
+		t.Errorf("got return code %d on another endpoint, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestTokenMaxConcurrency(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	ah := attestHandler{
+		logger:     logging.SimpleLogger(),
+		launchSpec: spec.LaunchSpec{TokenMaxConcurrency: 1},
+		clients: AttestClients{
+			spec.GCAVerifier: &fakeVerifierClient{},
+		},
+		attestAgent: fakeAttestationAgent{
+			attestWithClientFunc: func(context.Context, agent.AttestAgentOpts, spec.VerifierType, verifier.Client) ([]byte, error) {
+				started <- struct{}{}
+				<-unblock
+				return []byte("test token"), nil
+			},
+		}}
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		ah.getToken(w, httptest.NewRequest(http.MethodGet, "/v1/token", nil))
+		done <- w.Code
+	}()
+	<-started
+
+	// The second request waits for the first one, until it is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	ah.getToken(w, httptest.NewRequest(http.MethodGet, "/v1/token", nil).WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got return code %d for a waiting request, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	close(unblock)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("got return code %d for the running request, want %d", code, http.StatusOK)
+	}
+
+	// The slot is released.
+	started = make(chan struct{}, 1)
+	w = httptest.NewRecorder()
+	ah.getToken(w, httptest.NewRequest(http.MethodGet, "/v1/token", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("got return code %d after release, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	validToken := func() *TokenNotifier {
+		n := NewTokenNotifier()
+		n.SetToken([]byte("token"), time.Now().Add(time.Hour))
+		return n
+	}
+	for _, tc := range []struct {
+		name     string
+		tokens   func() *TokenNotifier
+		wantCode int
+	}{
+		{"NoTokens", func() *TokenNotifier { return nil }, http.StatusOK},
+		{"NoTokenYet", NewTokenNotifier, http.StatusOK},
+		{"ValidToken", validToken, http.StatusOK},
+		{"RetryingRefresh", func() *TokenNotifier {
+			n := validToken()
+			n.SetRefreshError(errors.New("attest error"), true)
+			return n
+		}, http.StatusOK},
+		{"StoppedRefresher", func() *TokenNotifier {
+			n := validToken()
+			n.SetRefreshError(errors.New("attest error"), false)
+			return n
+		}, http.StatusServiceUnavailable},
+		{"ExpiredToken", func() *TokenNotifier {
+			n := NewTokenNotifier()
+			n.SetToken([]byte("token"), time.Now().Add(-time.Minute))
+			return n
+		}, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ah := &attestHandler{
+				logger: logging.SimpleLogger(),
+				tokens: tc.tokens(),
+			}
+			w := httptest.NewRecorder()
+			ah.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if w.Code != tc.wantCode {
+				t.Errorf("got return code %d, want %d", w.Code, tc.wantCode)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ah := &attestHandler{logger: logging.SimpleLogger()}
+	w := httptest.NewRecorder()
+	ah.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("got return code %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("got content type %q, want %q", got, metrics.ContentType)
+	}
+	if want := "# TYPE launcher_attest_total counter\n"; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("got metrics %q, want %q", w.Body.String(), want)
+	}
+}
@@ -0,0 +1,87 @@
+package teeserver
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-tpm-tools/verifier/models"
+)
+
+const (
+	// A cached token is served while it is valid for at least this long, so
+	// that the workload has time to use it.
+	minCachedTokenLifetime = 5 * time.Minute
+	// The limit on the number of cached tokens.
+	maxCachedTokens = 128
+)
+
+// tokenCache caches the custom tokens by endpoint and TokenOptions, until
+// they expire.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	token     []byte
+	expiresAt time.Time
+}
+
+// tokenCacheKey returns the cache key of the token options on the endpoint.
+func tokenCacheKey(endpoint string, opts *models.TokenOptions) (string, error) {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	return endpoint + " " + string(data), nil
+}
+
+// get returns the cached token of the key, if it is valid for at least
+// minCachedTokenLifetime.
+func (c *tokenCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.tokens[key]
+	if !ok || time.Until(cached.expiresAt) < minCachedTokenLifetime {
+		return nil, false
+	}
+	return cached.token, true
+}
+
+// put caches the token under the key, until its "exp" claim. Tokens without
+// an expiry are not cached. If the cache is full, the tokens expiring first
+// are evicted.
+func (c *tokenCache) put(key string, token []byte) {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(string(token), claims); err != nil || claims.ExpiresAt == nil {
+		return
+	}
+	expiresAt := claims.ExpiresAt.Time
+	if time.Until(expiresAt) < minCachedTokenLifetime {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = make(map[string]cachedToken)
+	}
+	now := time.Now()
+	for k, cached := range c.tokens {
+		if now.After(cached.expiresAt) {
+			delete(c.tokens, k)
+		}
+	}
+	if _, ok := c.tokens[key]; !ok && len(c.tokens) >= maxCachedTokens {
+		var first string
+		for k, cached := range c.tokens {
+			if first == "" || cached.expiresAt.Before(c.tokens[first].expiresAt) {
+				first = k
+			}
+		}
+		delete(c.tokens, first)
+	}
+	c.tokens[key] = cachedToken{token: token, expiresAt: expiresAt}
+}
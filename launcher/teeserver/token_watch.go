@@ -0,0 +1,176 @@
+package teeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenNotifier holds the latest default token of the launcher's token
+// refresher, and notifies the watchers of the token of its updates. A nil
+// TokenNotifier ignores updates.
+type TokenNotifier struct {
+	mu    sync.Mutex
+	state tokenState
+	// changed is closed, and replaced, on every update.
+	changed chan struct{}
+}
+
+// tokenState is the state of the token refresher.
+type tokenState struct {
+	// version increments on every update, starting from 0 before the first.
+	version   uint64
+	token     []byte
+	expiresAt time.Time
+	// refreshErr is the error of the last refresh, if it failed.
+	refreshErr error
+	// retrying is false if the refresher stopped after refreshErr.
+	retrying bool
+}
+
+// NewTokenNotifier creates a TokenNotifier without a token.
+func NewTokenNotifier() *TokenNotifier {
+	return &TokenNotifier{changed: make(chan struct{})}
+}
+
+// SetToken publishes a refreshed default token, which expires at expiresAt.
+func (n *TokenNotifier) SetToken(token []byte, expiresAt time.Time) {
+	n.update(func(s *tokenState) {
+		s.token = token
+		s.expiresAt = expiresAt
+		s.refreshErr = nil
+	})
+}
+
+// SetRefreshError publishes that refreshing the default token failed. The
+// refresher retries if retrying is true. Otherwise, it stopped: the last
+// token is never refreshed again.
+func (n *TokenNotifier) SetRefreshError(err error, retrying bool) {
+	n.update(func(s *tokenState) {
+		s.refreshErr = err
+		s.retrying = retrying
+	})
+}
+
+func (n *TokenNotifier) update(f func(*tokenState)) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f(&n.state)
+	n.state.version++
+	close(n.changed)
+	n.changed = make(chan struct{})
+}
+
+// watch returns the current state, and a channel closed on the next update.
+func (n *TokenNotifier) watch() (tokenState, <-chan struct{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.state, n.changed
+}
+
+// healthy returns an error if the refresher stopped, or if the last token
+// expired.
+func (n *TokenNotifier) healthy() error {
+	if n == nil {
+		return nil
+	}
+	state, _ := n.watch()
+	if state.refreshErr != nil && !state.retrying {
+		return fmt.Errorf("the default token refresher stopped: %v", state.refreshErr)
+	}
+	if !state.expiresAt.IsZero() && time.Now().After(state.expiresAt) {
+		return fmt.Errorf("the default token expired at %v", state.expiresAt)
+	}
+	return nil
+}
+
+// tokenEvent is the data of an event of the token watch stream.
+type tokenEvent struct {
+	Token string `json:"token,omitempty"`
+	// ExpiresAt is the expiry of the latest token, in RFC 3339 format.
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Retrying  bool   `json:"retrying,omitempty"`
+}
+
+// Events of the token watch stream.
+const (
+	tokenEventName        = "token"
+	refreshErrorEventName = "refresh_error"
+)
+
+// writeTokenEvent writes the state as a server-sent event: a "token" event
+// with a refreshed token, or a "refresh_error" event if the last refresh
+// failed.
+func writeTokenEvent(w http.ResponseWriter, state tokenState) error {
+	name := tokenEventName
+	event := tokenEvent{}
+	if !state.expiresAt.IsZero() {
+		event.ExpiresAt = state.expiresAt.UTC().Format(time.RFC3339)
+	}
+	if state.refreshErr != nil {
+		name = refreshErrorEventName
+		event.Error = state.refreshErr.Error()
+		event.Retrying = state.retrying
+	} else {
+		event.Token = string(state.token)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
+
+// watchToken streams the default token as server-sent events, as it is
+// refreshed: the current state first, if any, then every update. A workload
+// should stop using its token when it gets a "refresh_error" event without
+// "retrying", once the token expires.
+func (a *attestHandler) watchToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := fmt.Errorf("TEE server received an invalid HTTP method: %s", r.Method)
+		a.logAndWriteHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	if a.tokens == nil {
+		err := fmt.Errorf("no default token is refreshed for the %s verifier", a.launchSpec.Verifier)
+		a.logAndWriteHTTPError(w, http.StatusPreconditionFailed, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.logAndWriteHTTPError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var version uint64
+	for {
+		state, changed := a.tokens.watch()
+		// Only the latest state is sent to a slow watcher.
+		if state.version != version {
+			if err := writeTokenEvent(w, state); err != nil {
+				a.logger.Warn(fmt.Sprintf("failed to write the token event: %v", err))
+				return
+			}
+			flusher.Flush()
+			version = state.version
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-a.shutdown:
+			return
+		}
+	}
+}
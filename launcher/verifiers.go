@@ -0,0 +1,164 @@
+package launcher
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/launcher/launcherfile"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm-tools/verifier"
+	"github.com/google/go-tpm-tools/verifier/ita"
+	"github.com/google/go-tpm-tools/verifier/local"
+	"github.com/google/go-tpm-tools/verifier/util"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// verifierFactory creates the client of a verifier backend from the launch
+// spec. tpm is the TPM of the VM, for the backends keeping keys in it.
+type verifierFactory func(ctx context.Context, launchSpec spec.LaunchSpec, tpm io.ReadWriter) (verifier.Client, error)
+
+// verifierFactories is the registry of the verifier backends, by the verifier
+// type selected in the launch spec.
+var verifierFactories = map[spec.VerifierType]verifierFactory{
+	spec.GCAVerifier: func(ctx context.Context, launchSpec spec.LaunchSpec, _ io.ReadWriter) (verifier.Client, error) {
+		return util.NewRESTClient(ctx, launchSpec.AttestationServiceAddr, launchSpec.ProjectID, launchSpec.Region)
+	},
+	spec.ITAVerifier: func(_ context.Context, launchSpec spec.LaunchSpec, _ io.ReadWriter) (verifier.Client, error) {
+		return ita.NewClient(launchSpec.ITARegion, launchSpec.ITAKey)
+	},
+	spec.LocalVerifier: newLocalVerifierClient,
+}
+
+// newVerifierClient creates the client of the verifier selected in the launch
+// spec.
+func newVerifierClient(ctx context.Context, launchSpec spec.LaunchSpec, tpm io.ReadWriter) (verifier.Client, error) {
+	factory, ok := verifierFactories[launchSpec.Verifier]
+	if !ok {
+		return nil, fmt.Errorf("unsupported verifier %q", launchSpec.Verifier)
+	}
+	client, err := factory(ctx, launchSpec, tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s verifier client: %v", launchSpec.Verifier, err)
+	}
+	return client, nil
+}
+
+// localVerifierConfig is the verifier config of the local verifier.
+type localVerifierConfig struct {
+	Issuer string `json:"issuer"`
+	// Policy is the JSON encoding of the attest.Policy that the workload must
+	// satisfy to get tokens, if any.
+	Policy json.RawMessage `json:"policy"`
+}
+
+// localVerifierKeyPath is the file holding the signing key of the local
+// verifier, sealed to localVerifierKeyPCRSel. Like the CEL journals, it lasts
+// until the VM reboots, so that a restarted launcher signs with, and measures,
+// the same key.
+var localVerifierKeyPath = path.Join(launcherfile.CELJournalPath, "local_verifier_key")
+
+// localVerifierKeyPCRSel selects the PCRs the signing key of the local verifier
+// is sealed to: the ones pinning the COS image. The COS event PCR is left out,
+// as a restarted launcher unseals the key after resuming the CEL.
+var localVerifierKeyPCRSel = tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{0, 4, 7, 8, 9}}
+
+// newLocalVerifierClient creates a local verifier signing the tokens with a
+// P-256 key generated in the VM, which only leaves the launcher sealed to the
+// TPM. The launcher measures its public key in a LocalVerifierKey event, so
+// that relying parties learn it from an attestation.
+func newLocalVerifierClient(_ context.Context, launchSpec spec.LaunchSpec, tpm io.ReadWriter) (verifier.Client, error) {
+	var config localVerifierConfig
+	decoder := json.NewDecoder(strings.NewReader(launchSpec.VerifierConfig))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse the verifier config: %v", err)
+	}
+	signer, err := loadLocalVerifierKey(tpm, localVerifierKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := local.Options{
+		Signer: signer,
+		Issuer: config.Issuer,
+	}
+	if len(config.Policy) > 0 {
+		opts.Policy = &attestpb.Policy{}
+		if err := protojson.Unmarshal(config.Policy, opts.Policy); err != nil {
+			return nil, fmt.Errorf("failed to parse the verifier policy: %v", err)
+		}
+	}
+	return local.NewClient(opts)
+}
+
+// loadLocalVerifierKey unseals the signing key at keyPath, or generates one and
+// seals it there if there is none yet.
+func loadLocalVerifierKey(tpm io.ReadWriter, keyPath string) (*ecdsa.PrivateKey, error) {
+	srk, err := client.StorageRootKeyECC(tpm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the SRK: %v", err)
+	}
+	defer srk.Close()
+
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		sealed := &tpmpb.SealedBytes{}
+		if err := proto.Unmarshal(data, sealed); err != nil {
+			return nil, fmt.Errorf("failed to parse the sealed signing key: %v", err)
+		}
+		der, err := srk.Unseal(sealed, client.UnsealOpts{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal the signing key: %v", err)
+		}
+		key, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the signing key: %v", err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the signing key: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the signing key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the signing key: %v", err)
+	}
+	sealed, err := srk.Seal(der, client.SealOpts{Current: localVerifierKeyPCRSel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal the signing key: %v", err)
+	}
+	if data, err = proto.Marshal(sealed); err != nil {
+		return nil, fmt.Errorf("failed to marshal the sealed signing key: %v", err)
+	}
+	if err := os.MkdirAll(path.Dir(keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the signing key directory: %v", err)
+	}
+	// Write the key atomically, so that a crash never leaves a partial key.
+	tmpPath := keyPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the sealed signing key: %v", err)
+	}
+	if err := os.Rename(tmpPath, keyPath); err != nil {
+		return nil, fmt.Errorf("failed to write the sealed signing key: %v", err)
+	}
+	return key, nil
+}
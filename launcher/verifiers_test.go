@@ -0,0 +1,82 @@
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"testing"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+func TestNewLocalVerifierClient(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	defer func(keyPath string) { localVerifierKeyPath = keyPath }(localVerifierKeyPath)
+	localVerifierKeyPath = path.Join(t.TempDir(), "local_verifier_key")
+	config := func(fields map[string]any) string {
+		b, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	for _, tc := range []struct {
+		testName string
+		config   string
+		wantErr  bool
+	}{
+		{"Issuer", config(map[string]any{"issuer": "https://verifier.example.com"}), false},
+		{"IssuerWithPolicy", config(map[string]any{"issuer": "https://verifier.example.com", "policy": map[string]any{"platform": map[string]any{"minimum_technology": "AMD_SEV_SNP"}}}), false},
+		{"NotJSON", "issuer", true},
+		{"UnknownField", config(map[string]any{"issuer": "https://verifier.example.com", "audience": "aud"}), true},
+		{"SigningKey", config(map[string]any{"issuer": "https://verifier.example.com", "signing_key": "key"}), true},
+		{"NoIssuer", config(map[string]any{}), true},
+		{"BadPolicy", config(map[string]any{"issuer": "https://verifier.example.com", "policy": map[string]any{"platform": "none"}}), true},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			_, err := newVerifierClient(context.Background(), spec.LaunchSpec{Verifier: spec.LocalVerifier, VerifierConfig: tc.config}, tpm)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("newVerifierClient() got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewVerifierClientUnknownVerifier(t *testing.T) {
+	if _, err := newVerifierClient(context.Background(), spec.LaunchSpec{Verifier: "other"}, nil); err == nil {
+		t.Error("newVerifierClient() got nil error, want error")
+	}
+}
+
+func TestLoadLocalVerifierKey(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+	keyPath := path.Join(t.TempDir(), "local_verifier_key")
+
+	key, err := loadLocalVerifierKey(tpm, keyPath)
+	if err != nil {
+		t.Fatalf("loadLocalVerifierKey() failed: %v", err)
+	}
+	again, err := loadLocalVerifierKey(tpm, keyPath)
+	if err != nil {
+		t.Fatalf("loadLocalVerifierKey() of the sealed key failed: %v", err)
+	}
+	if !key.Equal(again) {
+		t.Error("loadLocalVerifierKey() of the sealed key got another key")
+	}
+
+	// Another boot chain cannot unseal the key.
+	if err := tpm2.PCRExtend(tpm, tpmutil.Handle(9), tpm2.AlgSHA256, make([]byte, 32), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLocalVerifierKey(tpm, keyPath); err == nil {
+		t.Error("loadLocalVerifierKey() after extending PCR 9 got nil error, want error")
+	}
+}
@@ -0,0 +1,325 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/containerd/containerd"
+	"github.com/containerd/containerd/cio"
+	"github.com/containerd/containerd/oci"
+	"github.com/google/go-tpm-tools/cel"
+	"github.com/google/go-tpm-tools/launcher/internal/logging"
+	"github.com/google/go-tpm-tools/launcher/internal/signaturediscovery"
+	"github.com/google/go-tpm-tools/launcher/launcherfile"
+	"github.com/google/go-tpm-tools/launcher/spec"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/oauth2"
+)
+
+// workloadContainer is a container of the workload other than the main
+// container: an init container or a sidecar.
+type workloadContainer struct {
+	spec      spec.Container
+	container containerd.Container
+}
+
+// The workload container and snapshot ids are derived from the main ones and
+// the container name, which is unique in the launch spec.
+func workloadContainerID(name string) string {
+	return containerID + "-" + name
+}
+
+func workloadSnapshotID(name string) string {
+	return snapshotID + "-" + name
+}
+
+// appendSharedVolumeMount appends the mount spec for the volume shared by the
+// main container and the other containers of the workload.
+func appendSharedVolumeMount(mounts []specs.Mount) []specs.Mount {
+	m := specs.Mount{}
+	m.Destination = launcherfile.ContainerSharedVolumePath
+	m.Type = "bind"
+	m.Source = launcherfile.HostSharedVolumePath
+	m.Options = []string{"rbind", "rw"}
+
+	return append(mounts, m)
+}
+
+// workloadContainerMounts returns the mounts of the container: the given
+// mounts shared with the main container, and the attestation token and TEE
+// server socket mount if the launch spec opts in.
+func workloadContainerMounts(containerSpec spec.Container, mounts []specs.Mount) []specs.Mount {
+	if !containerSpec.AttestationAccess {
+		return mounts
+	}
+	return appendTokenMounts(append([]specs.Mount{}, mounts...))
+}
+
+// createSharedVolume creates the host directory of the volume shared by the
+// main container and the other containers of the workload.
+func createSharedVolume() error {
+	return os.MkdirAll(launcherfile.HostSharedVolumePath, 0755)
+}
+
+// newWorkloadContainer pulls the image of the container, verifies the
+// container overrides against the launch policy of its image, and creates the
+// container. The container shares the host network, and the given mounts,
+// with the main container. It only gets the attestation token and the TEE
+// server socket if the launch spec opts in.
+func newWorkloadContainer(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, containerSpec spec.Container, mounts []specs.Mount, hostname string, logger logging.Logger) (workloadContainer, error) {
+	image, err := initImage(ctx, cdClient, containerSpec.ImageRef, token)
+	if err != nil {
+		return workloadContainer{}, fmt.Errorf("container %q: %w", containerSpec.Name, err)
+	}
+
+	envs, err := formatEnvVars(containerSpec.Envs)
+	if err != nil {
+		return workloadContainer{}, err
+	}
+	// Check if there is already a container
+	container, err := cdClient.LoadContainer(ctx, workloadContainerID(containerSpec.Name))
+	if err == nil {
+		// container exists, delete it first
+		container.Delete(ctx, containerd.WithSnapshotCleanup)
+	}
+
+	logger.Info("Preparing workload container",
+		"container", containerSpec.Name,
+		"operator_input_image_ref", image.Name(),
+		"image_digest", image.Target().Digest,
+		"operator_override_env_vars", envs,
+		"operator_override_cmd", containerSpec.Cmd,
+	)
+
+	imageConfig, err := getImageConfig(ctx, image)
+	if err != nil {
+		return workloadContainer{}, err
+	}
+	if err := openPorts(imageConfig.ExposedPorts); err != nil {
+		return workloadContainer{}, err
+	}
+	launchPolicy, err := spec.GetLaunchPolicy(imageConfig.Labels, logger)
+	if err != nil {
+		return workloadContainer{}, fmt.Errorf("failed to parse Launch Policy of container %q image: %v: contact the image author", containerSpec.Name, err)
+	}
+	if err := launchPolicy.VerifyContainer(containerSpec); err != nil {
+		return workloadContainer{}, err
+	}
+
+	specOpts := []oci.SpecOpts{
+		oci.WithImageConfigArgs(image, containerSpec.Cmd),
+		oci.WithEnv(envs),
+		oci.WithMounts(workloadContainerMounts(containerSpec, mounts)),
+		// Share the host network with the main container.
+		oci.WithHostHostsFile,
+		oci.WithHostResolvconf,
+		oci.WithHostNamespace(specs.NetworkNamespace),
+		oci.WithEnv([]string{fmt.Sprintf("HOSTNAME=%s", hostname)}),
+		withRlimits([]specs.POSIXRlimit{{
+			Type: "RLIMIT_NOFILE",
+			Hard: nofile,
+			Soft: nofile,
+		}}),
+		withOOMScoreAdj(defaultOOMScore),
+	}
+	container, err = cdClient.NewContainer(
+		ctx,
+		workloadContainerID(containerSpec.Name),
+		containerd.WithImage(image),
+		containerd.WithNewSnapshot(workloadSnapshotID(containerSpec.Name), image),
+		containerd.WithNewSpec(specOpts...),
+	)
+	if err != nil {
+		if container != nil {
+			container.Delete(ctx, containerd.WithSnapshotCleanup)
+		}
+		return workloadContainer{}, &RetryableError{fmt.Errorf("failed to create container %q: [%w]", containerSpec.Name, err)}
+	}
+
+	ociSpec, err := container.Spec(ctx)
+	if err != nil {
+		container.Delete(ctx, containerd.WithSnapshotCleanup)
+		return workloadContainer{}, &RetryableError{err}
+	}
+	// As for the main container, the Entrypoint is mandatory for the image.
+	if len(ociSpec.Process.Args) <= len(containerSpec.Cmd) {
+		container.Delete(ctx, containerd.WithSnapshotCleanup)
+		return workloadContainer{}, fmt.Errorf("container %q: length of Args [%d] is shorter or equal to the length of the given Cmd [%d], maybe the Entrypoint is set to empty in the image?",
+			containerSpec.Name, len(ociSpec.Process.Args), len(containerSpec.Cmd))
+	}
+	return workloadContainer{containerSpec, container}, nil
+}
+
+// newWorkloadContainers creates the given containers. On error, the containers
+// already created are deleted.
+func newWorkloadContainers(ctx context.Context, cdClient *containerd.Client, token oauth2.Token, containerSpecs []spec.Container, mounts []specs.Mount, hostname string, logger logging.Logger) ([]workloadContainer, error) {
+	var containers []workloadContainer
+	for _, containerSpec := range containerSpecs {
+		c, err := newWorkloadContainer(ctx, cdClient, token, containerSpec, mounts, hostname, logger)
+		if err != nil {
+			deleteWorkloadContainers(ctx, containers)
+			return nil, err
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+func deleteWorkloadContainers(ctx context.Context, containers []workloadContainer) {
+	for _, c := range containers {
+		c.container.Delete(ctx, containerd.WithSnapshotCleanup)
+	}
+}
+
+// measureWorkloadContainerClaims will measure the claims of every container
+// into the COS eventlog in the AttestationAgent. The claims of each container
+// follow an event of the given type (SidecarType or InitContainerType) with
+// its name.
+func (r *ContainerRunner) measureWorkloadContainerClaims(ctx context.Context, eventType cel.CosType, containers []workloadContainer) error {
+	for _, c := range containers {
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: eventType, EventContent: []byte(c.spec.Name)}); err != nil {
+			return err
+		}
+		image, err := c.container.Image(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte(image.Name())}); err != nil {
+			return err
+		}
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageDigestType, EventContent: []byte(image.Target().Digest)}); err != nil {
+			return err
+		}
+		if imageConfigDescriptor, err := image.Config(ctx); err == nil { // if NO error
+			if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.ImageIDType, EventContent: []byte(imageConfigDescriptor.Digest)}); err != nil {
+				return err
+			}
+		}
+		if err := r.measureProcessClaims(ctx, c.container, c.spec.Envs, c.spec.Cmd); err != nil {
+			return err
+		}
+		if err := r.measureMounts(); err != nil {
+			return err
+		}
+		var attestationAccess uint8
+		if c.spec.AttestationAccess {
+			attestationAccess = 1
+		}
+		if err := r.attestAgent.MeasureEvent(cel.CosTlv{EventType: cel.AttestationAccessType, EventContent: []byte{attestationAccess}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workloadSignatureFetcher returns a Fetcher of the signatures of the main
+// container image and of the images of the other containers, so that the
+// verifier checks the signatures of all the workload images.
+func workloadSignatureFetcher(ctx context.Context, cdClient *containerd.Client, mdsClient *metadata.Client, mainImage containerd.Image, containers ...[]workloadContainer) (signaturediscovery.Fetcher, error) {
+	fetchers := []signaturediscovery.Fetcher{getSignatureDiscoveryClient(cdClient, mdsClient, mainImage.Target())}
+	for _, cs := range containers {
+		for _, c := range cs {
+			image, err := c.container.Image(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the image of container %q: %v", c.spec.Name, err)
+			}
+			fetchers = append(fetchers, getSignatureDiscoveryClient(cdClient, mdsClient, image.Target()))
+		}
+	}
+	if len(fetchers) == 1 {
+		return fetchers[0], nil
+	}
+	return signaturediscovery.NewMulti(fetchers...), nil
+}
+
+// runInitContainers runs the init containers one after the other, each to
+// completion, with their output redirected like the main container output.
+// It fails on the first init container which does not exit with 0.
+func (r *ContainerRunner) runInitContainers(ctx context.Context, streamOpt cio.Opt) error {
+	for _, c := range r.initContainers {
+		if err := r.runInitContainer(ctx, c, streamOpt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ContainerRunner) runInitContainer(ctx context.Context, c workloadContainer, streamOpt cio.Opt) error {
+	task, err := c.container.NewTask(ctx, cio.NewCreator(streamOpt))
+	if err != nil {
+		return &RetryableError{fmt.Errorf("failed to create init container %q task: %w", c.spec.Name, err)}
+	}
+	defer task.Delete(ctx)
+
+	exitStatusC, err := task.Wait(ctx)
+	if err != nil {
+		return &RetryableError{fmt.Errorf("failed to wait for init container %q task: %w", c.spec.Name, err)}
+	}
+	r.logger.Info("init container task started", "container", c.spec.Name)
+	if err := task.Start(ctx); err != nil {
+		return &RetryableError{fmt.Errorf("failed to start init container %q task: %w", c.spec.Name, err)}
+	}
+	status := <-exitStatusC
+	code, _, err := status.Result()
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		r.logger.Error("init container task ended and returned non-zero", "container", c.spec.Name, "exit_code", code)
+		return &WorkloadError{code}
+	}
+	r.logger.Info("init container task ended and returned 0", "container", c.spec.Name)
+	return nil
+}
+
+// startSidecars creates and starts the tasks of the sidecars, with their
+// output redirected like the main container output. The tasks must be
+// deleted by the caller, even on error, after closing workloadEnded.
+func (r *ContainerRunner) startSidecars(ctx context.Context, streamOpt cio.Opt, workloadEnded <-chan struct{}) ([]containerd.Task, error) {
+	var tasks []containerd.Task
+	for _, s := range r.sidecars {
+		task, err := s.container.NewTask(ctx, cio.NewCreator(streamOpt))
+		if err != nil {
+			return tasks, &RetryableError{fmt.Errorf("failed to create sidecar %q task: %w", s.spec.Name, err)}
+		}
+		tasks = append(tasks, task)
+
+		exitStatusC, err := task.Wait(ctx)
+		if err != nil {
+			r.logger.Error(err.Error())
+		}
+		if err := task.Start(ctx); err != nil {
+			return tasks, &RetryableError{fmt.Errorf("failed to start sidecar %q task: %w", s.spec.Name, err)}
+		}
+		r.logger.Info("sidecar task started", "container", s.spec.Name)
+
+		if exitStatusC != nil {
+			name := s.spec.Name
+			go func() {
+				status := <-exitStatusC
+				select {
+				case <-workloadEnded:
+					// The sidecars are killed when the main container exits.
+					return
+				default:
+				}
+				code, _, err := status.Result()
+				if err != nil {
+					r.logger.Error(fmt.Sprintf("sidecar %q task failed: %v", name, err))
+					return
+				}
+				r.logger.Info("sidecar task ended before the workload", "container", name, "exit_code", code)
+			}()
+		}
+	}
+	return tasks, nil
+}
+
+// deleteSidecarTasks kills the sidecar tasks still running, and deletes them.
+func deleteSidecarTasks(ctx context.Context, tasks []containerd.Task) {
+	for _, task := range tasks {
+		task.Delete(ctx, containerd.WithProcessKill)
+	}
+}
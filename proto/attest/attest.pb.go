@@ -140,6 +140,63 @@ func (WellKnownCertificate) EnumDescriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{1}
 }
 
+// The source of the Secure Boot authority used by shim to verify an image.
+type ShimAuthoritySource int32
+
+const (
+	ShimAuthoritySource_AUTHORITY_SOURCE_UNKNOWN ShimAuthoritySource = 0
+	// The UEFI Secure Boot db variable.
+	ShimAuthoritySource_AUTHORITY_SOURCE_DB ShimAuthoritySource = 1
+	// shim's MokList variable, containing the Machine Owner Keys, which shim
+	// measures under the name of its runtime copy, MokListRT.
+	ShimAuthoritySource_AUTHORITY_SOURCE_MOK ShimAuthoritySource = 2
+	// The vendor certificate or database built into shim.
+	ShimAuthoritySource_AUTHORITY_SOURCE_SHIM_VENDOR ShimAuthoritySource = 3
+)
+
+// Enum value maps for ShimAuthoritySource.
+var (
+	ShimAuthoritySource_name = map[int32]string{
+		0: "AUTHORITY_SOURCE_UNKNOWN",
+		1: "AUTHORITY_SOURCE_DB",
+		2: "AUTHORITY_SOURCE_MOK",
+		3: "AUTHORITY_SOURCE_SHIM_VENDOR",
+	}
+	ShimAuthoritySource_value = map[string]int32{
+		"AUTHORITY_SOURCE_UNKNOWN":     0,
+		"AUTHORITY_SOURCE_DB":          1,
+		"AUTHORITY_SOURCE_MOK":         2,
+		"AUTHORITY_SOURCE_SHIM_VENDOR": 3,
+	}
+)
+
+func (x ShimAuthoritySource) Enum() *ShimAuthoritySource {
+	p := new(ShimAuthoritySource)
+	*p = x
+	return p
+}
+
+func (x ShimAuthoritySource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ShimAuthoritySource) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[2].Descriptor()
+}
+
+func (ShimAuthoritySource) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[2]
+}
+
+func (x ShimAuthoritySource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ShimAuthoritySource.Descriptor instead.
+func (ShimAuthoritySource) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{2}
+}
+
 // The container's restart policy.
 // See the following Kubernetes documentation for more details:
 // https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#restart-policy
@@ -179,11 +236,11 @@ func (x RestartPolicy) String() string {
 }
 
 func (RestartPolicy) Descriptor() protoreflect.EnumDescriptor {
-	return file_attest_proto_enumTypes[2].Descriptor()
+	return file_attest_proto_enumTypes[3].Descriptor()
 }
 
 func (RestartPolicy) Type() protoreflect.EnumType {
-	return &file_attest_proto_enumTypes[2]
+	return &file_attest_proto_enumTypes[3]
 }
 
 func (x RestartPolicy) Number() protoreflect.EnumNumber {
@@ -192,7 +249,173 @@ func (x RestartPolicy) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RestartPolicy.Descriptor instead.
 func (RestartPolicy) EnumDescriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{2}
+	return file_attest_proto_rawDescGZIP(), []int{3}
+}
+
+// The role of a container in a multi-container workload.
+type ContainerRole int32
+
+const (
+	// The main container, whose exit ends the workload.
+	ContainerRole_CONTAINER_ROLE_MAIN ContainerRole = 0
+	// A container run alongside the main container (e.g., a proxy or a log
+	// shipper), sharing its network and mounts.
+	ContainerRole_CONTAINER_ROLE_SIDECAR ContainerRole = 1
+	// A container run to completion before the main container starts (e.g., to
+	// fetch model weights), sharing its network and mounts.
+	ContainerRole_CONTAINER_ROLE_INIT ContainerRole = 2
+)
+
+// Enum value maps for ContainerRole.
+var (
+	ContainerRole_name = map[int32]string{
+		0: "CONTAINER_ROLE_MAIN",
+		1: "CONTAINER_ROLE_SIDECAR",
+		2: "CONTAINER_ROLE_INIT",
+	}
+	ContainerRole_value = map[string]int32{
+		"CONTAINER_ROLE_MAIN":    0,
+		"CONTAINER_ROLE_SIDECAR": 1,
+		"CONTAINER_ROLE_INIT":    2,
+	}
+)
+
+func (x ContainerRole) Enum() *ContainerRole {
+	p := new(ContainerRole)
+	*p = x
+	return p
+}
+
+func (x ContainerRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ContainerRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[4].Descriptor()
+}
+
+func (ContainerRole) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[4]
+}
+
+func (x ContainerRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ContainerRole.Descriptor instead.
+func (ContainerRole) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{4}
+}
+
+// The location the container logs are redirected to.
+type LogRedirectLocation int32
+
+const (
+	// The launcher did not measure the log redirect location.
+	LogRedirectLocation_LOG_REDIRECT_UNSPECIFIED   LogRedirectLocation = 0
+	LogRedirectLocation_LOG_REDIRECT_NOWHERE       LogRedirectLocation = 1
+	LogRedirectLocation_LOG_REDIRECT_CLOUD_LOGGING LogRedirectLocation = 2
+	LogRedirectLocation_LOG_REDIRECT_SERIAL        LogRedirectLocation = 3
+	// Both Cloud Logging and the serial console.
+	LogRedirectLocation_LOG_REDIRECT_EVERYWHERE LogRedirectLocation = 4
+)
+
+// Enum value maps for LogRedirectLocation.
+var (
+	LogRedirectLocation_name = map[int32]string{
+		0: "LOG_REDIRECT_UNSPECIFIED",
+		1: "LOG_REDIRECT_NOWHERE",
+		2: "LOG_REDIRECT_CLOUD_LOGGING",
+		3: "LOG_REDIRECT_SERIAL",
+		4: "LOG_REDIRECT_EVERYWHERE",
+	}
+	LogRedirectLocation_value = map[string]int32{
+		"LOG_REDIRECT_UNSPECIFIED":   0,
+		"LOG_REDIRECT_NOWHERE":       1,
+		"LOG_REDIRECT_CLOUD_LOGGING": 2,
+		"LOG_REDIRECT_SERIAL":        3,
+		"LOG_REDIRECT_EVERYWHERE":    4,
+	}
+)
+
+func (x LogRedirectLocation) Enum() *LogRedirectLocation {
+	p := new(LogRedirectLocation)
+	*p = x
+	return p
+}
+
+func (x LogRedirectLocation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogRedirectLocation) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[5].Descriptor()
+}
+
+func (LogRedirectLocation) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[5]
+}
+
+func (x LogRedirectLocation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogRedirectLocation.Descriptor instead.
+func (LogRedirectLocation) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{5}
+}
+
+// The type of the launcher image.
+type LauncherImageType int32
+
+const (
+	// The launcher did not measure the image type.
+	LauncherImageType_LAUNCHER_IMAGE_UNSPECIFIED LauncherImageType = 0
+	// A hardened image, which does not allow access to the VM or the workload.
+	LauncherImageType_LAUNCHER_IMAGE_HARDENED LauncherImageType = 1
+	// A debug image, which allows SSH access to the VM.
+	LauncherImageType_LAUNCHER_IMAGE_DEBUG LauncherImageType = 2
+)
+
+// Enum value maps for LauncherImageType.
+var (
+	LauncherImageType_name = map[int32]string{
+		0: "LAUNCHER_IMAGE_UNSPECIFIED",
+		1: "LAUNCHER_IMAGE_HARDENED",
+		2: "LAUNCHER_IMAGE_DEBUG",
+	}
+	LauncherImageType_value = map[string]int32{
+		"LAUNCHER_IMAGE_UNSPECIFIED": 0,
+		"LAUNCHER_IMAGE_HARDENED":    1,
+		"LAUNCHER_IMAGE_DEBUG":       2,
+	}
+)
+
+func (x LauncherImageType) Enum() *LauncherImageType {
+	p := new(LauncherImageType)
+	*p = x
+	return p
+}
+
+func (x LauncherImageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LauncherImageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_attest_proto_enumTypes[6].Descriptor()
+}
+
+func (LauncherImageType) Type() protoreflect.EnumType {
+	return &file_attest_proto_enumTypes[6]
+}
+
+func (x LauncherImageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LauncherImageType.Descriptor instead.
+func (LauncherImageType) EnumDescriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{6}
 }
 
 // Confidential Computing mode for GPU device. Reference for these CC mode values: https://developer.nvidia.com/blog/confidential-computing-on-h100-gpus-for-secure-and-trustworthy-ai/#hardware_security_for_nvidia_h100_gpus
@@ -235,11 +458,11 @@ func (x GPUDeviceCCMode) String() string {
 }
 
 func (GPUDeviceCCMode) Descriptor() protoreflect.EnumDescriptor {
-	return file_attest_proto_enumTypes[3].Descriptor()
+	return file_attest_proto_enumTypes[7].Descriptor()
 }
 
 func (GPUDeviceCCMode) Type() protoreflect.EnumType {
-	return &file_attest_proto_enumTypes[3]
+	return &file_attest_proto_enumTypes[7]
 }
 
 func (x GPUDeviceCCMode) Number() protoreflect.EnumNumber {
@@ -248,7 +471,7 @@ func (x GPUDeviceCCMode) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use GPUDeviceCCMode.Descriptor instead.
 func (GPUDeviceCCMode) EnumDescriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{3}
+	return file_attest_proto_rawDescGZIP(), []int{7}
 }
 
 // Information uniquely identifying a GCE instance. Can be used to create an
@@ -365,6 +588,14 @@ type Attestation struct {
 	//	*Attestation_SevSnpAttestation
 	//	*Attestation_TdxAttestation
 	TeeAttestation isAttestation_TeeAttestation `protobuf_oneof:"tee_attestation"`
+	// The systemd userspace TPM measurement log (usually found at
+	// /run/log/systemd/tpm2-measure.log), encoded in the CEL-JSON format.
+	// Optional.
+	SystemdEventLog []byte `protobuf:"bytes,10,opt,name=systemd_event_log,json=systemdEventLog,proto3" json:"systemd_event_log,omitempty"`
+	// The Linux IMA runtime measurement list, in either the ascii or the binary
+	// format (usually found at
+	// /sys/kernel/security/ima/binary_runtime_measurements). Optional.
+	ImaEventLog []byte `protobuf:"bytes,11,opt,name=ima_event_log,json=imaEventLog,proto3" json:"ima_event_log,omitempty"`
 }
 
 func (x *Attestation) Reset() {
@@ -469,6 +700,20 @@ func (x *Attestation) GetTdxAttestation() *tdx.QuoteV4 {
 	return nil
 }
 
+func (x *Attestation) GetSystemdEventLog() []byte {
+	if x != nil {
+		return x.SystemdEventLog
+	}
+	return nil
+}
+
+func (x *Attestation) GetImaEventLog() []byte {
+	if x != nil {
+		return x.ImaEventLog
+	}
+	return nil
+}
+
 type isAttestation_TeeAttestation interface {
 	isAttestation_TeeAttestation()
 }
@@ -703,8 +948,9 @@ func (x *GrubState) GetCommands() []string {
 }
 
 // The state of the Linux kernel.
-// At the moment, parsing LinuxKernelState relies on parsing the GrubState.
-// To do so, use ParseOpts{Loader: GRUB} when calling ParseMachineState.
+// Parsing LinuxKernelState relies on parsing either the GrubState or the
+// UkiState. To do so, use ParseOpts{Loader: GRUB} or
+// ParseOpts{Loader: SystemdBoot} when calling ParseMachineState.
 type LinuxKernelState struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -712,6 +958,12 @@ type LinuxKernelState struct {
 
 	// The kernel command line.
 	CommandLine string `protobuf:"bytes,1,opt,name=command_line,json=commandLine,proto3" json:"command_line,omitempty"`
+	// The PCR12 events of systemd-stub labelled as the cpio archive of
+	// credentials or system extensions, in measurement order. The event log does
+	// not hold the archives, so their digests are not verified: any of them may
+	// as well override the kernel command line, without command_line reflecting
+	// it. Only set with ParseOpts{Loader: SystemdBoot}.
+	UnverifiedUkiEvents []*UnverifiedUkiEvent `protobuf:"bytes,2,rep,name=unverified_uki_events,json=unverifiedUkiEvents,proto3" json:"unverified_uki_events,omitempty"`
 }
 
 func (x *LinuxKernelState) Reset() {
@@ -753,29 +1005,27 @@ func (x *LinuxKernelState) GetCommandLine() string {
 	return ""
 }
 
-// A parsed event from the TCG event log
-type Event struct {
+func (x *LinuxKernelState) GetUnverifiedUkiEvents() []*UnverifiedUkiEvent {
+	if x != nil {
+		return x.UnverifiedUkiEvents
+	}
+	return nil
+}
+
+// A systemd-stub event whose digest cannot be verified against its data.
+type UnverifiedUkiEvent struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The Platform Control Register (PCR) this event was extended into.
-	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
-	// The type of this event. Note that this value is not verified, so it should
-	// only be used as a hint during event parsing.
-	UntrustedType uint32 `protobuf:"varint,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
-	// The raw data associated to this event. The meaning of this data is
-	// specific to the type of the event.
-	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	// The event digest actually extended into the TPM. This is often the hash of
-	// the data field, but in some cases it may have a type-specific calculation.
-	Digest []byte `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
-	// This is true if hash(data) == digest.
-	DigestVerified bool `protobuf:"varint,5,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+	// The description of the event (e.g., "Credentials initrd"), not verified.
+	UntrustedDescription string `protobuf:"bytes,1,opt,name=untrusted_description,json=untrustedDescription,proto3" json:"untrusted_description,omitempty"`
+	// The digest of the event, not verified.
+	Digest []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *UnverifiedUkiEvent) Reset() {
+	*x = UnverifiedUkiEvent{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -783,13 +1033,13 @@ func (x *Event) Reset() {
 	}
 }
 
-func (x *Event) String() string {
+func (x *UnverifiedUkiEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*UnverifiedUkiEvent) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
+func (x *UnverifiedUkiEvent) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -801,64 +1051,39 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
+// Deprecated: Use UnverifiedUkiEvent.ProtoReflect.Descriptor instead.
+func (*UnverifiedUkiEvent) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Event) GetPcrIndex() uint32 {
-	if x != nil {
-		return x.PcrIndex
-	}
-	return 0
-}
-
-func (x *Event) GetUntrustedType() uint32 {
-	if x != nil {
-		return x.UntrustedType
-	}
-	return 0
-}
-
-func (x *Event) GetData() []byte {
+func (x *UnverifiedUkiEvent) GetUntrustedDescription() string {
 	if x != nil {
-		return x.Data
+		return x.UntrustedDescription
 	}
-	return nil
+	return ""
 }
 
-func (x *Event) GetDigest() []byte {
+func (x *UnverifiedUkiEvent) GetDigest() []byte {
 	if x != nil {
 		return x.Digest
 	}
 	return nil
 }
 
-func (x *Event) GetDigestVerified() bool {
-	if x != nil {
-		return x.DigestVerified
-	}
-	return false
-}
-
-type Certificate struct {
+// A PE section of a Unified Kernel Image, as measured by systemd-stub.
+type UkiSection struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The representation of the certificate. If the certificate matches a
-	// well-known certificate above, representation should contain the value in
-	// the enum. Otherwise, it will contain the raw DER.
-	//
-	// Types that are assignable to Representation:
-	//
-	//	*Certificate_Der
-	//	*Certificate_WellKnown
-	Representation isCertificate_Representation `protobuf_oneof:"representation"`
+	// The section name, including the leading dot (e.g., ".linux").
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The digest of the section contents.
+	Digest []byte `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
 }
 
-func (x *Certificate) Reset() {
-	*x = Certificate{}
+func (x *UkiSection) Reset() {
+	*x = UkiSection{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -866,13 +1091,13 @@ func (x *Certificate) Reset() {
 	}
 }
 
-func (x *Certificate) String() string {
+func (x *UkiSection) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Certificate) ProtoMessage() {}
+func (*UkiSection) ProtoMessage() {}
 
-func (x *Certificate) ProtoReflect() protoreflect.Message {
+func (x *UkiSection) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -884,62 +1109,48 @@ func (x *Certificate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
-func (*Certificate) Descriptor() ([]byte, []int) {
+// Deprecated: Use UkiSection.ProtoReflect.Descriptor instead.
+func (*UkiSection) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{7}
 }
 
-func (m *Certificate) GetRepresentation() isCertificate_Representation {
-	if m != nil {
-		return m.Representation
+func (x *UkiSection) GetName() string {
+	if x != nil {
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *Certificate) GetDer() []byte {
-	if x, ok := x.GetRepresentation().(*Certificate_Der); ok {
-		return x.Der
+func (x *UkiSection) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
 	}
 	return nil
 }
 
-func (x *Certificate) GetWellKnown() WellKnownCertificate {
-	if x, ok := x.GetRepresentation().(*Certificate_WellKnown); ok {
-		return x.WellKnown
-	}
-	return WellKnownCertificate_UNKNOWN
-}
-
-type isCertificate_Representation interface {
-	isCertificate_Representation()
-}
-
-type Certificate_Der struct {
-	// DER representation of the certificate.
-	Der []byte `protobuf:"bytes,1,opt,name=der,proto3,oneof"`
-}
-
-type Certificate_WellKnown struct {
-	WellKnown WellKnownCertificate `protobuf:"varint,2,opt,name=well_known,json=wellKnown,proto3,enum=attest.WellKnownCertificate,oneof"`
-}
-
-func (*Certificate_Der) isCertificate_Representation() {}
-
-func (*Certificate_WellKnown) isCertificate_Representation() {}
-
-// A Secure Boot database containing lists of hashes and certificates,
-// as defined by section 32.4.1 Signature Database in the UEFI spec.
-type Database struct {
+// The state of a Unified Kernel Image (UKI) booted via systemd-stub.
+// See https://uapi-group.org/specifications/specs/unified_kernel_image/.
+type UkiState struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Certs  []*Certificate `protobuf:"bytes,1,rep,name=certs,proto3" json:"certs,omitempty"`
-	Hashes [][]byte       `protobuf:"bytes,2,rep,name=hashes,proto3" json:"hashes,omitempty"`
-}
-
-func (x *Database) Reset() {
-	*x = Database{}
+	// The digest of the UKI PE image, as measured by the firmware into PCR4.
+	ImageDigest []byte `protobuf:"bytes,1,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	// All PE sections measured into PCR11, in measurement order.
+	Sections []*UkiSection `protobuf:"bytes,2,rep,name=sections,proto3" json:"sections,omitempty"`
+	// The digest of the .linux section (the kernel image).
+	LinuxDigest []byte `protobuf:"bytes,3,opt,name=linux_digest,json=linuxDigest,proto3" json:"linux_digest,omitempty"`
+	// The digest of the .initrd section.
+	InitrdDigest []byte `protobuf:"bytes,4,opt,name=initrd_digest,json=initrdDigest,proto3" json:"initrd_digest,omitempty"`
+	// The digest of the .cmdline section (the embedded kernel command line).
+	CmdlineDigest []byte `protobuf:"bytes,5,opt,name=cmdline_digest,json=cmdlineDigest,proto3" json:"cmdline_digest,omitempty"`
+	// The digest of the .osrel section (the embedded os-release file).
+	OsrelDigest []byte `protobuf:"bytes,6,opt,name=osrel_digest,json=osrelDigest,proto3" json:"osrel_digest,omitempty"`
+}
+
+func (x *UkiState) Reset() {
+	*x = UkiState{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_attest_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -947,13 +1158,13 @@ func (x *Database) Reset() {
 	}
 }
 
-func (x *Database) String() string {
+func (x *UkiState) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Database) ProtoMessage() {}
+func (*UkiState) ProtoMessage() {}
 
-func (x *Database) ProtoReflect() protoreflect.Message {
+func (x *UkiState) ProtoReflect() protoreflect.Message {
 	mi := &file_attest_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -965,32 +1176,901 @@ func (x *Database) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Database.ProtoReflect.Descriptor instead.
-func (*Database) Descriptor() ([]byte, []int) {
+// Deprecated: Use UkiState.ProtoReflect.Descriptor instead.
+func (*UkiState) Descriptor() ([]byte, []int) {
 	return file_attest_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *Database) GetCerts() []*Certificate {
+func (x *UkiState) GetImageDigest() []byte {
 	if x != nil {
-		return x.Certs
+		return x.ImageDigest
 	}
 	return nil
 }
 
-func (x *Database) GetHashes() [][]byte {
+func (x *UkiState) GetSections() []*UkiSection {
 	if x != nil {
-		return x.Hashes
+		return x.Sections
 	}
 	return nil
 }
 
-// The Secure Boot state for this instance.
-type SecureBootState struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
-	// Whether Secure Boot is enabled.
+func (x *UkiState) GetLinuxDigest() []byte {
+	if x != nil {
+		return x.LinuxDigest
+	}
+	return nil
+}
+
+func (x *UkiState) GetInitrdDigest() []byte {
+	if x != nil {
+		return x.InitrdDigest
+	}
+	return nil
+}
+
+func (x *UkiState) GetCmdlineDigest() []byte {
+	if x != nil {
+		return x.CmdlineDigest
+	}
+	return nil
+}
+
+func (x *UkiState) GetOsrelDigest() []byte {
+	if x != nil {
+		return x.OsrelDigest
+	}
+	return nil
+}
+
+// A parsed event from the systemd userspace TPM measurement log.
+type SystemdEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The PCR index the event was extended into.
+	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	// The systemd event type (e.g., "phase", "machine-id", "filesystem").
+	// Not verified by the replay.
+	UntrustedType string `protobuf:"bytes,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
+	// The string measured by systemd (e.g., "enter-initrd").
+	Data string `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// The event digest for the hash algorithm used in the replay.
+	Digest []byte `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+	// This is true if hash(data) == digest. Volume key measurements are keyed
+	// with the volume key and can never be verified.
+	DigestVerified bool `protobuf:"varint,5,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+}
+
+func (x *SystemdEvent) Reset() {
+	*x = SystemdEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemdEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemdEvent) ProtoMessage() {}
+
+func (x *SystemdEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemdEvent.ProtoReflect.Descriptor instead.
+func (*SystemdEvent) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SystemdEvent) GetPcrIndex() uint32 {
+	if x != nil {
+		return x.PcrIndex
+	}
+	return 0
+}
+
+func (x *SystemdEvent) GetUntrustedType() string {
+	if x != nil {
+		return x.UntrustedType
+	}
+	return ""
+}
+
+func (x *SystemdEvent) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *SystemdEvent) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *SystemdEvent) GetDigestVerified() bool {
+	if x != nil {
+		return x.DigestVerified
+	}
+	return false
+}
+
+// The state measured by systemd into PCRs 11 and 15 after the kernel started.
+// See https://systemd.io/TPM2_PCR_MEASUREMENTS/.
+type SystemdState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The boot phases measured into PCR11 by systemd-pcrphase, in order
+	// (e.g., "enter-initrd", "leave-initrd", "sysinit", "ready").
+	BootPhases []string `protobuf:"bytes,1,rep,name=boot_phases,json=bootPhases,proto3" json:"boot_phases,omitempty"`
+	// The machine ID measured into PCR15 by systemd-pcrmachine.
+	MachineId string `protobuf:"bytes,2,opt,name=machine_id,json=machineId,proto3" json:"machine_id,omitempty"`
+	// The file systems measured into PCR15 by systemd-pcrfs, in the format
+	// "file-system:<mount point>:<type>:<uuid>:<label>:<partition uuid>:...".
+	FileSystems []string `protobuf:"bytes,3,rep,name=file_systems,json=fileSystems,proto3" json:"file_systems,omitempty"`
+	// All replayed events from the systemd measurement log.
+	Events []*SystemdEvent `protobuf:"bytes,4,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *SystemdState) Reset() {
+	*x = SystemdState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemdState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemdState) ProtoMessage() {}
+
+func (x *SystemdState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemdState.ProtoReflect.Descriptor instead.
+func (*SystemdState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SystemdState) GetBootPhases() []string {
+	if x != nil {
+		return x.BootPhases
+	}
+	return nil
+}
+
+func (x *SystemdState) GetMachineId() string {
+	if x != nil {
+		return x.MachineId
+	}
+	return ""
+}
+
+func (x *SystemdState) GetFileSystems() []string {
+	if x != nil {
+		return x.FileSystems
+	}
+	return nil
+}
+
+func (x *SystemdState) GetEvents() []*SystemdEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// A file measurement from the Linux IMA runtime measurement list.
+// Only the ima-ng and ima-sig templates are supported.
+type ImaEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The PCR index the entry was extended into (usually 10).
+	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	// The IMA template name (e.g., "ima-ng" or "ima-sig").
+	TemplateName string `protobuf:"bytes,2,opt,name=template_name,json=templateName,proto3" json:"template_name,omitempty"`
+	// The name of the hash algorithm used for the file digest
+	// (e.g., "sha256").
+	FileHashAlgorithm string `protobuf:"bytes,3,opt,name=file_hash_algorithm,json=fileHashAlgorithm,proto3" json:"file_hash_algorithm,omitempty"`
+	// The digest of the file contents.
+	FileDigest []byte `protobuf:"bytes,4,opt,name=file_digest,json=fileDigest,proto3" json:"file_digest,omitempty"`
+	// The path of the measured file, or "boot_aggregate".
+	Path string `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	// The file signature (ima-sig only). Not verified.
+	Signature []byte `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Set if the entry records a measurement violation (e.g., a file opened
+	// for write while being measured). Violations invalidate the PCR value.
+	Violation bool `protobuf:"varint,7,opt,name=violation,proto3" json:"violation,omitempty"`
+}
+
+func (x *ImaEntry) Reset() {
+	*x = ImaEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImaEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImaEntry) ProtoMessage() {}
+
+func (x *ImaEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImaEntry.ProtoReflect.Descriptor instead.
+func (*ImaEntry) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ImaEntry) GetPcrIndex() uint32 {
+	if x != nil {
+		return x.PcrIndex
+	}
+	return 0
+}
+
+func (x *ImaEntry) GetTemplateName() string {
+	if x != nil {
+		return x.TemplateName
+	}
+	return ""
+}
+
+func (x *ImaEntry) GetFileHashAlgorithm() string {
+	if x != nil {
+		return x.FileHashAlgorithm
+	}
+	return ""
+}
+
+func (x *ImaEntry) GetFileDigest() []byte {
+	if x != nil {
+		return x.FileDigest
+	}
+	return nil
+}
+
+func (x *ImaEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ImaEntry) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *ImaEntry) GetViolation() bool {
+	if x != nil {
+		return x.Violation
+	}
+	return false
+}
+
+// The state of the Linux userspace as measured by IMA.
+// See https://ima-doc.readthedocs.io/en/latest/ima-concepts.html.
+type ImaState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The IMA entries verified by the replay, in measurement order: the longest
+	// prefix of the log matching the quoted PCRs.
+	Entries []*ImaEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ImaState) Reset() {
+	*x = ImaState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImaState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImaState) ProtoMessage() {}
+
+func (x *ImaState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImaState.ProtoReflect.Descriptor instead.
+func (*ImaState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ImaState) GetEntries() []*ImaEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// A firmware component measured into PCRs 0-2.
+type FirmwareComponent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The PCR index the component was measured into.
+	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	// The untrusted event type (e.g., EV_EFI_PLATFORM_FIRMWARE_BLOB2).
+	UntrustedType uint32 `protobuf:"varint,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
+	// The digest of the component, as measured by the firmware.
+	Digest []byte `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The blob or table description, or the EV_POST_CODE string.
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// The memory location of the blob or image, if logged.
+	Base uint64 `protobuf:"varint,5,opt,name=base,proto3" json:"base,omitempty"`
+	// The length of the blob or image, if logged.
+	Length uint64 `protobuf:"varint,6,opt,name=length,proto3" json:"length,omitempty"`
+	// The device path of a driver or option ROM, in the UEFI text format.
+	DevicePath string `protobuf:"bytes,7,opt,name=device_path,json=devicePath,proto3" json:"device_path,omitempty"`
+	// The vendor GUIDs of the handoff tables (e.g., SMBIOS or ACPI tables).
+	TableGuids []string `protobuf:"bytes,8,rep,name=table_guids,json=tableGuids,proto3" json:"table_guids,omitempty"`
+}
+
+func (x *FirmwareComponent) Reset() {
+	*x = FirmwareComponent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FirmwareComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FirmwareComponent) ProtoMessage() {}
+
+func (x *FirmwareComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FirmwareComponent.ProtoReflect.Descriptor instead.
+func (*FirmwareComponent) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FirmwareComponent) GetPcrIndex() uint32 {
+	if x != nil {
+		return x.PcrIndex
+	}
+	return 0
+}
+
+func (x *FirmwareComponent) GetUntrustedType() uint32 {
+	if x != nil {
+		return x.UntrustedType
+	}
+	return 0
+}
+
+func (x *FirmwareComponent) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *FirmwareComponent) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *FirmwareComponent) GetBase() uint64 {
+	if x != nil {
+		return x.Base
+	}
+	return 0
+}
+
+func (x *FirmwareComponent) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FirmwareComponent) GetDevicePath() string {
+	if x != nil {
+		return x.DevicePath
+	}
+	return ""
+}
+
+func (x *FirmwareComponent) GetTableGuids() []string {
+	if x != nil {
+		return x.TableGuids
+	}
+	return nil
+}
+
+// A UEFI boot option (Boot####) measured into PCR1.
+type BootOption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The option number (e.g., 1 for Boot0001).
+	Number uint32 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	// The EFI_LOAD_OPTION attributes.
+	Attributes uint32 `protobuf:"varint,2,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	// The human-readable description of the option.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// The device paths of the option, in the UEFI text format.
+	DevicePaths []string `protobuf:"bytes,4,rep,name=device_paths,json=devicePaths,proto3" json:"device_paths,omitempty"`
+	// The digest of the event.
+	Digest []byte `protobuf:"bytes,5,opt,name=digest,proto3" json:"digest,omitempty"`
+	// This is true if the digest matches the logged variable. Some firmware
+	// only measures the variable data, which is also accepted.
+	DigestVerified bool `protobuf:"varint,6,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+}
+
+func (x *BootOption) Reset() {
+	*x = BootOption{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BootOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BootOption) ProtoMessage() {}
+
+func (x *BootOption) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BootOption.ProtoReflect.Descriptor instead.
+func (*BootOption) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BootOption) GetNumber() uint32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *BootOption) GetAttributes() uint32 {
+	if x != nil {
+		return x.Attributes
+	}
+	return 0
+}
+
+func (x *BootOption) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *BootOption) GetDevicePaths() []string {
+	if x != nil {
+		return x.DevicePaths
+	}
+	return nil
+}
+
+func (x *BootOption) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *BootOption) GetDigestVerified() bool {
+	if x != nil {
+		return x.DigestVerified
+	}
+	return false
+}
+
+// The inventory of firmware components and boot configuration measured into
+// PCRs 0-2.
+type FirmwareInventory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// All firmware blobs, POST codes, handoff tables and drivers, in
+	// measurement order.
+	Components []*FirmwareComponent `protobuf:"bytes,1,rep,name=components,proto3" json:"components,omitempty"`
+	// The boot order measured into PCR1, as option numbers.
+	BootOrder []uint32 `protobuf:"varint,2,rep,packed,name=boot_order,json=bootOrder,proto3" json:"boot_order,omitempty"`
+	// The boot options measured into PCR1, in measurement order.
+	BootOptions []*BootOption `protobuf:"bytes,3,rep,name=boot_options,json=bootOptions,proto3" json:"boot_options,omitempty"`
+	// Why the events of PCRs 0-2 which could not be parsed were left out of the
+	// inventory, or only partially decoded. Parsing the inventory never fails
+	// the verification of the event log.
+	ParseErrors []string `protobuf:"bytes,4,rep,name=parse_errors,json=parseErrors,proto3" json:"parse_errors,omitempty"`
+}
+
+func (x *FirmwareInventory) Reset() {
+	*x = FirmwareInventory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FirmwareInventory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FirmwareInventory) ProtoMessage() {}
+
+func (x *FirmwareInventory) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FirmwareInventory.ProtoReflect.Descriptor instead.
+func (*FirmwareInventory) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FirmwareInventory) GetComponents() []*FirmwareComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *FirmwareInventory) GetBootOrder() []uint32 {
+	if x != nil {
+		return x.BootOrder
+	}
+	return nil
+}
+
+func (x *FirmwareInventory) GetBootOptions() []*BootOption {
+	if x != nil {
+		return x.BootOptions
+	}
+	return nil
+}
+
+func (x *FirmwareInventory) GetParseErrors() []string {
+	if x != nil {
+		return x.ParseErrors
+	}
+	return nil
+}
+
+// A parsed event from the TCG event log
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The Platform Control Register (PCR) this event was extended into.
+	PcrIndex uint32 `protobuf:"varint,1,opt,name=pcr_index,json=pcrIndex,proto3" json:"pcr_index,omitempty"`
+	// The type of this event. Note that this value is not verified, so it should
+	// only be used as a hint during event parsing.
+	UntrustedType uint32 `protobuf:"varint,2,opt,name=untrusted_type,json=untrustedType,proto3" json:"untrusted_type,omitempty"`
+	// The raw data associated to this event. The meaning of this data is
+	// specific to the type of the event.
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	// The event digest actually extended into the TPM. This is often the hash of
+	// the data field, but in some cases it may have a type-specific calculation.
+	Digest []byte `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+	// This is true if hash(data) == digest.
+	DigestVerified bool `protobuf:"varint,5,opt,name=digest_verified,json=digestVerified,proto3" json:"digest_verified,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Event) GetPcrIndex() uint32 {
+	if x != nil {
+		return x.PcrIndex
+	}
+	return 0
+}
+
+func (x *Event) GetUntrustedType() uint32 {
+	if x != nil {
+		return x.UntrustedType
+	}
+	return 0
+}
+
+func (x *Event) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *Event) GetDigestVerified() bool {
+	if x != nil {
+		return x.DigestVerified
+	}
+	return false
+}
+
+type Certificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The representation of the certificate. If the certificate matches a
+	// well-known certificate above, representation should contain the value in
+	// the enum. Otherwise, it will contain the raw DER.
+	//
+	// Types that are assignable to Representation:
+	//
+	//	*Certificate_Der
+	//	*Certificate_WellKnown
+	Representation isCertificate_Representation `protobuf_oneof:"representation"`
+}
+
+func (x *Certificate) Reset() {
+	*x = Certificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Certificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Certificate) ProtoMessage() {}
+
+func (x *Certificate) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Certificate.ProtoReflect.Descriptor instead.
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{17}
+}
+
+func (m *Certificate) GetRepresentation() isCertificate_Representation {
+	if m != nil {
+		return m.Representation
+	}
+	return nil
+}
+
+func (x *Certificate) GetDer() []byte {
+	if x, ok := x.GetRepresentation().(*Certificate_Der); ok {
+		return x.Der
+	}
+	return nil
+}
+
+func (x *Certificate) GetWellKnown() WellKnownCertificate {
+	if x, ok := x.GetRepresentation().(*Certificate_WellKnown); ok {
+		return x.WellKnown
+	}
+	return WellKnownCertificate_UNKNOWN
+}
+
+type isCertificate_Representation interface {
+	isCertificate_Representation()
+}
+
+type Certificate_Der struct {
+	// DER representation of the certificate.
+	Der []byte `protobuf:"bytes,1,opt,name=der,proto3,oneof"`
+}
+
+type Certificate_WellKnown struct {
+	WellKnown WellKnownCertificate `protobuf:"varint,2,opt,name=well_known,json=wellKnown,proto3,enum=attest.WellKnownCertificate,oneof"`
+}
+
+func (*Certificate_Der) isCertificate_Representation() {}
+
+func (*Certificate_WellKnown) isCertificate_Representation() {}
+
+// A Secure Boot database containing lists of hashes and certificates,
+// as defined by section 32.4.1 Signature Database in the UEFI spec.
+type Database struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Certs  []*Certificate `protobuf:"bytes,1,rep,name=certs,proto3" json:"certs,omitempty"`
+	Hashes [][]byte       `protobuf:"bytes,2,rep,name=hashes,proto3" json:"hashes,omitempty"`
+}
+
+func (x *Database) Reset() {
+	*x = Database{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Database) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Database) ProtoMessage() {}
+
+func (x *Database) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Database.ProtoReflect.Descriptor instead.
+func (*Database) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Database) GetCerts() []*Certificate {
+	if x != nil {
+		return x.Certs
+	}
+	return nil
+}
+
+func (x *Database) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// The Secure Boot state for this instance.
+type SecureBootState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Whether Secure Boot is enabled.
 	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	// The Secure Boot signature (allowed) database.
 	Db *Database `protobuf:"bytes,2,opt,name=db,proto3" json:"db,omitempty"`
@@ -1003,12 +2083,27 @@ type SecureBootState struct {
 	Pk *Database `protobuf:"bytes,5,opt,name=pk,proto3" json:"pk,omitempty"`
 	// The Secure Boot Key Exchange Keys, used to sign db and dbx updates.
 	Kek *Database `protobuf:"bytes,6,opt,name=kek,proto3" json:"kek,omitempty"`
+	// The digest of shim's MokList variable (allowed Machine Owner Keys), as
+	// measured into PCR14. Unset if shim was not used.
+	MokListDigest []byte `protobuf:"bytes,7,opt,name=mok_list_digest,json=mokListDigest,proto3" json:"mok_list_digest,omitempty"`
+	// The digest of shim's MokListX variable (forbidden Machine Owner Keys), as
+	// measured into PCR14.
+	MokListXDigest []byte `protobuf:"bytes,8,opt,name=mok_list_x_digest,json=mokListXDigest,proto3" json:"mok_list_x_digest,omitempty"`
+	// The raw contents of shim's MokListTrusted variable, as measured into
+	// PCR7. It controls whether the kernel trusts the Machine Owner Keys.
+	MokListTrusted []byte `protobuf:"bytes,9,opt,name=mok_list_trusted,json=mokListTrusted,proto3" json:"mok_list_trusted,omitempty"`
+	// The contents of shim's SbatLevel variable, as measured into PCR7
+	// (e.g., "sbat,1,2022052400\ngrub,2\n").
+	SbatLevel string `protobuf:"bytes,10,opt,name=sbat_level,json=sbatLevel,proto3" json:"sbat_level,omitempty"`
+	// The source of the last authority shim used to verify an image. This is
+	// usually the kernel, verified through shim's protocol by the boot loader.
+	KernelAuthoritySource ShimAuthoritySource `protobuf:"varint,11,opt,name=kernel_authority_source,json=kernelAuthoritySource,proto3,enum=attest.ShimAuthoritySource" json:"kernel_authority_source,omitempty"`
 }
 
 func (x *SecureBootState) Reset() {
 	*x = SecureBootState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[9]
+		mi := &file_attest_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1021,7 +2116,7 @@ func (x *SecureBootState) String() string {
 func (*SecureBootState) ProtoMessage() {}
 
 func (x *SecureBootState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[9]
+	mi := &file_attest_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1034,89 +2129,302 @@ func (x *SecureBootState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecureBootState.ProtoReflect.Descriptor instead.
 func (*SecureBootState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{9}
+	return file_attest_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SecureBootState) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SecureBootState) GetDb() *Database {
+	if x != nil {
+		return x.Db
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetDbx() *Database {
+	if x != nil {
+		return x.Dbx
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetAuthority() *Database {
+	if x != nil {
+		return x.Authority
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetPk() *Database {
+	if x != nil {
+		return x.Pk
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetKek() *Database {
+	if x != nil {
+		return x.Kek
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetMokListDigest() []byte {
+	if x != nil {
+		return x.MokListDigest
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetMokListXDigest() []byte {
+	if x != nil {
+		return x.MokListXDigest
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetMokListTrusted() []byte {
+	if x != nil {
+		return x.MokListTrusted
+	}
+	return nil
+}
+
+func (x *SecureBootState) GetSbatLevel() string {
+	if x != nil {
+		return x.SbatLevel
+	}
+	return ""
+}
+
+func (x *SecureBootState) GetKernelAuthoritySource() ShimAuthoritySource {
+	if x != nil {
+		return x.KernelAuthoritySource
+	}
+	return ShimAuthoritySource_AUTHORITY_SOURCE_UNKNOWN
+}
+
+type ContainerState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ImageReference string `protobuf:"bytes,1,opt,name=image_reference,json=imageReference,proto3" json:"image_reference,omitempty"`
+	// Digest of the registry's image manifest, which contains a list of the
+	// layers comprising the image.
+	ImageDigest   string        `protobuf:"bytes,2,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
+	RestartPolicy RestartPolicy `protobuf:"varint,3,opt,name=restart_policy,json=restartPolicy,proto3,enum=attest.RestartPolicy" json:"restart_policy,omitempty"`
+	// Digest of the local image configuration object, containing config items
+	// such as local layer digests.
+	ImageId string            `protobuf:"bytes,4,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Args    []string          `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`
+	EnvVars map[string]string `protobuf:"bytes,6,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Record operator input Env Vars and Args, should be subsets of the above
+	// Env Vars and Args.
+	OverriddenArgs    []string          `protobuf:"bytes,7,rep,name=overridden_args,json=overriddenArgs,proto3" json:"overridden_args,omitempty"`
+	OverriddenEnvVars map[string]string `protobuf:"bytes,8,rep,name=overridden_env_vars,json=overriddenEnvVars,proto3" json:"overridden_env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The Linux capabilities added to the container (e.g., CAP_SYS_ADMIN).
+	AddedCapabilities []string `protobuf:"bytes,9,rep,name=added_capabilities,json=addedCapabilities,proto3" json:"added_capabilities,omitempty"`
+	// Whether the container runs in its own cgroup namespace, with a writable
+	// cgroup filesystem.
+	CgroupNamespace bool `protobuf:"varint,10,opt,name=cgroup_namespace,json=cgroupNamespace,proto3" json:"cgroup_namespace,omitempty"`
+	// The mounts added to the container by the operator, in measurement order.
+	Mounts []*ContainerMount `protobuf:"bytes,11,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	// The service accounts the launcher impersonates to fetch ID tokens for the
+	// attestation service.
+	ImpersonatedServiceAccounts []string `protobuf:"bytes,12,rep,name=impersonated_service_accounts,json=impersonatedServiceAccounts,proto3" json:"impersonated_service_accounts,omitempty"`
+	// Where the container logs are redirected to.
+	LogRedirect LogRedirectLocation `protobuf:"varint,13,opt,name=log_redirect,json=logRedirect,proto3,enum=attest.LogRedirectLocation" json:"log_redirect,omitempty"`
+	// The name of the container in the workload, empty for the main container.
+	Name string        `protobuf:"bytes,14,opt,name=name,proto3" json:"name,omitempty"`
+	Role ContainerRole `protobuf:"varint,15,opt,name=role,proto3,enum=attest.ContainerRole" json:"role,omitempty"`
+	// Whether an init container or a sidecar can read the attestation token and
+	// reach the TEE server of the launcher. The main container always can.
+	AttestationAccess bool `protobuf:"varint,16,opt,name=attestation_access,json=attestationAccess,proto3" json:"attestation_access,omitempty"`
+}
+
+func (x *ContainerState) Reset() {
+	*x = ContainerState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContainerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContainerState) ProtoMessage() {}
+
+func (x *ContainerState) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContainerState.ProtoReflect.Descriptor instead.
+func (*ContainerState) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ContainerState) GetImageReference() string {
+	if x != nil {
+		return x.ImageReference
+	}
+	return ""
+}
+
+func (x *ContainerState) GetImageDigest() string {
+	if x != nil {
+		return x.ImageDigest
+	}
+	return ""
+}
+
+func (x *ContainerState) GetRestartPolicy() RestartPolicy {
+	if x != nil {
+		return x.RestartPolicy
+	}
+	return RestartPolicy_Always
+}
+
+func (x *ContainerState) GetImageId() string {
+	if x != nil {
+		return x.ImageId
+	}
+	return ""
+}
+
+func (x *ContainerState) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ContainerState) GetEnvVars() map[string]string {
+	if x != nil {
+		return x.EnvVars
+	}
+	return nil
+}
+
+func (x *ContainerState) GetOverriddenArgs() []string {
+	if x != nil {
+		return x.OverriddenArgs
+	}
+	return nil
+}
+
+func (x *ContainerState) GetOverriddenEnvVars() map[string]string {
+	if x != nil {
+		return x.OverriddenEnvVars
+	}
+	return nil
+}
+
+func (x *ContainerState) GetAddedCapabilities() []string {
+	if x != nil {
+		return x.AddedCapabilities
+	}
+	return nil
 }
 
-func (x *SecureBootState) GetEnabled() bool {
+func (x *ContainerState) GetCgroupNamespace() bool {
 	if x != nil {
-		return x.Enabled
+		return x.CgroupNamespace
 	}
 	return false
 }
 
-func (x *SecureBootState) GetDb() *Database {
+func (x *ContainerState) GetMounts() []*ContainerMount {
 	if x != nil {
-		return x.Db
+		return x.Mounts
 	}
 	return nil
 }
 
-func (x *SecureBootState) GetDbx() *Database {
+func (x *ContainerState) GetImpersonatedServiceAccounts() []string {
 	if x != nil {
-		return x.Dbx
+		return x.ImpersonatedServiceAccounts
 	}
 	return nil
 }
 
-func (x *SecureBootState) GetAuthority() *Database {
+func (x *ContainerState) GetLogRedirect() LogRedirectLocation {
 	if x != nil {
-		return x.Authority
+		return x.LogRedirect
 	}
-	return nil
+	return LogRedirectLocation_LOG_REDIRECT_UNSPECIFIED
 }
 
-func (x *SecureBootState) GetPk() *Database {
+func (x *ContainerState) GetName() string {
 	if x != nil {
-		return x.Pk
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-func (x *SecureBootState) GetKek() *Database {
+func (x *ContainerState) GetRole() ContainerRole {
 	if x != nil {
-		return x.Kek
+		return x.Role
 	}
-	return nil
+	return ContainerRole_CONTAINER_ROLE_MAIN
 }
 
-type ContainerState struct {
+func (x *ContainerState) GetAttestationAccess() bool {
+	if x != nil {
+		return x.AttestationAccess
+	}
+	return false
+}
+
+// A mount added to a container.
+type ContainerMount struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ImageReference string `protobuf:"bytes,1,opt,name=image_reference,json=imageReference,proto3" json:"image_reference,omitempty"`
-	// Digest of the registry's image manifest, which contains a list of the
-	// layers comprising the image.
-	ImageDigest   string        `protobuf:"bytes,2,opt,name=image_digest,json=imageDigest,proto3" json:"image_digest,omitempty"`
-	RestartPolicy RestartPolicy `protobuf:"varint,3,opt,name=restart_policy,json=restartPolicy,proto3,enum=attest.RestartPolicy" json:"restart_policy,omitempty"`
-	// Digest of the local image configuration object, containing config items
-	// such as local layer digests.
-	ImageId string            `protobuf:"bytes,4,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
-	Args    []string          `protobuf:"bytes,5,rep,name=args,proto3" json:"args,omitempty"`
-	EnvVars map[string]string `protobuf:"bytes,6,rep,name=env_vars,json=envVars,proto3" json:"env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	// Record operator input Env Vars and Args, should be subsets of the above
-	// Env Vars and Args.
-	OverriddenArgs    []string          `protobuf:"bytes,7,rep,name=overridden_args,json=overriddenArgs,proto3" json:"overridden_args,omitempty"`
-	OverriddenEnvVars map[string]string `protobuf:"bytes,8,rep,name=overridden_env_vars,json=overriddenEnvVars,proto3" json:"overridden_env_vars,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The mount type (e.g., tmpfs).
+	MountType string `protobuf:"bytes,1,opt,name=mount_type,json=mountType,proto3" json:"mount_type,omitempty"`
+	Source    string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// The absolute mount point in the container.
+	Destination string `protobuf:"bytes,3,opt,name=destination,proto3" json:"destination,omitempty"`
+	// The other mount options (e.g., "nosuid" or "size=1024" for a tmpfs
+	// mount), in the order they were measured.
+	Options []string `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty"`
 }
 
-func (x *ContainerState) Reset() {
-	*x = ContainerState{}
+func (x *ContainerMount) Reset() {
+	*x = ContainerMount{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[10]
+		mi := &file_attest_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ContainerState) String() string {
+func (x *ContainerMount) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContainerState) ProtoMessage() {}
+func (*ContainerMount) ProtoMessage() {}
 
-func (x *ContainerState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[10]
+func (x *ContainerMount) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1127,63 +2435,35 @@ func (x *ContainerState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContainerState.ProtoReflect.Descriptor instead.
-func (*ContainerState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ContainerMount.ProtoReflect.Descriptor instead.
+func (*ContainerMount) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *ContainerState) GetImageReference() string {
+func (x *ContainerMount) GetMountType() string {
 	if x != nil {
-		return x.ImageReference
+		return x.MountType
 	}
 	return ""
 }
 
-func (x *ContainerState) GetImageDigest() string {
+func (x *ContainerMount) GetSource() string {
 	if x != nil {
-		return x.ImageDigest
+		return x.Source
 	}
 	return ""
 }
 
-func (x *ContainerState) GetRestartPolicy() RestartPolicy {
-	if x != nil {
-		return x.RestartPolicy
-	}
-	return RestartPolicy_Always
-}
-
-func (x *ContainerState) GetImageId() string {
+func (x *ContainerMount) GetDestination() string {
 	if x != nil {
-		return x.ImageId
+		return x.Destination
 	}
 	return ""
 }
 
-func (x *ContainerState) GetArgs() []string {
-	if x != nil {
-		return x.Args
-	}
-	return nil
-}
-
-func (x *ContainerState) GetEnvVars() map[string]string {
-	if x != nil {
-		return x.EnvVars
-	}
-	return nil
-}
-
-func (x *ContainerState) GetOverriddenArgs() []string {
-	if x != nil {
-		return x.OverriddenArgs
-	}
-	return nil
-}
-
-func (x *ContainerState) GetOverriddenEnvVars() map[string]string {
+func (x *ContainerMount) GetOptions() []string {
 	if x != nil {
-		return x.OverriddenEnvVars
+		return x.Options
 	}
 	return nil
 }
@@ -1201,7 +2481,7 @@ type SemanticVersion struct {
 func (x *SemanticVersion) Reset() {
 	*x = SemanticVersion{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[11]
+		mi := &file_attest_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1214,7 +2494,7 @@ func (x *SemanticVersion) String() string {
 func (*SemanticVersion) ProtoMessage() {}
 
 func (x *SemanticVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[11]
+	mi := &file_attest_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1227,7 +2507,7 @@ func (x *SemanticVersion) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SemanticVersion.ProtoReflect.Descriptor instead.
 func (*SemanticVersion) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{11}
+	return file_attest_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *SemanticVersion) GetMajor() uint32 {
@@ -1263,7 +2543,7 @@ type HealthMonitoringState struct {
 func (x *HealthMonitoringState) Reset() {
 	*x = HealthMonitoringState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[12]
+		mi := &file_attest_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1276,7 +2556,7 @@ func (x *HealthMonitoringState) String() string {
 func (*HealthMonitoringState) ProtoMessage() {}
 
 func (x *HealthMonitoringState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[12]
+	mi := &file_attest_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1289,7 +2569,7 @@ func (x *HealthMonitoringState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthMonitoringState.ProtoReflect.Descriptor instead.
 func (*HealthMonitoringState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{12}
+	return file_attest_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *HealthMonitoringState) GetMemoryEnabled() bool {
@@ -1311,7 +2591,7 @@ type GpuDeviceState struct {
 func (x *GpuDeviceState) Reset() {
 	*x = GpuDeviceState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[13]
+		mi := &file_attest_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1324,7 +2604,7 @@ func (x *GpuDeviceState) String() string {
 func (*GpuDeviceState) ProtoMessage() {}
 
 func (x *GpuDeviceState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[13]
+	mi := &file_attest_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1337,7 +2617,7 @@ func (x *GpuDeviceState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GpuDeviceState.ProtoReflect.Descriptor instead.
 func (*GpuDeviceState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{13}
+	return file_attest_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *GpuDeviceState) GetCcMode() GPUDeviceCCMode {
@@ -1352,17 +2632,31 @@ type AttestedCosState struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Container        *ContainerState        `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
-	CosVersion       *SemanticVersion       `protobuf:"bytes,2,opt,name=cos_version,json=cosVersion,proto3" json:"cos_version,omitempty"`
-	LauncherVersion  *SemanticVersion       `protobuf:"bytes,3,opt,name=launcher_version,json=launcherVersion,proto3" json:"launcher_version,omitempty"`
-	HealthMonitoring *HealthMonitoringState `protobuf:"bytes,4,opt,name=health_monitoring,json=healthMonitoring,proto3" json:"health_monitoring,omitempty"`
-	GpuDeviceState   *GpuDeviceState        `protobuf:"bytes,5,opt,name=gpu_device_state,json=gpuDeviceState,proto3" json:"gpu_device_state,omitempty"`
+	Container         *ContainerState        `protobuf:"bytes,1,opt,name=container,proto3" json:"container,omitempty"`
+	CosVersion        *SemanticVersion       `protobuf:"bytes,2,opt,name=cos_version,json=cosVersion,proto3" json:"cos_version,omitempty"`
+	LauncherVersion   *SemanticVersion       `protobuf:"bytes,3,opt,name=launcher_version,json=launcherVersion,proto3" json:"launcher_version,omitempty"`
+	HealthMonitoring  *HealthMonitoringState `protobuf:"bytes,4,opt,name=health_monitoring,json=healthMonitoring,proto3" json:"health_monitoring,omitempty"`
+	GpuDeviceState    *GpuDeviceState        `protobuf:"bytes,5,opt,name=gpu_device_state,json=gpuDeviceState,proto3" json:"gpu_device_state,omitempty"`
+	LauncherImageType LauncherImageType      `protobuf:"varint,6,opt,name=launcher_image_type,json=launcherImageType,proto3,enum=attest.LauncherImageType" json:"launcher_image_type,omitempty"`
+	// All the containers of the workload in measurement order: the main
+	// container (also in container) first, followed by its init containers in
+	// the order they run, then its sidecars.
+	Containers []*ContainerState `protobuf:"bytes,7,rep,name=containers,proto3" json:"containers,omitempty"`
+	// The signal stopping the workload (e.g., "SIGTERM"), if the launcher
+	// measured a Shutdown event after the LaunchSeparator event: the workload is
+	// in its shutdown grace period.
+	ShutdownSignal string `protobuf:"bytes,8,opt,name=shutdown_signal,json=shutdownSignal,proto3" json:"shutdown_signal,omitempty"`
+	// The DER-encoded PKIX public key with which the local verifier signs the
+	// tokens, if the launch spec selects the local verifier. The launcher
+	// generates the key in the VM: the tokens verifying with it are minted by
+	// the attested launcher.
+	LocalVerifierKey []byte `protobuf:"bytes,9,opt,name=local_verifier_key,json=localVerifierKey,proto3" json:"local_verifier_key,omitempty"`
 }
 
 func (x *AttestedCosState) Reset() {
 	*x = AttestedCosState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[14]
+		mi := &file_attest_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1375,7 +2669,7 @@ func (x *AttestedCosState) String() string {
 func (*AttestedCosState) ProtoMessage() {}
 
 func (x *AttestedCosState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[14]
+	mi := &file_attest_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1388,7 +2682,7 @@ func (x *AttestedCosState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AttestedCosState.ProtoReflect.Descriptor instead.
 func (*AttestedCosState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{14}
+	return file_attest_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *AttestedCosState) GetContainer() *ContainerState {
@@ -1426,6 +2720,34 @@ func (x *AttestedCosState) GetGpuDeviceState() *GpuDeviceState {
 	return nil
 }
 
+func (x *AttestedCosState) GetLauncherImageType() LauncherImageType {
+	if x != nil {
+		return x.LauncherImageType
+	}
+	return LauncherImageType_LAUNCHER_IMAGE_UNSPECIFIED
+}
+
+func (x *AttestedCosState) GetContainers() []*ContainerState {
+	if x != nil {
+		return x.Containers
+	}
+	return nil
+}
+
+func (x *AttestedCosState) GetShutdownSignal() string {
+	if x != nil {
+		return x.ShutdownSignal
+	}
+	return ""
+}
+
+func (x *AttestedCosState) GetLocalVerifierKey() []byte {
+	if x != nil {
+		return x.LocalVerifierKey
+	}
+	return nil
+}
+
 type EfiApp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1438,7 +2760,7 @@ type EfiApp struct {
 func (x *EfiApp) Reset() {
 	*x = EfiApp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[15]
+		mi := &file_attest_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1451,7 +2773,7 @@ func (x *EfiApp) String() string {
 func (*EfiApp) ProtoMessage() {}
 
 func (x *EfiApp) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[15]
+	mi := &file_attest_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1464,7 +2786,7 @@ func (x *EfiApp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EfiApp.ProtoReflect.Descriptor instead.
 func (*EfiApp) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{15}
+	return file_attest_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *EfiApp) GetDigest() []byte {
@@ -1491,7 +2813,7 @@ type EfiState struct {
 func (x *EfiState) Reset() {
 	*x = EfiState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[16]
+		mi := &file_attest_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1504,7 +2826,7 @@ func (x *EfiState) String() string {
 func (*EfiState) ProtoMessage() {}
 
 func (x *EfiState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[16]
+	mi := &file_attest_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1517,7 +2839,7 @@ func (x *EfiState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EfiState.ProtoReflect.Descriptor instead.
 func (*EfiState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{16}
+	return file_attest_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *EfiState) GetApps() []*EfiApp {
@@ -1553,13 +2875,17 @@ type MachineState struct {
 	//
 	//	*MachineState_SevSnpAttestation
 	//	*MachineState_TdxAttestation
-	TeeAttestation isMachineState_TeeAttestation `protobuf_oneof:"tee_attestation"`
+	TeeAttestation    isMachineState_TeeAttestation `protobuf_oneof:"tee_attestation"`
+	Uki               *UkiState                     `protobuf:"bytes,11,opt,name=uki,proto3" json:"uki,omitempty"`
+	Systemd           *SystemdState                 `protobuf:"bytes,12,opt,name=systemd,proto3" json:"systemd,omitempty"`
+	Ima               *ImaState                     `protobuf:"bytes,13,opt,name=ima,proto3" json:"ima,omitempty"`
+	FirmwareInventory *FirmwareInventory            `protobuf:"bytes,14,opt,name=firmware_inventory,json=firmwareInventory,proto3" json:"firmware_inventory,omitempty"`
 }
 
 func (x *MachineState) Reset() {
 	*x = MachineState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[17]
+		mi := &file_attest_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1572,7 +2898,7 @@ func (x *MachineState) String() string {
 func (*MachineState) ProtoMessage() {}
 
 func (x *MachineState) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[17]
+	mi := &file_attest_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1585,7 +2911,7 @@ func (x *MachineState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MachineState.ProtoReflect.Descriptor instead.
 func (*MachineState) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{17}
+	return file_attest_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *MachineState) GetPlatform() *PlatformState {
@@ -1665,6 +2991,34 @@ func (x *MachineState) GetTdxAttestation() *tdx.QuoteV4 {
 	return nil
 }
 
+func (x *MachineState) GetUki() *UkiState {
+	if x != nil {
+		return x.Uki
+	}
+	return nil
+}
+
+func (x *MachineState) GetSystemd() *SystemdState {
+	if x != nil {
+		return x.Systemd
+	}
+	return nil
+}
+
+func (x *MachineState) GetIma() *ImaState {
+	if x != nil {
+		return x.Ima
+	}
+	return nil
+}
+
+func (x *MachineState) GetFirmwareInventory() *FirmwareInventory {
+	if x != nil {
+		return x.FirmwareInventory
+	}
+	return nil
+}
+
 type isMachineState_TeeAttestation interface {
 	isMachineState_TeeAttestation()
 }
@@ -1702,7 +3056,7 @@ type PlatformPolicy struct {
 func (x *PlatformPolicy) Reset() {
 	*x = PlatformPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[18]
+		mi := &file_attest_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1715,7 +3069,7 @@ func (x *PlatformPolicy) String() string {
 func (*PlatformPolicy) ProtoMessage() {}
 
 func (x *PlatformPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[18]
+	mi := &file_attest_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1728,7 +3082,7 @@ func (x *PlatformPolicy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PlatformPolicy.ProtoReflect.Descriptor instead.
 func (*PlatformPolicy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{18}
+	return file_attest_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *PlatformPolicy) GetAllowedScrtmVersionIds() [][]byte {
@@ -1747,47 +3101,165 @@ func (x *PlatformPolicy) GetMinimumGceFirmwareVersion() uint32 {
 
 func (x *PlatformPolicy) GetMinimumTechnology() GCEConfidentialTechnology {
 	if x != nil {
-		return x.MinimumTechnology
+		return x.MinimumTechnology
+	}
+	return GCEConfidentialTechnology_NONE
+}
+
+// A policy about what parts of a RIM to compare against machine state as
+// reflected in a quote or (verified) event log. Reference measurements for
+// a component are expected to be addressable by the machine state's reported
+// digest, or otherwise presented as cached collateral with the attestation
+// itself. The method of delivery is vendor-specific.
+type RIMPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// If true, the signed measurement must be available (by means that can be
+	// vendor-specific), and the target measurement must be among the listed
+	// signed measurements.  If false, then only error if there is a problem
+	// verifying the signed measurements when they are available.
+	RequireSigned bool `protobuf:"varint,1,opt,name=require_signed,json=requireSigned,proto3" json:"require_signed,omitempty"`
+	// x.509 certificates in ASN.1 DER format.
+	RootCerts [][]byte `protobuf:"bytes,2,rep,name=root_certs,json=rootCerts,proto3" json:"root_certs,omitempty"`
+}
+
+func (x *RIMPolicy) Reset() {
+	*x = RIMPolicy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RIMPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RIMPolicy) ProtoMessage() {}
+
+func (x *RIMPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RIMPolicy.ProtoReflect.Descriptor instead.
+func (*RIMPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RIMPolicy) GetRequireSigned() bool {
+	if x != nil {
+		return x.RequireSigned
+	}
+	return false
+}
+
+func (x *RIMPolicy) GetRootCerts() [][]byte {
+	if x != nil {
+		return x.RootCerts
+	}
+	return nil
+}
+
+// Represent minimal decisions about attestation fields until necessary to
+// add for policy reasons.
+type SevSnpPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The policy for checking the signed reference values for the UEFI at launch.
+	Uefi *RIMPolicy `protobuf:"bytes,1,opt,name=uefi,proto3" json:"uefi,omitempty"`
+}
+
+func (x *SevSnpPolicy) Reset() {
+	*x = SevSnpPolicy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_attest_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SevSnpPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SevSnpPolicy) ProtoMessage() {}
+
+func (x *SevSnpPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SevSnpPolicy.ProtoReflect.Descriptor instead.
+func (*SevSnpPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SevSnpPolicy) GetUefi() *RIMPolicy {
+	if x != nil {
+		return x.Uefi
 	}
-	return GCEConfidentialTechnology_NONE
+	return nil
 }
 
-// A policy about what parts of a RIM to compare against machine state as
-// reflected in a quote or (verified) event log. Reference measurements for
-// a component are expected to be addressable by the machine state's reported
-// digest, or otherwise presented as cached collateral with the attestation
-// itself. The method of delivery is vendor-specific.
-type RIMPolicy struct {
+// A policy requiring the measurements of a MachineState to match the
+// reference values of Reference Integrity Manifests (CoRIM or CoSWID
+// documents), instead of hand-written allowlists of digests.
+type ReferenceManifestPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// If true, the signed measurement must be available (by means that can be
-	// vendor-specific), and the target measurement must be among the listed
-	// signed measurements.  If false, then only error if there is a problem
-	// verifying the signed measurements when they are available.
-	RequireSigned bool `protobuf:"varint,1,opt,name=require_signed,json=requireSigned,proto3" json:"require_signed,omitempty"`
-	// x.509 certificates in ASN.1 DER format.
-	RootCerts [][]byte `protobuf:"bytes,2,rep,name=root_certs,json=rootCerts,proto3" json:"root_certs,omitempty"`
-}
-
-func (x *RIMPolicy) Reset() {
-	*x = RIMPolicy{}
+	// The CoRIM or CoSWID documents, signed using COSE_Sign1 with the signing
+	// certificate chain in the x5chain header.
+	Manifests [][]byte `protobuf:"bytes,1,rep,name=manifests,proto3" json:"manifests,omitempty"`
+	// The certificates trusted to sign the manifests, and whether unsigned
+	// manifests are rejected.
+	Signing *RIMPolicy `protobuf:"bytes,2,opt,name=signing,proto3" json:"signing,omitempty"`
+	// If true, the digest of every firmware blob and driver measured into PCRs
+	// 0-2 must match a reference value.
+	RequireFirmware bool `protobuf:"varint,3,opt,name=require_firmware,json=requireFirmware,proto3" json:"require_firmware,omitempty"`
+	// If true, the digest of every EFI application must match a reference
+	// value.
+	RequireEfiApps bool `protobuf:"varint,4,opt,name=require_efi_apps,json=requireEfiApps,proto3" json:"require_efi_apps,omitempty"`
+}
+
+func (x *ReferenceManifestPolicy) Reset() {
+	*x = ReferenceManifestPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[19]
+		mi := &file_attest_proto_msgTypes[32]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RIMPolicy) String() string {
+func (x *ReferenceManifestPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RIMPolicy) ProtoMessage() {}
+func (*ReferenceManifestPolicy) ProtoMessage() {}
 
-func (x *RIMPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[19]
+func (x *ReferenceManifestPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[32]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1798,53 +3270,73 @@ func (x *RIMPolicy) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RIMPolicy.ProtoReflect.Descriptor instead.
-func (*RIMPolicy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ReferenceManifestPolicy.ProtoReflect.Descriptor instead.
+func (*ReferenceManifestPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *RIMPolicy) GetRequireSigned() bool {
+func (x *ReferenceManifestPolicy) GetManifests() [][]byte {
 	if x != nil {
-		return x.RequireSigned
+		return x.Manifests
 	}
-	return false
+	return nil
 }
 
-func (x *RIMPolicy) GetRootCerts() [][]byte {
+func (x *ReferenceManifestPolicy) GetSigning() *RIMPolicy {
 	if x != nil {
-		return x.RootCerts
+		return x.Signing
 	}
 	return nil
 }
 
-// Represent minimal decisions about attestation fields until necessary to
-// add for policy reasons.
-type SevSnpPolicy struct {
+func (x *ReferenceManifestPolicy) GetRequireFirmware() bool {
+	if x != nil {
+		return x.RequireFirmware
+	}
+	return false
+}
+
+func (x *ReferenceManifestPolicy) GetRequireEfiApps() bool {
+	if x != nil {
+		return x.RequireEfiApps
+	}
+	return false
+}
+
+// A policy dictating which files may be measured by Linux IMA.
+type ImaPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The policy for checking the signed reference values for the UEFI at launch.
-	Uefi *RIMPolicy `protobuf:"bytes,1,opt,name=uefi,proto3" json:"uefi,omitempty"`
+	// If non-empty, the digest of every measured file (other than the
+	// boot_aggregate) must appear in this list.
+	AllowedFileDigests [][]byte `protobuf:"bytes,1,rep,name=allowed_file_digests,json=allowedFileDigests,proto3" json:"allowed_file_digests,omitempty"`
+	// The digest of a measured file must not appear in this list.
+	DeniedFileDigests [][]byte `protobuf:"bytes,2,rep,name=denied_file_digests,json=deniedFileDigests,proto3" json:"denied_file_digests,omitempty"`
+	// The path of a measured file must not appear in this list.
+	DeniedPaths []string `protobuf:"bytes,3,rep,name=denied_paths,json=deniedPaths,proto3" json:"denied_paths,omitempty"`
+	// Whether measurement violations are allowed in the IMA state.
+	AllowViolations bool `protobuf:"varint,4,opt,name=allow_violations,json=allowViolations,proto3" json:"allow_violations,omitempty"`
 }
 
-func (x *SevSnpPolicy) Reset() {
-	*x = SevSnpPolicy{}
+func (x *ImaPolicy) Reset() {
+	*x = ImaPolicy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[20]
+		mi := &file_attest_proto_msgTypes[33]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *SevSnpPolicy) String() string {
+func (x *ImaPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SevSnpPolicy) ProtoMessage() {}
+func (*ImaPolicy) ProtoMessage() {}
 
-func (x *SevSnpPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[20]
+func (x *ImaPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_attest_proto_msgTypes[33]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1855,18 +3347,39 @@ func (x *SevSnpPolicy) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SevSnpPolicy.ProtoReflect.Descriptor instead.
-func (*SevSnpPolicy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use ImaPolicy.ProtoReflect.Descriptor instead.
+func (*ImaPolicy) Descriptor() ([]byte, []int) {
+	return file_attest_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *SevSnpPolicy) GetUefi() *RIMPolicy {
+func (x *ImaPolicy) GetAllowedFileDigests() [][]byte {
 	if x != nil {
-		return x.Uefi
+		return x.AllowedFileDigests
+	}
+	return nil
+}
+
+func (x *ImaPolicy) GetDeniedFileDigests() [][]byte {
+	if x != nil {
+		return x.DeniedFileDigests
+	}
+	return nil
+}
+
+func (x *ImaPolicy) GetDeniedPaths() []string {
+	if x != nil {
+		return x.DeniedPaths
 	}
 	return nil
 }
 
+func (x *ImaPolicy) GetAllowViolations() bool {
+	if x != nil {
+		return x.AllowViolations
+	}
+	return false
+}
+
 // A policy dictating which type of MachineStates to allow
 type Policy struct {
 	state         protoimpl.MessageState
@@ -1877,12 +3390,19 @@ type Policy struct {
 	// When the attestation is on SEV-SNP, this is the policy. Unset means no
 	// constraints.
 	SevSnp *SevSnpPolicy `protobuf:"bytes,3,opt,name=sev_snp,json=sevSnp,proto3" json:"sev_snp,omitempty"`
+	// When the MachineState contains an ImaState, this is the policy. Unset
+	// means no constraints.
+	Ima *ImaPolicy `protobuf:"bytes,4,opt,name=ima,proto3" json:"ima,omitempty"`
+	// Reference values from signed manifests. A PCR for which a manifest
+	// contains reference values must always match one of them. Unset means no
+	// constraints.
+	ReferenceManifests *ReferenceManifestPolicy `protobuf:"bytes,5,opt,name=reference_manifests,json=referenceManifests,proto3" json:"reference_manifests,omitempty"`
 }
 
 func (x *Policy) Reset() {
 	*x = Policy{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_attest_proto_msgTypes[21]
+		mi := &file_attest_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1895,7 +3415,7 @@ func (x *Policy) String() string {
 func (*Policy) ProtoMessage() {}
 
 func (x *Policy) ProtoReflect() protoreflect.Message {
-	mi := &file_attest_proto_msgTypes[21]
+	mi := &file_attest_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1908,7 +3428,7 @@ func (x *Policy) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Policy.ProtoReflect.Descriptor instead.
 func (*Policy) Descriptor() ([]byte, []int) {
-	return file_attest_proto_rawDescGZIP(), []int{21}
+	return file_attest_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *Policy) GetPlatform() *PlatformPolicy {
@@ -1925,6 +3445,20 @@ func (x *Policy) GetSevSnp() *SevSnpPolicy {
 	return nil
 }
 
+func (x *Policy) GetIma() *ImaPolicy {
+	if x != nil {
+		return x.Ima
+	}
+	return nil
+}
+
+func (x *Policy) GetReferenceManifests() *ReferenceManifestPolicy {
+	if x != nil {
+		return x.ReferenceManifests
+	}
+	return nil
+}
+
 var File_attest_proto protoreflect.FileDescriptor
 
 var file_attest_proto_rawDesc = []byte{
@@ -1943,7 +3477,7 @@ var file_attest_proto_rawDesc = []byte{
 	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x69, 0x6e, 0x73,
 	0x74, 0x61, 0x6e, 0x63, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x73,
 	0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a,
-	0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x22, 0xae, 0x03, 0x0a, 0x0b, 0x41,
+	0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x22, 0xfe, 0x03, 0x0a, 0x0b, 0x41,
 	0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x15, 0x0a, 0x06, 0x61, 0x6b,
 	0x5f, 0x70, 0x75, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x61, 0x6b, 0x50, 0x75,
 	0x62, 0x12, 0x22, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
@@ -1969,7 +3503,12 @@ var file_attest_proto_rawDesc = []byte{
 	0x37, 0x0a, 0x0f, 0x74, 0x64, 0x78, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69,
 	0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x64, 0x78, 0x2e, 0x51,
 	0x75, 0x6f, 0x74, 0x65, 0x56, 0x34, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x64, 0x78, 0x41, 0x74, 0x74,
-	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x11, 0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x64, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x4c, 0x6f, 0x67, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x6d, 0x61, 0x5f, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x5f, 0x6c, 0x6f, 0x67, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x69, 0x6d, 0x61,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x4c, 0x6f, 0x67, 0x42, 0x11, 0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f,
 	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xeb, 0x01, 0x0a, 0x0d,
 	0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2a, 0x0a,
 	0x10, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69,
@@ -1995,81 +3534,239 @@ var file_attest_proto_rawDesc = []byte{
 	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
 	0x74, 0x2e, 0x47, 0x72, 0x75, 0x62, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65,
 	0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x22, 0x35, 0x0a,
-	0x10, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74,
-	0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x6c, 0x69, 0x6e,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x4c, 0x69, 0x6e, 0x65, 0x22, 0xa0, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1b,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x22, 0x85, 0x01,
+	0x0a, 0x10, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x6c, 0x69,
+	0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x4c, 0x69, 0x6e, 0x65, 0x12, 0x4e, 0x0a, 0x15, 0x75, 0x6e, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x5f, 0x75, 0x6b, 0x69, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x55, 0x6e,
+	0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x55, 0x6b, 0x69, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x13, 0x75, 0x6e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x55, 0x6b, 0x69, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x61, 0x0a, 0x12, 0x55, 0x6e, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x55, 0x6b, 0x69, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x33, 0x0a, 0x15, 0x75,
+	0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x75, 0x6e, 0x74, 0x72,
+	0x75, 0x73, 0x74, 0x65, 0x64, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x38, 0x0a, 0x0a, 0x55, 0x6b, 0x69, 0x53,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x22, 0xef, 0x01, 0x0a, 0x08, 0x55, 0x6b, 0x69, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x12, 0x2e, 0x0a, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x55, 0x6b,
+	0x69, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x73, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x5f, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x44,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x72, 0x64, 0x5f,
+	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x69, 0x6e,
+	0x69, 0x74, 0x72, 0x64, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6d,
+	0x64, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0d, 0x63, 0x6d, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x73, 0x72, 0x65, 0x6c, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x6f, 0x73, 0x72, 0x65, 0x6c, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x22, 0xa7, 0x01, 0x0a, 0x0c, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x63, 0x72, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x75, 0x6e, 0x74, 0x72,
+	0x75, 0x73, 0x74, 0x65, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f,
+	0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e,
+	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0x9f,
+	0x01, 0x0a, 0x0c, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x70, 0x68, 0x61, 0x73, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f, 0x6f, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x73,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x64, 0x12,
+	0x21, 0x0a, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65,
+	0x6d, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x22, 0xed, 0x01, 0x0a, 0x08, 0x49, 0x6d, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1b, 0x0a,
+	0x09, 0x70, 0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x70, 0x63, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x2e, 0x0a, 0x13, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x61, 0x6c, 0x67,
+	0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x66, 0x69,
+	0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x41, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12,
+	0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x22, 0x36, 0x0a, 0x08, 0x49, 0x6d, 0x61, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2a, 0x0a, 0x07,
+	0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0xff, 0x01, 0x0a, 0x11, 0x46, 0x69, 0x72,
+	0x6d, 0x77, 0x61, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x12, 0x1b,
 	0x0a, 0x09, 0x70, 0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x0d, 0x52, 0x08, 0x70, 0x63, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x25, 0x0a, 0x0e, 0x75,
 	0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20,
 	0x01, 0x28, 0x0d, 0x52, 0x0d, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x54, 0x79,
-	0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x27,
+	0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x62, 0x61, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x62, 0x61, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x67, 0x75, 0x69, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a,
+	0x74, 0x61, 0x62, 0x6c, 0x65, 0x47, 0x75, 0x69, 0x64, 0x73, 0x22, 0xca, 0x01, 0x0a, 0x0a, 0x42,
+	0x6f, 0x6f, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65,
+	0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x61,
+	0x74, 0x68, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x27,
 	0x0a, 0x0f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65,
-	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x56,
-	0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0x72, 0x0a, 0x0b, 0x43, 0x65, 0x72, 0x74, 0x69,
-	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x03, 0x64, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x0a, 0x77, 0x65,
-	0x6c, 0x6c, 0x5f, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c,
-	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77,
-	0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x09,
-	0x77, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x10, 0x0a, 0x0e, 0x72, 0x65, 0x70,
-	0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4d, 0x0a, 0x08, 0x44,
-	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x65, 0x72, 0x74, 0x73,
-	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
-	0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x05, 0x63, 0x65, 0x72,
-	0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
-	0x28, 0x0c, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0xe7, 0x01, 0x0a, 0x0f, 0x53,
-	0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x02, 0x64, 0x62, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61,
-	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x02, 0x64, 0x62, 0x12, 0x22, 0x0a, 0x03, 0x64, 0x62,
-	0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
-	0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x03, 0x64, 0x62, 0x78, 0x12, 0x2e,
-	0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x22, 0xc7, 0x01, 0x0a, 0x11, 0x46, 0x69, 0x72, 0x6d,
+	0x77, 0x61, 0x72, 0x65, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x39, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x69, 0x72, 0x6d, 0x77,
+	0x61, 0x72, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x63, 0x6f,
+	0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6f, 0x6f, 0x74,
+	0x5f, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x09, 0x62, 0x6f,
+	0x6f, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x35, 0x0a, 0x0c, 0x62, 0x6f, 0x6f, 0x74, 0x5f,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x42, 0x6f, 0x6f, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0b, 0x62, 0x6f, 0x6f, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x70, 0x61, 0x72, 0x73, 0x65, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x61, 0x72, 0x73, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x73, 0x22, 0xa0, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x63, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x70, 0x63, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x6e, 0x74, 0x72,
+	0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0d, 0x75, 0x6e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x65, 0x64, 0x22, 0x72, 0x0a, 0x0b, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x03, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x48, 0x00, 0x52, 0x03, 0x64, 0x65, 0x72, 0x12, 0x3d, 0x0a, 0x0a, 0x77, 0x65, 0x6c, 0x6c, 0x5f,
+	0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x65,
+	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x09, 0x77, 0x65, 0x6c,
+	0x6c, 0x4b, 0x6e, 0x6f, 0x77, 0x6e, 0x42, 0x10, 0x0a, 0x0e, 0x72, 0x65, 0x70, 0x72, 0x65, 0x73,
+	0x65, 0x6e, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x4d, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x05, 0x63, 0x65, 0x72, 0x74, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22, 0xd8, 0x03, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65,
+	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e,
+	0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x02, 0x64, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28,
 	0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62,
-	0x61, 0x73, 0x65, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x20,
-	0x0a, 0x02, 0x70, 0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74,
-	0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x02, 0x70, 0x6b,
-	0x12, 0x22, 0x0a, 0x03, 0x6b, 0x65, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
-	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52,
-	0x03, 0x6b, 0x65, 0x6b, 0x22, 0x93, 0x04, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
-	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x61, 0x67, 0x65,
-	0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0e, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
-	0x12, 0x21, 0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x67,
-	0x65, 0x73, 0x74, 0x12, 0x3c, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x70,
-	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69,
-	0x63, 0x79, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63,
-	0x79, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
-	0x61, 0x72, 0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73,
-	0x12, 0x3e, 0x0a, 0x08, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x23, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
-	0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x45, 0x6e, 0x76, 0x56, 0x61,
-	0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73,
-	0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x61,
-	0x72, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72, 0x72,
-	0x69, 0x64, 0x64, 0x65, 0x6e, 0x41, 0x72, 0x67, 0x73, 0x12, 0x5d, 0x0a, 0x13, 0x6f, 0x76, 0x65,
-	0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73,
-	0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
-	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4f,
-	0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65,
-	0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x45, 0x6e, 0x76, 0x56,
+	0x61, 0x73, 0x65, 0x52, 0x02, 0x64, 0x62, 0x12, 0x22, 0x0a, 0x03, 0x64, 0x62, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61,
+	0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x03, 0x64, 0x62, 0x78, 0x12, 0x2e, 0x0a, 0x09, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65,
+	0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x20, 0x0a, 0x02, 0x70,
+	0x6b, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x02, 0x70, 0x6b, 0x12, 0x22, 0x0a,
+	0x03, 0x6b, 0x65, 0x6b, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x52, 0x03, 0x6b, 0x65,
+	0x6b, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x6f, 0x6b, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x6d, 0x6f, 0x6b, 0x4c,
+	0x69, 0x73, 0x74, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x11, 0x6d, 0x6f, 0x6b,
+	0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x78, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x6d, 0x6f, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x58, 0x44, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x10, 0x6d, 0x6f, 0x6b, 0x5f, 0x6c, 0x69, 0x73, 0x74,
+	0x5f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e,
+	0x6d, 0x6f, 0x6b, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x62, 0x61, 0x74, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x62, 0x61, 0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x53, 0x0a,
+	0x17, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74,
+	0x79, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b,
+	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x68, 0x69, 0x6d, 0x41, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x15, 0x6b, 0x65, 0x72,
+	0x6e, 0x65, 0x6c, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x53, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x22, 0x8f, 0x07, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x21,
+	0x0a, 0x0c, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x12, 0x3c, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x70, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65,
+	0x73, 0x74, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x0d, 0x72, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x19, 0x0a, 0x08, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72,
+	0x67, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x3e,
+	0x0a, 0x08, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x23, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x12, 0x27,
+	0x0a, 0x0f, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x61, 0x72, 0x67,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64,
+	0x64, 0x65, 0x6e, 0x41, 0x72, 0x67, 0x73, 0x12, 0x5d, 0x0a, 0x13, 0x6f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x4f, 0x76, 0x65,
+	0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x11, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45,
+	0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x64, 0x64, 0x65, 0x64, 0x5f,
+	0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x11, 0x61, 0x64, 0x64, 0x65, 0x64, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x12, 0x2e, 0x0a, 0x06, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69,
+	0x6e, 0x65, 0x72, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x06, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x73,
+	0x12, 0x42, 0x0a, 0x1d, 0x69, 0x6d, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1b, 0x69, 0x6d, 0x70, 0x65, 0x72, 0x73, 0x6f,
+	0x6e, 0x61, 0x74, 0x65, 0x64, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x41, 0x63, 0x63, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x12, 0x3e, 0x0a, 0x0c, 0x6c, 0x6f, 0x67, 0x5f, 0x72, 0x65, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x4c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6c, 0x6f, 0x67, 0x52, 0x65, 0x64, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72,
+	0x6f, 0x6c, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x11, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x44,
+	0x0a, 0x16, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56,
 	0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
 	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x44, 0x0a, 0x16, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x64,
-	0x65, 0x6e, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
-	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0x83, 0x01, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x4d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x53, 0x0a, 0x0f, 0x53, 0x65,
 	0x6d, 0x61, 0x6e, 0x74, 0x69, 0x63, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a,
 	0x05, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6d, 0x61,
 	0x6a, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
@@ -2084,7 +3781,7 @@ var file_attest_proto_rawDesc = []byte{
 	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x30, 0x0a, 0x07, 0x63, 0x63, 0x5f,
 	0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74,
 	0x65, 0x73, 0x74, 0x2e, 0x47, 0x50, 0x55, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x43, 0x4d,
-	0x6f, 0x64, 0x65, 0x52, 0x06, 0x63, 0x63, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0xd4, 0x02, 0x0a, 0x10,
+	0x6f, 0x64, 0x65, 0x52, 0x06, 0x63, 0x63, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0xae, 0x04, 0x0a, 0x10,
 	0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74, 0x65,
 	0x12, 0x34, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e,
@@ -2106,101 +3803,190 @@ var file_attest_proto_rawDesc = []byte{
 	0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74,
 	0x65, 0x73, 0x74, 0x2e, 0x47, 0x70, 0x75, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61,
 	0x74, 0x65, 0x52, 0x0e, 0x67, 0x70, 0x75, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x22, 0x20, 0x0a, 0x06, 0x45, 0x66, 0x69, 0x41, 0x70, 0x70, 0x12, 0x16, 0x0a, 0x06,
-	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69,
-	0x67, 0x65, 0x73, 0x74, 0x22, 0x2e, 0x0a, 0x08, 0x45, 0x66, 0x69, 0x53, 0x74, 0x61, 0x74, 0x65,
-	0x12, 0x22, 0x0a, 0x04, 0x61, 0x70, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
-	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x66, 0x69, 0x41, 0x70, 0x70, 0x52, 0x04,
-	0x61, 0x70, 0x70, 0x73, 0x22, 0x93, 0x04, 0x0a, 0x0c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
-	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
-	0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08,
-	0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x38, 0x0a, 0x0b, 0x73, 0x65, 0x63, 0x75,
-	0x72, 0x65, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
-	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f,
-	0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f,
-	0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x0a, 0x72, 0x61, 0x77, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
-	0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x72, 0x61, 0x77, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73,
-	0x12, 0x21, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d,
-	0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x41, 0x6c, 0x67, 0x6f, 0x52, 0x04, 0x68,
-	0x61, 0x73, 0x68, 0x12, 0x25, 0x0a, 0x04, 0x67, 0x72, 0x75, 0x62, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x72, 0x75, 0x62, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x52, 0x04, 0x67, 0x72, 0x75, 0x62, 0x12, 0x3b, 0x0a, 0x0c, 0x6c, 0x69,
-	0x6e, 0x75, 0x78, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x4b,
-	0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0b, 0x6c, 0x69, 0x6e, 0x75,
-	0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x12, 0x2a, 0x0a, 0x03, 0x63, 0x6f, 0x73, 0x18, 0x07,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03,
-	0x63, 0x6f, 0x73, 0x12, 0x22, 0x0a, 0x03, 0x65, 0x66, 0x69, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x66, 0x69, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x52, 0x03, 0x65, 0x66, 0x69, 0x12, 0x45, 0x0a, 0x13, 0x73, 0x65, 0x76, 0x5f, 0x73,
-	0x6e, 0x70, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x65, 0x76, 0x73, 0x6e, 0x70, 0x2e, 0x41, 0x74,
-	0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x11, 0x73, 0x65, 0x76,
-	0x53, 0x6e, 0x70, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37,
-	0x0a, 0x0f, 0x74, 0x64, 0x78, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x64, 0x78, 0x2e, 0x51, 0x75,
-	0x6f, 0x74, 0x65, 0x56, 0x34, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x64, 0x78, 0x41, 0x74, 0x74, 0x65,
-	0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x11, 0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f, 0x61,
-	0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xde, 0x01, 0x0a, 0x0e, 0x50,
-	0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x39, 0x0a,
-	0x19, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c,
-	0x52, 0x16, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x53, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x73, 0x12, 0x3f, 0x0a, 0x1c, 0x6d, 0x69, 0x6e, 0x69,
-	0x6d, 0x75, 0x6d, 0x5f, 0x67, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65,
-	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19,
-	0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x47, 0x63, 0x65, 0x46, 0x69, 0x72, 0x6d, 0x77, 0x61,
-	0x72, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x12, 0x6d, 0x69, 0x6e,
-	0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47,
-	0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65,
-	0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
-	0x6d, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x22, 0x51, 0x0a, 0x09, 0x52,
-	0x49, 0x4d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75,
-	0x69, 0x72, 0x65, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x12,
-	0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x43, 0x65, 0x72, 0x74, 0x73, 0x22, 0x35,
-	0x0a, 0x0c, 0x53, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x25,
-	0x0a, 0x04, 0x75, 0x65, 0x66, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61,
+	0x74, 0x65, 0x12, 0x49, 0x0a, 0x13, 0x6c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72, 0x5f, 0x69,
+	0x6d, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x4c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65,
+	0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x11, 0x6c, 0x61, 0x75, 0x6e,
+	0x63, 0x68, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x36, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77,
+	0x6e, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x2c,
+	0x0a, 0x12, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72,
+	0x5f, 0x6b, 0x65, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x4b, 0x65, 0x79, 0x22, 0x20, 0x0a, 0x06,
+	0x45, 0x66, 0x69, 0x41, 0x70, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x2e,
+	0x0a, 0x08, 0x45, 0x66, 0x69, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x04, 0x61, 0x70,
+	0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x45, 0x66, 0x69, 0x41, 0x70, 0x70, 0x52, 0x04, 0x61, 0x70, 0x70, 0x73, 0x22, 0xd5,
+	0x05, 0x0a, 0x0c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x31, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66,
+	0x6f, 0x72, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x12, 0x38, 0x0a, 0x0b, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x5f, 0x62, 0x6f, 0x6f,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74,
+	0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x0a, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x2c, 0x0a, 0x0a,
+	0x72, 0x61, 0x77, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x09, 0x72, 0x61, 0x77, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x74, 0x70, 0x6d, 0x2e, 0x48,
+	0x61, 0x73, 0x68, 0x41, 0x6c, 0x67, 0x6f, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x25, 0x0a,
+	0x04, 0x67, 0x72, 0x75, 0x62, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x72, 0x75, 0x62, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x04,
+	0x67, 0x72, 0x75, 0x62, 0x12, 0x3b, 0x0a, 0x0c, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x5f, 0x6b, 0x65,
+	0x72, 0x6e, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x0b, 0x6c, 0x69, 0x6e, 0x75, 0x78, 0x4b, 0x65, 0x72, 0x6e, 0x65,
+	0x6c, 0x12, 0x2a, 0x0a, 0x03, 0x63, 0x6f, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x65, 0x64,
+	0x43, 0x6f, 0x73, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x63, 0x6f, 0x73, 0x12, 0x22, 0x0a,
+	0x03, 0x65, 0x66, 0x69, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x2e, 0x45, 0x66, 0x69, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x65, 0x66,
+	0x69, 0x12, 0x45, 0x0a, 0x13, 0x73, 0x65, 0x76, 0x5f, 0x73, 0x6e, 0x70, 0x5f, 0x61, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x73, 0x65, 0x76, 0x73, 0x6e, 0x70, 0x2e, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x11, 0x73, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x41, 0x74, 0x74,
+	0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x0f, 0x74, 0x64, 0x78, 0x5f,
+	0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x74, 0x64, 0x78, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x56, 0x34, 0x48,
+	0x00, 0x52, 0x0e, 0x74, 0x64, 0x78, 0x41, 0x74, 0x74, 0x65, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x22, 0x0a, 0x03, 0x75, 0x6b, 0x69, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x55, 0x6b, 0x69, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x03, 0x75, 0x6b, 0x69, 0x12, 0x2e, 0x0a, 0x07, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x07, 0x73, 0x79,
+	0x73, 0x74, 0x65, 0x6d, 0x64, 0x12, 0x22, 0x0a, 0x03, 0x69, 0x6d, 0x61, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x49, 0x6d, 0x61, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x69, 0x6d, 0x61, 0x12, 0x48, 0x0a, 0x12, 0x66, 0x69, 0x72,
+	0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x69, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x18,
+	0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x46,
+	0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x11, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74,
+	0x6f, 0x72, 0x79, 0x42, 0x11, 0x0a, 0x0f, 0x74, 0x65, 0x65, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xde, 0x01, 0x0a, 0x0e, 0x50, 0x6c, 0x61, 0x74, 0x66,
+	0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x39, 0x0a, 0x19, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x65, 0x64, 0x5f, 0x73, 0x63, 0x72, 0x74, 0x6d, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x16, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x65, 0x64, 0x53, 0x63, 0x72, 0x74, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x73, 0x12, 0x3f, 0x0a, 0x1c, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f,
+	0x67, 0x63, 0x65, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x19, 0x6d, 0x69, 0x6e, 0x69,
+	0x6d, 0x75, 0x6d, 0x47, 0x63, 0x65, 0x46, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x21, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x47, 0x43, 0x45, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f,
+	0x6c, 0x6f, 0x67, 0x79, 0x52, 0x11, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x65, 0x63,
+	0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x22, 0x51, 0x0a, 0x09, 0x52, 0x49, 0x4d, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x72, 0x65,
+	0x71, 0x75, 0x69, 0x72, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x72,
+	0x6f, 0x6f, 0x74, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x09, 0x72, 0x6f, 0x6f, 0x74, 0x43, 0x65, 0x72, 0x74, 0x73, 0x22, 0x35, 0x0a, 0x0c, 0x53, 0x65,
+	0x76, 0x53, 0x6e, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x25, 0x0a, 0x04, 0x75, 0x65,
+	0x66, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x52, 0x49, 0x4d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x04, 0x75, 0x65, 0x66,
+	0x69, 0x22, 0xb9, 0x01, 0x0a, 0x17, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x4d,
+	0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1c, 0x0a,
+	0x09, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x09, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x07, 0x73,
+	0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61,
 	0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x49, 0x4d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
-	0x04, 0x75, 0x65, 0x66, 0x69, 0x22, 0x6b, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
-	0x32, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66,
-	0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66,
-	0x6f, 0x72, 0x6d, 0x12, 0x2d, 0x0a, 0x07, 0x73, 0x65, 0x76, 0x5f, 0x73, 0x6e, 0x70, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65,
-	0x76, 0x53, 0x6e, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x73, 0x65, 0x76, 0x53,
-	0x6e, 0x70, 0x2a, 0x62, 0x0a, 0x19, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65,
-	0x6e, 0x74, 0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12,
-	0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x4d, 0x44,
-	0x5f, 0x53, 0x45, 0x56, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45,
-	0x56, 0x5f, 0x45, 0x53, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x54, 0x45, 0x4c, 0x5f,
-	0x54, 0x44, 0x58, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56,
-	0x5f, 0x53, 0x4e, 0x50, 0x10, 0x04, 0x2a, 0x96, 0x01, 0x0a, 0x14, 0x57, 0x65, 0x6c, 0x6c, 0x4b,
-	0x6e, 0x6f, 0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12,
-	0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18,
-	0x4d, 0x53, 0x5f, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x5f, 0x50, 0x52, 0x4f, 0x44, 0x5f,
-	0x50, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x01, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x53,
-	0x5f, 0x54, 0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x59, 0x5f, 0x55, 0x45, 0x46,
-	0x49, 0x5f, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x4d,
-	0x53, 0x5f, 0x54, 0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x59, 0x5f, 0x4b, 0x45,
-	0x4b, 0x5f, 0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x47,
-	0x43, 0x45, 0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x5f, 0x50, 0x4b, 0x10, 0x04, 0x2a,
-	0x35, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x74, 0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
-	0x12, 0x0a, 0x0a, 0x06, 0x41, 0x6c, 0x77, 0x61, 0x79, 0x73, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09,
-	0x4f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x4e,
-	0x65, 0x76, 0x65, 0x72, 0x10, 0x02, 0x2a, 0x3b, 0x0a, 0x0f, 0x47, 0x50, 0x55, 0x44, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x43, 0x43, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53,
-	0x45, 0x54, 0x10, 0x00, 0x12, 0x06, 0x0a, 0x02, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03,
-	0x4f, 0x46, 0x46, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x45, 0x56, 0x54, 0x4f, 0x4f, 0x4c,
-	0x53, 0x10, 0x03, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x67, 0x6f, 0x2d, 0x74, 0x70, 0x6d, 0x2d,
-	0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x74, 0x74, 0x65,
-	0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x07, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x71, 0x75,
+	0x69, 0x72, 0x65, 0x5f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x46, 0x69, 0x72, 0x6d, 0x77,
+	0x61, 0x72, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x65,
+	0x66, 0x69, 0x5f, 0x61, 0x70, 0x70, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x45, 0x66, 0x69, 0x41, 0x70, 0x70, 0x73, 0x22, 0xbb, 0x01,
+	0x0a, 0x09, 0x49, 0x6d, 0x61, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x30, 0x0a, 0x14, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67, 0x65,
+	0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x12, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x2e, 0x0a,
+	0x13, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x64, 0x69, 0x67,
+	0x65, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x11, 0x64, 0x65, 0x6e, 0x69,
+	0x65, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x6e, 0x69, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73,
+	0x12, 0x29, 0x0a, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x56, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xe2, 0x01, 0x0a, 0x06,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x32, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73,
+	0x74, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x2d, 0x0a, 0x07, 0x73, 0x65,
+	0x76, 0x5f, 0x73, 0x6e, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x52, 0x06, 0x73, 0x65, 0x76, 0x53, 0x6e, 0x70, 0x12, 0x23, 0x0a, 0x03, 0x69, 0x6d, 0x61,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x2e,
+	0x49, 0x6d, 0x61, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x03, 0x69, 0x6d, 0x61, 0x12, 0x50,
+	0x0a, 0x13, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x74,
+	0x74, 0x65, 0x73, 0x74, 0x2e, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x4d, 0x61,
+	0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x12, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x73,
+	0x2a, 0x62, 0x0a, 0x19, 0x47, 0x43, 0x45, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x61, 0x6c, 0x54, 0x65, 0x63, 0x68, 0x6e, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x08, 0x0a,
+	0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x41, 0x4d, 0x44, 0x5f, 0x53,
+	0x45, 0x56, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f,
+	0x45, 0x53, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x49, 0x4e, 0x54, 0x45, 0x4c, 0x5f, 0x54, 0x44,
+	0x58, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x4d, 0x44, 0x5f, 0x53, 0x45, 0x56, 0x5f, 0x53,
+	0x4e, 0x50, 0x10, 0x04, 0x2a, 0x96, 0x01, 0x0a, 0x14, 0x57, 0x65, 0x6c, 0x6c, 0x4b, 0x6e, 0x6f,
+	0x77, 0x6e, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a,
+	0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x53,
+	0x5f, 0x57, 0x49, 0x4e, 0x44, 0x4f, 0x57, 0x53, 0x5f, 0x50, 0x52, 0x4f, 0x44, 0x5f, 0x50, 0x43,
+	0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x01, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x53, 0x5f, 0x54,
+	0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x59, 0x5f, 0x55, 0x45, 0x46, 0x49, 0x5f,
+	0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x4d, 0x53, 0x5f,
+	0x54, 0x48, 0x49, 0x52, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x59, 0x5f, 0x4b, 0x45, 0x4b, 0x5f,
+	0x43, 0x41, 0x5f, 0x32, 0x30, 0x31, 0x31, 0x10, 0x03, 0x12, 0x12, 0x0a, 0x0e, 0x47, 0x43, 0x45,
+	0x5f, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x5f, 0x50, 0x4b, 0x10, 0x04, 0x2a, 0x88, 0x01,
+	0x0a, 0x13, 0x53, 0x68, 0x69, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x53,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x41, 0x55, 0x54, 0x48, 0x4f, 0x52, 0x49,
+	0x54, 0x59, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
+	0x4e, 0x10, 0x00, 0x12, 0x17, 0x0a, 0x13, 0x41, 0x55, 0x54, 0x48, 0x4f, 0x52, 0x49, 0x54, 0x59,
+	0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x44, 0x42, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14,
+	0x41, 0x55, 0x54, 0x48, 0x4f, 0x52, 0x49, 0x54, 0x59, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45,
+	0x5f, 0x4d, 0x4f, 0x4b, 0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x41, 0x55, 0x54, 0x48, 0x4f, 0x52,
+	0x49, 0x54, 0x59, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x53, 0x48, 0x49, 0x4d, 0x5f,
+	0x56, 0x45, 0x4e, 0x44, 0x4f, 0x52, 0x10, 0x03, 0x2a, 0x35, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x0a, 0x0a, 0x06, 0x41, 0x6c, 0x77,
+	0x61, 0x79, 0x73, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x4f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x4e, 0x65, 0x76, 0x65, 0x72, 0x10, 0x02, 0x2a,
+	0x5d, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65,
+	0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4e, 0x54, 0x41, 0x49, 0x4e, 0x45, 0x52, 0x5f, 0x52, 0x4f,
+	0x4c, 0x45, 0x5f, 0x4d, 0x41, 0x49, 0x4e, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x43, 0x4f, 0x4e,
+	0x54, 0x41, 0x49, 0x4e, 0x45, 0x52, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x53, 0x49, 0x44, 0x45,
+	0x43, 0x41, 0x52, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4e, 0x54, 0x41, 0x49, 0x4e,
+	0x45, 0x52, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x49, 0x4e, 0x49, 0x54, 0x10, 0x02, 0x2a, 0xa3,
+	0x01, 0x0a, 0x13, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x4c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x18, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x45,
+	0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x45, 0x44, 0x49,
+	0x52, 0x45, 0x43, 0x54, 0x5f, 0x4e, 0x4f, 0x57, 0x48, 0x45, 0x52, 0x45, 0x10, 0x01, 0x12, 0x1e,
+	0x0a, 0x1a, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x45, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x43,
+	0x4c, 0x4f, 0x55, 0x44, 0x5f, 0x4c, 0x4f, 0x47, 0x47, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x17,
+	0x0a, 0x13, 0x4c, 0x4f, 0x47, 0x5f, 0x52, 0x45, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x53,
+	0x45, 0x52, 0x49, 0x41, 0x4c, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x4c, 0x4f, 0x47, 0x5f, 0x52,
+	0x45, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x5f, 0x45, 0x56, 0x45, 0x52, 0x59, 0x57, 0x48, 0x45,
+	0x52, 0x45, 0x10, 0x04, 0x2a, 0x6a, 0x0a, 0x11, 0x4c, 0x61, 0x75, 0x6e, 0x63, 0x68, 0x65, 0x72,
+	0x49, 0x6d, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x1a, 0x4c, 0x41, 0x55,
+	0x4e, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x49, 0x4d, 0x41, 0x47, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x4c, 0x41, 0x55,
+	0x4e, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x49, 0x4d, 0x41, 0x47, 0x45, 0x5f, 0x48, 0x41, 0x52, 0x44,
+	0x45, 0x4e, 0x45, 0x44, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x4c, 0x41, 0x55, 0x4e, 0x43, 0x48,
+	0x45, 0x52, 0x5f, 0x49, 0x4d, 0x41, 0x47, 0x45, 0x5f, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10, 0x02,
+	0x2a, 0x3b, 0x0a, 0x0f, 0x47, 0x50, 0x55, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x43, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x55, 0x4e, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x06,
+	0x0a, 0x02, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x4f, 0x46, 0x46, 0x10, 0x02, 0x12,
+	0x0c, 0x0a, 0x08, 0x44, 0x45, 0x56, 0x54, 0x4f, 0x4f, 0x4c, 0x53, 0x10, 0x03, 0x42, 0x2d, 0x5a,
+	0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x67, 0x6f, 0x2d, 0x74, 0x70, 0x6d, 0x2d, 0x74, 0x6f, 0x6f, 0x6c, 0x73, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x74, 0x74, 0x65, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -2215,86 +4001,122 @@ func file_attest_proto_rawDescGZIP() []byte {
 	return file_attest_proto_rawDescData
 }
 
-var file_attest_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_attest_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_attest_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_attest_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_attest_proto_goTypes = []interface{}{
-	(GCEConfidentialTechnology)(0), // 0: attest.GCEConfidentialTechnology
-	(WellKnownCertificate)(0),      // 1: attest.WellKnownCertificate
-	(RestartPolicy)(0),             // 2: attest.RestartPolicy
-	(GPUDeviceCCMode)(0),           // 3: attest.GPUDeviceCCMode
-	(*GCEInstanceInfo)(nil),        // 4: attest.GCEInstanceInfo
-	(*Attestation)(nil),            // 5: attest.Attestation
-	(*PlatformState)(nil),          // 6: attest.PlatformState
-	(*GrubFile)(nil),               // 7: attest.GrubFile
-	(*GrubState)(nil),              // 8: attest.GrubState
-	(*LinuxKernelState)(nil),       // 9: attest.LinuxKernelState
-	(*Event)(nil),                  // 10: attest.Event
-	(*Certificate)(nil),            // 11: attest.Certificate
-	(*Database)(nil),               // 12: attest.Database
-	(*SecureBootState)(nil),        // 13: attest.SecureBootState
-	(*ContainerState)(nil),         // 14: attest.ContainerState
-	(*SemanticVersion)(nil),        // 15: attest.SemanticVersion
-	(*HealthMonitoringState)(nil),  // 16: attest.HealthMonitoringState
-	(*GpuDeviceState)(nil),         // 17: attest.GpuDeviceState
-	(*AttestedCosState)(nil),       // 18: attest.AttestedCosState
-	(*EfiApp)(nil),                 // 19: attest.EfiApp
-	(*EfiState)(nil),               // 20: attest.EfiState
-	(*MachineState)(nil),           // 21: attest.MachineState
-	(*PlatformPolicy)(nil),         // 22: attest.PlatformPolicy
-	(*RIMPolicy)(nil),              // 23: attest.RIMPolicy
-	(*SevSnpPolicy)(nil),           // 24: attest.SevSnpPolicy
-	(*Policy)(nil),                 // 25: attest.Policy
-	nil,                            // 26: attest.ContainerState.EnvVarsEntry
-	nil,                            // 27: attest.ContainerState.OverriddenEnvVarsEntry
-	(*tpm.Quote)(nil),              // 28: tpm.Quote
-	(*sevsnp.Attestation)(nil),     // 29: sevsnp.Attestation
-	(*tdx.QuoteV4)(nil),            // 30: tdx.QuoteV4
-	(tpm.HashAlgo)(0),              // 31: tpm.HashAlgo
+	(GCEConfidentialTechnology)(0),  // 0: attest.GCEConfidentialTechnology
+	(WellKnownCertificate)(0),       // 1: attest.WellKnownCertificate
+	(ShimAuthoritySource)(0),        // 2: attest.ShimAuthoritySource
+	(RestartPolicy)(0),              // 3: attest.RestartPolicy
+	(ContainerRole)(0),              // 4: attest.ContainerRole
+	(LogRedirectLocation)(0),        // 5: attest.LogRedirectLocation
+	(LauncherImageType)(0),          // 6: attest.LauncherImageType
+	(GPUDeviceCCMode)(0),            // 7: attest.GPUDeviceCCMode
+	(*GCEInstanceInfo)(nil),         // 8: attest.GCEInstanceInfo
+	(*Attestation)(nil),             // 9: attest.Attestation
+	(*PlatformState)(nil),           // 10: attest.PlatformState
+	(*GrubFile)(nil),                // 11: attest.GrubFile
+	(*GrubState)(nil),               // 12: attest.GrubState
+	(*LinuxKernelState)(nil),        // 13: attest.LinuxKernelState
+	(*UnverifiedUkiEvent)(nil),      // 14: attest.UnverifiedUkiEvent
+	(*UkiSection)(nil),              // 15: attest.UkiSection
+	(*UkiState)(nil),                // 16: attest.UkiState
+	(*SystemdEvent)(nil),            // 17: attest.SystemdEvent
+	(*SystemdState)(nil),            // 18: attest.SystemdState
+	(*ImaEntry)(nil),                // 19: attest.ImaEntry
+	(*ImaState)(nil),                // 20: attest.ImaState
+	(*FirmwareComponent)(nil),       // 21: attest.FirmwareComponent
+	(*BootOption)(nil),              // 22: attest.BootOption
+	(*FirmwareInventory)(nil),       // 23: attest.FirmwareInventory
+	(*Event)(nil),                   // 24: attest.Event
+	(*Certificate)(nil),             // 25: attest.Certificate
+	(*Database)(nil),                // 26: attest.Database
+	(*SecureBootState)(nil),         // 27: attest.SecureBootState
+	(*ContainerState)(nil),          // 28: attest.ContainerState
+	(*ContainerMount)(nil),          // 29: attest.ContainerMount
+	(*SemanticVersion)(nil),         // 30: attest.SemanticVersion
+	(*HealthMonitoringState)(nil),   // 31: attest.HealthMonitoringState
+	(*GpuDeviceState)(nil),          // 32: attest.GpuDeviceState
+	(*AttestedCosState)(nil),        // 33: attest.AttestedCosState
+	(*EfiApp)(nil),                  // 34: attest.EfiApp
+	(*EfiState)(nil),                // 35: attest.EfiState
+	(*MachineState)(nil),            // 36: attest.MachineState
+	(*PlatformPolicy)(nil),          // 37: attest.PlatformPolicy
+	(*RIMPolicy)(nil),               // 38: attest.RIMPolicy
+	(*SevSnpPolicy)(nil),            // 39: attest.SevSnpPolicy
+	(*ReferenceManifestPolicy)(nil), // 40: attest.ReferenceManifestPolicy
+	(*ImaPolicy)(nil),               // 41: attest.ImaPolicy
+	(*Policy)(nil),                  // 42: attest.Policy
+	nil,                             // 43: attest.ContainerState.EnvVarsEntry
+	nil,                             // 44: attest.ContainerState.OverriddenEnvVarsEntry
+	(*tpm.Quote)(nil),               // 45: tpm.Quote
+	(*sevsnp.Attestation)(nil),      // 46: sevsnp.Attestation
+	(*tdx.QuoteV4)(nil),             // 47: tdx.QuoteV4
+	(tpm.HashAlgo)(0),               // 48: tpm.HashAlgo
 }
 var file_attest_proto_depIdxs = []int32{
-	28, // 0: attest.Attestation.quotes:type_name -> tpm.Quote
-	4,  // 1: attest.Attestation.instance_info:type_name -> attest.GCEInstanceInfo
-	29, // 2: attest.Attestation.sev_snp_attestation:type_name -> sevsnp.Attestation
-	30, // 3: attest.Attestation.tdx_attestation:type_name -> tdx.QuoteV4
+	45, // 0: attest.Attestation.quotes:type_name -> tpm.Quote
+	8,  // 1: attest.Attestation.instance_info:type_name -> attest.GCEInstanceInfo
+	46, // 2: attest.Attestation.sev_snp_attestation:type_name -> sevsnp.Attestation
+	47, // 3: attest.Attestation.tdx_attestation:type_name -> tdx.QuoteV4
 	0,  // 4: attest.PlatformState.technology:type_name -> attest.GCEConfidentialTechnology
-	4,  // 5: attest.PlatformState.instance_info:type_name -> attest.GCEInstanceInfo
-	7,  // 6: attest.GrubState.files:type_name -> attest.GrubFile
-	1,  // 7: attest.Certificate.well_known:type_name -> attest.WellKnownCertificate
-	11, // 8: attest.Database.certs:type_name -> attest.Certificate
-	12, // 9: attest.SecureBootState.db:type_name -> attest.Database
-	12, // 10: attest.SecureBootState.dbx:type_name -> attest.Database
-	12, // 11: attest.SecureBootState.authority:type_name -> attest.Database
-	12, // 12: attest.SecureBootState.pk:type_name -> attest.Database
-	12, // 13: attest.SecureBootState.kek:type_name -> attest.Database
-	2,  // 14: attest.ContainerState.restart_policy:type_name -> attest.RestartPolicy
-	26, // 15: attest.ContainerState.env_vars:type_name -> attest.ContainerState.EnvVarsEntry
-	27, // 16: attest.ContainerState.overridden_env_vars:type_name -> attest.ContainerState.OverriddenEnvVarsEntry
-	3,  // 17: attest.GpuDeviceState.cc_mode:type_name -> attest.GPUDeviceCCMode
-	14, // 18: attest.AttestedCosState.container:type_name -> attest.ContainerState
-	15, // 19: attest.AttestedCosState.cos_version:type_name -> attest.SemanticVersion
-	15, // 20: attest.AttestedCosState.launcher_version:type_name -> attest.SemanticVersion
-	16, // 21: attest.AttestedCosState.health_monitoring:type_name -> attest.HealthMonitoringState
-	17, // 22: attest.AttestedCosState.gpu_device_state:type_name -> attest.GpuDeviceState
-	19, // 23: attest.EfiState.apps:type_name -> attest.EfiApp
-	6,  // 24: attest.MachineState.platform:type_name -> attest.PlatformState
-	13, // 25: attest.MachineState.secure_boot:type_name -> attest.SecureBootState
-	10, // 26: attest.MachineState.raw_events:type_name -> attest.Event
-	31, // 27: attest.MachineState.hash:type_name -> tpm.HashAlgo
-	8,  // 28: attest.MachineState.grub:type_name -> attest.GrubState
-	9,  // 29: attest.MachineState.linux_kernel:type_name -> attest.LinuxKernelState
-	18, // 30: attest.MachineState.cos:type_name -> attest.AttestedCosState
-	20, // 31: attest.MachineState.efi:type_name -> attest.EfiState
-	29, // 32: attest.MachineState.sev_snp_attestation:type_name -> sevsnp.Attestation
-	30, // 33: attest.MachineState.tdx_attestation:type_name -> tdx.QuoteV4
-	0,  // 34: attest.PlatformPolicy.minimum_technology:type_name -> attest.GCEConfidentialTechnology
-	23, // 35: attest.SevSnpPolicy.uefi:type_name -> attest.RIMPolicy
-	22, // 36: attest.Policy.platform:type_name -> attest.PlatformPolicy
-	24, // 37: attest.Policy.sev_snp:type_name -> attest.SevSnpPolicy
-	38, // [38:38] is the sub-list for method output_type
-	38, // [38:38] is the sub-list for method input_type
-	38, // [38:38] is the sub-list for extension type_name
-	38, // [38:38] is the sub-list for extension extendee
-	0,  // [0:38] is the sub-list for field type_name
+	8,  // 5: attest.PlatformState.instance_info:type_name -> attest.GCEInstanceInfo
+	11, // 6: attest.GrubState.files:type_name -> attest.GrubFile
+	14, // 7: attest.LinuxKernelState.unverified_uki_events:type_name -> attest.UnverifiedUkiEvent
+	15, // 8: attest.UkiState.sections:type_name -> attest.UkiSection
+	17, // 9: attest.SystemdState.events:type_name -> attest.SystemdEvent
+	19, // 10: attest.ImaState.entries:type_name -> attest.ImaEntry
+	21, // 11: attest.FirmwareInventory.components:type_name -> attest.FirmwareComponent
+	22, // 12: attest.FirmwareInventory.boot_options:type_name -> attest.BootOption
+	1,  // 13: attest.Certificate.well_known:type_name -> attest.WellKnownCertificate
+	25, // 14: attest.Database.certs:type_name -> attest.Certificate
+	26, // 15: attest.SecureBootState.db:type_name -> attest.Database
+	26, // 16: attest.SecureBootState.dbx:type_name -> attest.Database
+	26, // 17: attest.SecureBootState.authority:type_name -> attest.Database
+	26, // 18: attest.SecureBootState.pk:type_name -> attest.Database
+	26, // 19: attest.SecureBootState.kek:type_name -> attest.Database
+	2,  // 20: attest.SecureBootState.kernel_authority_source:type_name -> attest.ShimAuthoritySource
+	3,  // 21: attest.ContainerState.restart_policy:type_name -> attest.RestartPolicy
+	43, // 22: attest.ContainerState.env_vars:type_name -> attest.ContainerState.EnvVarsEntry
+	44, // 23: attest.ContainerState.overridden_env_vars:type_name -> attest.ContainerState.OverriddenEnvVarsEntry
+	29, // 24: attest.ContainerState.mounts:type_name -> attest.ContainerMount
+	5,  // 25: attest.ContainerState.log_redirect:type_name -> attest.LogRedirectLocation
+	4,  // 26: attest.ContainerState.role:type_name -> attest.ContainerRole
+	7,  // 27: attest.GpuDeviceState.cc_mode:type_name -> attest.GPUDeviceCCMode
+	28, // 28: attest.AttestedCosState.container:type_name -> attest.ContainerState
+	30, // 29: attest.AttestedCosState.cos_version:type_name -> attest.SemanticVersion
+	30, // 30: attest.AttestedCosState.launcher_version:type_name -> attest.SemanticVersion
+	31, // 31: attest.AttestedCosState.health_monitoring:type_name -> attest.HealthMonitoringState
+	32, // 32: attest.AttestedCosState.gpu_device_state:type_name -> attest.GpuDeviceState
+	6,  // 33: attest.AttestedCosState.launcher_image_type:type_name -> attest.LauncherImageType
+	28, // 34: attest.AttestedCosState.containers:type_name -> attest.ContainerState
+	34, // 35: attest.EfiState.apps:type_name -> attest.EfiApp
+	10, // 36: attest.MachineState.platform:type_name -> attest.PlatformState
+	27, // 37: attest.MachineState.secure_boot:type_name -> attest.SecureBootState
+	24, // 38: attest.MachineState.raw_events:type_name -> attest.Event
+	48, // 39: attest.MachineState.hash:type_name -> tpm.HashAlgo
+	12, // 40: attest.MachineState.grub:type_name -> attest.GrubState
+	13, // 41: attest.MachineState.linux_kernel:type_name -> attest.LinuxKernelState
+	33, // 42: attest.MachineState.cos:type_name -> attest.AttestedCosState
+	35, // 43: attest.MachineState.efi:type_name -> attest.EfiState
+	46, // 44: attest.MachineState.sev_snp_attestation:type_name -> sevsnp.Attestation
+	47, // 45: attest.MachineState.tdx_attestation:type_name -> tdx.QuoteV4
+	16, // 46: attest.MachineState.uki:type_name -> attest.UkiState
+	18, // 47: attest.MachineState.systemd:type_name -> attest.SystemdState
+	20, // 48: attest.MachineState.ima:type_name -> attest.ImaState
+	23, // 49: attest.MachineState.firmware_inventory:type_name -> attest.FirmwareInventory
+	0,  // 50: attest.PlatformPolicy.minimum_technology:type_name -> attest.GCEConfidentialTechnology
+	38, // 51: attest.SevSnpPolicy.uefi:type_name -> attest.RIMPolicy
+	38, // 52: attest.ReferenceManifestPolicy.signing:type_name -> attest.RIMPolicy
+	37, // 53: attest.Policy.platform:type_name -> attest.PlatformPolicy
+	39, // 54: attest.Policy.sev_snp:type_name -> attest.SevSnpPolicy
+	41, // 55: attest.Policy.ima:type_name -> attest.ImaPolicy
+	40, // 56: attest.Policy.reference_manifests:type_name -> attest.ReferenceManifestPolicy
+	57, // [57:57] is the sub-list for method output_type
+	57, // [57:57] is the sub-list for method input_type
+	57, // [57:57] is the sub-list for extension type_name
+	57, // [57:57] is the sub-list for extension extendee
+	0,  // [0:57] is the sub-list for field type_name
 }
 
 func init() { file_attest_proto_init() }
@@ -2376,7 +4198,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Event); i {
+			switch v := v.(*UnverifiedUkiEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2388,7 +4210,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Certificate); i {
+			switch v := v.(*UkiSection); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2400,7 +4222,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Database); i {
+			switch v := v.(*UkiState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2412,7 +4234,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SecureBootState); i {
+			switch v := v.(*SystemdEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2424,7 +4246,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ContainerState); i {
+			switch v := v.(*SystemdState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2436,7 +4258,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SemanticVersion); i {
+			switch v := v.(*ImaEntry); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2448,7 +4270,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthMonitoringState); i {
+			switch v := v.(*ImaState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2460,7 +4282,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GpuDeviceState); i {
+			switch v := v.(*FirmwareComponent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2472,7 +4294,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AttestedCosState); i {
+			switch v := v.(*BootOption); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2484,7 +4306,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EfiApp); i {
+			switch v := v.(*FirmwareInventory); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2496,7 +4318,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EfiState); i {
+			switch v := v.(*Event); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2508,7 +4330,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MachineState); i {
+			switch v := v.(*Certificate); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2520,7 +4342,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PlatformPolicy); i {
+			switch v := v.(*Database); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2532,7 +4354,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RIMPolicy); i {
+			switch v := v.(*SecureBootState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2544,7 +4366,7 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SevSnpPolicy); i {
+			switch v := v.(*ContainerState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -2556,6 +4378,162 @@ func file_attest_proto_init() {
 			}
 		}
 		file_attest_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContainerMount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SemanticVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthMonitoringState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GpuDeviceState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttestedCosState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EfiApp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EfiState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MachineState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PlatformPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RIMPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SevSnpPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReferenceManifestPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImaPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_attest_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Policy); i {
 			case 0:
 				return &v.state
@@ -2576,12 +4554,12 @@ func file_attest_proto_init() {
 		(*PlatformState_ScrtmVersionId)(nil),
 		(*PlatformState_GceVersion)(nil),
 	}
-	file_attest_proto_msgTypes[7].OneofWrappers = []interface{}{
+	file_attest_proto_msgTypes[17].OneofWrappers = []interface{}{
 		(*Certificate_Der)(nil),
 		(*Certificate_WellKnown)(nil),
 	}
-	file_attest_proto_msgTypes[12].OneofWrappers = []interface{}{}
-	file_attest_proto_msgTypes[17].OneofWrappers = []interface{}{
+	file_attest_proto_msgTypes[23].OneofWrappers = []interface{}{}
+	file_attest_proto_msgTypes[28].OneofWrappers = []interface{}{
 		(*MachineState_SevSnpAttestation)(nil),
 		(*MachineState_TdxAttestation)(nil),
 	}
@@ -2590,8 +4568,8 @@ func file_attest_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_attest_proto_rawDesc,
-			NumEnums:      4,
-			NumMessages:   24,
+			NumEnums:      8,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// EFI device path node types.
+// See the UEFI Specification, Section 10.3 Device Path Nodes.
+const (
+	hardwareDevicePath  = 0x01
+	acpiDevicePath      = 0x02
+	messagingDevicePath = 0x03
+	mediaDevicePath     = 0x04
+	biosBootDevicePath  = 0x05
+	endDevicePath       = 0x7f
+
+	endInstanceSubType = 0x01
+	endEntireSubType   = 0xff
+)
+
+// formatEFIGUID formats a GUID in its mixed-endian EFI_GUID encoding as a
+// canonical string (e.g., "8be4df61-93ca-11d2-aa0d-00e098032b8c").
+func formatEFIGUID(guid []byte) string {
+	if len(guid) != 16 {
+		return hex.EncodeToString(guid)
+	}
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(guid[0:4]),
+		binary.LittleEndian.Uint16(guid[4:6]),
+		binary.LittleEndian.Uint16(guid[6:8]),
+		guid[8:10], guid[10:16])
+}
+
+// parseEFIDevicePaths decodes a list of EFI device path instances into their
+// UEFI text representation (e.g., "PciRoot(0x0)/Pci(0x1,0x0)/HD(...)").
+// Unknown nodes are rendered with the generic "Path(type,subtype,data)"
+// format.
+func parseEFIDevicePaths(data []byte) ([]string, error) {
+	var paths []string
+	var nodes []string
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("truncated device path node: %d bytes", len(data))
+		}
+		nodeType := data[0]
+		subType := data[1]
+		nodeLen := int(binary.LittleEndian.Uint16(data[2:4]))
+		if nodeLen < 4 || nodeLen > len(data) {
+			return nil, fmt.Errorf("invalid device path node length %d", nodeLen)
+		}
+		node := data[4:nodeLen]
+		data = data[nodeLen:]
+
+		if nodeType == endDevicePath {
+			paths = append(paths, strings.Join(nodes, "/"))
+			nodes = nil
+			if subType == endEntireSubType {
+				break
+			}
+			continue
+		}
+		nodes = append(nodes, formatDevicePathNode(nodeType, subType, node))
+	}
+	// Tolerate a missing End of Hardware Device Path node.
+	if len(nodes) > 0 {
+		paths = append(paths, strings.Join(nodes, "/"))
+	}
+	return paths, nil
+}
+
+func formatDevicePathNode(nodeType, subType uint8, node []byte) string {
+	switch {
+	case nodeType == hardwareDevicePath && subType == 0x01 && len(node) == 2:
+		return fmt.Sprintf("Pci(0x%x,0x%x)", node[1], node[0])
+	case nodeType == hardwareDevicePath && subType == 0x03 && len(node) == 20:
+		return fmt.Sprintf("MemoryMapped(0x%x,0x%x,0x%x)", binary.LittleEndian.Uint32(node[0:4]),
+			binary.LittleEndian.Uint64(node[4:12]), binary.LittleEndian.Uint64(node[12:20]))
+	case nodeType == hardwareDevicePath && subType == 0x04 && len(node) >= 16:
+		return formatVendorNode("VenHw", node)
+	case nodeType == acpiDevicePath && subType == 0x01 && len(node) == 8:
+		hid := binary.LittleEndian.Uint32(node[0:4])
+		uid := binary.LittleEndian.Uint32(node[4:8])
+		switch hid {
+		case 0x0a0341d0:
+			return fmt.Sprintf("PciRoot(0x%x)", uid)
+		case 0x0a0841d0:
+			return fmt.Sprintf("PcieRoot(0x%x)", uid)
+		}
+		return fmt.Sprintf("Acpi(0x%x,0x%x)", hid, uid)
+	case nodeType == messagingDevicePath && subType == 0x02 && len(node) == 4:
+		return fmt.Sprintf("Scsi(0x%x,0x%x)", binary.LittleEndian.Uint16(node[0:2]), binary.LittleEndian.Uint16(node[2:4]))
+	case nodeType == messagingDevicePath && subType == 0x05 && len(node) == 2:
+		return fmt.Sprintf("USB(0x%x,0x%x)", node[0], node[1])
+	case nodeType == messagingDevicePath && subType == 0x0a && len(node) >= 16:
+		return formatVendorNode("VenMsg", node)
+	case nodeType == messagingDevicePath && subType == 0x0b && len(node) == 33:
+		return fmt.Sprintf("MAC(%x,0x%x)", node[0:6], node[32])
+	case nodeType == messagingDevicePath && subType == 0x0c && len(node) == 23:
+		mode := "DHCP"
+		if node[14] != 0 {
+			mode = "Static"
+		}
+		return fmt.Sprintf("IPv4(%s,0x%x,%s,%s,%s,%s)", net.IP(node[4:8]), binary.LittleEndian.Uint16(node[12:14]),
+			mode, net.IP(node[0:4]), net.IP(node[15:19]), net.IP(node[19:23]))
+	case nodeType == messagingDevicePath && subType == 0x12 && len(node) == 6:
+		return fmt.Sprintf("Sata(0x%x,0x%x,0x%x)", binary.LittleEndian.Uint16(node[0:2]),
+			binary.LittleEndian.Uint16(node[2:4]), binary.LittleEndian.Uint16(node[4:6]))
+	case nodeType == messagingDevicePath && subType == 0x17 && len(node) == 12:
+		return fmt.Sprintf("NVMe(0x%x,%s)", binary.LittleEndian.Uint32(node[0:4]), formatEUI64(node[4:12]))
+	case nodeType == messagingDevicePath && subType == 0x18:
+		return fmt.Sprintf("Uri(%s)", node)
+	case nodeType == mediaDevicePath && subType == 0x01 && len(node) == 38:
+		return formatHardDriveNode(node)
+	case nodeType == mediaDevicePath && subType == 0x02 && len(node) == 20:
+		return fmt.Sprintf("CDROM(0x%x,0x%x,0x%x)", binary.LittleEndian.Uint32(node[0:4]),
+			binary.LittleEndian.Uint64(node[4:12]), binary.LittleEndian.Uint64(node[12:20]))
+	case nodeType == mediaDevicePath && subType == 0x03 && len(node) >= 16:
+		return formatVendorNode("VenMedia", node)
+	case nodeType == mediaDevicePath && subType == 0x04:
+		if path, err := decodeUTF16LE(node); err == nil {
+			return path
+		}
+	case nodeType == mediaDevicePath && subType == 0x06 && len(node) == 16:
+		return fmt.Sprintf("FvFile(%s)", formatEFIGUID(node))
+	case nodeType == mediaDevicePath && subType == 0x07 && len(node) == 16:
+		return fmt.Sprintf("Fv(%s)", formatEFIGUID(node))
+	case nodeType == mediaDevicePath && subType == 0x08 && len(node) == 20:
+		return fmt.Sprintf("Offset(0x%x,0x%x)", binary.LittleEndian.Uint64(node[4:12]), binary.LittleEndian.Uint64(node[12:20]))
+	case nodeType == biosBootDevicePath && subType == 0x01 && len(node) >= 4:
+		return fmt.Sprintf("BBS(0x%x,%s,0x%x)", binary.LittleEndian.Uint16(node[0:2]),
+			strings.TrimRight(string(node[4:]), "\x00"), binary.LittleEndian.Uint16(node[2:4]))
+	}
+	return fmt.Sprintf("Path(%d,%d,%x)", nodeType, subType, node)
+}
+
+func formatVendorNode(name string, node []byte) string {
+	if len(node) == 16 {
+		return fmt.Sprintf("%s(%s)", name, formatEFIGUID(node))
+	}
+	return fmt.Sprintf("%s(%s,%x)", name, formatEFIGUID(node[:16]), node[16:])
+}
+
+func formatEUI64(eui []byte) string {
+	parts := make([]string, len(eui))
+	for i, b := range eui {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, "-")
+}
+
+// formatHardDriveNode formats a Hard Drive Media Device Path node.
+func formatHardDriveNode(node []byte) string {
+	partition := binary.LittleEndian.Uint32(node[0:4])
+	start := binary.LittleEndian.Uint64(node[4:12])
+	size := binary.LittleEndian.Uint64(node[12:20])
+	signature := node[20:36]
+	// node[36] is the partition format, node[37] is the signature type.
+	switch node[37] {
+	case 0x01:
+		return fmt.Sprintf("HD(%d,MBR,0x%08x,0x%x,0x%x)", partition, binary.LittleEndian.Uint32(signature[0:4]), start, size)
+	case 0x02:
+		return fmt.Sprintf("HD(%d,GPT,%s,0x%x,0x%x)", partition, formatEFIGUID(signature), start, size)
+	}
+	return fmt.Sprintf("HD(%d,%d,0,0x%x,0x%x)", partition, node[37], start, size)
+}
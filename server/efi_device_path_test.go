@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// devicePathNode encodes an EFI device path node.
+func devicePathNode(nodeType, subType uint8, data ...[]byte) []byte {
+	node := []byte{nodeType, subType, 0, 0}
+	for _, d := range data {
+		node = append(node, d...)
+	}
+	binary.LittleEndian.PutUint16(node[2:4], uint16(len(node)))
+	return node
+}
+
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func le64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func TestParseEFIDevicePaths(t *testing.T) {
+	fvGUID := decodeHex("c9bdb87cebf8344faaea3ee4af6516a1")
+	partGUID := decodeHex("e1c9ec8624fbe6478b51005a5aded596")
+	endInstance := devicePathNode(endDevicePath, endInstanceSubType)
+	endEntire := devicePathNode(endDevicePath, endEntireSubType)
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{
+			name: "NVMeHardDriveFile",
+			data: bytes.Join([][]byte{
+				devicePathNode(acpiDevicePath, 0x01, le32(0x0a0341d0), le32(0)),
+				devicePathNode(hardwareDevicePath, 0x01, []byte{0x0, 0x4}),
+				devicePathNode(messagingDevicePath, 0x17, le32(1), make([]byte, 8)),
+				devicePathNode(mediaDevicePath, 0x01, le32(15), le64(0x2800), le64(0x35000), partGUID, []byte{0x02, 0x02}),
+				devicePathNode(mediaDevicePath, 0x04, utf16Bytes(`\EFI\ubuntu\shimx64.efi`)),
+				endEntire,
+			}, nil),
+			want: []string{`PciRoot(0x0)/Pci(0x4,0x0)/NVMe(0x1,00-00-00-00-00-00-00-00)/HD(15,GPT,86ecc9e1-fb24-47e6-8b51-005a5aded596,0x2800,0x35000)/\EFI\ubuntu\shimx64.efi`},
+		},
+		{
+			name: "MultipleInstances",
+			data: bytes.Join([][]byte{
+				devicePathNode(mediaDevicePath, 0x08, le32(0), le64(0xf250), le64(0x201ff)),
+				endInstance,
+				devicePathNode(mediaDevicePath, 0x07, fvGUID),
+				endEntire,
+			}, nil),
+			want: []string{"Offset(0xf250,0x201ff)", "Fv(7cb8bdc9-f8eb-4f34-aaea-3ee4af6516a1)"},
+		},
+		{
+			name: "MissingEnd",
+			data: devicePathNode(messagingDevicePath, 0x05, []byte{0x1, 0x0}),
+			want: []string{"USB(0x1,0x0)"},
+		},
+		{
+			name: "UnknownNode",
+			data: append(devicePathNode(messagingDevicePath, 0x63, []byte{0x01}), endEntire...),
+			want: []string{"Path(3,99,01)"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseEFIDevicePaths(tc.data)
+			if err != nil {
+				t.Fatalf("parseEFIDevicePaths() got err = %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseEFIDevicePaths() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseEFIDevicePathsInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{0x01, 0x01},
+		{0x01, 0x01, 0x02, 0x00},
+		{0x01, 0x01, 0x10, 0x00, 0x00},
+	} {
+		if _, err := parseEFIDevicePaths(data); err == nil {
+			t.Errorf("parseEFIDevicePaths(%x) got nil error, want error", data)
+		}
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/x509"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
+	"unicode/utf16"
 
 	"github.com/google/go-attestation/attest"
 	"github.com/google/go-eventlog/register"
@@ -61,14 +63,22 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 	if err != nil {
 		errors = append(errors, err)
 	}
+	if sbState != nil {
+		if err := addShimState(rawEvents, sbState); err != nil {
+			errors = append(errors, err)
+		}
+	}
 	efiState, err := getEfiState(cryptoHash, rawEvents)
 	if err != nil {
 		errors = append(errors, err)
 	}
+	firmware := getFirmwareInventory(cryptoHash, rawEvents)
 
 	var grub *pb.GrubState
+	var uki *pb.UkiState
 	var kernel *pb.LinuxKernelState
-	if loader == GRUB {
+	switch loader {
+	case GRUB:
 		grub, err = getGrubState(cryptoHash, rawEvents)
 		if err != nil {
 			errors = append(errors, err)
@@ -77,33 +87,57 @@ func parsePCClientEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs, loader Bootload
 		if err != nil {
 			errors = append(errors, err)
 		}
+	case SystemdBoot:
+		uki, err = getUkiState(cryptoHash, rawEvents)
+		if err != nil {
+			errors = append(errors, err)
+		}
+		kernel, err = getLinuxKernelStateFromUKI(cryptoHash, rawEvents)
+		if err != nil {
+			errors = append(errors, err)
+		}
 	}
 
 	return &pb.MachineState{
-		Platform:    platform,
-		SecureBoot:  sbState,
-		Efi:         efiState,
-		RawEvents:   rawEvents,
-		Hash:        pcrs.GetHash(),
-		Grub:        grub,
-		LinuxKernel: kernel,
+		Platform:          platform,
+		SecureBoot:        sbState,
+		Efi:               efiState,
+		RawEvents:         rawEvents,
+		Hash:              pcrs.GetHash(),
+		Grub:              grub,
+		LinuxKernel:       kernel,
+		Uki:               uki,
+		FirmwareInventory: firmware,
 	}, createGroupedError("failed to fully parse MachineState:", errors)
 }
 
 // ParseCosCELPCR takes an encoded COS CEL and PCR bank, replays the CEL against the PCRs,
 // and returns the AttestedCosState
 func ParseCosCELPCR(cosEventLog []byte, p register.PCRBank) (*pb.AttestedCosState, error) {
-	return getCosStateFromCEL(cosEventLog, p, cel.PCRTypeValue)
+	return ParseCosCELPCRWithLimits(cosEventLog, p, cel.DecodeLimits{})
+}
+
+// ParseCosCELPCRWithLimits is like ParseCosCELPCR, but decodes the CEL within
+// the given limits. Use it with cel.DefaultDecodeLimits() for untrusted input.
+func ParseCosCELPCRWithLimits(cosEventLog []byte, p register.PCRBank, limits cel.DecodeLimits) (*pb.AttestedCosState, error) {
+	return getCosStateFromCEL(cosEventLog, p, cel.PCRTypeValue, limits)
 }
 
 // ParseCosCELRTMR takes in a raw COS CEL and a RTMR bank, validates and returns it's
 // COS states as parts of the MachineState.
 func ParseCosCELRTMR(cosEventLog []byte, r register.RTMRBank) (*pb.AttestedCosState, error) {
-	return getCosStateFromCEL(cosEventLog, r, cel.CCMRTypeValue)
+	return ParseCosCELRTMRWithLimits(cosEventLog, r, cel.DecodeLimits{})
 }
 
-func getCosStateFromCEL(rawCanonicalEventLog []byte, register register.MRBank, trustingRegisterType uint8) (*pb.AttestedCosState, error) {
-	decodedCEL, err := cel.DecodeToCEL(bytes.NewBuffer(rawCanonicalEventLog))
+// ParseCosCELRTMRWithLimits is like ParseCosCELRTMR, but decodes the CEL
+// within the given limits. Use it with cel.DefaultDecodeLimits() for untrusted
+// input.
+func ParseCosCELRTMRWithLimits(cosEventLog []byte, r register.RTMRBank, limits cel.DecodeLimits) (*pb.AttestedCosState, error) {
+	return getCosStateFromCEL(cosEventLog, r, cel.CCMRTypeValue, limits)
+}
+
+func getCosStateFromCEL(rawCanonicalEventLog []byte, register register.MRBank, trustingRegisterType uint8, limits cel.DecodeLimits) (*pb.AttestedCosState, error) {
+	decodedCEL, err := cel.DecodeToCELWithLimits(bytes.NewReader(rawCanonicalEventLog), limits)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +154,15 @@ func getCosStateFromCEL(rawCanonicalEventLog []byte, register register.MRBank, t
 	return cosState, err
 }
 
+func toContainerMount(mount cel.ContainerMount) *pb.ContainerMount {
+	return &pb.ContainerMount{
+		MountType:   mount.Type,
+		Source:      mount.Source,
+		Destination: mount.Destination,
+		Options:     mount.Options,
+	}
+}
+
 func contains(set [][]byte, value []byte) bool {
 	for _, setItem := range set {
 		if bytes.Equal(value, setItem) {
@@ -139,9 +182,20 @@ func getVerifiedCosState(coscel cel.CEL, registerType uint8) (*pb.AttestedCosSta
 	cosState.Container.Args = make([]string, 0)
 	cosState.Container.EnvVars = make(map[string]string)
 	cosState.Container.OverriddenEnvVars = make(map[string]string)
+	cosState.Containers = []*pb.ContainerState{cosState.Container}
 
+	// The container described by the container events, which is the main
+	// container until the first Sidecar or InitContainer event.
+	container := cosState.Container
 	seenSeparator := false
+	seenCgroupNamespace := false
+	seenAttestationAccess := make(map[*pb.ContainerState]bool)
 	for _, record := range coscel.Records {
+		// CEL management records (e.g., the CEL version) are not measured, so
+		// they cannot be trusted and are ignored.
+		if record.Content.Type == cel.CelMgtType {
+			continue
+		}
 		if record.IndexType != registerType {
 			return nil, fmt.Errorf("expect registerType: %d, but get %d in a CEL record", registerType, record.IndexType)
 		}
@@ -174,6 +228,21 @@ func getVerifiedCosState(coscel cel.CEL, registerType uint8) (*pb.AttestedCosSta
 			return nil, err
 		}
 
+		// The launcher measures the workload shutdown after the separator.
+		if cosTlv.EventType == cel.ShutdownType {
+			if !seenSeparator {
+				return nil, fmt.Errorf("found Shutdown event before LaunchSeparator event")
+			}
+			if cosState.GetShutdownSignal() != "" {
+				return nil, fmt.Errorf("found more than one Shutdown event")
+			}
+			if len(cosTlv.EventContent) == 0 {
+				return nil, fmt.Errorf("found Shutdown event without a signal")
+			}
+			cosState.ShutdownSignal = string(cosTlv.EventContent)
+			continue
+		}
+
 		// TODO: Add support for post-separator container data
 		if seenSeparator {
 			return nil, fmt.Errorf("found COS Event Type %v after LaunchSeparator event", cosTlv.EventType)
@@ -181,49 +250,52 @@ func getVerifiedCosState(coscel cel.CEL, registerType uint8) (*pb.AttestedCosSta
 
 		switch cosTlv.EventType {
 		case cel.ImageRefType:
-			if cosState.Container.GetImageReference() != "" {
+			if container.GetImageReference() != "" {
 				return nil, fmt.Errorf("found more than one ImageRef event")
 			}
-			cosState.Container.ImageReference = string(cosTlv.EventContent)
+			container.ImageReference = string(cosTlv.EventContent)
 
 		case cel.ImageDigestType:
-			if cosState.Container.GetImageDigest() != "" {
+			if container.GetImageDigest() != "" {
 				return nil, fmt.Errorf("found more than one ImageDigest event")
 			}
-			cosState.Container.ImageDigest = string(cosTlv.EventContent)
+			container.ImageDigest = string(cosTlv.EventContent)
 
 		case cel.RestartPolicyType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found RestartPolicy event for container %q", container.GetName())
+			}
 			restartPolicy, ok := pb.RestartPolicy_value[string(cosTlv.EventContent)]
 			if !ok {
 				return nil, fmt.Errorf("unknown restart policy in COS eventlog: %s", string(cosTlv.EventContent))
 			}
-			cosState.Container.RestartPolicy = pb.RestartPolicy(restartPolicy)
+			container.RestartPolicy = pb.RestartPolicy(restartPolicy)
 
 		case cel.ImageIDType:
-			if cosState.Container.GetImageId() != "" {
+			if container.GetImageId() != "" {
 				return nil, fmt.Errorf("found more than one ImageId event")
 			}
-			cosState.Container.ImageId = string(cosTlv.EventContent)
+			container.ImageId = string(cosTlv.EventContent)
 
 		case cel.EnvVarType:
 			envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
 			if err != nil {
 				return nil, err
 			}
-			cosState.Container.EnvVars[envName] = envVal
+			container.EnvVars[envName] = envVal
 
 		case cel.ArgType:
-			cosState.Container.Args = append(cosState.Container.Args, string(cosTlv.EventContent))
+			container.Args = append(container.Args, string(cosTlv.EventContent))
 
 		case cel.OverrideArgType:
-			cosState.Container.OverriddenArgs = append(cosState.Container.OverriddenArgs, string(cosTlv.EventContent))
+			container.OverriddenArgs = append(container.OverriddenArgs, string(cosTlv.EventContent))
 
 		case cel.OverrideEnvType:
 			envName, envVal, err := cel.ParseEnvVar(string(cosTlv.EventContent))
 			if err != nil {
 				return nil, err
 			}
-			cosState.Container.OverriddenEnvVars[envName] = envVal
+			container.OverriddenEnvVars[envName] = envVal
 		case cel.LaunchSeparatorType:
 			seenSeparator = true
 		case cel.MemoryMonitorType:
@@ -238,6 +310,102 @@ func getVerifiedCosState(coscel cel.CEL, registerType uint8) (*pb.AttestedCosSta
 				return nil, fmt.Errorf("unknown GPU device CC mode in COS eventlog: %s", string(cosTlv.EventContent))
 			}
 			cosState.GpuDeviceState.CcMode = pb.GPUDeviceCCMode(ccMode)
+		case cel.AddedCapabilityType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found AddedCapability event for container %q", container.GetName())
+			}
+			cosState.Container.AddedCapabilities = append(cosState.Container.AddedCapabilities, string(cosTlv.EventContent))
+		case cel.CgroupNamespaceType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found CgroupNamespace event for container %q", container.GetName())
+			}
+			if seenCgroupNamespace {
+				return nil, fmt.Errorf("found more than one CgroupNamespace event")
+			}
+			seenCgroupNamespace = true
+			if len(cosTlv.EventContent) != 1 || cosTlv.EventContent[0] > 1 {
+				return nil, fmt.Errorf("invalid CgroupNamespace event content in COS eventlog: %v", cosTlv.EventContent)
+			}
+			cosState.Container.CgroupNamespace = cosTlv.EventContent[0] == 1
+		case cel.MountType:
+			mount, err := cel.ParseContainerMount(string(cosTlv.EventContent))
+			if err != nil {
+				return nil, err
+			}
+			container.Mounts = append(container.Mounts, toContainerMount(mount))
+		case cel.LogRedirectType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found LogRedirect event for container %q", container.GetName())
+			}
+			if cosState.Container.GetLogRedirect() != pb.LogRedirectLocation_LOG_REDIRECT_UNSPECIFIED {
+				return nil, fmt.Errorf("found more than one LogRedirect event")
+			}
+			location, ok := pb.LogRedirectLocation_value[string(cosTlv.EventContent)]
+			if !ok || location == int32(pb.LogRedirectLocation_LOG_REDIRECT_UNSPECIFIED) {
+				return nil, fmt.Errorf("unknown log redirect location in COS eventlog: %s", string(cosTlv.EventContent))
+			}
+			cosState.Container.LogRedirect = pb.LogRedirectLocation(location)
+		case cel.ImpersonatedServiceAccountType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found ImpersonatedServiceAccount event for container %q", container.GetName())
+			}
+			cosState.Container.ImpersonatedServiceAccounts = append(cosState.Container.ImpersonatedServiceAccounts, string(cosTlv.EventContent))
+		case cel.HardenedImageType:
+			if cosState.GetLauncherImageType() != pb.LauncherImageType_LAUNCHER_IMAGE_UNSPECIFIED {
+				return nil, fmt.Errorf("found more than one HardenedImage event")
+			}
+			if len(cosTlv.EventContent) != 1 || cosTlv.EventContent[0] > 1 {
+				return nil, fmt.Errorf("invalid HardenedImage event content in COS eventlog: %v", cosTlv.EventContent)
+			}
+			cosState.LauncherImageType = pb.LauncherImageType_LAUNCHER_IMAGE_DEBUG
+			if cosTlv.EventContent[0] == 1 {
+				cosState.LauncherImageType = pb.LauncherImageType_LAUNCHER_IMAGE_HARDENED
+			}
+		case cel.LocalVerifierKeyType:
+			if container != cosState.Container {
+				return nil, fmt.Errorf("found LocalVerifierKey event for container %q", container.GetName())
+			}
+			if len(cosState.GetLocalVerifierKey()) != 0 {
+				return nil, fmt.Errorf("found more than one LocalVerifierKey event")
+			}
+			if _, err := x509.ParsePKIXPublicKey(cosTlv.EventContent); err != nil {
+				return nil, fmt.Errorf("invalid LocalVerifierKey event content in COS eventlog: %v", err)
+			}
+			cosState.LocalVerifierKey = cosTlv.EventContent
+		case cel.AttestationAccessType:
+			if container == cosState.Container {
+				return nil, fmt.Errorf("found AttestationAccess event for the main container")
+			}
+			if seenAttestationAccess[container] {
+				return nil, fmt.Errorf("found more than one AttestationAccess event for container %q", container.GetName())
+			}
+			seenAttestationAccess[container] = true
+			if len(cosTlv.EventContent) != 1 || cosTlv.EventContent[0] > 1 {
+				return nil, fmt.Errorf("invalid AttestationAccess event content in COS eventlog: %v", cosTlv.EventContent)
+			}
+			container.AttestationAccess = cosTlv.EventContent[0] == 1
+		case cel.SidecarType, cel.InitContainerType:
+			name := string(cosTlv.EventContent)
+			if name == "" {
+				return nil, fmt.Errorf("found %v event without a name", cosTlv.EventType)
+			}
+			for _, c := range cosState.Containers {
+				if c.GetName() == name {
+					return nil, fmt.Errorf("found more than one container named %q", name)
+				}
+			}
+			role := pb.ContainerRole_CONTAINER_ROLE_SIDECAR
+			if cosTlv.EventType == cel.InitContainerType {
+				role = pb.ContainerRole_CONTAINER_ROLE_INIT
+			}
+			container = &pb.ContainerState{
+				Name:              name,
+				Role:              role,
+				Args:              make([]string, 0),
+				EnvVars:           make(map[string]string),
+				OverriddenEnvVars: make(map[string]string),
+			}
+			cosState.Containers = append(cosState.Containers, container)
 
 		default:
 			return nil, fmt.Errorf("found unknown COS Event Type %v", cosTlv.EventType)
@@ -348,7 +516,8 @@ func getPlatformState(hash crypto.Hash, events []*pb.Event) (*pb.PlatformState,
 }
 
 // Separate helper function so we can use attest.ParseSecurebootState without
-// needing to reparse the entire event log.
+// needing to reparse the entire event log. The caller checks the log against
+// its limits first, with checkEventLogLimits.
 func parseReplayHelper(rawEventLog []byte, pcrs *tpmpb.PCRs) ([]attest.Event, error) {
 	// Similar to ParseCosCanonicalEventLogPCR, just return an empty array of events for an empty log
 	if len(rawEventLog) == 0 {
@@ -370,6 +539,105 @@ func parseReplayHelper(rawEventLog []byte, pcrs *tpmpb.PCRs) ([]attest.Event, er
 	return events, nil
 }
 
+// checkLogTotalSize returns an error if a raw log is larger than the limit.
+func checkLogTotalSize(rawLog []byte, limits cel.DecodeLimits) error {
+	if limits.MaxTotalSize > 0 && int64(len(rawLog)) > limits.MaxTotalSize {
+		return fmt.Errorf("log size %d exceeds the limit of %d bytes", len(rawLog), limits.MaxTotalSize)
+	}
+	return nil
+}
+
+// checkLogRecordLimits returns an error if the record with the given index
+// and encoded size exceeds the limits.
+func checkLogRecordLimits(index int, size int, limits cel.DecodeLimits) error {
+	if limits.MaxRecords > 0 && index >= limits.MaxRecords {
+		return fmt.Errorf("log has more than the limit of %d records", limits.MaxRecords)
+	}
+	if limits.MaxRecordSize > 0 && size > limits.MaxRecordSize {
+		return fmt.Errorf("record #%d of %d bytes exceeds the limit of %d bytes", index, size, limits.MaxRecordSize)
+	}
+	return nil
+}
+
+// checkEventLogLimits walks the event headers of a raw TCG event log, without
+// allocating memory for the events, and returns an error if the log has more
+// events, or larger events, than the limits allow. This runs before the log is
+// given to the event log parser, which has no limits of its own.
+//
+// Structural errors (e.g., a truncated event) are left to the parser.
+func checkEventLogLimits(rawEventLog []byte, limits cel.DecodeLimits) error {
+	if limits.MaxTotalSize > 0 && int64(len(rawEventLog)) > limits.MaxTotalSize {
+		return fmt.Errorf("event log size %d exceeds the limit of %d bytes", len(rawEventLog), limits.MaxTotalSize)
+	}
+	// The first event always uses the TPM 1.2 (SHA-1 only) format. If it is a
+	// Spec ID Event, the remaining events use the crypto agile format, with
+	// the digest sizes listed in the Spec ID Event.
+	var digestSizes map[uint16]int
+	for offset, count := 0, 0; offset < len(rawEventLog); count++ {
+		if limits.MaxRecords > 0 && count >= limits.MaxRecords {
+			return fmt.Errorf("event log has more than the limit of %d events", limits.MaxRecords)
+		}
+		start := offset
+		// PCR index and event type.
+		offset += 8
+		if digestSizes == nil {
+			offset += crypto.SHA1.Size()
+		} else {
+			if offset+4 > len(rawEventLog) {
+				return nil
+			}
+			numDigests := binary.LittleEndian.Uint32(rawEventLog[offset:])
+			offset += 4
+			for i := uint32(0); i < numDigests; i++ {
+				if offset+2 > len(rawEventLog) {
+					return nil
+				}
+				size, ok := digestSizes[binary.LittleEndian.Uint16(rawEventLog[offset:])]
+				if !ok {
+					return nil
+				}
+				offset += 2 + size
+			}
+		}
+		if offset+4 > len(rawEventLog) {
+			return nil
+		}
+		eventSize := int(binary.LittleEndian.Uint32(rawEventLog[offset:]))
+		offset += 4
+		if limits.MaxRecordSize > 0 && offset-start+eventSize > limits.MaxRecordSize {
+			return fmt.Errorf("event #%d size exceeds the limit of %d bytes", count, limits.MaxRecordSize)
+		}
+		if eventSize > len(rawEventLog)-offset {
+			return nil
+		}
+		if count == 0 {
+			digestSizes = parseSpecIDEventDigestSizes(rawEventLog[offset : offset+eventSize])
+		}
+		offset += eventSize
+	}
+	return nil
+}
+
+// parseSpecIDEventDigestSizes returns the digest sizes listed in a
+// TCG_EfiSpecIDEvent, or nil if the data is not a Spec ID Event.
+func parseSpecIDEventDigestSizes(data []byte) map[uint16]int {
+	// Signature, platform class, version, errata and uintn size.
+	const headerSize = 16 + 4 + 3 + 1
+	if len(data) < headerSize+4 || !bytes.HasPrefix(data, []byte("Spec ID Event03\x00")) {
+		return nil
+	}
+	numAlgs := binary.LittleEndian.Uint32(data[headerSize:])
+	algs := data[headerSize+4:]
+	if uint64(numAlgs)*4 > uint64(len(algs)) {
+		return nil
+	}
+	sizes := make(map[uint16]int, numAlgs)
+	for i := 0; i < int(numAlgs); i++ {
+		sizes[binary.LittleEndian.Uint16(algs[4*i:])] = int(binary.LittleEndian.Uint16(algs[4*i+2:]))
+	}
+	return sizes
+}
+
 func convertToAttestPcrs(pcrProto *tpmpb.PCRs) ([]attest.PCR, error) {
 	hash := tpm2.Algorithm(pcrProto.GetHash())
 	cryptoHash, err := hash.Hash()
@@ -458,6 +726,114 @@ func getSecureBootState(attestEvents []attest.Event) (*pb.SecureBootState, error
 	}, nil
 }
 
+// addShimState adds the shim Machine Owner Key (MOK) and SBAT state to the
+// SecureBootState. shim logs the MokList and MokListX variables to PCR14 as
+// EV_IPL events whose data is the variable name, and measures MokListTrusted
+// and SbatLevel to PCR7 as EV_EFI_VARIABLE_AUTHORITY events. When verifying an
+// image, shim also measures the authority it used to PCR7, using the name of
+// the variable the certificate came from: MokListRT, the runtime copy of
+// MokList, for a MOK.
+// See https://github.com/rhboot/shim/blob/main/MokVars.txt.
+func addShimState(events []*pb.Event, sbState *pb.SecureBootState) error {
+	for idx, event := range events {
+		switch event.GetPcrIndex() {
+		case 14:
+			if event.GetUntrustedType() != IPL {
+				continue
+			}
+			// The variable contents are not logged, so the digest cannot be
+			// verified against the data.
+			switch string(bytes.TrimRight(event.GetData(), "\x00")) {
+			case "MokList":
+				sbState.MokListDigest = event.GetDigest()
+			case "MokListX":
+				sbState.MokListXDigest = event.GetDigest()
+			}
+		case 7:
+			if event.GetUntrustedType() != EFIVariableAuthority {
+				continue
+			}
+			guid, name, value, err := parseUEFIVariableData(event.GetData())
+			if err != nil {
+				return fmt.Errorf("invalid EV_EFI_VARIABLE_AUTHORITY event #%d: %v", idx, err)
+			}
+			if !isShimVariable(guid, name) {
+				continue
+			}
+			// Some firmware and older versions of shim do not measure the
+			// entire UEFI_VARIABLE_DATA (see
+			// https://github.com/rhboot/shim/commit/8a27a4809a6a2b40fb6a4049071bf96d6ad71b50).
+			// The data of such events cannot be trusted, so ignore them.
+			if !event.GetDigestVerified() {
+				continue
+			}
+			switch {
+			case guid == shimLockGUID && name == "SbatLevel":
+				sbState.SbatLevel = string(bytes.TrimRight(value, "\x00"))
+			case guid == shimLockGUID && name == "MokListTrusted":
+				sbState.MokListTrusted = value
+			case guid == shimLockGUID && name == "MokListRT":
+				sbState.KernelAuthoritySource = pb.ShimAuthoritySource_AUTHORITY_SOURCE_MOK
+			case guid == shimLockGUID && (name == "Shim" || name == "vendor_db"):
+				sbState.KernelAuthoritySource = pb.ShimAuthoritySource_AUTHORITY_SOURCE_SHIM_VENDOR
+			case guid == imageSecurityDatabaseGUID && name == "db":
+				sbState.KernelAuthoritySource = pb.ShimAuthoritySource_AUTHORITY_SOURCE_DB
+			}
+		}
+	}
+	return nil
+}
+
+// isShimVariable returns whether the EFI variable is one addShimState
+// interprets.
+func isShimVariable(guid [16]byte, name string) bool {
+	switch guid {
+	case shimLockGUID:
+		switch name {
+		case "SbatLevel", "MokListTrusted", "MokListRT", "Shim", "vendor_db":
+			return true
+		}
+	case imageSecurityDatabaseGUID:
+		return name == "db"
+	}
+	return false
+}
+
+var (
+	// shim's SHIM_LOCK_GUID (605dab50-e046-4300-abb6-3dd810dd8b23), in the
+	// mixed-endian EFI_GUID encoding.
+	shimLockGUID = [16]byte{0x50, 0xab, 0x5d, 0x60, 0x46, 0xe0, 0x00, 0x43,
+		0xab, 0xb6, 0x3d, 0xd8, 0x10, 0xdd, 0x8b, 0x23}
+	// EFI_IMAGE_SECURITY_DATABASE_GUID (d719b2cb-3d3a-4596-a3bc-dad00e67656f),
+	// used by the db and dbx variables.
+	imageSecurityDatabaseGUID = [16]byte{0xcb, 0xb2, 0x19, 0xd7, 0x3a, 0x3d, 0x96, 0x45,
+		0xa3, 0xbc, 0xda, 0xd0, 0x0e, 0x67, 0x65, 0x6f}
+)
+
+// parseUEFIVariableData parses a UEFI_VARIABLE_DATA structure, as defined in
+// the TCG PC Client Platform Firmware Profile Specification.
+func parseUEFIVariableData(data []byte) (guid [16]byte, name string, value []byte, err error) {
+	const headerSize = 16 + 8 + 8
+	if len(data) < headerSize {
+		return guid, "", nil, fmt.Errorf("UEFI_VARIABLE_DATA too short: %d bytes", len(data))
+	}
+	copy(guid[:], data[:16])
+	nameLen := binary.LittleEndian.Uint64(data[16:24])
+	valueLen := binary.LittleEndian.Uint64(data[24:32])
+	// Some firmware appends padding after the variable data, so only check
+	// that the lengths fit.
+	rest := uint64(len(data) - headerSize)
+	if nameLen > rest/2 || valueLen > rest-2*nameLen {
+		return guid, "", nil, fmt.Errorf("UEFI_VARIABLE_DATA lengths (name %d, data %d) exceed size %d", nameLen, valueLen, len(data))
+	}
+	name, err = decodeUTF16LE(data[headerSize : headerSize+2*nameLen])
+	if err != nil {
+		return guid, "", nil, err
+	}
+	valueStart := headerSize + 2*nameLen
+	return guid, name, data[valueStart : valueStart+valueLen], nil
+}
+
 func getGrubState(hash crypto.Hash, events []*pb.Event) (*pb.GrubState, error) {
 	var files []*pb.GrubFile
 	var commands []string
@@ -675,3 +1051,178 @@ func getGrubKernelCmdlineSuffix(grubCmd []byte) int {
 	}
 	return -1
 }
+
+// getUkiState extracts the state of a Unified Kernel Image booted through
+// systemd-stub. The stub measures each PE section into PCR11 as a pair of
+// EV_IPL events: the first event measures the (null-terminated) section name,
+// and the second measures the section contents under the same description.
+// See https://github.com/systemd/systemd/blob/main/src/boot/measure.c.
+func getUkiState(hash crypto.Hash, events []*pb.Event) (*pb.UkiState, error) {
+	hasher := hash.New()
+	state := &pb.UkiState{}
+	var lastBootApp []byte
+	pendingSection := ""
+	for idx, event := range events {
+		index := event.GetPcrIndex()
+		evtType := event.GetUntrustedType()
+		switch index {
+		case 4:
+			// The UKI image is the last EFI application measured before
+			// systemd-stub starts measuring its sections.
+			if evtType == EFIBootServicesApplication && len(state.GetSections()) == 0 && pendingSection == "" {
+				lastBootApp = event.GetDigest()
+			}
+		case 11:
+			if evtType != IPL {
+				continue
+			}
+			name, err := decodeUkiDescription(event.GetData())
+			if err != nil {
+				return nil, fmt.Errorf("invalid UKI section event #%d: %v", idx, err)
+			}
+			if pendingSection == "" {
+				if err := verifyNullTerminatedDataDigest(hasher, append([]byte(name), '\x00'), event.GetDigest()); err != nil {
+					return nil, fmt.Errorf("invalid UKI section name event #%d: %v", idx, err)
+				}
+				pendingSection = name
+				continue
+			}
+			if name != pendingSection {
+				return nil, fmt.Errorf("UKI section event #%d for %q does not follow its name event (got %q)", idx, name, pendingSection)
+			}
+			pendingSection = ""
+			state.Sections = append(state.Sections, &pb.UkiSection{Name: name, Digest: event.GetDigest()})
+			switch name {
+			case ".linux":
+				state.LinuxDigest = event.GetDigest()
+			case ".initrd":
+				state.InitrdDigest = event.GetDigest()
+			case ".cmdline":
+				state.CmdlineDigest = event.GetDigest()
+			case ".osrel":
+				state.OsrelDigest = event.GetDigest()
+			}
+		}
+	}
+	if pendingSection != "" {
+		return nil, fmt.Errorf("UKI section %q is missing its contents measurement", pendingSection)
+	}
+	if len(state.GetSections()) == 0 {
+		return nil, errors.New("no UKI measurements found")
+	}
+	if state.GetLinuxDigest() == nil {
+		return nil, errors.New("no UKI .linux section measurement found")
+	}
+	state.ImageDigest = lastBootApp
+	return state, nil
+}
+
+// getLinuxKernelStateFromUKI returns the kernel command line measured by
+// systemd-stub into PCR12. The stub only measures the command line when the
+// one embedded in the .cmdline section is overridden by the boot loader,
+// either as an EV_IPL event or as an EV_EVENT_TAG event with the
+// LOAD_OPTIONS_EVENT_TAG_ID tag. In both cases, the UTF-16 command line is
+// hashed directly. The events of the credential and system extension archives
+// are reported as UnverifiedUkiEvents.
+func getLinuxKernelStateFromUKI(hash crypto.Hash, events []*pb.Event) (*pb.LinuxKernelState, error) {
+	hasher := hash.New()
+	state := &pb.LinuxKernelState{}
+	seen := false
+	for idx, event := range events {
+		if event.GetPcrIndex() != 12 {
+			continue
+		}
+		var data []byte
+		switch event.GetUntrustedType() {
+		case IPL:
+			// The credentials and system extensions are measured with a
+			// description, which does not match the digest of their cpio
+			// archive. As nothing verifies the description, these events are
+			// reported as unverified. Any other event is a command line.
+			if desc, err := decodeUTF16LE(event.GetData()); err == nil && ukiCpioDescriptions[desc] {
+				state.UnverifiedUkiEvents = append(state.UnverifiedUkiEvents, &pb.UnverifiedUkiEvent{
+					UntrustedDescription: desc,
+					Digest:               event.GetDigest(),
+				})
+				continue
+			}
+			data = event.GetData()
+		case EventTag:
+			id, tagData, err := parseTaggedEvent(event.GetData())
+			if err != nil {
+				return nil, fmt.Errorf("invalid tagged event #%d in PCR12: %v", idx, err)
+			}
+			if id != LoadOptionsEventTagID {
+				continue
+			}
+			data = tagData
+		default:
+			continue
+		}
+		if err := verifyDataDigest(hasher, data, event.GetDigest()); err != nil {
+			return nil, fmt.Errorf("invalid kernel command line event #%d: %v", idx, err)
+		}
+		if seen {
+			return nil, fmt.Errorf("more than one kernel command line in PCR12 (event #%d)", idx)
+		}
+		decoded, err := decodeUTF16LE(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kernel command line event #%d: %v", idx, err)
+		}
+		seen = true
+		state.CommandLine = decoded
+	}
+	return state, nil
+}
+
+// ukiCpioDescriptions are the descriptions of the PCR12 EV_IPL events of
+// systemd-stub measuring the cpio archives it passes to the kernel.
+var ukiCpioDescriptions = map[string]bool{
+	"Credentials initrd":             true,
+	"Global credentials initrd":      true,
+	"System extension initrd":        true,
+	"Configuration extension initrd": true,
+}
+
+// parseTaggedEvent returns the tag ID and the data of a
+// TCG_PCClientTaggedEvent.
+func parseTaggedEvent(data []byte) (uint32, []byte, error) {
+	if len(data) < 8 {
+		return 0, nil, fmt.Errorf("tagged event too short: %d bytes", len(data))
+	}
+	id := binary.LittleEndian.Uint32(data[:4])
+	size := binary.LittleEndian.Uint32(data[4:8])
+	if uint64(size) != uint64(len(data)-8) {
+		return 0, nil, fmt.Errorf("tagged event size %d does not match remaining data %d", size, len(data)-8)
+	}
+	return id, data[8:], nil
+}
+
+// decodeUkiDescription decodes the description of a systemd-stub PCR11
+// event. Depending on the systemd version, this is either ASCII or UTF-16.
+func decodeUkiDescription(data []byte) (string, error) {
+	if len(data) >= 2 && data[1] == '\x00' {
+		return decodeUTF16LE(data)
+	}
+	desc := string(bytes.TrimRight(data, "\x00"))
+	if desc == "" {
+		return "", errors.New("empty description")
+	}
+	return desc, nil
+}
+
+// decodeUTF16LE decodes a little-endian UTF-16 string, dropping any trailing
+// null terminators.
+func decodeUTF16LE(data []byte) (string, error) {
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("odd length for UTF-16 string: %d", len(data))
+	}
+	u16 := make([]uint16, 0, len(data)/2)
+	for i := 0; i < len(data); i += 2 {
+		u16 = append(u16, binary.LittleEndian.Uint16(data[i:]))
+	}
+	for len(u16) > 0 && u16[len(u16)-1] == 0 {
+		u16 = u16[:len(u16)-1]
+	}
+	return string(utf16.Decode(u16)), nil
+}
@@ -0,0 +1,441 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/go-attestation/attest"
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+// EventCategory classifies an event by the boot component which measured it.
+type EventCategory int
+
+// Known event categories. Events are classified by their PCR index and type,
+// neither of which is verified, so the category is only a hint.
+const (
+	UnknownCategory EventCategory = iota
+	FirmwareCategory
+	BootloaderCategory
+	KernelCategory
+	SecureBootCategory
+	COSCategory
+)
+
+func (c EventCategory) String() string {
+	switch c {
+	case FirmwareCategory:
+		return "firmware"
+	case BootloaderCategory:
+		return "bootloader"
+	case KernelCategory:
+		return "kernel"
+	case SecureBootCategory:
+		return "secure boot"
+	case COSCategory:
+		return "COS"
+	}
+	return "unknown"
+}
+
+// EventChange describes how an event differs between two event logs.
+type EventChange int
+
+// Kinds of event differences.
+const (
+	// EventAdded is an event which is only present in the other log.
+	EventAdded EventChange = iota + 1
+	// EventRemoved is an event which is only present in the base log.
+	EventRemoved
+	// EventModified is an event present in both logs with a different digest.
+	EventModified
+)
+
+func (c EventChange) String() string {
+	switch c {
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// EventDiff is a single difference between two event logs.
+type EventDiff struct {
+	Change   EventChange
+	Category EventCategory
+	// The event in the base log, nil for added events.
+	Base *pb.Event
+	// The event in the other log, nil for removed events.
+	Other *pb.Event
+	// The index of the events in their log, -1 if the event is absent.
+	BaseIndex  int
+	OtherIndex int
+	// A human-readable description of the event, based on its untrusted
+	// type and data.
+	Description string
+}
+
+// PCRDiff contains the differences between the events of a single PCR.
+type PCRDiff struct {
+	Index uint32
+	// The PCR values obtained by replaying each log from a zeroed PCR.
+	BaseDigest  []byte
+	OtherDigest []byte
+	Events      []EventDiff
+}
+
+// EventLogDiff contains the differences between two event logs, grouped by
+// PCR. PCRs whose events are identical in both logs are omitted.
+type EventLogDiff struct {
+	Hash tpmpb.HashAlgo
+	PCRs []PCRDiff
+}
+
+// Empty returns true if both event logs contain the same events.
+func (d *EventLogDiff) Empty() bool {
+	return len(d.PCRs) == 0
+}
+
+// Categories returns the sorted categories of all the differing events.
+func (d *EventLogDiff) Categories() []EventCategory {
+	seen := make(map[EventCategory]bool)
+	var categories []EventCategory
+	for _, pcr := range d.PCRs {
+		for _, event := range pcr.Events {
+			if !seen[event.Category] {
+				seen[event.Category] = true
+				categories = append(categories, event.Category)
+			}
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+	return categories
+}
+
+// WriteReport writes a human-readable report of the differences to w.
+func (d *EventLogDiff) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	if d.Empty() {
+		b.WriteString("Event logs are identical\n")
+	}
+	for _, pcr := range d.PCRs {
+		fmt.Fprintf(&b, "PCR %d (%v): %d differing event(s)\n", pcr.Index, d.Hash, len(pcr.Events))
+		fmt.Fprintf(&b, "  base:  %x\n", pcr.BaseDigest)
+		fmt.Fprintf(&b, "  other: %x\n", pcr.OtherDigest)
+		for _, event := range pcr.Events {
+			switch event.Change {
+			case EventAdded:
+				fmt.Fprintf(&b, "  + [%v] #%d %s\n", event.Category, event.OtherIndex, event.Description)
+				fmt.Fprintf(&b, "      digest %x\n", event.Other.GetDigest())
+			case EventRemoved:
+				fmt.Fprintf(&b, "  - [%v] #%d %s\n", event.Category, event.BaseIndex, event.Description)
+				fmt.Fprintf(&b, "      digest %x\n", event.Base.GetDigest())
+			case EventModified:
+				fmt.Fprintf(&b, "  ~ [%v] #%d -> #%d %s\n", event.Category, event.BaseIndex, event.OtherIndex, event.Description)
+				fmt.Fprintf(&b, "      digest %x -> %x\n", event.Base.GetDigest(), event.Other.GetDigest())
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// DiffEventLogs compares two raw TCG event logs event by event, using the
+// digests of the given hash algorithm. The logs are not replayed against any
+// PCR values, so this should only be used to debug attestation failures, not
+// to establish trust in either log.
+func DiffEventLogs(base, other []byte, hash tpmpb.HashAlgo) (*EventLogDiff, error) {
+	baseEvents, err := parseUnverifiedEvents(base, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base event log: %v", err)
+	}
+	otherEvents, err := parseUnverifiedEvents(other, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse other event log: %v", err)
+	}
+	return diffEvents(hash, baseEvents, otherEvents, classifyEvent, describeEvent)
+}
+
+// DiffMachineStates compares the raw events of two MachineStates, which must
+// have been parsed using the same hash algorithm.
+func DiffMachineStates(base, other *pb.MachineState) (*EventLogDiff, error) {
+	if base.GetHash() != other.GetHash() {
+		return nil, fmt.Errorf("MachineStates use different hash algorithms: %v and %v", base.GetHash(), other.GetHash())
+	}
+	return diffEvents(base.GetHash(), base.GetRawEvents(), other.GetRawEvents(), classifyEvent, describeEvent)
+}
+
+// DiffCanonicalEventLogs compares two raw COS Canonical Event Logs record by
+// record, using the digests of the given hash algorithm. For logs extended
+// into RTMRs, the PCRDiff index is the CCEL measurement register index.
+func DiffCanonicalEventLogs(base, other []byte, hash tpmpb.HashAlgo) (*EventLogDiff, error) {
+	cryptoHash, err := tpm2.Algorithm(hash).Hash()
+	if err != nil {
+		return nil, err
+	}
+	baseEvents, err := celToPbEvents(base, cryptoHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base canonical event log: %v", err)
+	}
+	otherEvents, err := celToPbEvents(other, cryptoHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse other canonical event log: %v", err)
+	}
+	classify := func(*pb.Event) EventCategory { return COSCategory }
+	return diffEvents(hash, baseEvents, otherEvents, classify, describeCELEvent)
+}
+
+// parseUnverifiedEvents parses a raw TCG event log without replaying it.
+func parseUnverifiedEvents(rawEventLog []byte, hash tpmpb.HashAlgo) ([]*pb.Event, error) {
+	if len(rawEventLog) == 0 {
+		return nil, nil
+	}
+	cryptoHash, err := tpm2.Algorithm(hash).Hash()
+	if err != nil {
+		return nil, err
+	}
+	eventLog, err := attest.ParseEventLog(rawEventLog)
+	if err != nil {
+		return nil, err
+	}
+	return convertToPbEvents(cryptoHash, eventLog.Events(attest.HashAlg(hash))), nil
+}
+
+// celToPbEvents converts the records of a raw CEL into events. The content
+// type is stored as the event type and the content value as the event data.
+func celToPbEvents(rawCEL []byte, hash crypto.Hash) ([]*pb.Event, error) {
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*pb.Event, 0, len(decoded.Records))
+	for _, record := range decoded.Records {
+		// CEL management records are not measured into any PCR.
+		if record.Content.Type == cel.CelMgtType {
+			continue
+		}
+		event := &pb.Event{
+			PcrIndex:      uint32(record.Index),
+			UntrustedType: uint32(record.Content.Type),
+			Data:          record.Content.Value,
+			Digest:        record.Digests[hash],
+		}
+		if cosTlv, err := record.Content.ParseToCosTlv(); err == nil {
+			event.DigestVerified = cel.VerifyDigests(cosTlv, map[crypto.Hash][]byte{hash: event.Digest}) == nil
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+type indexedEvent struct {
+	index int
+	event *pb.Event
+}
+
+func diffEvents(hash tpmpb.HashAlgo, base, other []*pb.Event, classify func(*pb.Event) EventCategory, describe func(*pb.Event) string) (*EventLogDiff, error) {
+	cryptoHash, err := tpm2.Algorithm(hash).Hash()
+	if err != nil {
+		return nil, err
+	}
+	basePCRs := groupEventsByPCR(base)
+	otherPCRs := groupEventsByPCR(other)
+	indexes := make(map[uint32]bool)
+	for index := range basePCRs {
+		indexes[index] = true
+	}
+	for index := range otherPCRs {
+		indexes[index] = true
+	}
+	sorted := make([]uint32, 0, len(indexes))
+	for index := range indexes {
+		sorted = append(sorted, index)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	diff := &EventLogDiff{Hash: hash}
+	for _, index := range sorted {
+		events := diffPCREvents(basePCRs[index], otherPCRs[index])
+		if len(events) == 0 {
+			continue
+		}
+		for i := range events {
+			event := events[i].Other
+			if event == nil {
+				event = events[i].Base
+			}
+			events[i].Category = classify(event)
+			events[i].Description = describe(event)
+		}
+		diff.PCRs = append(diff.PCRs, PCRDiff{
+			Index:       index,
+			BaseDigest:  replayEvents(cryptoHash, basePCRs[index]),
+			OtherDigest: replayEvents(cryptoHash, otherPCRs[index]),
+			Events:      events,
+		})
+	}
+	return diff, nil
+}
+
+func groupEventsByPCR(events []*pb.Event) map[uint32][]indexedEvent {
+	pcrs := make(map[uint32][]indexedEvent)
+	for i, event := range events {
+		pcrs[event.GetPcrIndex()] = append(pcrs[event.GetPcrIndex()], indexedEvent{i, event})
+	}
+	return pcrs
+}
+
+// replayEvents computes the PCR value obtained by extending the event digests
+// into a zeroed PCR. EV_NO_ACTION events are not extended.
+func replayEvents(hash crypto.Hash, events []indexedEvent) []byte {
+	pcr := make([]byte, hash.Size())
+	for _, e := range events {
+		if e.event.GetUntrustedType() == NoAction {
+			continue
+		}
+		pcr = extendDigest(hash, pcr, e.event.GetDigest())
+	}
+	return pcr
+}
+
+func sameEvent(a, b *pb.Event) bool {
+	return a.GetUntrustedType() == b.GetUntrustedType() && bytes.Equal(a.GetDigest(), b.GetDigest())
+}
+
+// diffPCREvents aligns the events of a single PCR using their longest common
+// subsequence. Within each unaligned run, removed and added events of the
+// same type are reported as modified.
+func diffPCREvents(base, other []indexedEvent) []EventDiff {
+	// lcs[i][j] is the length of the LCS of base[i:] and other[j:].
+	lcs := make([][]int, len(base)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(other)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			if sameEvent(base[i].event, other[j].event) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diffs []EventDiff
+	var removed, added []indexedEvent
+	flush := func() {
+		diffs = append(diffs, pairEvents(removed, added)...)
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(base) || j < len(other) {
+		switch {
+		case i < len(base) && j < len(other) && sameEvent(base[i].event, other[j].event):
+			flush()
+			i++
+			j++
+		case j == len(other) || (i < len(base) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, base[i])
+			i++
+		default:
+			added = append(added, other[j])
+			j++
+		}
+	}
+	flush()
+	return diffs
+}
+
+func pairEvents(removed, added []indexedEvent) []EventDiff {
+	var diffs []EventDiff
+	for len(removed) > 0 && len(added) > 0 && removed[0].event.GetUntrustedType() == added[0].event.GetUntrustedType() {
+		diffs = append(diffs, EventDiff{
+			Change:     EventModified,
+			Base:       removed[0].event,
+			Other:      added[0].event,
+			BaseIndex:  removed[0].index,
+			OtherIndex: added[0].index,
+		})
+		removed, added = removed[1:], added[1:]
+	}
+	for _, e := range removed {
+		diffs = append(diffs, EventDiff{Change: EventRemoved, Base: e.event, BaseIndex: e.index, OtherIndex: -1})
+	}
+	for _, e := range added {
+		diffs = append(diffs, EventDiff{Change: EventAdded, Other: e.event, BaseIndex: -1, OtherIndex: e.index})
+	}
+	return diffs
+}
+
+// classifyEvent classifies a TCG event by its PCR index, following the TCG PC
+// Client Platform Firmware Profile and the Linux TPM PCR Registry.
+func classifyEvent(event *pb.Event) EventCategory {
+	switch event.GetPcrIndex() {
+	case 0, 1, 2, 3, 5, 6:
+		return FirmwareCategory
+	case 4:
+		if event.GetUntrustedType() == EFIBootServicesApplication {
+			return BootloaderCategory
+		}
+		return FirmwareCategory
+	case 7, 14:
+		return SecureBootCategory
+	case 8:
+		for _, prefix := range [][]byte{newGrubKernelCmdlinePrefix, oldGrubKernelCmdlinePrefix} {
+			if bytes.HasPrefix(event.GetData(), prefix) {
+				return KernelCategory
+			}
+		}
+		return BootloaderCategory
+	case 9:
+		// GRUB measures the files it reads into PCR9, including its own
+		// configuration and modules.
+		path := string(bytes.TrimRight(event.GetData(), "\x00"))
+		if strings.HasSuffix(path, ".cfg") || strings.HasSuffix(path, ".mod") {
+			return BootloaderCategory
+		}
+		return KernelCategory
+	case 10, 11, 12:
+		return KernelCategory
+	case cel.CosEventPCR:
+		return COSCategory
+	}
+	return UnknownCategory
+}
+
+func describeEvent(event *pb.Event) string {
+	name := attest.EventType(event.GetUntrustedType()).String()
+	if data := decodeEventData(event); data != "" {
+		return fmt.Sprintf("%s %q", name, truncate(data, 80))
+	}
+	return name
+}
+
+func describeCELEvent(event *pb.Event) string {
+	content := cel.TLV{Type: uint8(event.GetUntrustedType()), Value: event.GetData()}
+	cosTlv, err := content.ParseToCosTlv()
+	if err != nil {
+		return fmt.Sprintf("CEL content type %d", content.Type)
+	}
+	return fmt.Sprintf("%v %q", cosTlv.EventType, truncate(string(cosTlv.EventContent), 80))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
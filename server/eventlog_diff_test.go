@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+func TestDiffEventLogsIdentical(t *testing.T) {
+	for _, log := range []eventLog{Rhel8GCE, UbuntuAmdSevGCE, COS101AmdSev, ArchLinuxWorkstation} {
+		for _, bank := range log.Banks {
+			diff, err := DiffEventLogs(log.RawLog, log.RawLog, bank.GetHash())
+			if err != nil {
+				t.Fatalf("DiffEventLogs() got err = %v, want nil", err)
+			}
+			if !diff.Empty() {
+				t.Errorf("DiffEventLogs() of identical logs got %d differing PCRs, want none", len(diff.PCRs))
+			}
+		}
+	}
+}
+
+func TestDiffEventLogsDifferentBoots(t *testing.T) {
+	diff, err := DiffEventLogs(COS85AmdSev.RawLog, COS93AmdSev.RawLog, pb.HashAlgo_SHA256)
+	if err != nil {
+		t.Fatalf("DiffEventLogs() got err = %v, want nil", err)
+	}
+	if diff.Empty() {
+		t.Fatal("DiffEventLogs() of different COS versions got no differences")
+	}
+	for _, pcr := range diff.PCRs {
+		if bytes.Equal(pcr.BaseDigest, pcr.OtherDigest) {
+			t.Errorf("PCR %d is reported as differing, but has the same replayed value", pcr.Index)
+		}
+	}
+	var report strings.Builder
+	if err := diff.WriteReport(&report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.String(), "[kernel]") {
+		t.Errorf("expected a kernel difference in the report, got:\n%s", report.String())
+	}
+}
+
+func testEvent(pcr uint32, eventType uint32, data string) *attestpb.Event {
+	digest := sha256.Sum256([]byte(data))
+	return &attestpb.Event{PcrIndex: pcr, UntrustedType: eventType, Data: []byte(data), Digest: digest[:], DigestVerified: true}
+}
+
+func TestDiffMachineStates(t *testing.T) {
+	base := &attestpb.MachineState{
+		Hash: pb.HashAlgo_SHA256,
+		RawEvents: []*attestpb.Event{
+			testEvent(0, SCRTMVersion, "version 1"),
+			testEvent(0, Separator, "\x00\x00\x00\x00"),
+			testEvent(4, EFIBootServicesApplication, "shim"),
+			testEvent(8, 0xD, "grub_cmd: linux /vmlinuz"),
+			testEvent(8, 0xD, "kernel_cmdline: /vmlinuz ro"),
+			testEvent(7, EFIVariableAuthority, "db"),
+		},
+	}
+	other := &attestpb.MachineState{
+		Hash: pb.HashAlgo_SHA256,
+		RawEvents: []*attestpb.Event{
+			testEvent(0, SCRTMVersion, "version 2"),
+			testEvent(0, Separator, "\x00\x00\x00\x00"),
+			testEvent(4, EFIBootServicesApplication, "shim"),
+			testEvent(4, EFIBootServicesApplication, "grub"),
+			testEvent(8, 0xD, "grub_cmd: linux /vmlinuz"),
+			testEvent(8, 0xD, "kernel_cmdline: /vmlinuz ro debug"),
+		},
+	}
+
+	diff, err := DiffMachineStates(base, other)
+	if err != nil {
+		t.Fatalf("DiffMachineStates() got err = %v, want nil", err)
+	}
+	type result struct {
+		PCR        uint32
+		Change     EventChange
+		Category   EventCategory
+		BaseIndex  int
+		OtherIndex int
+	}
+	var got []result
+	for _, pcr := range diff.PCRs {
+		for _, event := range pcr.Events {
+			got = append(got, result{pcr.Index, event.Change, event.Category, event.BaseIndex, event.OtherIndex})
+		}
+	}
+	want := []result{
+		{0, EventModified, FirmwareCategory, 0, 0},
+		{4, EventAdded, BootloaderCategory, -1, 3},
+		{7, EventRemoved, SecureBootCategory, 5, -1},
+		{8, EventModified, KernelCategory, 4, 5},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffMachineStates() returned unexpected diff (-want +got):\n%s", diff)
+	}
+	wantCategories := []EventCategory{FirmwareCategory, BootloaderCategory, KernelCategory, SecureBootCategory}
+	if diff := cmp.Diff(wantCategories, diff.Categories()); diff != "" {
+		t.Errorf("Categories() returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	other.Hash = pb.HashAlgo_SHA1
+	if _, err := DiffMachineStates(base, other); err == nil {
+		t.Error("DiffMachineStates() with different hash algorithms got nil error, want error")
+	}
+}
+
+func makeTestCEL(t *testing.T, events []cel.CosTlv) []byte {
+	t.Helper()
+	var coscel cel.CEL
+	for i, event := range events {
+		content, err := event.GetTLV()
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest, err := event.GenerateDigest(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coscel.Records = append(coscel.Records, cel.Record{
+			RecNum:    uint64(i),
+			Index:     cel.CosEventPCR,
+			IndexType: cel.PCRTypeValue,
+			Digests:   map[crypto.Hash][]byte{crypto.SHA256: digest},
+			Content:   content,
+		})
+	}
+	var buf bytes.Buffer
+	if err := coscel.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiffCanonicalEventLogs(t *testing.T) {
+	base := makeTestCEL(t, []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/nginx:1.25")},
+		{EventType: cel.ArgType, EventContent: []byte("--port=80")},
+		{EventType: cel.LaunchSeparatorType},
+	})
+	other := makeTestCEL(t, []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/nginx:1.26")},
+		{EventType: cel.ArgType, EventContent: []byte("--port=80")},
+		{EventType: cel.EnvVarType, EventContent: []byte("DEBUG=1")},
+		{EventType: cel.LaunchSeparatorType},
+	})
+
+	diff, err := DiffCanonicalEventLogs(base, other, pb.HashAlgo_SHA256)
+	if err != nil {
+		t.Fatalf("DiffCanonicalEventLogs() got err = %v, want nil", err)
+	}
+	if len(diff.PCRs) != 1 || diff.PCRs[0].Index != cel.CosEventPCR {
+		t.Fatalf("DiffCanonicalEventLogs() got %d differing PCRs, want only PCR %d", len(diff.PCRs), cel.CosEventPCR)
+	}
+	events := diff.PCRs[0].Events
+	if len(events) != 2 {
+		t.Fatalf("DiffCanonicalEventLogs() got %d differing events, want 2", len(events))
+	}
+	if events[0].Change != EventModified || !strings.Contains(events[0].Description, "nginx:1.26") {
+		t.Errorf("got first event diff %v %q, want modified image reference", events[0].Change, events[0].Description)
+	}
+	if events[1].Change != EventAdded || events[1].Category != COSCategory || !events[1].Other.GetDigestVerified() {
+		t.Errorf("got second event diff %v %v, want a verified added COS event", events[1].Change, events[1].Category)
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/go-attestation/attest"
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+// DecodedEvent is a human-readable representation of an event from a TCG or
+// Canonical event log, meant for debugging. Only the events returned by
+// ReplayEventLog or ReplayCanonicalEventLog are verified against PCR values,
+// and even then the type and decoded data are only a hint unless the digest
+// is verified.
+type DecodedEvent struct {
+	// The index of the event in a TCG log, or the record number in a CEL.
+	Index int `json:"index"`
+	// The PCR (or CCEL measurement register) the event was extended into.
+	PCR uint32 `json:"pcr"`
+	// The name of the event type (e.g., EV_SEPARATOR or ImageRef).
+	Type      string `json:"type"`
+	TypeValue uint32 `json:"type_value"`
+	// The hex-encoded digest extended into the PCR.
+	Digest string `json:"digest"`
+	// This is true if the digest is the hash of the event data.
+	DigestVerified bool `json:"digest_verified"`
+	// The decoded event data, such as a UEFI variable name, a device path or
+	// the content of a COS event.
+	Description string `json:"description,omitempty"`
+	// The hex-encoded raw event data.
+	Data string `json:"data,omitempty"`
+}
+
+// DecodeEventLog decodes all the events of a raw TCG event log, using the
+// digests of the given hash algorithm. The log is not replayed.
+func DecodeEventLog(rawEventLog []byte, hash tpmpb.HashAlgo) ([]DecodedEvent, error) {
+	events, err := parseUnverifiedEvents(rawEventLog, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event log: %v", err)
+	}
+	return decodeEvents(events), nil
+}
+
+// ReplayEventLog replays a raw TCG event log against the PCR values and
+// decodes the events. It returns an error if the replay for any PCR fails.
+// As with parsing a MachineState, it is the caller's responsibility to ensure
+// that the PCR values can be trusted.
+func ReplayEventLog(rawEventLog []byte, pcrs *tpmpb.PCRs) ([]DecodedEvent, error) {
+	return ReplayEventLogWithLimits(rawEventLog, pcrs, cel.DecodeLimits{})
+}
+
+// ReplayEventLogWithLimits is like ReplayEventLog, but first checks that the
+// event log is within the given limits. Use it with cel.DefaultDecodeLimits()
+// for untrusted input.
+func ReplayEventLogWithLimits(rawEventLog []byte, pcrs *tpmpb.PCRs, limits cel.DecodeLimits) ([]DecodedEvent, error) {
+	if err := checkEventLogLimits(rawEventLog, limits); err != nil {
+		return nil, err
+	}
+	events, err := parseReplayHelper(rawEventLog, pcrs)
+	if err != nil {
+		return nil, err
+	}
+	cryptoHash, _ := tpm2.Algorithm(pcrs.GetHash()).Hash()
+	return decodeEvents(convertToPbEvents(cryptoHash, events)), nil
+}
+
+// DecodeCanonicalEventLog decodes all the records of a raw Canonical Event
+// Log, using the digests of the given hash algorithm. The log is not
+// replayed.
+func DecodeCanonicalEventLog(rawCEL []byte, hash tpmpb.HashAlgo) ([]DecodedEvent, error) {
+	cryptoHash, err := tpm2.Algorithm(hash).Hash()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode canonical event log: %v", err)
+	}
+	return decodeCELRecords(decoded, cryptoHash), nil
+}
+
+// ReplayCanonicalEventLog replays a raw Canonical Event Log against the PCR
+// values and decodes the records.
+func ReplayCanonicalEventLog(rawCEL []byte, pcrs *tpmpb.PCRs) ([]DecodedEvent, error) {
+	cryptoHash, err := tpm2.Algorithm(pcrs.GetHash()).Hash()
+	if err != nil {
+		return nil, err
+	}
+	pcrBank, err := toPCRBank(pcrs)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode canonical event log: %v", err)
+	}
+	if err := decoded.Replay(pcrBank); err != nil {
+		return nil, err
+	}
+	return decodeCELRecords(decoded, cryptoHash), nil
+}
+
+// CanonicalEventLogReplayReport replays a raw Canonical Event Log against the
+// PCR values, and reports the replay of each PCR with records in the log.
+// Unlike ReplayCanonicalEventLog, a failed replay is not an error, so the
+// report can be used to diagnose it.
+func CanonicalEventLogReplayReport(rawCEL []byte, pcrs *tpmpb.PCRs) (*cel.ReplayReport, error) {
+	pcrBank, err := toPCRBank(pcrs)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := cel.DecodeToCEL(bytes.NewBuffer(rawCEL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode canonical event log: %v", err)
+	}
+	return decoded.ReplayReport(pcrBank)
+}
+
+func decodeEvents(events []*pb.Event) []DecodedEvent {
+	decoded := make([]DecodedEvent, 0, len(events))
+	for i, event := range events {
+		decoded = append(decoded, DecodedEvent{
+			Index:          i,
+			PCR:            event.GetPcrIndex(),
+			Type:           attest.EventType(event.GetUntrustedType()).String(),
+			TypeValue:      event.GetUntrustedType(),
+			Digest:         hex.EncodeToString(event.GetDigest()),
+			DigestVerified: event.GetDigestVerified(),
+			Description:    decodeEventData(event),
+			Data:           hex.EncodeToString(event.GetData()),
+		})
+	}
+	return decoded
+}
+
+func decodeCELRecords(decoded cel.CEL, hash crypto.Hash) []DecodedEvent {
+	events := make([]DecodedEvent, 0, len(decoded.Records))
+	for _, record := range decoded.Records {
+		event := DecodedEvent{
+			Index:     int(record.RecNum),
+			PCR:       uint32(record.Index),
+			Type:      fmt.Sprintf("CEL content type %d", record.Content.Type),
+			TypeValue: uint32(record.Content.Type),
+			Digest:    hex.EncodeToString(record.Digests[hash]),
+			Data:      hex.EncodeToString(record.Content.Value),
+		}
+		if cosTlv, err := record.Content.ParseToCosTlv(); err == nil {
+			event.Type = cosTlv.EventType.String()
+			event.TypeValue = uint32(cosTlv.EventType)
+			event.DigestVerified = cel.VerifyDigests(cosTlv, map[crypto.Hash][]byte{hash: record.Digests[hash]}) == nil
+			event.Description = string(cosTlv.EventContent)
+		} else if celMgt, err := record.Content.ParseToCelMgt(); err == nil {
+			// CEL management records are not measured, so they have no digest.
+			event.Type = celMgt.EventType.String()
+			event.TypeValue = uint32(celMgt.EventType)
+			event.Description = describeCelMgt(celMgt)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func describeCelMgt(event cel.CelMgt) string {
+	switch event.EventType {
+	case cel.CelVersionType:
+		if major, minor, err := event.CelVersion(); err == nil {
+			return fmt.Sprintf("%d.%d", major, minor)
+		}
+	case cel.TransferChangeType:
+		if agent, err := event.TransferChange(); err == nil {
+			return agent
+		}
+	case cel.CelTimestampType:
+		if timestamp, err := event.CelTimestamp(); err == nil {
+			return timestamp.UTC().Format(time.RFC3339Nano)
+		}
+	case cel.StateTransType:
+		if transition, err := event.StateTrans(); err == nil {
+			return transition.String()
+		}
+	}
+	return ""
+}
+
+// decodeEventData returns a human-readable representation of the data of a
+// TCG event, or an empty string if the data cannot be decoded.
+func decodeEventData(event *pb.Event) string {
+	data := event.GetData()
+	switch event.GetUntrustedType() {
+	case EFIVariableDriverConfig, EFIVariableBoot, EFIVariableBoot2, EFIVariableAuthority:
+		if guid, name, _, err := parseUEFIVariableData(data); err == nil {
+			return formatEFIGUID(guid[:]) + ":" + name
+		}
+	case EFIBootServicesApplication, EFIBootServicesDriver, EFIRuntimeServicesDriver:
+		if _, _, devicePath, err := parseImageLoadEvent(data); err == nil {
+			return devicePath
+		}
+	case SCRTMVersion:
+		// The version is usually a UCS-2 string (e.g., GCE firmware).
+		if version, err := decodeUTF16LE(data); err == nil && isPrintable([]byte(version)) {
+			return version
+		}
+	case EFIPlatformFirmwareBlob2, EFIHandoffTables2:
+		if desc, _, err := parseDescription(data); err == nil && isPrintable(desc) {
+			return string(desc)
+		}
+	}
+	if len(data) > 0 && isPrintable(data) {
+		return string(bytes.TrimRight(data, "\x00"))
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"crypto"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeEventLog(t *testing.T) {
+	log := UbuntuAmdSevGCE
+	bank := log.Banks[1]
+	decoded, err := DecodeEventLog(log.RawLog, bank.GetHash())
+	if err != nil {
+		t.Fatalf("DecodeEventLog() got err = %v, want nil", err)
+	}
+	replayed, err := ReplayEventLog(log.RawLog, bank)
+	if err != nil {
+		t.Fatalf("ReplayEventLog() got err = %v, want nil", err)
+	}
+	if len(decoded) != len(replayed) {
+		t.Errorf("DecodeEventLog() got %d events, ReplayEventLog() got %d events", len(decoded), len(replayed))
+	}
+
+	var descriptions []string
+	for _, event := range replayed {
+		if event.Type == "EV_EFI_VARIABLE_BOOT" || event.Type == "EV_EFI_BOOT_SERVICES_APPLICATION" {
+			descriptions = append(descriptions, event.Description)
+		}
+	}
+	want := []string{
+		"8be4df61-93ca-11d2-aa0d-00e098032b8c:BootOrder",
+		"8be4df61-93ca-11d2-aa0d-00e098032b8c:Boot0002",
+		"8be4df61-93ca-11d2-aa0d-00e098032b8c:Boot0000",
+		"8be4df61-93ca-11d2-aa0d-00e098032b8c:Boot0001",
+		`PciRoot(0x0)/Pci(0x4,0x0)/NVMe(0x1,00-00-00-00-00-00-00-00)/HD(15,GPT,86ecc9e1-fb24-47e6-8b51-005a5aded596,0x2800,0x35000)/\EFI\ubuntu\shimx64.efi`,
+	}
+	if diff := cmp.Diff(want, descriptions[:len(want)]); diff != "" {
+		t.Errorf("unexpected decoded events (-want +got):\n%s", diff)
+	}
+}
+
+func TestReplayEventLogFail(t *testing.T) {
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	bank.Pcrs[4] = make([]byte, len(bank.Pcrs[4]))
+	if _, err := ReplayEventLog(UbuntuAmdSevGCE.RawLog, bank); err == nil {
+		t.Error("ReplayEventLog() got nil error, want replay error")
+	}
+}
+
+func TestReplayEventLogWithLimits(t *testing.T) {
+	bank := UbuntuAmdSevGCE.Banks[1]
+	if _, err := ReplayEventLogWithLimits(UbuntuAmdSevGCE.RawLog, bank, cel.DefaultDecodeLimits()); err != nil {
+		t.Errorf("ReplayEventLogWithLimits() got err = %v, want nil", err)
+	}
+	limits := cel.DecodeLimits{MaxTotalSize: int64(len(UbuntuAmdSevGCE.RawLog) - 1)}
+	if _, err := ReplayEventLogWithLimits(UbuntuAmdSevGCE.RawLog, bank, limits); err == nil {
+		t.Error("ReplayEventLogWithLimits() got nil error, want limit error")
+	}
+}
+
+func TestReplayCanonicalEventLog(t *testing.T) {
+	events := []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/nginx:1.25")},
+		{EventType: cel.EnvVarType, EventContent: []byte("PORT=80")},
+		{EventType: cel.LaunchSeparatorType},
+	}
+	rawCEL := makeTestCEL(t, events)
+	pcr := make([]byte, crypto.SHA256.Size())
+	for _, event := range events {
+		digest, err := event.GenerateDigest(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcr = extendDigest(crypto.SHA256, pcr, digest)
+	}
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{cel.CosEventPCR: pcr}}
+
+	decoded, err := ReplayCanonicalEventLog(rawCEL, pcrs)
+	if err != nil {
+		t.Fatalf("ReplayCanonicalEventLog() got err = %v, want nil", err)
+	}
+	var got []string
+	for _, event := range decoded {
+		if !event.DigestVerified || event.PCR != cel.CosEventPCR {
+			t.Errorf("got unverified event %+v", event)
+		}
+		got = append(got, strings.TrimSpace(event.Type+" "+event.Description))
+	}
+	want := []string{"ImageRef docker.io/library/nginx:1.25", "EnvVar PORT=80", "LaunchSeparator"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReplayCanonicalEventLog() returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	pcrs.Pcrs[cel.CosEventPCR] = make([]byte, crypto.SHA256.Size())
+	if _, err := ReplayCanonicalEventLog(rawCEL, pcrs); err == nil {
+		t.Error("ReplayCanonicalEventLog() got nil error, want replay error")
+	}
+	if _, err := DecodeCanonicalEventLog(rawCEL, pb.HashAlgo_SHA256); err != nil {
+		t.Errorf("DecodeCanonicalEventLog() got err = %v, want nil", err)
+	}
+}
+
+func TestCanonicalEventLogReplayReport(t *testing.T) {
+	events := []cel.CosTlv{
+		{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/nginx:1.25")},
+		{EventType: cel.EnvVarType, EventContent: []byte("PORT=80")},
+		{EventType: cel.LaunchSeparatorType},
+	}
+	rawCEL := makeTestCEL(t, events)
+	// The PCR value after each event.
+	pcrValues := [][]byte{make([]byte, crypto.SHA256.Size())}
+	for _, event := range events {
+		digest, err := event.GenerateDigest(crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcrValues = append(pcrValues, extendDigest(crypto.SHA256, pcrValues[len(pcrValues)-1], digest))
+	}
+	extra := extendDigest(crypto.SHA256, pcrValues[3], make([]byte, crypto.SHA256.Size()))
+
+	for _, tc := range []struct {
+		name             string
+		pcr              []byte
+		wantMatch        bool
+		wantDivergent    int
+		wantExtraExtends bool
+	}{
+		{"Match", pcrValues[3], true, -1, false},
+		{"MissingLastExtend", pcrValues[2], false, 2, false},
+		{"NoExtends", pcrValues[0], false, 0, false},
+		{"ExtraExtend", extra, false, -1, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{cel.CosEventPCR: tc.pcr}}
+			report, err := CanonicalEventLogReplayReport(rawCEL, pcrs)
+			if err != nil {
+				t.Fatalf("CanonicalEventLogReplayReport() failed: %v", err)
+			}
+			if len(report.Registers) != 1 {
+				t.Fatalf("CanonicalEventLogReplayReport() got %d registers, want 1", len(report.Registers))
+			}
+			reg := report.Registers[0]
+			if reg.Match() != tc.wantMatch || reg.FirstDivergentRecord != tc.wantDivergent || reg.ExtraExtends != tc.wantExtraExtends {
+				t.Errorf("CanonicalEventLogReplayReport() got %+v, want match %v, first divergent record %d, extra extends %v", reg, tc.wantMatch, tc.wantDivergent, tc.wantExtraExtends)
+			}
+			if gotErr := report.Err() != nil; gotErr == tc.wantMatch {
+				t.Errorf("ReplayReport.Err() got %v, want error %v", report.Err(), !tc.wantMatch)
+			}
+		})
+	}
+}
@@ -3,12 +3,18 @@ package server
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-attestation/attest"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-configfs-tsm/configfs/fakertmr"
 	configfstsmrtmr "github.com/google/go-configfs-tsm/rtmr"
@@ -807,6 +813,11 @@ func TestParsingRTMREventlog(t *testing.T) {
 		{cel.ArgType, cel.CosRTMR, []byte("")},
 		{cel.MemoryMonitorType, cel.CosRTMR, []byte{1}},
 		{cel.GpuCCModeType, cel.CosRTMR, []byte(attestpb.GPUDeviceCCMode_ON.String())},
+		{cel.AddedCapabilityType, cel.CosRTMR, []byte("CAP_SYS_ADMIN")},
+		{cel.CgroupNamespaceType, cel.CosRTMR, []byte{1}},
+		{cel.MountType, cel.CosRTMR, []byte("type=tmpfs,source=tmpfs,destination=/tmpmount,nosuid,size=1024")},
+		{cel.LogRedirectType, cel.CosRTMR, []byte(attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL.String())},
+		{cel.ImpersonatedServiceAccountType, cel.CosRTMR, []byte("sa@project.iam.gserviceaccount.com")},
 	}
 
 	expectedEnvVars := make(map[string]string)
@@ -816,12 +827,22 @@ func TestParsingRTMREventlog(t *testing.T) {
 	expectedEnvVars["empty"] = ""
 
 	wantContainerState := attestpb.ContainerState{
-		ImageReference: string(testCELEvents[0].eventPayload),
-		ImageDigest:    string(testCELEvents[1].eventPayload),
-		RestartPolicy:  attestpb.RestartPolicy_Always,
-		ImageId:        string(testCELEvents[3].eventPayload),
-		EnvVars:        expectedEnvVars,
-		Args:           []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		ImageReference:    string(testCELEvents[0].eventPayload),
+		ImageDigest:       string(testCELEvents[1].eventPayload),
+		RestartPolicy:     attestpb.RestartPolicy_Always,
+		ImageId:           string(testCELEvents[3].eventPayload),
+		EnvVars:           expectedEnvVars,
+		Args:              []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		AddedCapabilities: []string{"CAP_SYS_ADMIN"},
+		CgroupNamespace:   true,
+		Mounts: []*attestpb.ContainerMount{{
+			MountType:   "tmpfs",
+			Source:      "tmpfs",
+			Destination: "/tmpmount",
+			Options:     []string{"nosuid", "size=1024"},
+		}},
+		LogRedirect:                 attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL,
+		ImpersonatedServiceAccounts: []string{"sa@project.iam.gserviceaccount.com"},
 	}
 	enabled := true
 	wantHealthMonitoringState := attestpb.HealthMonitoringState{
@@ -941,6 +962,24 @@ func TestParsingCELEventLog(t *testing.T) {
 		{cel.ArgType, cel.CosEventPCR, []byte("")},
 		{cel.MemoryMonitorType, cel.CosEventPCR, []byte{1}},
 		{cel.GpuCCModeType, cel.CosEventPCR, []byte(attestpb.GPUDeviceCCMode_OFF.String())},
+		{cel.AddedCapabilityType, cel.CosEventPCR, []byte("CAP_SYS_ADMIN")},
+		{cel.CgroupNamespaceType, cel.CosEventPCR, []byte{1}},
+		{cel.MountType, cel.CosEventPCR, []byte("type=tmpfs,source=tmpfs,destination=/tmpmount,nosuid,size=1024")},
+		{cel.LogRedirectType, cel.CosEventPCR, []byte(attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL.String())},
+		{cel.ImpersonatedServiceAccountType, cel.CosEventPCR, []byte("sa@project.iam.gserviceaccount.com")},
+		{cel.HardenedImageType, cel.CosEventPCR, []byte{1}},
+		{cel.InitContainerType, cel.CosEventPCR, []byte("weights")},
+		{cel.ImageRefType, cel.CosEventPCR, []byte("gcr.io/project/fetch-weights:latest")},
+		{cel.ArgType, cel.CosEventPCR, []byte("fetch")},
+		{cel.AttestationAccessType, cel.CosEventPCR, []byte{0}},
+		{cel.SidecarType, cel.CosEventPCR, []byte("proxy")},
+		{cel.ImageRefType, cel.CosEventPCR, []byte("docker.io/library/envoy:latest")},
+		{cel.ImageDigestType, cel.CosEventPCR, []byte("sha256:1c9d6b7f5aa3a0b2b7e0a54e4d8e63a5ea37e3c0d41d1fd3b8ec88ad0bd2b6d0")},
+		{cel.ArgType, cel.CosEventPCR, []byte("envoy")},
+		{cel.EnvVarType, cel.CosEventPCR, []byte("foo=baz")},
+		{cel.OverrideArgType, cel.CosEventPCR, []byte("envoy")},
+		{cel.MountType, cel.CosEventPCR, []byte("type=tmpfs,source=tmpfs,destination=/tmpmount,nosuid,size=1024")},
+		{cel.AttestationAccessType, cel.CosEventPCR, []byte{1}},
 	}
 
 	expectedEnvVars := make(map[string]string)
@@ -950,12 +989,39 @@ func TestParsingCELEventLog(t *testing.T) {
 	expectedEnvVars["empty"] = ""
 
 	wantContainerState := attestpb.ContainerState{
-		ImageReference: string(testCELEvents[0].eventPayload),
-		ImageDigest:    string(testCELEvents[1].eventPayload),
-		RestartPolicy:  attestpb.RestartPolicy_Always,
-		ImageId:        string(testCELEvents[3].eventPayload),
-		EnvVars:        expectedEnvVars,
-		Args:           []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		ImageReference:    string(testCELEvents[0].eventPayload),
+		ImageDigest:       string(testCELEvents[1].eventPayload),
+		RestartPolicy:     attestpb.RestartPolicy_Always,
+		ImageId:           string(testCELEvents[3].eventPayload),
+		EnvVars:           expectedEnvVars,
+		Args:              []string{string(testCELEvents[8].eventPayload), string(testCELEvents[9].eventPayload), string(testCELEvents[10].eventPayload)},
+		AddedCapabilities: []string{"CAP_SYS_ADMIN"},
+		CgroupNamespace:   true,
+		Mounts: []*attestpb.ContainerMount{{
+			MountType:   "tmpfs",
+			Source:      "tmpfs",
+			Destination: "/tmpmount",
+			Options:     []string{"nosuid", "size=1024"},
+		}},
+		LogRedirect:                 attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL,
+		ImpersonatedServiceAccounts: []string{"sa@project.iam.gserviceaccount.com"},
+	}
+	wantInitContainerState := attestpb.ContainerState{
+		Name:           "weights",
+		Role:           attestpb.ContainerRole_CONTAINER_ROLE_INIT,
+		ImageReference: "gcr.io/project/fetch-weights:latest",
+		Args:           []string{"fetch"},
+	}
+	wantSidecarState := attestpb.ContainerState{
+		Name:              "proxy",
+		Role:              attestpb.ContainerRole_CONTAINER_ROLE_SIDECAR,
+		ImageReference:    "docker.io/library/envoy:latest",
+		ImageDigest:       "sha256:1c9d6b7f5aa3a0b2b7e0a54e4d8e63a5ea37e3c0d41d1fd3b8ec88ad0bd2b6d0",
+		Args:              []string{"envoy"},
+		EnvVars:           map[string]string{"foo": "baz"},
+		OverriddenArgs:    []string{"envoy"},
+		Mounts:            wantContainerState.Mounts,
+		AttestationAccess: true,
 	}
 	enabled := true
 	wantHealthMonitoringState := attestpb.HealthMonitoringState{
@@ -964,6 +1030,14 @@ func TestParsingCELEventLog(t *testing.T) {
 	wantGpuDeviceState := attestpb.GpuDeviceState{
 		CcMode: attestpb.GPUDeviceCCMode_OFF,
 	}
+	// CEL management records are not measured, and don't change the COS state.
+	celVersion, err := cel.NewCelVersion(1, 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := coscel.AppendCelMgtPCR(cel.CosEventPCR, celVersion); err != nil {
+		t.Fatal(err)
+	}
 	for _, testEvent := range testCELEvents {
 		cosEvent := cel.CosTlv{EventType: testEvent.cosNestedEventType, EventContent: testEvent.eventPayload}
 
@@ -971,6 +1045,9 @@ func TestParsingCELEventLog(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
+	if err := coscel.AppendCelMgtPCR(cel.CosEventPCR, cel.NewCelTimestamp(time.Now())); err != nil {
+		t.Fatal(err)
+	}
 	buf = bytes.Buffer{}
 	if err := coscel.EncodeCEL(&buf); err != nil {
 		t.Fatal(err)
@@ -988,12 +1065,18 @@ func TestParsingCELEventLog(t *testing.T) {
 			if diff := cmp.Diff(acosState.Container, &wantContainerState, protocmp.Transform()); diff != "" {
 				t.Errorf("unexpected container state difference:\n%v", diff)
 			}
+			if diff := cmp.Diff(acosState.Containers, []*attestpb.ContainerState{&wantContainerState, &wantInitContainerState, &wantSidecarState}, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected containers difference:\n%v", diff)
+			}
 			if diff := cmp.Diff(acosState.HealthMonitoring, &wantHealthMonitoringState, protocmp.Transform()); diff != "" {
 				t.Errorf("unexpected health monitoring state difference:\n%v", diff)
 			}
 			if diff := cmp.Diff(acosState.GpuDeviceState, &wantGpuDeviceState, protocmp.Transform()); diff != "" {
 				t.Errorf("unexpected GPU device state difference:\n%v", diff)
 			}
+			if acosState.GetLauncherImageType() != attestpb.LauncherImageType_LAUNCHER_IMAGE_HARDENED {
+				t.Errorf("unexpected launcher image type, want hardened, but got %v", acosState.GetLauncherImageType())
+			}
 		}
 	}
 
@@ -1032,6 +1115,192 @@ func TestParsingCELEventLog(t *testing.T) {
 	}
 }
 
+func TestParsingCELEventLogWorkloadContainerErrors(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	sidecar := cel.CosTlv{EventType: cel.SidecarType, EventContent: []byte("proxy")}
+	for _, tc := range []struct {
+		name   string
+		events []cel.CosTlv
+	}{
+		{"EmptyName", []cel.CosTlv{{EventType: cel.SidecarType, EventContent: []byte{}}}},
+		{"DuplicateName", []cel.CosTlv{sidecar, sidecar}},
+		{"InitContainerNamedLikeSidecar", []cel.CosTlv{{EventType: cel.InitContainerType, EventContent: []byte("proxy")}, sidecar}},
+		{"InitContainerRestartPolicy", []cel.CosTlv{{EventType: cel.InitContainerType, EventContent: []byte("weights")}, {EventType: cel.RestartPolicyType, EventContent: []byte(attestpb.RestartPolicy_Always.String())}}},
+		{"DuplicateImageRef", []cel.CosTlv{sidecar, {EventType: cel.ImageRefType, EventContent: []byte("a")}, {EventType: cel.ImageRefType, EventContent: []byte("b")}}},
+		{"RestartPolicy", []cel.CosTlv{sidecar, {EventType: cel.RestartPolicyType, EventContent: []byte(attestpb.RestartPolicy_Always.String())}}},
+		{"AddedCapability", []cel.CosTlv{sidecar, {EventType: cel.AddedCapabilityType, EventContent: []byte("CAP_SYS_ADMIN")}}},
+		{"CgroupNamespace", []cel.CosTlv{sidecar, {EventType: cel.CgroupNamespaceType, EventContent: []byte{1}}}},
+		{"LogRedirect", []cel.CosTlv{sidecar, {EventType: cel.LogRedirectType, EventContent: []byte(attestpb.LogRedirectLocation_LOG_REDIRECT_SERIAL.String())}}},
+		{"ImpersonatedServiceAccount", []cel.CosTlv{sidecar, {EventType: cel.ImpersonatedServiceAccountType, EventContent: []byte("sa@project.iam.gserviceaccount.com")}}},
+		{"MainContainerAttestationAccess", []cel.CosTlv{{EventType: cel.AttestationAccessType, EventContent: []byte{1}}}},
+		{"DuplicateAttestationAccess", []cel.CosTlv{sidecar, {EventType: cel.AttestationAccessType, EventContent: []byte{1}}, {EventType: cel.AttestationAccessType, EventContent: []byte{0}}}},
+		{"InvalidAttestationAccess", []cel.CosTlv{sidecar, {EventType: cel.AttestationAccessType, EventContent: []byte{2}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			coscel := cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEventPCR(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := getVerifiedCosState(coscel, cel.PCRTypeValue); err == nil {
+				t.Error("getVerifiedCosState() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestParsingCELEventLogShutdown(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	imageRef := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/hello-world:latest")}
+	separator := cel.CosTlv{EventType: cel.LaunchSeparatorType}
+	shutdown := cel.CosTlv{EventType: cel.ShutdownType, EventContent: []byte("SIGTERM")}
+	for _, tc := range []struct {
+		name       string
+		events     []cel.CosTlv
+		wantSignal string
+		wantErr    bool
+	}{
+		{"NoShutdown", []cel.CosTlv{imageRef, separator}, "", false},
+		{"Shutdown", []cel.CosTlv{imageRef, separator, shutdown}, "SIGTERM", false},
+		{"ShutdownBeforeSeparator", []cel.CosTlv{imageRef, shutdown, separator}, "", true},
+		{"DuplicateShutdown", []cel.CosTlv{imageRef, separator, shutdown, shutdown}, "", true},
+		{"EmptySignal", []cel.CosTlv{imageRef, separator, {EventType: cel.ShutdownType}}, "", true},
+		{"ContainerEventAfterShutdown", []cel.CosTlv{imageRef, separator, shutdown, {EventType: cel.ArgType, EventContent: []byte("--x")}}, "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			coscel := cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEventPCR(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cosState, err := getVerifiedCosState(coscel, cel.PCRTypeValue)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("getVerifiedCosState() got error %v, want error %v", err, tc.wantErr)
+			}
+			if got := cosState.GetShutdownSignal(); got != tc.wantSignal {
+				t.Errorf("got shutdown signal %q, want %q", got, tc.wantSignal)
+			}
+		})
+	}
+}
+
+func TestParsingCELEventLogLocalVerifierKey(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageRef := cel.CosTlv{EventType: cel.ImageRefType, EventContent: []byte("docker.io/library/hello-world:latest")}
+	key := cel.CosTlv{EventType: cel.LocalVerifierKeyType, EventContent: der}
+	sidecar := cel.CosTlv{EventType: cel.SidecarType, EventContent: []byte("proxy")}
+	separator := cel.CosTlv{EventType: cel.LaunchSeparatorType}
+	for _, tc := range []struct {
+		name    string
+		events  []cel.CosTlv
+		wantKey []byte
+		wantErr bool
+	}{
+		{"NoKey", []cel.CosTlv{imageRef, separator}, nil, false},
+		{"Key", []cel.CosTlv{imageRef, key, separator}, der, false},
+		{"DuplicateKey", []cel.CosTlv{imageRef, key, key, separator}, nil, true},
+		{"InvalidKey", []cel.CosTlv{imageRef, {EventType: cel.LocalVerifierKeyType, EventContent: []byte("key")}, separator}, nil, true},
+		{"SidecarKey", []cel.CosTlv{imageRef, sidecar, key, separator}, nil, true},
+		{"KeyAfterSeparator", []cel.CosTlv{imageRef, separator, key}, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			coscel := cel.CEL{}
+			for _, event := range tc.events {
+				if err := coscel.AppendEventPCR(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, event); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cosState, err := getVerifiedCosState(coscel, cel.PCRTypeValue)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("getVerifiedCosState() got error %v, want error %v", err, tc.wantErr)
+			}
+			if got := cosState.GetLocalVerifierKey(); !bytes.Equal(got, tc.wantKey) {
+				t.Errorf("got local verifier key %x, want %x", got, tc.wantKey)
+			}
+		})
+	}
+}
+
+func TestParsingCELEventLogInvalidFlagEvents(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	for _, eventType := range []cel.CosType{cel.CgroupNamespaceType, cel.HardenedImageType} {
+		for _, content := range [][]byte{{}, {2}, {1, 0}} {
+			t.Run(fmt.Sprintf("%v/%v", eventType, content), func(t *testing.T) {
+				coscel := cel.CEL{}
+				if err := coscel.AppendEventPCR(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, cel.CosTlv{EventType: eventType, EventContent: content}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := getVerifiedCosState(coscel, cel.PCRTypeValue); err == nil {
+					t.Error("getVerifiedCosState() got nil error, want error")
+				}
+			})
+		}
+	}
+}
+
+func TestParseCosCELPCRWithLimits(t *testing.T) {
+	test.SkipForRealTPM(t)
+	tpm := test.GetTPM(t)
+	defer client.CheckedClose(t, tpm)
+
+	// An image reference larger than the default record size limit.
+	coscel := cel.CEL{}
+	largeRef := bytes.Repeat([]byte("a"), cel.DefaultDecodeLimits().MaxRecordSize)
+	if err := coscel.AppendEventPCR(tpm, cel.CosEventPCR, []crypto.Hash{crypto.SHA256}, cel.CosTlv{EventType: cel.ImageRefType, EventContent: largeRef}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := coscel.EncodeCEL(&buf); err != nil {
+		t.Fatal(err)
+	}
+	bank, err := client.ReadPCRs(tpm, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{cel.CosEventPCR}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcrBank := convertToPCRBank(t, bank)
+
+	if _, err := ParseCosCELPCR(buf.Bytes(), pcrBank); err != nil {
+		t.Errorf("ParseCosCELPCR() got err = %v, want nil", err)
+	}
+	if _, err := ParseCosCELPCRWithLimits(buf.Bytes(), pcrBank, cel.DefaultDecodeLimits()); err == nil {
+		t.Error("ParseCosCELPCRWithLimits() with a record above the default limit got nil error, want error")
+	}
+	limits := cel.DefaultDecodeLimits()
+	limits.MaxRecordSize *= 2
+	acosState, err := ParseCosCELPCRWithLimits(buf.Bytes(), pcrBank, limits)
+	if err != nil {
+		t.Fatalf("ParseCosCELPCRWithLimits() with a loosened limit got err = %v, want nil", err)
+	}
+	if got := acosState.GetContainer().GetImageReference(); got != string(largeRef) {
+		t.Errorf("ParseCosCELPCRWithLimits() got an image reference of %d bytes, want %d", len(got), len(largeRef))
+	}
+	if _, err := ParseCosCELPCRWithLimits(buf.Bytes(), pcrBank, cel.DecodeLimits{MaxRecords: 1, MaxTotalSize: 1024}); err == nil {
+		t.Error("ParseCosCELPCRWithLimits() with a tightened limit got nil error, want error")
+	}
+}
+
 func generateNonCosCelEvent(hashAlgoList []crypto.Hash) (cel.Record, error) {
 	randRecord := cel.Record{}
 	randRecord.RecNum = 0
@@ -1271,6 +1540,373 @@ func TestGetGrubStateWithModifiedNullTerminator(t *testing.T) {
 	}
 }
 
+func TestParseShimState(t *testing.T) {
+	logs := []struct {
+		eventLog
+		name             string
+		wantMokList      string
+		wantMokListX     string
+		wantSbatLevel    string
+		wantAuthoritySrc attestpb.ShimAuthoritySource
+	}{
+		{Rhel8GCE, "Rhel8GCE", "b64394ecdac7000add7197d2ad5243c4c7752883", "525ff70d4cfa4b2c76a2e23fc4490797932bd3f2", "", attestpb.ShimAuthoritySource_AUTHORITY_SOURCE_SHIM_VENDOR},
+		{COS101AmdSev, "COS101AmdSev", "525ff70d4cfa4b2c76a2e23fc4490797932bd3f2", "525ff70d4cfa4b2c76a2e23fc4490797932bd3f2", "sbat,1,2021030218\n", attestpb.ShimAuthoritySource_AUTHORITY_SOURCE_DB},
+		{UbuntuAmdSevGCE, "UbuntuAmdSevGCE", "", "", "", attestpb.ShimAuthoritySource_AUTHORITY_SOURCE_UNKNOWN},
+	}
+	for _, log := range logs {
+		// Use the SHA1 bank, so the expected MOK digests are the same.
+		bank := log.Banks[0]
+		t.Run(log.name, func(t *testing.T) {
+			msState, err := parsePCClientEventLog(log.RawLog, bank, UnsupportedLoader)
+			if err != nil {
+				t.Fatalf("failed to parse and replay log: %v", err)
+			}
+			sbState := msState.GetSecureBoot()
+			if got := hex.EncodeToString(sbState.GetMokListDigest()); got != log.wantMokList {
+				t.Errorf("MokList digest got %s, want %s", got, log.wantMokList)
+			}
+			if got := hex.EncodeToString(sbState.GetMokListXDigest()); got != log.wantMokListX {
+				t.Errorf("MokListX digest got %s, want %s", got, log.wantMokListX)
+			}
+			if sbState.GetSbatLevel() != log.wantSbatLevel {
+				t.Errorf("SbatLevel got %q, want %q", sbState.GetSbatLevel(), log.wantSbatLevel)
+			}
+			if sbState.GetKernelAuthoritySource() != log.wantAuthoritySrc {
+				t.Errorf("kernel authority source got %v, want %v", sbState.GetKernelAuthoritySource(), log.wantAuthoritySrc)
+			}
+		})
+	}
+}
+
+func TestParseShimMokAuthority(t *testing.T) {
+	msState, err := parsePCClientEventLog(Rhel8GCE.RawLog, Rhel8GCE.Banks[0], UnsupportedLoader)
+	if err != nil {
+		t.Fatalf("failed to parse and replay log: %v", err)
+	}
+	// Rewrite the authority event of the RHEL 8 shim, which verified the
+	// kernel with its vendor certificate, as shim measures the same
+	// certificate found in the MOK list.
+	events := msState.GetRawEvents()
+	found := false
+	for _, event := range events {
+		if event.GetPcrIndex() != 7 || event.GetUntrustedType() != EFIVariableAuthority {
+			continue
+		}
+		guid, name, value, err := parseUEFIVariableData(event.GetData())
+		if err != nil || guid != shimLockGUID || name != "Shim" {
+			continue
+		}
+		var data []byte
+		data = append(data, shimLockGUID[:]...)
+		data = binary.LittleEndian.AppendUint64(data, uint64(len("MokListRT")))
+		data = binary.LittleEndian.AppendUint64(data, uint64(len(value)))
+		data = append(data, utf16Bytes("MokListRT")[:2*len("MokListRT")]...)
+		data = append(data, value...)
+		h := crypto.SHA1.New()
+		h.Write(data)
+		event.Data = data
+		event.Digest = h.Sum(nil)
+		found = true
+	}
+	if !found {
+		t.Fatal("no shim vendor authority event in the log")
+	}
+
+	sbState := &attestpb.SecureBootState{}
+	if err := addShimState(events, sbState); err != nil {
+		t.Fatalf("addShimState() got err = %v, want nil", err)
+	}
+	if sbState.GetKernelAuthoritySource() != attestpb.ShimAuthoritySource_AUTHORITY_SOURCE_MOK {
+		t.Errorf("kernel authority source got %v, want %v", sbState.GetKernelAuthoritySource(), attestpb.ShimAuthoritySource_AUTHORITY_SOURCE_MOK)
+	}
+}
+
+func TestParseFirmwareInventory(t *testing.T) {
+	logs := []struct {
+		eventLog
+		name           string
+		wantBootOrder  []uint32
+		wantBootOption *attestpb.BootOption
+		wantDriverPath string
+	}{
+		{
+			eventLog:      UbuntuAmdSevGCE,
+			name:          "UbuntuAmdSevGCE",
+			wantBootOrder: []uint32{2, 0, 1},
+			wantBootOption: &attestpb.BootOption{
+				Number:         2,
+				Attributes:     1,
+				Description:    "ubuntu",
+				DevicePaths:    []string{`HD(15,GPT,86ecc9e1-fb24-47e6-8b51-005a5aded596,0x2800,0x35000)/\EFI\ubuntu\shimx64.efi`},
+				DigestVerified: true,
+			},
+		},
+		{
+			eventLog:      ArchLinuxWorkstation,
+			name:          "ArchLinuxWorkstation",
+			wantBootOrder: []uint32{0, 3, 2},
+			wantBootOption: &attestpb.BootOption{
+				Number:         0,
+				Attributes:     1,
+				Description:    "Linux Boot Manager",
+				DevicePaths:    []string{`HD(1,GPT,1a504613-19b5-4b44-a83d-d926d40daa1c,0x800,0x80000)/\EFI\SYSTEMD\SYSTEMD-BOOTX64.EFI`},
+				DigestVerified: true,
+			},
+			wantDriverPath: "PciRoot(0x0)/Pci(0x1,0x0)/Pci(0x0,0x0)/Offset(0xf250,0x201ff)",
+		},
+	}
+	for _, log := range logs {
+		t.Run(log.name, func(t *testing.T) {
+			events, err := parseReplayHelper(log.RawLog, log.Banks[0])
+			if err != nil {
+				t.Fatalf("failed to parse and replay log: %v", err)
+			}
+			cryptoHash, _ := tpm2.Algorithm(log.Banks[0].Hash).Hash()
+			inventory := getFirmwareInventory(cryptoHash, convertToPbEvents(cryptoHash, events))
+			if len(inventory.GetParseErrors()) != 0 {
+				t.Errorf("getFirmwareInventory() got parse errors %v, want none", inventory.GetParseErrors())
+			}
+			if diff := cmp.Diff(log.wantBootOrder, inventory.GetBootOrder()); diff != "" {
+				t.Errorf("unexpected boot order (-want +got):\n%s", diff)
+			}
+			var gotOption *attestpb.BootOption
+			for _, option := range inventory.GetBootOptions() {
+				if option.GetNumber() == log.wantBootOption.GetNumber() {
+					gotOption = option
+				}
+			}
+			if diff := cmp.Diff(log.wantBootOption, gotOption, protocmp.Transform(), protocmp.IgnoreFields(&attestpb.BootOption{}, "digest")); diff != "" {
+				t.Errorf("unexpected boot option (-want +got):\n%s", diff)
+			}
+			if log.wantDriverPath == "" {
+				return
+			}
+			var found bool
+			for _, component := range inventory.GetComponents() {
+				if component.GetUntrustedType() == EFIBootServicesDriver && component.GetDevicePath() == log.wantDriverPath {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("driver with device path %q not found in inventory components", log.wantDriverPath)
+			}
+		})
+	}
+}
+
+func TestParseFirmwareInventoryMalformed(t *testing.T) {
+	events := []*attestpb.Event{
+		{PcrIndex: 0, UntrustedType: EFIPlatformFirmwareBlob, Data: []byte{1, 2, 3}, Digest: []byte("blob")},
+		{PcrIndex: 0, UntrustedType: EFIHandoffTables, Data: []byte{1}, Digest: []byte("tables")},
+		{PcrIndex: 1, UntrustedType: EFIVariableBoot, Data: []byte("not a variable"), Digest: []byte("boot")},
+		{PcrIndex: 2, UntrustedType: EFIBootServicesDriver, Data: []byte("short"), Digest: []byte("driver")},
+	}
+	inventory := getFirmwareInventory(crypto.SHA256, events)
+	want := &attestpb.FirmwareInventory{
+		Components: []*attestpb.FirmwareComponent{
+			{PcrIndex: 0, UntrustedType: EFIPlatformFirmwareBlob, Digest: []byte("blob")},
+			{PcrIndex: 0, UntrustedType: EFIHandoffTables, Digest: []byte("tables")},
+			{PcrIndex: 2, UntrustedType: EFIBootServicesDriver, Digest: []byte("driver")},
+		},
+	}
+	if diff := cmp.Diff(want, inventory, protocmp.Transform(), protocmp.IgnoreFields(&attestpb.FirmwareInventory{}, "parse_errors")); diff != "" {
+		t.Errorf("getFirmwareInventory() returned unexpected diff (-want +got):\n%s", diff)
+	}
+	if got := len(inventory.GetParseErrors()); got != len(events) {
+		t.Errorf("getFirmwareInventory() got %d parse errors, want %d", got, len(events))
+	}
+}
+
+func TestParseUEFIVariableData(t *testing.T) {
+	name := "MokList"
+	value := []byte{1, 2, 3}
+	var data []byte
+	data = append(data, shimLockGUID[:]...)
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(name)))
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(value)))
+	data = append(data, utf16Bytes(name)[:2*len(name)]...)
+	data = append(data, value...)
+
+	guid, gotName, gotValue, err := parseUEFIVariableData(data)
+	if err != nil {
+		t.Fatalf("parseUEFIVariableData() got err = %v, want nil", err)
+	}
+	if guid != shimLockGUID || gotName != name || !bytes.Equal(gotValue, value) {
+		t.Errorf("parseUEFIVariableData() got (%x, %q, %x), want (%x, %q, %x)", guid, gotName, gotValue, shimLockGUID, name, value)
+	}
+	if _, _, _, err := parseUEFIVariableData(data[:len(data)-1]); err == nil {
+		t.Error("parseUEFIVariableData() on truncated data got nil error, want error")
+	}
+}
+
+func utf16Bytes(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		b = append(b, byte(r), 0)
+	}
+	return append(b, 0, 0)
+}
+
+func ukiEvents(hash crypto.Hash, cmdline string) []*attestpb.Event {
+	digest := func(data []byte) []byte {
+		h := hash.New()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+	events := []*attestpb.Event{
+		{PcrIndex: 4, UntrustedType: EFIBootServicesApplication, Digest: digest([]byte("systemd-boot"))},
+		{PcrIndex: 4, UntrustedType: EFIBootServicesApplication, Digest: digest([]byte("uki"))},
+	}
+	for _, section := range []string{".linux", ".osrel", ".cmdline", ".initrd"} {
+		events = append(events,
+			&attestpb.Event{PcrIndex: 11, UntrustedType: IPL, Data: utf16Bytes(section), Digest: digest(append([]byte(section), 0))},
+			&attestpb.Event{PcrIndex: 11, UntrustedType: IPL, Data: utf16Bytes(section), Digest: digest([]byte(section + " contents"))},
+		)
+	}
+	if cmdline != "" {
+		options := utf16Bytes(cmdline)
+		events = append(events, &attestpb.Event{PcrIndex: 12, UntrustedType: IPL, Data: options, Digest: digest(options)})
+	}
+	// Credential and system extension measurements, whose descriptions do not
+	// match their digests.
+	events = append(events,
+		&attestpb.Event{PcrIndex: 12, UntrustedType: IPL, Data: utf16Bytes("Credentials initrd"), Digest: digest([]byte("cpio"))},
+		&attestpb.Event{PcrIndex: 12, UntrustedType: IPL, Data: utf16Bytes("System extension initrd"), Digest: digest([]byte("cpio"))},
+	)
+	return events
+}
+
+func TestParseUkiState(t *testing.T) {
+	hash := crypto.SHA256
+	digest := func(data string) []byte {
+		h := hash.New()
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	events := ukiEvents(hash, "console=ttyS0 root=/dev/sda1")
+
+	uki, err := getUkiState(hash, events)
+	if err != nil {
+		t.Fatalf("getUkiState() got err = %v, want nil", err)
+	}
+	want := &attestpb.UkiState{
+		ImageDigest: digest("uki"),
+		Sections: []*attestpb.UkiSection{
+			{Name: ".linux", Digest: digest(".linux contents")},
+			{Name: ".osrel", Digest: digest(".osrel contents")},
+			{Name: ".cmdline", Digest: digest(".cmdline contents")},
+			{Name: ".initrd", Digest: digest(".initrd contents")},
+		},
+		LinuxDigest:   digest(".linux contents"),
+		InitrdDigest:  digest(".initrd contents"),
+		CmdlineDigest: digest(".cmdline contents"),
+		OsrelDigest:   digest(".osrel contents"),
+	}
+	if diff := cmp.Diff(want, uki, protocmp.Transform()); diff != "" {
+		t.Errorf("getUkiState() returned unexpected diff (-want +got):\n%s", diff)
+	}
+
+	kernel, err := getLinuxKernelStateFromUKI(hash, events)
+	if err != nil {
+		t.Fatalf("getLinuxKernelStateFromUKI() got err = %v, want nil", err)
+	}
+	wantKernel := &attestpb.LinuxKernelState{
+		CommandLine: "console=ttyS0 root=/dev/sda1",
+		UnverifiedUkiEvents: []*attestpb.UnverifiedUkiEvent{
+			{UntrustedDescription: "Credentials initrd", Digest: digest("cpio")},
+			{UntrustedDescription: "System extension initrd", Digest: digest("cpio")},
+		},
+	}
+	if diff := cmp.Diff(wantKernel, kernel, protocmp.Transform()); diff != "" {
+		t.Errorf("getLinuxKernelStateFromUKI() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseUkiRelabelledCmdline(t *testing.T) {
+	hash := crypto.SHA256
+	h := hash.New()
+	h.Write(utf16Bytes("console=ttyS0 init=/bin/sh"))
+	cmdlineDigest := h.Sum(nil)
+	// A command line override measured with the description of a credential
+	// archive.
+	events := append(ukiEvents(hash, ""), &attestpb.Event{PcrIndex: 12, UntrustedType: IPL, Data: utf16Bytes("Credentials initrd"), Digest: cmdlineDigest})
+
+	kernel, err := getLinuxKernelStateFromUKI(hash, events)
+	if err != nil {
+		t.Fatalf("getLinuxKernelStateFromUKI() got err = %v, want nil", err)
+	}
+	if kernel.GetCommandLine() != "" {
+		t.Errorf("getLinuxKernelStateFromUKI() got command line %q, want none verified", kernel.GetCommandLine())
+	}
+	unverified := kernel.GetUnverifiedUkiEvents()
+	if len(unverified) != 3 || !bytes.Equal(unverified[2].GetDigest(), cmdlineDigest) {
+		t.Errorf("getLinuxKernelStateFromUKI() got unverified events %v, want the relabelled command line last", unverified)
+	}
+}
+
+func TestParseUkiStateEmbeddedCmdline(t *testing.T) {
+	hash := crypto.SHA256
+	kernel, err := getLinuxKernelStateFromUKI(hash, ukiEvents(hash, ""))
+	if err != nil {
+		t.Fatalf("getLinuxKernelStateFromUKI() got err = %v, want nil", err)
+	}
+	if kernel.GetCommandLine() != "" {
+		t.Errorf("getLinuxKernelStateFromUKI() got command line %q, want empty", kernel.GetCommandLine())
+	}
+}
+
+func TestParseUkiCmdlineFail(t *testing.T) {
+	hash := crypto.SHA256
+	digest := func(data []byte) []byte {
+		h := hash.New()
+		h.Write(data)
+		return h.Sum(nil)
+	}
+	options := utf16Bytes("console=ttyS0 init=/bin/sh")
+	tagged := binary.LittleEndian.AppendUint32(nil, LoadOptionsEventTagID)
+	tagged = binary.LittleEndian.AppendUint32(tagged, uint32(len(options)))
+	tagged = append(tagged, options...)
+	tests := []struct {
+		name  string
+		event *attestpb.Event
+	}{
+		{"ForgedIPLCmdline", &attestpb.Event{PcrIndex: 12, UntrustedType: IPL, Data: options, Digest: digest(utf16Bytes("console=ttyS0"))}},
+		{"ForgedTaggedCmdline", &attestpb.Event{PcrIndex: 12, UntrustedType: EventTag, Data: tagged, Digest: digest(utf16Bytes("console=ttyS0"))}},
+		{"TruncatedTaggedEvent", &attestpb.Event{PcrIndex: 12, UntrustedType: EventTag, Data: tagged[:len(tagged)-2], Digest: digest(options)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events := append(ukiEvents(hash, ""), tc.event)
+			if _, err := getLinuxKernelStateFromUKI(hash, events); err == nil {
+				t.Error("getLinuxKernelStateFromUKI() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestParseUkiStateFail(t *testing.T) {
+	hash := crypto.SHA256
+	events := ukiEvents(hash, "")
+	tests := []struct {
+		name   string
+		events []*attestpb.Event
+	}{
+		{"NoUkiEvents", events[:2]},
+		{"MissingSectionContents", events[:3]},
+		{"ModifiedSectionName", append(append([]*attestpb.Event{}, events[:2]...),
+			&attestpb.Event{PcrIndex: 11, UntrustedType: IPL, Data: utf16Bytes(".initrd"), Digest: events[2].GetDigest()},
+			events[3])},
+		{"MismatchedSectionContents", append(append([]*attestpb.Event{}, events[:3]...), events[5])},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := getUkiState(hash, tc.events); err == nil {
+				t.Error("getUkiState() got nil error, want error")
+			}
+		})
+	}
+}
+
 func decodeHex(hexStr string) []byte {
 	bytes, err := hex.DecodeString(hexStr)
 	if err != nil {
@@ -1278,3 +1914,45 @@ func decodeHex(hexStr string) []byte {
 	}
 	return bytes
 }
+
+func TestCheckEventLogLimits(t *testing.T) {
+	for _, log := range []struct {
+		eventLog
+		name string
+		// Crypto agile logs start with a Spec ID Event, which is not returned
+		// by the parser.
+		cryptoAgile bool
+	}{
+		{Rhel8GCE, "Rhel8GCE", true},
+		{UbuntuAmdSevGCE, "UbuntuAmdSevGCE", true},
+		{ArchLinuxWorkstation, "ArchLinuxWorkstation", true},
+		{Debian10GCE, "Debian10GCE", false},
+		{COS101AmdSev, "COS101AmdSev", true},
+	} {
+		t.Run(log.name, func(t *testing.T) {
+			if err := checkEventLogLimits(log.RawLog, cel.DefaultDecodeLimits()); err != nil {
+				t.Fatalf("checkEventLogLimits() with default limits failed: %v", err)
+			}
+			eventLog, err := attest.ParseEventLog(log.RawLog)
+			if err != nil {
+				t.Fatal(err)
+			}
+			numEvents := len(eventLog.Events(attest.HashSHA1))
+			if log.cryptoAgile {
+				numEvents++
+			}
+			if err := checkEventLogLimits(log.RawLog, cel.DecodeLimits{MaxRecords: numEvents}); err != nil {
+				t.Errorf("checkEventLogLimits() with %d events failed: %v", numEvents, err)
+			}
+			if err := checkEventLogLimits(log.RawLog, cel.DecodeLimits{MaxRecords: numEvents - 1}); err == nil {
+				t.Errorf("checkEventLogLimits() with %d events succeeded, want error", numEvents-1)
+			}
+			if err := checkEventLogLimits(log.RawLog, cel.DecodeLimits{MaxRecordSize: 64}); err == nil {
+				t.Error("checkEventLogLimits() with 64 byte events succeeded, want error")
+			}
+			if err := checkEventLogLimits(log.RawLog, cel.DecodeLimits{MaxTotalSize: int64(len(log.RawLog) - 1)}); err == nil {
+				t.Error("checkEventLogLimits() with a smaller total size succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	pb "github.com/google/go-tpm-tools/proto/attest"
+)
+
+// efiGlobalVariableGUID is EFI_GLOBAL_VARIABLE (8be4df61-93ca-11d2-aa0d-00e098032b8c),
+// used by the BootOrder and Boot#### variables, in the mixed-endian EFI_GUID
+// encoding.
+var efiGlobalVariableGUID = [16]byte{0x61, 0xdf, 0xe4, 0x8b, 0xca, 0x93, 0xd2, 0x11,
+	0xaa, 0x0d, 0x00, 0xe0, 0x98, 0x03, 0x2b, 0x8c}
+
+// getFirmwareInventory builds the inventory of firmware components and boot
+// options measured into PCRs 0-2. The digests of firmware blobs, handoff
+// tables and drivers are over memory contents which are not part of the log,
+// so only their digests (verified by the PCR replay) can be trusted. The
+// decoded descriptions, memory locations and device paths are informational,
+// so the inventory is best-effort: events which cannot be parsed are recorded
+// in ParseErrors instead of failing the verification of the event log.
+func getFirmwareInventory(hash crypto.Hash, events []*pb.Event) *pb.FirmwareInventory {
+	hasher := hash.New()
+	inventory := &pb.FirmwareInventory{}
+	for idx, event := range events {
+		if event.GetPcrIndex() > 2 {
+			continue
+		}
+		component := &pb.FirmwareComponent{
+			PcrIndex:      event.GetPcrIndex(),
+			UntrustedType: event.GetUntrustedType(),
+			Digest:        event.GetDigest(),
+		}
+		data := event.GetData()
+		var err error
+		switch event.GetUntrustedType() {
+		case PostCode:
+			// EV_POST_CODE contains either a UEFI_PLATFORM_FIRMWARE_BLOB or
+			// a string such as "POST CODE" or "Embedded UEFI Driver".
+			if len(data) == 16 && !isPrintable(data) {
+				component.Base, component.Length, err = parseFirmwareBlob(data)
+			} else {
+				component.Description = string(bytes.TrimRight(data, "\x00"))
+			}
+		case EFIPlatformFirmwareBlob:
+			component.Base, component.Length, err = parseFirmwareBlob(data)
+		case EFIPlatformFirmwareBlob2:
+			var desc []byte
+			if desc, data, err = parseDescription(data); err == nil {
+				component.Description = string(desc)
+				component.Base, component.Length, err = parseFirmwareBlob(data)
+			}
+		case EFIHandoffTables:
+			component.TableGuids, err = parseHandoffTables(data)
+		case EFIHandoffTables2:
+			var desc []byte
+			if desc, data, err = parseDescription(data); err == nil {
+				component.Description = string(desc)
+				component.TableGuids, err = parseHandoffTables(data)
+			}
+		case EFIBootServicesDriver, EFIRuntimeServicesDriver:
+			component.Base, component.Length, component.DevicePath, err = parseImageLoadEvent(data)
+		case EFIVariableBoot, EFIVariableBoot2:
+			if err := addBootVariable(hasher, event, inventory); err != nil {
+				inventory.ParseErrors = append(inventory.ParseErrors, fmt.Sprintf("invalid boot variable event #%d: %v", idx, err))
+			}
+			continue
+		default:
+			continue
+		}
+		// The digest of a component is still worth reporting when its data
+		// cannot be decoded.
+		if err != nil {
+			inventory.ParseErrors = append(inventory.ParseErrors, fmt.Sprintf("invalid firmware event #%d (type %#x): %v", idx, event.GetUntrustedType(), err))
+		}
+		inventory.Components = append(inventory.Components, component)
+	}
+	if len(inventory.GetComponents()) == 0 && len(inventory.GetBootOptions()) == 0 && len(inventory.GetBootOrder()) == 0 && len(inventory.GetParseErrors()) == 0 {
+		return nil
+	}
+	return inventory
+}
+
+func isPrintable(data []byte) bool {
+	for _, b := range bytes.TrimRight(data, "\x00") {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFirmwareBlob parses a UEFI_PLATFORM_FIRMWARE_BLOB.
+func parseFirmwareBlob(data []byte) (base uint64, length uint64, err error) {
+	if len(data) != 16 {
+		return 0, 0, fmt.Errorf("UEFI_PLATFORM_FIRMWARE_BLOB has invalid size %d", len(data))
+	}
+	return binary.LittleEndian.Uint64(data[0:8]), binary.LittleEndian.Uint64(data[8:16]), nil
+}
+
+// parseDescription parses the byte-length-prefixed description of the
+// EV_EFI_PLATFORM_FIRMWARE_BLOB2 and EV_EFI_HANDOFF_TABLES2 events. It returns
+// the description and the remaining data.
+func parseDescription(data []byte) ([]byte, []byte, error) {
+	if len(data) < 1 || int(data[0]) > len(data)-1 {
+		return nil, nil, errors.New("invalid description size")
+	}
+	size := int(data[0])
+	return bytes.TrimRight(data[1:1+size], "\x00"), data[1+size:], nil
+}
+
+// parseHandoffTables parses a UEFI_HANDOFF_TABLE_POINTERS and returns the
+// vendor GUIDs of the tables.
+func parseHandoffTables(data []byte) ([]string, error) {
+	const tableSize = 16 + 8
+	if len(data) < 8 {
+		return nil, errors.New("UEFI_HANDOFF_TABLE_POINTERS too short")
+	}
+	count := binary.LittleEndian.Uint64(data[0:8])
+	tables := data[8:]
+	if count != uint64(len(tables)/tableSize) || len(tables)%tableSize != 0 {
+		return nil, fmt.Errorf("UEFI_HANDOFF_TABLE_POINTERS has %d tables, but %d bytes of tables", count, len(tables))
+	}
+	guids := make([]string, 0, count)
+	for i := 0; i < len(tables); i += tableSize {
+		guids = append(guids, formatEFIGUID(tables[i:i+16]))
+	}
+	return guids, nil
+}
+
+// parseImageLoadEvent parses a UEFI_IMAGE_LOAD_EVENT and returns the image
+// location, length and device path.
+func parseImageLoadEvent(data []byte) (base uint64, length uint64, devicePath string, err error) {
+	const headerSize = 4 * 8
+	if len(data) < headerSize {
+		return 0, 0, "", errors.New("UEFI_IMAGE_LOAD_EVENT too short")
+	}
+	base = binary.LittleEndian.Uint64(data[0:8])
+	length = binary.LittleEndian.Uint64(data[8:16])
+	pathLen := binary.LittleEndian.Uint64(data[24:32])
+	if pathLen > uint64(len(data)-headerSize) {
+		return 0, 0, "", fmt.Errorf("device path length %d exceeds event size", pathLen)
+	}
+	paths, err := parseEFIDevicePaths(data[headerSize : headerSize+pathLen])
+	if err != nil {
+		return 0, 0, "", err
+	}
+	return base, length, strings.Join(paths, ","), nil
+}
+
+// addBootVariable adds the BootOrder or Boot#### variable to the inventory.
+// Other variables (e.g., BootNext or OsIndications) are ignored.
+func addBootVariable(hasher hash.Hash, event *pb.Event, inventory *pb.FirmwareInventory) error {
+	guid, name, value, err := parseUEFIVariableData(event.GetData())
+	if err != nil {
+		return err
+	}
+	if guid != efiGlobalVariableGUID {
+		return nil
+	}
+	if name == "BootOrder" {
+		if len(value)%2 != 0 {
+			return fmt.Errorf("BootOrder has odd size %d", len(value))
+		}
+		for i := 0; i < len(value); i += 2 {
+			inventory.BootOrder = append(inventory.BootOrder, uint32(binary.LittleEndian.Uint16(value[i:])))
+		}
+		return nil
+	}
+	if len(name) != 8 || !strings.HasPrefix(name, "Boot") {
+		return nil
+	}
+	number, err := strconv.ParseUint(name[4:], 16, 16)
+	if err != nil {
+		// Not a Boot#### variable (e.g., BootNext).
+		return nil
+	}
+	option, err := parseLoadOption(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", name, err)
+	}
+	option.Number = uint32(number)
+	option.Digest = event.GetDigest()
+	option.DigestVerified = event.GetDigestVerified() ||
+		verifyDataDigest(hasher, value, event.GetDigest()) == nil
+	inventory.BootOptions = append(inventory.BootOptions, option)
+	return nil
+}
+
+// parseLoadOption parses an EFI_LOAD_OPTION.
+func parseLoadOption(data []byte) (*pb.BootOption, error) {
+	if len(data) < 6 {
+		return nil, errors.New("EFI_LOAD_OPTION too short")
+	}
+	option := &pb.BootOption{Attributes: binary.LittleEndian.Uint32(data[0:4])}
+	pathLen := int(binary.LittleEndian.Uint16(data[4:6]))
+	data = data[6:]
+	// The description is a null-terminated UCS-2 string.
+	end := -1
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, errors.New("EFI_LOAD_OPTION description is not null-terminated")
+	}
+	desc, err := decodeUTF16LE(data[:end])
+	if err != nil {
+		return nil, err
+	}
+	option.Description = desc
+	data = data[end+2:]
+	if pathLen > len(data) {
+		return nil, fmt.Errorf("file path list length %d exceeds option size", pathLen)
+	}
+	if option.DevicePaths, err = parseEFIDevicePaths(data[:pathLen]); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+// Supported IMA templates.
+// See https://www.kernel.org/doc/html/latest/security/IMA-templates.html.
+const (
+	ImaNgTemplate  = "ima-ng"
+	ImaSigTemplate = "ima-sig"
+)
+
+const (
+	// ImaPCR is the default PCR used by IMA.
+	ImaPCR = 10
+	// ImaBootAggregate is the path of the first IMA entry, which measures
+	// the PCRs extended by the firmware and boot loader.
+	ImaBootAggregate = "boot_aggregate"
+	// TCG_EVENT_NAME_LEN_MAX in the kernel.
+	imaMaxTemplateNameLen = 255
+)
+
+// imaEntry is a parsed IMA measurement list entry.
+type imaEntry struct {
+	pcr            uint32
+	templateDigest []byte
+	templateName   string
+	// The template data fields, without their length prefix.
+	fields [][]byte
+}
+
+// templateData returns the template data as hashed by the kernel: each field
+// is prefixed by its little-endian 32-bit length.
+func (e *imaEntry) templateData() []byte {
+	var data []byte
+	for _, field := range e.fields {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(field)))
+		data = append(data, field...)
+	}
+	return data
+}
+
+// isViolation returns whether the entry records a measurement violation, in
+// which case the kernel logs a zero template digest.
+func (e *imaEntry) isViolation() bool {
+	for _, b := range e.templateDigest {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImaEventLog parses a binary or ascii IMA runtime measurement list and
+// replays it against the given PCRs. Every entry must extend a PCR in pcrs.
+// As a measurement list read along with a quote may have grown since the
+// quote, the log is replayed to its longest prefix matching the PCRs. It
+// returns the ImaState containing the entries of this prefix, or nil if the
+// log has no entries. The log is decoded within the limits.
+func parseImaEventLog(rawImaLog []byte, pcrs *tpmpb.PCRs, limits cel.DecodeLimits) (*pb.ImaState, error) {
+	hashAlg := tpm2.Algorithm(pcrs.GetHash())
+	cryptoHash, err := hashAlg.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("received bad PCR proto: %v", err)
+	}
+	if err := checkLogTotalSize(rawImaLog, limits); err != nil {
+		return nil, fmt.Errorf("invalid IMA log: %v", err)
+	}
+
+	var entries []*imaEntry
+	if isBinaryImaLog(rawImaLog) {
+		entries, err = parseBinaryImaLog(rawImaLog, limits)
+	} else {
+		entries, err = parseASCIIImaLog(rawImaLog, limits)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	replayed := make(map[uint32][]byte)
+	for idx, entry := range entries {
+		if _, ok := pcrs.GetPcrs()[entry.pcr]; !ok {
+			return nil, fmt.Errorf("IMA entry #%d extends PCR %d, which is not quoted", idx, entry.pcr)
+		}
+		replayed[entry.pcr] = make([]byte, cryptoHash.Size())
+	}
+	verified := -1
+	if failed := imaReplayMismatches(replayed, pcrs); len(failed) == 0 {
+		verified = 0
+	}
+	var failedPCRs []uint32
+	for idx, entry := range entries {
+		replayed[entry.pcr] = extendDigest(cryptoHash, replayed[entry.pcr], imaExtendDigest(cryptoHash, entry))
+		if failedPCRs = imaReplayMismatches(replayed, pcrs); len(failedPCRs) == 0 {
+			verified = idx + 1
+		}
+	}
+	if verified < 0 {
+		return nil, fmt.Errorf("IMA log replay failed for PCRs %v", failedPCRs)
+	}
+
+	state := &pb.ImaState{}
+	for idx, entry := range entries[:verified] {
+		pbEntry, err := convertToPbImaEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IMA entry #%d: %v", idx, err)
+		}
+		state.Entries = append(state.Entries, pbEntry)
+	}
+	return state, nil
+}
+
+// imaReplayMismatches returns the sorted indexes of the replayed PCRs which
+// do not match the quoted PCRs.
+func imaReplayMismatches(replayed map[uint32][]byte, pcrs *tpmpb.PCRs) []uint32 {
+	var failed []uint32
+	for index, value := range replayed {
+		if !bytes.Equal(value, pcrs.GetPcrs()[index]) {
+			failed = append(failed, index)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i] < failed[j] })
+	return failed
+}
+
+// imaExtendDigest returns the digest extended into the PCR bank for the entry.
+// Violations are extended as all 0xFF bytes.
+func imaExtendDigest(hash crypto.Hash, entry *imaEntry) []byte {
+	if entry.isViolation() {
+		return bytes.Repeat([]byte{0xff}, hash.Size())
+	}
+	hasher := hash.New()
+	hasher.Write(entry.templateData())
+	return hasher.Sum(nil)
+}
+
+func isBinaryImaLog(rawImaLog []byte) bool {
+	// An ascii log starts with the PCR index in decimal.
+	return len(rawImaLog) >= 4 && binary.LittleEndian.Uint32(rawImaLog) < 24
+}
+
+// parseBinaryImaLog parses the binary_runtime_measurements format. The size
+// of the template digest depends on the file the log was read from (e.g.,
+// binary_runtime_measurements_sha256), so it is inferred from the first entry.
+func parseBinaryImaLog(rawImaLog []byte, limits cel.DecodeLimits) ([]*imaEntry, error) {
+	digestSize := 0
+	var entries []*imaEntry
+	buf := bytes.NewBuffer(rawImaLog)
+	for buf.Len() > 0 {
+		start := buf.Len()
+		if buf.Len() < 4 {
+			return nil, fmt.Errorf("truncated IMA entry #%d", len(entries))
+		}
+		entry := &imaEntry{pcr: binary.LittleEndian.Uint32(buf.Next(4))}
+		if digestSize == 0 {
+			digestSize = inferImaDigestSize(buf.Bytes())
+			if digestSize == 0 {
+				return nil, errors.New("failed to determine the IMA template digest size")
+			}
+		}
+		if buf.Len() < digestSize {
+			return nil, fmt.Errorf("truncated template digest in IMA entry #%d", len(entries))
+		}
+		entry.templateDigest = append([]byte{}, buf.Next(digestSize)...)
+		name, err := readImaField(buf, imaMaxTemplateNameLen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template name in IMA entry #%d: %v", len(entries), err)
+		}
+		entry.templateName = string(name)
+		data, err := readImaField(buf, buf.Len())
+		if err != nil {
+			return nil, fmt.Errorf("invalid template data in IMA entry #%d: %v", len(entries), err)
+		}
+		dataBuf := bytes.NewBuffer(data)
+		for dataBuf.Len() > 0 {
+			field, err := readImaField(dataBuf, dataBuf.Len())
+			if err != nil {
+				return nil, fmt.Errorf("invalid template field in IMA entry #%d: %v", len(entries), err)
+			}
+			entry.fields = append(entry.fields, field)
+		}
+		if err := checkLogRecordLimits(len(entries), start-buf.Len(), limits); err != nil {
+			return nil, fmt.Errorf("invalid IMA log: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// inferImaDigestSize returns the template digest size for which the following
+// template name is one of the supported templates, or 0.
+func inferImaDigestSize(data []byte) int {
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		size := hash.Size()
+		if len(data) < size+4 {
+			continue
+		}
+		nameLen := int(binary.LittleEndian.Uint32(data[size:]))
+		if nameLen > imaMaxTemplateNameLen || len(data) < size+4+nameLen {
+			continue
+		}
+		switch string(data[size+4 : size+4+nameLen]) {
+		case ImaNgTemplate, ImaSigTemplate:
+			return size
+		}
+	}
+	return 0
+}
+
+func readImaField(buf *bytes.Buffer, maxLen int) ([]byte, error) {
+	if buf.Len() < 4 {
+		return nil, errors.New("missing field length")
+	}
+	fieldLen := int(binary.LittleEndian.Uint32(buf.Next(4)))
+	if fieldLen > maxLen || fieldLen > buf.Len() {
+		return nil, fmt.Errorf("field length %d is too large", fieldLen)
+	}
+	return append([]byte{}, buf.Next(fieldLen)...), nil
+}
+
+// parseASCIIImaLog parses the ascii_runtime_measurements format:
+//
+//	<pcr> <template digest> <template name> <algo>:<file digest> <path> [<signature>]
+//
+// The template data is rebuilt from the printed fields and checked against
+// the printed template digest.
+func parseASCIIImaLog(rawImaLog []byte, limits cel.DecodeLimits) ([]*imaEntry, error) {
+	var entries []*imaEntry
+	scanner := bufio.NewScanner(bytes.NewReader(rawImaLog))
+	scanner.Buffer(nil, len(rawImaLog)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if err := checkLogRecordLimits(len(entries), len(line), limits); err != nil {
+			return nil, fmt.Errorf("invalid IMA log: %v", err)
+		}
+		entry, err := parseASCIIImaLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IMA entry #%d: %v", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IMA log: %v", err)
+	}
+	return entries, nil
+}
+
+func parseASCIIImaLine(line string) (*imaEntry, error) {
+	parts := strings.SplitN(line, " ", 5)
+	if len(parts) != 5 {
+		return nil, errors.New("too few fields")
+	}
+	pcr, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PCR index: %v", err)
+	}
+	templateDigest, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid template digest: %v", err)
+	}
+	algo, fileDigestHex, found := strings.Cut(parts[3], ":")
+	if !found {
+		return nil, fmt.Errorf("invalid file digest %q", parts[3])
+	}
+	fileDigest, err := hex.DecodeString(fileDigestHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file digest: %v", err)
+	}
+	digestField := append([]byte(algo+":\x00"), fileDigest...)
+
+	entry := &imaEntry{
+		pcr:            uint32(pcr),
+		templateDigest: templateDigest,
+		templateName:   parts[2],
+	}
+	switch entry.templateName {
+	case ImaNgTemplate:
+		entry.fields = [][]byte{digestField, append([]byte(parts[4]), '\x00')}
+	case ImaSigTemplate:
+		// The signature is omitted when empty, and paths may contain spaces,
+		// so use the printed template digest to pick the interpretation.
+		entry.fields = [][]byte{digestField, append([]byte(parts[4]), '\x00'), nil}
+		if path, sigHex, found := cutLast(parts[4], " "); found {
+			if sig, err := hex.DecodeString(sigHex); err == nil {
+				withSig := &imaEntry{templateDigest: templateDigest,
+					fields: [][]byte{digestField, append([]byte(path), '\x00'), sig}}
+				if withSig.matchesTemplateDigest() {
+					entry.fields = withSig.fields
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported IMA template %q", entry.templateName)
+	}
+	if !entry.matchesTemplateDigest() {
+		return nil, fmt.Errorf("template digest %x does not match template data", templateDigest)
+	}
+	return entry, nil
+}
+
+// matchesTemplateDigest checks the logged template digest against the
+// template data. The digest algorithm is inferred from the digest size.
+func (e *imaEntry) matchesTemplateDigest() bool {
+	if e.isViolation() {
+		return true
+	}
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		if hash.Size() != len(e.templateDigest) {
+			continue
+		}
+		hasher := hash.New()
+		hasher.Write(e.templateData())
+		return bytes.Equal(hasher.Sum(nil), e.templateDigest)
+	}
+	return false
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func convertToPbImaEntry(entry *imaEntry) (*pb.ImaEntry, error) {
+	pbEntry := &pb.ImaEntry{
+		PcrIndex:     entry.pcr,
+		TemplateName: entry.templateName,
+		Violation:    entry.isViolation(),
+	}
+	switch entry.templateName {
+	case ImaNgTemplate:
+		if len(entry.fields) != 2 {
+			return nil, fmt.Errorf("%s template has %d fields, expected 2", entry.templateName, len(entry.fields))
+		}
+	case ImaSigTemplate:
+		if len(entry.fields) != 3 {
+			return nil, fmt.Errorf("%s template has %d fields, expected 3", entry.templateName, len(entry.fields))
+		}
+		pbEntry.Signature = entry.fields[2]
+	default:
+		return nil, fmt.Errorf("unsupported IMA template %q", entry.templateName)
+	}
+
+	// The d-ng field is "<algo>:\x00<digest>".
+	algo, digest, found := bytes.Cut(entry.fields[0], []byte(":\x00"))
+	if !found {
+		return nil, errors.New("invalid d-ng field")
+	}
+	pbEntry.FileHashAlgorithm = string(algo)
+	pbEntry.FileDigest = digest
+	// The n-ng field is a null-terminated path.
+	pbEntry.Path = string(bytes.TrimSuffix(entry.fields[1], []byte{'\x00'}))
+	return pbEntry, nil
+}
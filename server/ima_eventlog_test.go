@@ -0,0 +1,227 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type imaTestEntry struct {
+	template  string
+	path      string
+	contents  string
+	signature []byte
+	violation bool
+}
+
+var imaTestEntries = []imaTestEntry{
+	{template: ImaNgTemplate, path: ImaBootAggregate, contents: "pcrs"},
+	{template: ImaNgTemplate, path: "/usr/bin/bash", contents: "bash"},
+	{template: ImaSigTemplate, path: "/usr/lib/my library.so", contents: "lib", signature: []byte{0x03, 0x02, 0x04, 0xaa}},
+	{template: ImaSigTemplate, path: "/etc/hosts", contents: "hosts"},
+	{template: ImaNgTemplate, path: "/var/log/app.log", contents: "log", violation: true},
+}
+
+func (e imaTestEntry) fileDigest() []byte {
+	digest := sha256.Sum256([]byte(e.contents))
+	return digest[:]
+}
+
+func (e imaTestEntry) imaEntry() *imaEntry {
+	entry := &imaEntry{
+		pcr:          ImaPCR,
+		templateName: e.template,
+		fields: [][]byte{
+			append([]byte("sha256:\x00"), e.fileDigest()...),
+			append([]byte(e.path), '\x00'),
+		},
+	}
+	if e.template == ImaSigTemplate {
+		entry.fields = append(entry.fields, e.signature)
+	}
+	if e.violation {
+		entry.templateDigest = make([]byte, sha1.Size)
+	} else {
+		digest := sha1.Sum(entry.templateData())
+		entry.templateDigest = digest[:]
+	}
+	return entry
+}
+
+// makeImaLogs returns the binary and ascii IMA logs for the entries, along
+// with the resulting SHA256 PCR10 value.
+func makeImaLogs(entries []imaTestEntry) ([]byte, []byte, []byte) {
+	var binaryLog bytes.Buffer
+	var asciiLog strings.Builder
+	pcr := make([]byte, crypto.SHA256.Size())
+	for _, e := range entries {
+		entry := e.imaEntry()
+		binary.Write(&binaryLog, binary.LittleEndian, entry.pcr)
+		binaryLog.Write(entry.templateDigest)
+		binary.Write(&binaryLog, binary.LittleEndian, uint32(len(entry.templateName)))
+		binaryLog.WriteString(entry.templateName)
+		data := entry.templateData()
+		binary.Write(&binaryLog, binary.LittleEndian, uint32(len(data)))
+		binaryLog.Write(data)
+
+		fmt.Fprintf(&asciiLog, "%d %x %s sha256:%x %s", entry.pcr, entry.templateDigest, entry.templateName, e.fileDigest(), e.path)
+		if len(e.signature) > 0 {
+			fmt.Fprintf(&asciiLog, " %x", e.signature)
+		}
+		asciiLog.WriteString("\n")
+
+		pcr = extendDigest(crypto.SHA256, pcr, imaExtendDigest(crypto.SHA256, entry))
+	}
+	return binaryLog.Bytes(), []byte(asciiLog.String()), pcr
+}
+
+func TestParseImaEventLog(t *testing.T) {
+	binaryLog, asciiLog, pcr := makeImaLogs(imaTestEntries)
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: pcr}}
+
+	var want []*attestpb.ImaEntry
+	for _, e := range imaTestEntries {
+		want = append(want, &attestpb.ImaEntry{
+			PcrIndex:          ImaPCR,
+			TemplateName:      e.template,
+			FileHashAlgorithm: "sha256",
+			FileDigest:        e.fileDigest(),
+			Path:              e.path,
+			Signature:         e.signature,
+			Violation:         e.violation,
+		})
+	}
+
+	for _, tc := range []struct {
+		name string
+		log  []byte
+	}{
+		{"Binary", binaryLog},
+		{"ASCII", asciiLog},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := parseImaEventLog(tc.log, pcrs, cel.DecodeLimits{})
+			if err != nil {
+				t.Fatalf("parseImaEventLog() got err = %v, want nil", err)
+			}
+			if diff := cmp.Diff(want, state.GetEntries(), protocmp.Transform()); diff != "" {
+				t.Errorf("parseImaEventLog() returned unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseImaEventLogReplayFail(t *testing.T) {
+	binaryLog, asciiLog, _ := makeImaLogs(imaTestEntries)
+	// PCR10 was extended with one more measurement than in the log.
+	_, _, pcr := makeImaLogs(append(imaTestEntries, imaTestEntry{template: ImaNgTemplate, path: "/tmp/x", contents: "x"}))
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: pcr}}
+
+	for _, log := range [][]byte{binaryLog, asciiLog} {
+		if _, err := parseImaEventLog(log, pcrs, cel.DecodeLimits{}); err == nil {
+			t.Error("parseImaEventLog() got nil error, want replay error")
+		}
+	}
+}
+
+func TestParseImaEventLogVerifiedPrefix(t *testing.T) {
+	binaryLog, asciiLog, _ := makeImaLogs(imaTestEntries)
+	// The log was read after the quote, which only covers the first entries.
+	_, _, pcr := makeImaLogs(imaTestEntries[:2])
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: pcr}}
+
+	for _, log := range [][]byte{binaryLog, asciiLog} {
+		state, err := parseImaEventLog(log, pcrs, cel.DecodeLimits{})
+		if err != nil {
+			t.Fatalf("parseImaEventLog() got err = %v, want nil", err)
+		}
+		var gotPaths []string
+		for _, entry := range state.GetEntries() {
+			gotPaths = append(gotPaths, entry.GetPath())
+		}
+		if want := []string{ImaBootAggregate, "/usr/bin/bash"}; !cmp.Equal(gotPaths, want) {
+			t.Errorf("parseImaEventLog() got entries %v, want %v", gotPaths, want)
+		}
+	}
+}
+
+func TestParseImaEventLogUnquotedPCR(t *testing.T) {
+	binaryLog, asciiLog, _ := makeImaLogs(imaTestEntries)
+	// The IMA PCR is not quoted, so no entry can be verified.
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{0: make([]byte, crypto.SHA256.Size())}}
+
+	for _, log := range [][]byte{binaryLog, asciiLog} {
+		if _, err := parseImaEventLog(log, pcrs, cel.DecodeLimits{}); err == nil {
+			t.Error("parseImaEventLog() got nil error, want unquoted PCR error")
+		}
+	}
+}
+
+func TestParseImaEventLogEmpty(t *testing.T) {
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: make([]byte, crypto.SHA256.Size())}}
+	state, err := parseImaEventLog([]byte{}, pcrs, cel.DecodeLimits{})
+	if err != nil {
+		t.Fatalf("parseImaEventLog() got err = %v, want nil", err)
+	}
+	if state != nil {
+		t.Errorf("parseImaEventLog() got state %v, want nil", state)
+	}
+}
+
+func TestParseImaEventLogLimits(t *testing.T) {
+	binaryLog, asciiLog, pcr := makeImaLogs(imaTestEntries)
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: pcr}}
+
+	for _, tc := range []struct {
+		name    string
+		log     []byte
+		limits  cel.DecodeLimits
+		wantErr bool
+	}{
+		{"BinaryDefaults", binaryLog, cel.DefaultDecodeLimits(), false},
+		{"BinaryTooManyEntries", binaryLog, cel.DecodeLimits{MaxRecords: len(imaTestEntries) - 1}, true},
+		{"BinaryEntryTooLarge", binaryLog, cel.DecodeLimits{MaxRecordSize: 64}, true},
+		{"BinaryTooLarge", binaryLog, cel.DecodeLimits{MaxTotalSize: int64(len(binaryLog) - 1)}, true},
+		{"ASCIIDefaults", asciiLog, cel.DefaultDecodeLimits(), false},
+		{"ASCIITooManyEntries", asciiLog, cel.DecodeLimits{MaxRecords: len(imaTestEntries) - 1}, true},
+		{"ASCIIEntryTooLarge", asciiLog, cel.DecodeLimits{MaxRecordSize: 64}, true},
+		{"ASCIITooLarge", asciiLog, cel.DecodeLimits{MaxTotalSize: int64(len(asciiLog) - 1)}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseImaEventLog(tc.log, pcrs, tc.limits)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("parseImaEventLog() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseImaEventLogTampered(t *testing.T) {
+	_, asciiLog, pcr := makeImaLogs(imaTestEntries)
+	pcrs := &pb.PCRs{Hash: pb.HashAlgo_SHA256, Pcrs: map[uint32][]byte{ImaPCR: pcr}}
+
+	// Changing the path must invalidate the printed template digest.
+	tampered := strings.Replace(string(asciiLog), "/usr/bin/bash", "/usr/bin/true", 1)
+	if _, err := parseImaEventLog([]byte(tampered), pcrs, cel.DecodeLimits{}); err == nil {
+		t.Error("parseImaEventLog() got nil error, want template digest error")
+	}
+
+	// Changing the file digest must invalidate the printed template digest.
+	otherDigest := sha256.Sum256([]byte("other"))
+	tampered = strings.Replace(string(asciiLog), hex.EncodeToString(imaTestEntries[1].fileDigest()), hex.EncodeToString(otherDigest[:]), 1)
+	if _, err := parseImaEventLog([]byte(tampered), pcrs, cel.DecodeLimits{}); err == nil {
+		t.Error("parseImaEventLog() got nil error, want template digest error")
+	}
+}
@@ -29,6 +29,11 @@ type PolicyOptions struct {
 	Getter trust.HTTPSGetter
 	// Now is the time to evaluate time-based constraints against.
 	Now time.Time
+	// ImaEntryHook is called for every entry of the MachineState's ImaState,
+	// after the ImaPolicy checks. A non-nil error fails the policy. This allows
+	// callers to plug in their own allowlists or denylists (e.g., keyed on
+	// file paths or signatures).
+	ImaEntryHook func(*pb.ImaEntry) error
 }
 
 // DefaultPolicyOptions returns a useful default for PolicyOptions.
@@ -48,6 +53,16 @@ func EvaluatePolicyOpt(state *pb.MachineState, policy *pb.Policy, opts *PolicyOp
 	if err := evaluatePlatformPolicy(state.GetPlatform(), policy.GetPlatform()); err != nil {
 		return err
 	}
+	var imaHook func(*pb.ImaEntry) error
+	if opts != nil {
+		imaHook = opts.ImaEntryHook
+	}
+	if err := evaluateImaPolicy(state.GetIma(), policy.GetIma(), imaHook); err != nil {
+		return err
+	}
+	if err := evaluateReferenceManifestPolicy(state, policy.GetReferenceManifests(), opts); err != nil {
+		return err
+	}
 	if state.GetTeeAttestation() == nil {
 		return nil
 	}
@@ -151,3 +166,93 @@ func hasAllowedVersion(state *pb.PlatformState, allowedVersions [][]byte) error
 	}
 	return fmt.Errorf("provided SCRTM version (%x) not allowed", version)
 }
+
+func evaluateImaPolicy(state *pb.ImaState, policy *pb.ImaPolicy, hook func(*pb.ImaEntry) error) error {
+	if policy == nil && hook == nil {
+		return nil
+	}
+	if state == nil {
+		if policy != nil {
+			return errors.New("IMA policy provided, but MachineState has no IMA state")
+		}
+		return nil
+	}
+	allowed := make(map[string]bool)
+	for _, digest := range policy.GetAllowedFileDigests() {
+		allowed[string(digest)] = true
+	}
+	denied := make(map[string]bool)
+	for _, digest := range policy.GetDeniedFileDigests() {
+		denied[string(digest)] = true
+	}
+	deniedPaths := make(map[string]bool)
+	for _, path := range policy.GetDeniedPaths() {
+		deniedPaths[path] = true
+	}
+
+	for _, entry := range state.GetEntries() {
+		if entry.GetViolation() {
+			if policy != nil && !policy.GetAllowViolations() {
+				return fmt.Errorf("IMA measurement violation for %q not allowed", entry.GetPath())
+			}
+			continue
+		}
+		if deniedPaths[entry.GetPath()] {
+			return fmt.Errorf("IMA measured file %q is denied", entry.GetPath())
+		}
+		if denied[string(entry.GetFileDigest())] {
+			return fmt.Errorf("IMA measured file %q has denied digest %x", entry.GetPath(), entry.GetFileDigest())
+		}
+		if len(allowed) > 0 && entry.GetPath() != ImaBootAggregate && !allowed[string(entry.GetFileDigest())] {
+			return fmt.Errorf("IMA measured file %q has digest %x not in allowlist", entry.GetPath(), entry.GetFileDigest())
+		}
+		if hook != nil {
+			if err := hook(entry); err != nil {
+				return fmt.Errorf("IMA measured file %q rejected: %w", entry.GetPath(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func evaluateReferenceManifestPolicy(state *pb.MachineState, policy *pb.ReferenceManifestPolicy, opts *PolicyOptions) error {
+	if len(policy.GetManifests()) == 0 {
+		return nil
+	}
+	now := time.Now()
+	if opts != nil && !opts.Now.IsZero() {
+		now = opts.Now
+	}
+	manifests := make([]*ReferenceManifest, 0, len(policy.GetManifests()))
+	for i, data := range policy.GetManifests() {
+		manifest, err := ParseReferenceManifest(data, policy.GetSigning(), now)
+		if err != nil {
+			return fmt.Errorf("invalid reference manifest #%d: %v", i, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+	result, err := MatchReferenceManifests(state, manifests)
+	if err != nil {
+		return err
+	}
+	for _, pcr := range result.PCRs {
+		if pcr.Reference == nil {
+			return fmt.Errorf("%s (%x) does not match any reference value", pcr.Measurement, pcr.Digest)
+		}
+	}
+	if policy.GetRequireFirmware() {
+		for _, component := range result.FirmwareComponents {
+			if component.Reference == nil {
+				return fmt.Errorf("%s (%x) does not match any reference value", component.Measurement, component.Digest)
+			}
+		}
+	}
+	if policy.GetRequireEfiApps() {
+		for _, app := range result.EfiApps {
+			if app.Reference == nil {
+				return fmt.Errorf("%s (%x) does not match any reference value", app.Measurement, app.Digest)
+			}
+		}
+	}
+	return nil
+}
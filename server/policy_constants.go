@@ -16,20 +16,35 @@ import (
 // Taken from TCG PC Client Platform Firmware Profile Specification,
 // Table 14 Events.
 const (
+	PostCode                   uint32 = 0x00000001
 	NoAction                   uint32 = 0x00000003
 	Separator                  uint32 = 0x00000004
 	EventTag                   uint32 = 0x00000006
 	SCRTMVersion               uint32 = 0x00000008
 	IPL                        uint32 = 0x0000000D
 	NonhostInfo                uint32 = 0x00000011
+	EFIVariableDriverConfig    uint32 = 0x80000001
+	EFIVariableBoot            uint32 = 0x80000002
 	EFIBootServicesApplication uint32 = 0x80000003
+	EFIBootServicesDriver      uint32 = 0x80000004
+	EFIRuntimeServicesDriver   uint32 = 0x80000005
 	EFIAction                  uint32 = 0x80000007
+	EFIPlatformFirmwareBlob    uint32 = 0x80000008
+	EFIHandoffTables           uint32 = 0x80000009
+	EFIPlatformFirmwareBlob2   uint32 = 0x8000000A
+	EFIHandoffTables2          uint32 = 0x8000000B
+	EFIVariableBoot2           uint32 = 0x8000000C
+	EFIVariableAuthority       uint32 = 0x800000E0
 )
 
 // EventTagLoadedImageHex used with type "EV_EVENT_TAG".
 // This corresponds to a TLV struct of type LOAD_OPTIONS_EVENT_TAG_ID (0x8F3B22ED, reversed endian), length 0x1a (26), value `LOADED_IMAGE::LoadOptions\n`.
 const EventTagLoadedImageHex = "ed223b8f1a0000004c4f414445445f494d4147453a3a4c6f61644f7074696f6e7300"
 
+// LoadOptionsEventTagID is the LOAD_OPTIONS_EVENT_TAG_ID used with type
+// "EV_EVENT_TAG" by systemd-stub when measuring the kernel command line.
+const LoadOptionsEventTagID uint32 = 0x8F3B22ED
+
 // Constant events used with type "EV_EFI_ACTION".
 // Taken from TCG PC Client Platform Firmware Profile Specification,
 // Table 17 EV_EFI_ACTION Strings.
@@ -6,6 +6,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"math/rand"
 	"os"
 	"path"
@@ -397,3 +398,52 @@ func TestSevSnpSignedUefi(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateImaPolicy(t *testing.T) {
+	bash := []byte("bash-digest")
+	hosts := []byte("hosts-digest")
+	state := &pb.MachineState{
+		Ima: &pb.ImaState{Entries: []*pb.ImaEntry{
+			{Path: ImaBootAggregate, FileDigest: []byte("aggregate")},
+			{Path: "/usr/bin/bash", FileDigest: bash},
+			{Path: "/etc/hosts", FileDigest: hosts},
+		}},
+	}
+	violationState := &pb.MachineState{
+		Ima: &pb.ImaState{Entries: []*pb.ImaEntry{{Path: "/var/log/app.log", Violation: true}}},
+	}
+
+	tests := []struct {
+		name    string
+		state   *pb.MachineState
+		policy  *pb.ImaPolicy
+		hook    func(*pb.ImaEntry) error
+		wantErr bool
+	}{
+		{"EmptyPolicy", state, &pb.ImaPolicy{}, nil, false},
+		{"Allowlist", state, &pb.ImaPolicy{AllowedFileDigests: [][]byte{bash, hosts}}, nil, false},
+		{"NotInAllowlist", state, &pb.ImaPolicy{AllowedFileDigests: [][]byte{bash}}, nil, true},
+		{"DeniedDigest", state, &pb.ImaPolicy{DeniedFileDigests: [][]byte{hosts}}, nil, true},
+		{"DeniedPath", state, &pb.ImaPolicy{DeniedPaths: []string{"/usr/bin/bash"}}, nil, true},
+		{"MissingImaState", &pb.MachineState{}, &pb.ImaPolicy{}, nil, true},
+		{"ViolationDenied", violationState, &pb.ImaPolicy{}, nil, true},
+		{"ViolationAllowed", violationState, &pb.ImaPolicy{AllowViolations: true}, nil, false},
+		{"HookAccepts", state, nil, func(*pb.ImaEntry) error { return nil }, false},
+		{"HookRejects", state, nil, func(e *pb.ImaEntry) error {
+			if strings.HasPrefix(e.GetPath(), "/etc/") {
+				return errors.New("config files not allowed")
+			}
+			return nil
+		}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultPolicyOptions()
+			opts.ImaEntryHook = tc.hook
+			err := EvaluatePolicyOpt(tc.state, &pb.Policy{Ima: tc.policy}, opts)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("EvaluatePolicyOpt() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
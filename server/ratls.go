@@ -0,0 +1,192 @@
+package server
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-tpm-tools/internal/cbor"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/proto"
+)
+
+// RATLSEvidenceOID identifies the extension of an RA-TLS certificate holding
+// the attestation evidence: tcg-dice-conceptual-message-wrapper, from the TCG
+// DICE Attestation Architecture (version 1.1). The extension value is a
+// Conceptual Message Wrapper (CMW, see draft-ietf-rats-msg-wrap) in its CBOR
+// form, as a DER OCTET STRING: a CBOR array of RATLSMediaType and of the
+// serialized attest.Attestation over the certificate key.
+var RATLSEvidenceOID asn1.ObjectIdentifier = []int{2, 23, 133, 5, 4, 9}
+
+// RATLSMediaType is the media type of the attestation in the CMW of an RA-TLS
+// certificate, which distinguishes it from the evidence of other attesters.
+const RATLSMediaType = "application/vnd.google.go-tpm-tools.attestation"
+
+// The prefix of the data hashed into the nonce of an RA-TLS attestation, so
+// that the nonce cannot be mistaken for the nonce of another protocol.
+const ratlsNoncePrefix = "go-tpm-tools RA-TLS v2\x00"
+
+// RATLSNonce returns the nonce of the attestation of an RA-TLS certificate
+// for the public key, valid from notBefore to notAfter: the SHA-256 digest of
+// a fixed prefix, of the DER SubjectPublicKeyInfo of the key, of notBefore and
+// notAfter, as big-endian Unix seconds, and of the length-prefixed challenge
+// of the verifier, if any. Binding the key to the attestation this way, the
+// certificate holds the key of the attested machine. Binding the validity
+// period, the attested machine chose when the certificate was issued and
+// when it expires. Binding the challenge, the attestation was made for the
+// verifier which chose it.
+func RATLSNonce(pub crypto.PublicKey, notBefore, notAfter time.Time, challenge []byte) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the RA-TLS public key: %v", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(ratlsNoncePrefix))
+	h.Write(der)
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(notBefore.Unix())))
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(notAfter.Unix())))
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(challenge))))
+	h.Write(challenge)
+	return h.Sum(nil), nil
+}
+
+// RATLSExtension returns the extension of an RA-TLS certificate holding the
+// attestation, which must be over the RATLSNonce of the certificate.
+func RATLSExtension(attestation *pb.Attestation) (pkix.Extension, error) {
+	evidence, err := proto.Marshal(attestation)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to marshal the RA-TLS attestation: %v", err)
+	}
+	cmw, err := cbor.Encode([]any{RATLSMediaType, evidence})
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode the RA-TLS CMW: %v", err)
+	}
+	value, err := asn1.Marshal(cmw)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode the RA-TLS extension: %v", err)
+	}
+	return pkix.Extension{Id: RATLSEvidenceOID, Value: value}, nil
+}
+
+// parseRATLSExtension returns the attestation in the value of an RA-TLS
+// extension.
+func parseRATLSExtension(value []byte) (*pb.Attestation, error) {
+	var cmw []byte
+	if rest, err := asn1.Unmarshal(value, &cmw); err != nil {
+		return nil, fmt.Errorf("failed to parse the RA-TLS extension: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("trailing data after the RA-TLS extension")
+	}
+	decoded, err := cbor.Decode(cmw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the RA-TLS CMW: %v", err)
+	}
+	record, ok := decoded.([]any)
+	if !ok || len(record) != 2 {
+		return nil, errors.New("the RA-TLS CMW is not a CMW record")
+	}
+	if mediaType, ok := record[0].(string); !ok || mediaType != RATLSMediaType {
+		return nil, fmt.Errorf("unsupported RA-TLS evidence type %v", record[0])
+	}
+	evidence, ok := record[1].([]byte)
+	if !ok {
+		return nil, errors.New("the RA-TLS CMW value is not a byte string")
+	}
+	attestation := &pb.Attestation{}
+	if err := proto.Unmarshal(evidence, attestation); err != nil {
+		return nil, fmt.Errorf("failed to parse the RA-TLS attestation: %v", err)
+	}
+	return attestation, nil
+}
+
+// RATLSOpts allows the verifier of an RA-TLS certificate to check the
+// freshness of its attestation. At least one of them must be set.
+type RATLSOpts struct {
+	// MaxAge is the maximum time since the certificate was issued, as of its
+	// attested NotBefore. The certificate holder cannot make it look newer,
+	// but it is only as accurate as the clock of the attested machine.
+	MaxAge time.Duration
+	// Challenge is the nonce the verifier gave the attested machine to
+	// include in the attestation of the certificate (see RATLSNonce), so
+	// that the certificate was issued for this verifier.
+	Challenge []byte
+}
+
+// VerifyRATLSCertificate verifies the attestation in the RA-TLS extension of
+// the certificate with VerifyAttestation, using the RATLSNonce of the
+// certificate key, validity period and ratlsOpts.Challenge as the nonce
+// (opts.Nonce is ignored). It also checks that the certificate is within its
+// validity period, and no older than ratlsOpts.MaxAge, if set. It does not
+// check the certificate issuer: the TLS handshake proves that the peer holds
+// the certificate key.
+func VerifyRATLSCertificate(cert *x509.Certificate, opts VerifyOpts, ratlsOpts RATLSOpts) (*pb.MachineState, error) {
+	if ratlsOpts.MaxAge <= 0 && len(ratlsOpts.Challenge) == 0 {
+		return nil, errors.New("RATLSOpts must set MaxAge or Challenge to check the freshness of the attestation")
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("the RA-TLS certificate is only valid from %v to %v", cert.NotBefore, cert.NotAfter)
+	}
+	if ratlsOpts.MaxAge > 0 && now.Sub(cert.NotBefore) > ratlsOpts.MaxAge {
+		return nil, fmt.Errorf("the RA-TLS certificate was issued at %v, more than %v ago", cert.NotBefore, ratlsOpts.MaxAge)
+	}
+	var value []byte
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(RATLSEvidenceOID) {
+			if value != nil {
+				return nil, errors.New("the certificate has more than one RA-TLS extension")
+			}
+			value = ext.Value
+		}
+	}
+	if value == nil {
+		return nil, errors.New("the certificate has no RA-TLS extension")
+	}
+	attestation, err := parseRATLSExtension(value)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := RATLSNonce(cert.PublicKey, cert.NotBefore, cert.NotAfter, ratlsOpts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	opts.Nonce = nonce
+	return VerifyAttestation(attestation, opts)
+}
+
+// RATLSVerifyPeerCertificate returns a tls.Config.VerifyPeerCertificate
+// callback, verifying the RA-TLS certificate of the peer with
+// VerifyRATLSCertificate, then the resulting MachineState with check, if not
+// nil (e.g., to evaluate a Policy, or to check the workload image digest).
+//
+// As the RA-TLS certificate has no trusted issuer, a client config must set
+// InsecureSkipVerify, and a server config must set ClientAuth to
+// tls.RequireAnyClientCert, for the callback to replace the certificate
+// chain verification.
+func RATLSVerifyPeerCertificate(opts VerifyOpts, ratlsOpts RATLSOpts, check func(*pb.MachineState) error) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("the peer sent no RA-TLS certificate")
+		}
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse the peer certificate: %v", err)
+		}
+		ms, err := VerifyRATLSCertificate(cert, opts, ratlsOpts)
+		if err != nil {
+			return fmt.Errorf("failed to verify the peer RA-TLS certificate: %v", err)
+		}
+		if check != nil {
+			if err := check(ms); err != nil {
+				return fmt.Errorf("the peer machine state was rejected: %v", err)
+			}
+		}
+		return nil
+	}
+}
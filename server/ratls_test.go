@@ -0,0 +1,289 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/cbor"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"google.golang.org/protobuf/proto"
+)
+
+// ratlsCertParams are the validity period and challenge of a test RA-TLS
+// certificate, and the ones bound by its attestation if they differ.
+type ratlsCertParams struct {
+	notBefore         time.Time
+	notAfter          time.Time
+	challenge         []byte
+	attestedNotAfter  time.Time
+	attestedChallenge []byte
+}
+
+// createRATLSCert returns a self-signed RA-TLS certificate for a new key,
+// issued a minute ago and expiring at notAfter, attested by the AK, and the
+// key.
+func createRATLSCert(t *testing.T, ak *client.Key, notAfter time.Time) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	return createRATLSCertWithParams(t, ak, ratlsCertParams{notBefore: time.Now().Add(-time.Minute), notAfter: notAfter})
+}
+
+// createRATLSCertWithParams returns a self-signed RA-TLS certificate like
+// createRATLSCert, with the given validity period and challenge.
+func createRATLSCertWithParams(t *testing.T, ak *client.Key, p ratlsCertParams) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestedNotAfter := p.notAfter
+	if !p.attestedNotAfter.IsZero() {
+		attestedNotAfter = p.attestedNotAfter
+	}
+	attestedChallenge := p.challenge
+	if p.attestedChallenge != nil {
+		attestedChallenge = p.attestedChallenge
+	}
+	nonce, err := RATLSNonce(key.Public(), p.notBefore, attestedNotAfter, attestedChallenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation, err := ak.Attest(client.AttestOpts{Nonce: nonce})
+	if err != nil {
+		t.Fatalf("failed to attest: %v", err)
+	}
+	ext, err := RATLSExtension(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "workload"},
+		DNSNames:        []string{"workload"},
+		NotBefore:       p.notBefore,
+		NotAfter:        p.notAfter,
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		ExtraExtensions: []pkix.Extension{ext},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestVerifyRATLSCertificate(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	opts := VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+	ratlsOpts := RATLSOpts{MaxAge: time.Hour}
+
+	cert, _ := createRATLSCert(t, ak, time.Now().Add(time.Hour))
+	if _, err := VerifyRATLSCertificate(cert, opts, ratlsOpts); err != nil {
+		t.Errorf("VerifyRATLSCertificate() failed: %v", err)
+	}
+
+	if _, err := VerifyRATLSCertificate(cert, opts, RATLSOpts{}); err == nil {
+		t.Error("VerifyRATLSCertificate() without a freshness check got nil error, want error")
+	}
+
+	// The attestation of the certificate does not attest another key.
+	otherCert, _ := createRATLSCert(t, ak, time.Now().Add(time.Hour))
+	otherCert.Extensions = cert.Extensions
+	if _, err := VerifyRATLSCertificate(otherCert, opts, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() with the attestation of another key got nil error, want error")
+	}
+
+	expiredCert, _ := createRATLSCert(t, ak, time.Now().Add(-time.Second))
+	if _, err := VerifyRATLSCertificate(expiredCert, opts, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() with an expired certificate got nil error, want error")
+	}
+
+	// The certificate cannot outlive the expiry attested by the machine.
+	extendedCert, _ := createRATLSCertWithParams(t, ak, ratlsCertParams{
+		notBefore:        time.Now().Add(-time.Minute),
+		notAfter:         time.Now().Add(24 * time.Hour),
+		attestedNotAfter: time.Now().Add(time.Hour),
+	})
+	if _, err := VerifyRATLSCertificate(extendedCert, opts, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() with an expiry other than the attested one got nil error, want error")
+	}
+
+	// A long-lived certificate is rejected once older than the maximum age.
+	oldCert, _ := createRATLSCertWithParams(t, ak, ratlsCertParams{
+		notBefore: time.Now().Add(-2 * time.Hour),
+		notAfter:  time.Now().Add(24 * time.Hour),
+	})
+	if _, err := VerifyRATLSCertificate(oldCert, opts, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() with a certificate older than MaxAge got nil error, want error")
+	}
+	if _, err := VerifyRATLSCertificate(oldCert, opts, RATLSOpts{MaxAge: 3 * time.Hour}); err != nil {
+		t.Errorf("VerifyRATLSCertificate() with a certificate within MaxAge failed: %v", err)
+	}
+
+	noExtCert := *cert
+	noExtCert.Extensions = nil
+	if _, err := VerifyRATLSCertificate(&noExtCert, opts, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() without the RA-TLS extension got nil error, want error")
+	}
+
+	otherAK, err := client.AttestationKeyRSA(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer otherAK.Close()
+	if _, err := VerifyRATLSCertificate(cert, VerifyOpts{TrustedAKs: []crypto.PublicKey{otherAK.PublicKey()}}, ratlsOpts); err == nil {
+		t.Error("VerifyRATLSCertificate() with an untrusted AK got nil error, want error")
+	}
+}
+
+func TestVerifyRATLSCertificateChallenge(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	opts := VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+	challenge := []byte("verifier challenge")
+
+	cert, _ := createRATLSCertWithParams(t, ak, ratlsCertParams{
+		notBefore: time.Now().Add(-time.Minute),
+		notAfter:  time.Now().Add(time.Hour),
+		challenge: challenge,
+	})
+	if _, err := VerifyRATLSCertificate(cert, opts, RATLSOpts{Challenge: challenge}); err != nil {
+		t.Errorf("VerifyRATLSCertificate() with the challenge failed: %v", err)
+	}
+	if _, err := VerifyRATLSCertificate(cert, opts, RATLSOpts{Challenge: []byte("another challenge")}); err == nil {
+		t.Error("VerifyRATLSCertificate() with another challenge got nil error, want error")
+	}
+	// A certificate attested without a challenge does not answer one.
+	unchallenged, _ := createRATLSCert(t, ak, time.Now().Add(time.Hour))
+	if _, err := VerifyRATLSCertificate(unchallenged, opts, RATLSOpts{Challenge: challenge}); err == nil {
+		t.Error("VerifyRATLSCertificate() with a certificate without the challenge got nil error, want error")
+	}
+}
+
+func TestRATLSExtensionEncoding(t *testing.T) {
+	want := &attestpb.Attestation{AkPub: []byte("ak")}
+	ext, err := RATLSExtension(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseRATLSExtension(ext.Value)
+	if err != nil {
+		t.Fatalf("parseRATLSExtension() failed: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("parseRATLSExtension() got %v, want %v", got, want)
+	}
+
+	evidence, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		cmw  any
+	}{
+		{"NotARecord", evidence},
+		{"OtherMediaType", []any{"application/eat+cwt", evidence}},
+		{"TextValue", []any{RATLSMediaType, "evidence"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmw, err := cbor.Encode(tc.cmw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := asn1.Marshal(cmw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := parseRATLSExtension(value); err == nil {
+				t.Error("parseRATLSExtension() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestRATLSHandshake(t *testing.T) {
+	rwc := test.GetTPM(t)
+	defer client.CheckedClose(t, rwc)
+	ak, err := client.AttestationKeyECC(rwc)
+	if err != nil {
+		t.Fatalf("failed to generate AK: %v", err)
+	}
+	defer ak.Close()
+	opts := VerifyOpts{TrustedAKs: []crypto.PublicKey{ak.PublicKey()}}
+	tlsCert := func() tls.Certificate {
+		cert, key := createRATLSCert(t, ak, time.Now().Add(time.Hour))
+		return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key, Leaf: cert}
+	}
+	clientCert := tlsCert()
+	serverCert := tlsCert()
+
+	for _, tc := range []struct {
+		name    string
+		check   func(*attestpb.MachineState) error
+		wantErr bool
+	}{
+		{"NoCheck", nil, false},
+		{"CheckAccepts", func(*attestpb.MachineState) error { return nil }, false},
+		{"CheckRejects", func(*attestpb.MachineState) error { return errors.New("unexpected workload") }, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			clientConn, serverConn := net.Pipe()
+			defer clientConn.Close()
+			defer serverConn.Close()
+
+			tlsServer := tls.Server(serverConn, &tls.Config{
+				Certificates:          []tls.Certificate{serverCert},
+				ClientAuth:            tls.RequireAnyClientCert,
+				VerifyPeerCertificate: RATLSVerifyPeerCertificate(opts, RATLSOpts{MaxAge: time.Hour}, tc.check),
+			})
+			serverErr := make(chan error, 1)
+			go func() {
+				serverErr <- tlsServer.Handshake()
+				// Unblock the client if the server failed first.
+				serverConn.Close()
+			}()
+
+			tlsClient := tls.Client(clientConn, &tls.Config{
+				Certificates:          []tls.Certificate{clientCert},
+				InsecureSkipVerify:    true,
+				VerifyPeerCertificate: RATLSVerifyPeerCertificate(opts, RATLSOpts{MaxAge: time.Hour}, tc.check),
+			})
+			clientErr := tlsClient.Handshake()
+			if gotErr := clientErr != nil; gotErr != tc.wantErr {
+				t.Errorf("client Handshake() got error %v, want error %v", clientErr, tc.wantErr)
+			}
+			if err := <-serverErr; tc.wantErr == false && err != nil {
+				t.Errorf("server Handshake() failed: %v", err)
+			}
+		})
+	}
+}
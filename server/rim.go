@@ -0,0 +1,590 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/go-attestation/attest"
+	"github.com/google/go-tpm-tools/internal/cbor"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm/legacy/tpm2"
+)
+
+// CBOR tags of signed and unsigned Reference Integrity Manifests.
+// See RFC 9052 (COSE), RFC 9393 (CoSWID) and draft-ietf-rats-corim.
+const (
+	coseSign1Tag = 18
+	corimTag     = 501
+	coswidTag    = 505
+	comidTag     = 506
+)
+
+// COSE header labels.
+const (
+	coseAlgLabel     = 1
+	coseX5ChainLabel = 33
+)
+
+// ReferenceValue is a single reference measurement from a Reference
+// Integrity Manifest.
+type ReferenceValue struct {
+	// A human-readable name, such as the CoSWID file name or the CoMID
+	// environment vendor and model.
+	Name   string
+	Hash   crypto.Hash
+	Digest []byte
+	// If PCR is non-negative, Digest is the expected value of this PCR,
+	// rather than the digest of a measured component.
+	PCR int
+}
+
+// ReferenceManifest contains the reference values of a CoRIM or CoSWID
+// document.
+type ReferenceManifest struct {
+	// The CoRIM identifier or the CoSWID tag identifier.
+	ID string
+	// The certificate which signed the manifest, nil if it is unsigned.
+	Signer          *x509.Certificate
+	ReferenceValues []ReferenceValue
+}
+
+// ParseReferenceManifest parses a CoRIM or CoSWID document, either unsigned
+// or signed using COSE_Sign1. The signing certificate must be in the x5chain
+// header and chain up to one of the policy's root certificates. Unsigned
+// documents are rejected if the policy requires signatures.
+//
+// In CoMID reference triples, a measurement whose key is an unsigned integer
+// is the expected value of that PCR, following the TCG TPM CoRIM profile.
+// Other measurements, and the file hashes of CoSWID tags, are digests of
+// measured components such as firmware blobs or EFI applications.
+//
+// TCG PC Client Base RIMs in the SWID XML format (signed with XML-DSig) are
+// not supported; use their CoSWID or CoRIM representation instead.
+func ParseReferenceManifest(data []byte, policy *pb.RIMPolicy, now time.Time) (*ReferenceManifest, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return nil, errors.New("XML SWID tags are not supported, use CoSWID or CoRIM")
+	}
+	item, err := cbor.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %v", err)
+	}
+
+	var signer *x509.Certificate
+	if tag, ok := item.(cbor.Tag); ok && tag.Number == coseSign1Tag {
+		item = tag.Content
+	}
+	if msg, ok := item.([]any); ok {
+		var payload []byte
+		if payload, signer, err = verifyCOSESign1(msg, policy.GetRootCerts(), now); err != nil {
+			return nil, fmt.Errorf("failed to verify manifest signature: %v", err)
+		}
+		if item, err = cbor.Decode(payload); err != nil {
+			return nil, fmt.Errorf("failed to decode signed manifest: %v", err)
+		}
+	} else if policy.GetRequireSigned() {
+		return nil, errors.New("manifest is not signed, but signatures are required")
+	}
+
+	manifest, err := parseManifestPayload(item)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Signer = signer
+	return manifest, nil
+}
+
+// verifyCOSESign1 verifies a COSE_Sign1 message and returns its payload and
+// signing certificate.
+func verifyCOSESign1(msg []any, rootCerts [][]byte, now time.Time) ([]byte, *x509.Certificate, error) {
+	if len(msg) != 4 {
+		return nil, nil, fmt.Errorf("COSE_Sign1 has %d elements, want 4", len(msg))
+	}
+	protectedBytes, ok1 := msg[0].([]byte)
+	unprotected, ok2 := msg[1].(map[any]any)
+	payload, ok3 := msg[2].([]byte)
+	signature, ok4 := msg[3].([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, nil, errors.New("malformed or detached COSE_Sign1")
+	}
+	protected := map[any]any{}
+	if len(protectedBytes) > 0 {
+		item, err := cbor.Decode(protectedBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid protected header: %v", err)
+		}
+		if protected, ok1 = item.(map[any]any); !ok1 {
+			return nil, nil, errors.New("protected header is not a map")
+		}
+	}
+	alg, ok := cborInt(protected[uint64(coseAlgLabel)])
+	if !ok {
+		return nil, nil, errors.New("missing algorithm in protected header")
+	}
+	x5chain, ok := protected[uint64(coseX5ChainLabel)]
+	if !ok {
+		x5chain = unprotected[uint64(coseX5ChainLabel)]
+	}
+	chain, err := parseX5Chain(x5chain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roots, err := rootOfTrust(rootCerts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid root certificate: %v", err)
+	}
+	if len(rootCerts) == 0 {
+		return nil, nil, errors.New("no root certificates to verify the signing certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, nil, fmt.Errorf("failed to verify signing certificate: %v", err)
+	}
+
+	toBeSigned, err := cbor.Encode([]any{"Signature1", protectedBytes, []byte{}, payload})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyCOSESignature(alg, chain[0].PublicKey, toBeSigned, signature); err != nil {
+		return nil, nil, err
+	}
+	return payload, chain[0], nil
+}
+
+func parseX5Chain(x5chain any) ([]*x509.Certificate, error) {
+	var ders [][]byte
+	switch v := x5chain.(type) {
+	case []byte:
+		ders = [][]byte{v}
+	case []any:
+		for _, item := range v {
+			der, ok := item.([]byte)
+			if !ok {
+				return nil, errors.New("x5chain contains a non-bstr certificate")
+			}
+			ders = append(ders, der)
+		}
+	}
+	if len(ders) == 0 {
+		return nil, errors.New("missing x5chain header with the signing certificate")
+	}
+	chain := make([]*x509.Certificate, 0, len(ders))
+	for _, der := range ders {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in x5chain: %v", err)
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+// verifyCOSESignature verifies a signature using a COSE algorithm identifier.
+// See the IANA COSE Algorithms registry.
+func verifyCOSESignature(alg int64, pub crypto.PublicKey, toBeSigned, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case -7, -37, -257:
+		hash = crypto.SHA256
+	case -35, -38, -258:
+		hash = crypto.SHA384
+	case -36, -39, -259:
+		hash = crypto.SHA512
+	case -8:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("EdDSA signature with %T key", pub)
+		}
+		if !ed25519.Verify(key, toBeSigned, signature) {
+			return errors.New("invalid EdDSA signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported COSE algorithm %d", alg)
+	}
+	hasher := hash.New()
+	hasher.Write(toBeSigned)
+	digest := hasher.Sum(nil)
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if alg != -7 && alg != -35 && alg != -36 {
+			return fmt.Errorf("COSE algorithm %d used with ECDSA key", alg)
+		}
+		// COSE ECDSA signatures are the concatenation of r and s.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("ECDSA signature has size %d, want %d", len(signature), 2*size)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		switch alg {
+		case -37, -38, -39:
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		case -257, -258, -259:
+			return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+		}
+		return fmt.Errorf("COSE algorithm %d used with RSA key", alg)
+	}
+	return fmt.Errorf("unsupported signing key type %T", pub)
+}
+
+func parseManifestPayload(item any) (*ReferenceManifest, error) {
+	if tag, ok := item.(cbor.Tag); ok {
+		switch tag.Number {
+		case corimTag, coswidTag:
+			item = tag.Content
+		default:
+			return nil, fmt.Errorf("unsupported manifest tag %d", tag.Number)
+		}
+	}
+	m, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("manifest is not a CoRIM or CoSWID map")
+	}
+	// Both start with an identifier, but a CoRIM contains a list of tags
+	// where a CoSWID contains the software name.
+	if _, ok := m[uint64(1)].([]any); ok {
+		return parseCoRIM(m)
+	}
+	manifest := &ReferenceManifest{}
+	if err := parseCoSWID(m, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// parseCoRIM parses an unsigned-corim-map, containing CoMID and CoSWID tags.
+func parseCoRIM(m map[any]any) (*ReferenceManifest, error) {
+	manifest := &ReferenceManifest{ID: formatTagID(m[uint64(0)])}
+	for i, item := range m[uint64(1)].([]any) {
+		tag, ok := item.(cbor.Tag)
+		if !ok {
+			return nil, fmt.Errorf("CoRIM tag #%d is not tagged", i)
+		}
+		encoded, ok := tag.Content.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("CoRIM tag #%d is not a byte string", i)
+		}
+		if tag.Number != comidTag && tag.Number != coswidTag {
+			// E.g., CoTL tags, which do not contain reference values.
+			continue
+		}
+		decoded, err := cbor.Decode(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CoRIM tag #%d: %v", i, err)
+		}
+		tagMap, ok := decoded.(map[any]any)
+		if !ok {
+			return nil, fmt.Errorf("CoRIM tag #%d is not a map", i)
+		}
+		if tag.Number == comidTag {
+			err = parseCoMID(tagMap, manifest)
+		} else {
+			err = parseCoSWID(tagMap, manifest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CoRIM tag #%d: %v", i, err)
+		}
+	}
+	return manifest, nil
+}
+
+// parseCoMID adds the reference triples of a concise-mid-tag to the manifest.
+func parseCoMID(m map[any]any, manifest *ReferenceManifest) error {
+	triples, _ := m[uint64(4)].(map[any]any)
+	refTriples, _ := triples[uint64(0)].([]any)
+	for i, item := range refTriples {
+		triple, ok := item.([]any)
+		if !ok || len(triple) != 2 {
+			return fmt.Errorf("reference triple #%d is not an [environment, measurements] pair", i)
+		}
+		env, _ := triple[0].(map[any]any)
+		class, _ := env[uint64(0)].(map[any]any)
+		var names []string
+		for _, key := range []uint64{1, 2} {
+			if name, ok := class[key].(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+		envName := strings.Join(names, " ")
+
+		measurements, ok := triple[1].([]any)
+		if !ok {
+			return fmt.Errorf("reference triple #%d has no measurements", i)
+		}
+		for _, item := range measurements {
+			measurement, ok := item.(map[any]any)
+			if !ok {
+				return fmt.Errorf("reference triple #%d has an invalid measurement", i)
+			}
+			name, pcr := envName, -1
+			switch mkey := measurement[uint64(0)].(type) {
+			case uint64:
+				if mkey >= 24 {
+					return fmt.Errorf("reference triple #%d has invalid PCR index %d", i, mkey)
+				}
+				pcr = int(mkey)
+			case string:
+				name = mkey
+			}
+			mval, _ := measurement[uint64(1)].(map[any]any)
+			digests, _ := mval[uint64(2)].([]any)
+			if err := addReferenceDigests(manifest, name, pcr, digests); err != nil {
+				return fmt.Errorf("reference triple #%d: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseCoSWID adds the file hashes of a concise-swid-tag to the manifest.
+func parseCoSWID(m map[any]any, manifest *ReferenceManifest) error {
+	name, ok := m[uint64(1)].(string)
+	if !ok {
+		return errors.New("CoSWID tag has no software name")
+	}
+	if manifest.ID == "" {
+		manifest.ID = formatTagID(m[uint64(0)])
+	}
+	// Both the payload and evidence contain resource collections.
+	for _, key := range []uint64{6, 3} {
+		if resources, ok := m[key]; ok {
+			if err := addCoSWIDFiles(manifest, resources, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addCoSWIDFiles recursively adds the hashes of the files and directories of
+// a CoSWID resource collection. Names are joined into a path.
+func addCoSWIDFiles(manifest *ReferenceManifest, item any, path string) error {
+	switch v := item.(type) {
+	case []any:
+		for _, child := range v {
+			if err := addCoSWIDFiles(manifest, child, path); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		if fsName, ok := v[uint64(24)].(string); ok {
+			path = path + "/" + fsName
+		}
+		if hashEntry, ok := v[uint64(7)]; ok {
+			if err := addReferenceDigests(manifest, path, -1, []any{hashEntry}); err != nil {
+				return err
+			}
+		}
+		// Directories, files and path elements.
+		for _, key := range []uint64{16, 17, 26} {
+			if child, ok := v[key]; ok {
+				if err := addCoSWIDFiles(manifest, child, path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addReferenceDigests adds a list of [algorithm, value] digests. Digests with
+// unknown algorithms are skipped, since they can never be matched.
+func addReferenceDigests(manifest *ReferenceManifest, name string, pcr int, digests []any) error {
+	for _, item := range digests {
+		entry, ok := item.([]any)
+		if !ok || len(entry) != 2 {
+			return errors.New("digest is not an [algorithm, value] pair")
+		}
+		value, ok := entry[1].([]byte)
+		if !ok {
+			return errors.New("digest value is not a byte string")
+		}
+		hash, ok := namedInformationHash(entry[0])
+		if !ok {
+			continue
+		}
+		if len(value) != hash.Size() {
+			return fmt.Errorf("%v digest has size %d", hash, len(value))
+		}
+		manifest.ReferenceValues = append(manifest.ReferenceValues, ReferenceValue{
+			Name:   name,
+			Hash:   hash,
+			Digest: value,
+			PCR:    pcr,
+		})
+	}
+	return nil
+}
+
+// namedInformationHash converts a hash algorithm from the IANA Named
+// Information Hash Algorithm registry, by ID or name.
+func namedInformationHash(alg any) (crypto.Hash, bool) {
+	switch alg {
+	case uint64(1), "sha-256":
+		return crypto.SHA256, true
+	case uint64(7), "sha-384":
+		return crypto.SHA384, true
+	case uint64(8), "sha-512":
+		return crypto.SHA512, true
+	case "sha-1":
+		return crypto.SHA1, true
+	}
+	return 0, false
+}
+
+func cborInt(v any) (int64, bool) {
+	switch i := v.(type) {
+	case int64:
+		return i, true
+	case uint64:
+		if i <= 1<<63-1 {
+			return int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// formatTagID formats a text identifier, or a UUID in its canonical form.
+func formatTagID(id any) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case []byte:
+		if len(v) == 16 {
+			return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+		}
+		return hex.EncodeToString(v)
+	case cbor.Tag:
+		return formatTagID(v.Content)
+	}
+	return ""
+}
+
+// ReferenceMatch is the result of matching a measurement from a MachineState
+// against reference values.
+type ReferenceMatch struct {
+	// A description of the measurement (e.g., "PCR 7" or "EFI app #1").
+	Measurement string
+	Digest      []byte
+	// The matching reference value, nil if no reference value matched.
+	Reference *ReferenceValue
+}
+
+// ReferenceMatchResult contains the results of matching a MachineState
+// against reference manifests.
+type ReferenceMatchResult struct {
+	// The firmware blobs and drivers measured into PCRs 0-2.
+	FirmwareComponents []ReferenceMatch
+	EfiApps            []ReferenceMatch
+	// Only the PCRs with reference values are matched.
+	PCRs []ReferenceMatch
+}
+
+// MatchReferenceManifests matches the firmware components, EFI applications
+// and PCR values of a MachineState against the reference values of the
+// manifests. Only reference values using the MachineState's hash algorithm
+// are considered. The PCR values are obtained by replaying the MachineState's
+// (verified) raw events.
+func MatchReferenceManifests(state *pb.MachineState, manifests []*ReferenceManifest) (*ReferenceMatchResult, error) {
+	hash, err := tpm2.Algorithm(state.GetHash()).Hash()
+	if err != nil {
+		return nil, fmt.Errorf("invalid MachineState hash algorithm: %v", err)
+	}
+	var components, pcrRefs []*ReferenceValue
+	for _, manifest := range manifests {
+		for i := range manifest.ReferenceValues {
+			ref := &manifest.ReferenceValues[i]
+			if ref.Hash != hash {
+				continue
+			}
+			if ref.PCR >= 0 {
+				pcrRefs = append(pcrRefs, ref)
+			} else {
+				components = append(components, ref)
+			}
+		}
+	}
+	match := func(desc string, digest []byte, refs []*ReferenceValue) ReferenceMatch {
+		for _, ref := range refs {
+			if bytes.Equal(ref.Digest, digest) {
+				return ReferenceMatch{Measurement: desc, Digest: digest, Reference: ref}
+			}
+		}
+		return ReferenceMatch{Measurement: desc, Digest: digest}
+	}
+
+	result := &ReferenceMatchResult{}
+	for i, component := range state.GetFirmwareInventory().GetComponents() {
+		switch component.GetUntrustedType() {
+		case PostCode, EFIPlatformFirmwareBlob, EFIPlatformFirmwareBlob2, EFIBootServicesDriver, EFIRuntimeServicesDriver:
+			desc := fmt.Sprintf("firmware component #%d (PCR%d %v)", i, component.GetPcrIndex(), attest.EventType(component.GetUntrustedType()))
+			result.FirmwareComponents = append(result.FirmwareComponents, match(desc, component.GetDigest(), components))
+		}
+	}
+	for i, app := range state.GetEfi().GetApps() {
+		result.EfiApps = append(result.EfiApps, match(fmt.Sprintf("EFI app #%d", i), app.GetDigest(), components))
+	}
+
+	pcrs := replayRawEvents(hash, state.GetRawEvents())
+	refsByPCR := make(map[int][]*ReferenceValue)
+	var indexes []int
+	for _, ref := range pcrRefs {
+		if _, ok := refsByPCR[ref.PCR]; !ok {
+			indexes = append(indexes, ref.PCR)
+		}
+		refsByPCR[ref.PCR] = append(refsByPCR[ref.PCR], ref)
+	}
+	for _, index := range indexes {
+		// A PCR without events has an unknown value, so it never matches.
+		result.PCRs = append(result.PCRs, match(fmt.Sprintf("PCR %d", index), pcrs[uint32(index)], refsByPCR[index]))
+	}
+	return result, nil
+}
+
+// replayRawEvents computes the PCR values from verified events, taking the
+// TPM startup locality of PCR0 into account.
+func replayRawEvents(hash crypto.Hash, events []*pb.Event) map[uint32][]byte {
+	pcrs := make(map[uint32][]byte)
+	for _, event := range events {
+		index := event.GetPcrIndex()
+		if _, ok := pcrs[index]; !ok {
+			pcrs[index] = make([]byte, hash.Size())
+		}
+		if event.GetUntrustedType() == NoAction {
+			// See the TCG PC Client Platform Firmware Profile, Section 10.4.5.3
+			// Startup Locality Event.
+			data := event.GetData()
+			if index == 0 && len(data) == 17 && bytes.HasPrefix(data, []byte("StartupLocality\x00")) {
+				pcrs[index][hash.Size()-1] = data[16]
+			}
+			continue
+		}
+		pcrs[index] = extendDigest(hash, pcrs[index], event.GetDigest())
+	}
+	return pcrs
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/internal/cbor"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+)
+
+func newTestRIMSigner(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func mustEncodeCBOR(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := cbor.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// signCOSE wraps the payload in a tagged ES256 COSE_Sign1 message.
+func signCOSE(t *testing.T, cert *x509.Certificate, key *ecdsa.PrivateKey, payload []byte) []byte {
+	t.Helper()
+	protected := mustEncodeCBOR(t, map[any]any{coseAlgLabel: -7, coseX5ChainLabel: cert.Raw})
+	digest := sha256.Sum256(mustEncodeCBOR(t, []any{"Signature1", protected, []byte{}, payload}))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return mustEncodeCBOR(t, cbor.Tag{Number: coseSign1Tag, Content: []any{protected, map[any]any{}, payload, signature}})
+}
+
+// makeTestCoRIM creates an unsigned CoRIM with a single CoMID, containing a
+// reference triple per value.
+func makeTestCoRIM(t *testing.T, values []ReferenceValue) []byte {
+	t.Helper()
+	var triples []any
+	for _, value := range values {
+		var mkey any = value.Name
+		if value.PCR >= 0 {
+			mkey = value.PCR
+		}
+		env := map[any]any{0: map[any]any{1: "Test Vendor", 2: "Test Model"}}
+		measurement := map[any]any{
+			0: mkey,
+			1: map[any]any{2: []any{[]any{1, value.Digest}}},
+		}
+		triples = append(triples, []any{env, []any{measurement}})
+	}
+	comid := mustEncodeCBOR(t, map[any]any{
+		1: map[any]any{0: "test-comid"},
+		4: map[any]any{0: triples},
+	})
+	return mustEncodeCBOR(t, cbor.Tag{Number: corimTag, Content: map[any]any{
+		0: "test-corim",
+		1: []any{cbor.Tag{Number: comidTag, Content: comid}},
+	}})
+}
+
+func ubuntuReferenceValues(t *testing.T) (*pb.MachineState, []ReferenceValue) {
+	t.Helper()
+	bank := UbuntuAmdSevGCE.Banks[1]
+	state, err := parsePCClientEventLog(UbuntuAmdSevGCE.RawLog, bank, UnsupportedLoader)
+	if err != nil {
+		t.Fatalf("failed to parse event log: %v", err)
+	}
+	var values []ReferenceValue
+	for _, app := range state.GetEfi().GetApps() {
+		values = append(values, ReferenceValue{Name: "app", Hash: crypto.SHA256, Digest: app.GetDigest(), PCR: -1})
+	}
+	for _, pcr := range []uint32{0, 4, 7} {
+		values = append(values, ReferenceValue{Name: "Test Vendor Test Model", Hash: crypto.SHA256, Digest: bank.GetPcrs()[pcr], PCR: int(pcr)})
+	}
+	return state, values
+}
+
+func TestParseReferenceManifest(t *testing.T) {
+	cert, key := newTestRIMSigner(t)
+	_, values := ubuntuReferenceValues(t)
+	signed := signCOSE(t, cert, key, makeTestCoRIM(t, values))
+
+	manifest, err := ParseReferenceManifest(signed, &pb.RIMPolicy{RequireSigned: true, RootCerts: [][]byte{cert.Raw}}, time.Now())
+	if err != nil {
+		t.Fatalf("ParseReferenceManifest() failed: %v", err)
+	}
+	if manifest.ID != "test-corim" {
+		t.Errorf("ParseReferenceManifest() got ID %q, want %q", manifest.ID, "test-corim")
+	}
+	if !manifest.Signer.Equal(cert) {
+		t.Error("ParseReferenceManifest() returned the wrong signer")
+	}
+	if diff := cmp.Diff(values, manifest.ReferenceValues); diff != "" {
+		t.Errorf("ParseReferenceManifest() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseReferenceManifestCoSWID(t *testing.T) {
+	digest := sha256.Sum256([]byte("shimx64.efi"))
+	coswid := mustEncodeCBOR(t, cbor.Tag{Number: coswidTag, Content: map[any]any{
+		0:  []byte{0x9b, 0x2a, 0x5e, 0x4c, 0x1d, 0x3f, 0x4e, 0x8a, 0x9c, 0x6b, 0x2d, 0x1e, 0x0f, 0x3a, 0x4b, 0x5c},
+		1:  "shim",
+		12: 0,
+		6: map[any]any{
+			16: map[any]any{
+				24: "EFI",
+				16: []any{map[any]any{
+					24: "ubuntu",
+					17: map[any]any{24: "shimx64.efi", 7: []any{"sha-256", digest[:]}},
+				}},
+			},
+		},
+	}})
+
+	manifest, err := ParseReferenceManifest(coswid, &pb.RIMPolicy{}, time.Now())
+	if err != nil {
+		t.Fatalf("ParseReferenceManifest() failed: %v", err)
+	}
+	want := &ReferenceManifest{
+		ID: "9b2a5e4c-1d3f-4e8a-9c6b-2d1e0f3a4b5c",
+		ReferenceValues: []ReferenceValue{
+			{Name: "shim/EFI/ubuntu/shimx64.efi", Hash: crypto.SHA256, Digest: digest[:], PCR: -1},
+		},
+	}
+	if diff := cmp.Diff(want, manifest); diff != "" {
+		t.Errorf("ParseReferenceManifest() returned unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseReferenceManifestFailures(t *testing.T) {
+	cert, key := newTestRIMSigner(t)
+	otherCert, _ := newTestRIMSigner(t)
+	unsigned := makeTestCoRIM(t, []ReferenceValue{{Name: "blob", Hash: crypto.SHA256, Digest: make([]byte, 32), PCR: -1}})
+	signed := signCOSE(t, cert, key, unsigned)
+	tampered := signCOSE(t, cert, key, unsigned)
+	tampered[len(tampered)-1] ^= 0xff
+	roots := [][]byte{cert.Raw}
+
+	tests := []struct {
+		name     string
+		manifest []byte
+		policy   *pb.RIMPolicy
+		now      time.Time
+		wantErr  string
+	}{
+		{"UnsignedRequired", unsigned, &pb.RIMPolicy{RequireSigned: true, RootCerts: roots}, time.Now(), "not signed"},
+		{"BadSignature", tampered, &pb.RIMPolicy{RootCerts: roots}, time.Now(), "invalid ECDSA signature"},
+		{"UntrustedSigner", signed, &pb.RIMPolicy{RootCerts: [][]byte{otherCert.Raw}}, time.Now(), "signing certificate"},
+		{"NoRoots", signed, &pb.RIMPolicy{}, time.Now(), "no root certificates"},
+		{"Expired", signed, &pb.RIMPolicy{RootCerts: roots}, time.Now().AddDate(2, 0, 0), "signing certificate"},
+		{"XML", []byte(`<?xml version="1.0"?><SoftwareIdentity/>`), &pb.RIMPolicy{}, time.Now(), "not supported"},
+		{"NotCBOR", []byte{0xff}, &pb.RIMPolicy{}, time.Now(), "failed to decode"},
+		{"BadDigestSize", makeTestCoRIM(t, []ReferenceValue{{Name: "blob", Digest: []byte{1}, PCR: -1}}), &pb.RIMPolicy{}, time.Now(), "size"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseReferenceManifest(tc.manifest, tc.policy, tc.now)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ParseReferenceManifest() got err = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchReferenceManifests(t *testing.T) {
+	state, values := ubuntuReferenceValues(t)
+	manifest, err := ParseReferenceManifest(makeTestCoRIM(t, values), &pb.RIMPolicy{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := MatchReferenceManifests(state, []*ReferenceManifest{manifest})
+	if err != nil {
+		t.Fatalf("MatchReferenceManifests() failed: %v", err)
+	}
+	if len(result.EfiApps) == 0 || len(result.EfiApps) != len(state.GetEfi().GetApps()) {
+		t.Errorf("MatchReferenceManifests() got %d EFI apps, want %d", len(result.EfiApps), len(state.GetEfi().GetApps()))
+	}
+	for _, app := range result.EfiApps {
+		if app.Reference == nil {
+			t.Errorf("%s did not match", app.Measurement)
+		}
+	}
+	if len(result.PCRs) != 3 {
+		t.Errorf("MatchReferenceManifests() got %d PCRs, want 3", len(result.PCRs))
+	}
+	for _, pcr := range result.PCRs {
+		if pcr.Reference == nil {
+			t.Errorf("%s (%x) did not match", pcr.Measurement, pcr.Digest)
+		}
+	}
+}
+
+func TestMatchReferenceManifestsFirmware(t *testing.T) {
+	blob := sha256.Sum256([]byte("firmware volume"))
+	driver := sha256.Sum256([]byte("option ROM"))
+	state := &pb.MachineState{
+		Hash: tpmpb.HashAlgo_SHA256,
+		FirmwareInventory: &pb.FirmwareInventory{Components: []*pb.FirmwareComponent{
+			{PcrIndex: 0, UntrustedType: EFIPlatformFirmwareBlob2, Digest: blob[:]},
+			{PcrIndex: 1, UntrustedType: EFIHandoffTables2, Digest: make([]byte, 32)},
+			{PcrIndex: 2, UntrustedType: EFIBootServicesDriver, Digest: driver[:]},
+		}},
+	}
+	values := []ReferenceValue{{Name: "OVMF", Hash: crypto.SHA256, Digest: blob[:], PCR: -1}}
+	manifest, err := ParseReferenceManifest(makeTestCoRIM(t, values), &pb.RIMPolicy{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := MatchReferenceManifests(state, []*ReferenceManifest{manifest})
+	if err != nil {
+		t.Fatalf("MatchReferenceManifests() failed: %v", err)
+	}
+	// Handoff tables are not firmware components with reference values.
+	if len(result.FirmwareComponents) != 2 {
+		t.Fatalf("MatchReferenceManifests() got %d firmware components, want 2", len(result.FirmwareComponents))
+	}
+	if result.FirmwareComponents[0].Reference == nil {
+		t.Errorf("%s did not match", result.FirmwareComponents[0].Measurement)
+	}
+	if result.FirmwareComponents[1].Reference != nil {
+		t.Errorf("%s unexpectedly matched", result.FirmwareComponents[1].Measurement)
+	}
+
+	policy := &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, values)}}
+	if err := evaluateReferenceManifestPolicy(state, policy, nil); err != nil {
+		t.Errorf("evaluateReferenceManifestPolicy() got err = %v, want nil", err)
+	}
+	policy.RequireFirmware = true
+	if err := evaluateReferenceManifestPolicy(state, policy, nil); err == nil {
+		t.Error("evaluateReferenceManifestPolicy() got nil error with an unknown driver, want error")
+	}
+}
+
+func TestEvaluateReferenceManifestPolicy(t *testing.T) {
+	state, values := ubuntuReferenceValues(t)
+	wrongPCR := append([]ReferenceValue{}, values...)
+	wrongPCR[len(wrongPCR)-1].Digest = make([]byte, 32)
+	extraPCR := []ReferenceValue{{Name: "PCR", Hash: crypto.SHA256, Digest: make([]byte, 32), PCR: 5}}
+
+	tests := []struct {
+		name    string
+		policy  *pb.ReferenceManifestPolicy
+		wantErr bool
+	}{
+		{"Matching", &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, values)}, RequireEfiApps: true}, false},
+		{"PCRMismatch", &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, wrongPCR)}}, true},
+		{"ExtraPCRMismatch", &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, values), makeTestCoRIM(t, extraPCR)}}, true},
+		{"MissingEfiApps", &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, values[len(values)-3:])}, RequireEfiApps: true}, true},
+		{"Unsigned", &pb.ReferenceManifestPolicy{Manifests: [][]byte{makeTestCoRIM(t, values)}, Signing: &pb.RIMPolicy{RequireSigned: true}}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := EvaluatePolicy(state, &pb.Policy{ReferenceManifests: tc.policy})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("EvaluatePolicy() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
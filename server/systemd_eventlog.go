@@ -0,0 +1,302 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-attestation/attest"
+	"github.com/google/go-tpm-tools/cel"
+	pb "github.com/google/go-tpm-tools/proto/attest"
+	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+// Event types used by systemd in its userspace measurement log.
+// See https://systemd.io/TPM2_PCR_MEASUREMENTS/.
+const (
+	SystemdPhaseEvent      = "phase"
+	SystemdMachineIDEvent  = "machine-id"
+	SystemdFileSystemEvent = "filesystem"
+	SystemdVolumeKeyEvent  = "volume-key"
+)
+
+const (
+	// systemdPhasePCR is measured by systemd-stub, through the firmware, and
+	// then by systemd-pcrphase, which measures the boot phases.
+	systemdPhasePCR = 11
+	// systemdOwnedPCR is only measured by systemd userspace.
+	systemdOwnedPCR = 15
+)
+
+// systemdPhases are the boot phases systemd-pcrphase measures into PCR11.
+var systemdPhases = []string{"enter-initrd", "leave-initrd", "sysinit", "ready", "shutdown", "final"}
+
+const (
+	systemdContentType     = "systemd"
+	systemdMachineIDPrefix = "machine-id:"
+	// JSON-SEQ (RFC 7464) record separator.
+	jsonSeqRecordSeparator = '\x1e'
+)
+
+// systemdLogRecord is a record in the CEL-JSON systemd measurement log.
+type systemdLogRecord struct {
+	PCR     uint32 `json:"pcr"`
+	Digests []struct {
+		HashAlg string `json:"hashAlg"`
+		Digest  string `json:"digest"`
+	} `json:"digests"`
+	ContentType string `json:"content_type"`
+	Content     struct {
+		String    string `json:"string"`
+		EventType string `json:"eventType"`
+	} `json:"content"`
+}
+
+// systemdHashAlgs maps CEL-JSON hash algorithm names to TPM algorithms.
+var systemdHashAlgs = map[string]tpm2.Algorithm{
+	"sha1":   tpm2.AlgSHA1,
+	"sha256": tpm2.AlgSHA256,
+	"sha384": tpm2.AlgSHA384,
+	"sha512": tpm2.AlgSHA512,
+}
+
+// parseSystemdLog decodes a systemd measurement log. The log is a JSON-SEQ
+// stream of CEL-JSON records, but plain newline-separated records are also
+// accepted. The returned events contain the digest for the given hash
+// algorithm.
+func parseSystemdLog(rawSystemdLog []byte, hashAlg tpm2.Algorithm, limits cel.DecodeLimits) ([]*pb.SystemdEvent, error) {
+	cryptoHash, err := hashAlg.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported hash algorithm: %v", err)
+	}
+	if err := checkLogTotalSize(rawSystemdLog, limits); err != nil {
+		return nil, fmt.Errorf("invalid systemd log: %v", err)
+	}
+	var events []*pb.SystemdEvent
+	scanner := bufio.NewScanner(bytes.NewReader(rawSystemdLog))
+	scanner.Buffer(nil, len(rawSystemdLog)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(bytes.TrimLeft(scanner.Bytes(), string(jsonSeqRecordSeparator)))
+		if len(line) == 0 {
+			continue
+		}
+		if err := checkLogRecordLimits(len(events), len(line), limits); err != nil {
+			return nil, fmt.Errorf("invalid systemd log: %v", err)
+		}
+		var record systemdLogRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode systemd log record #%d: %v", len(events), err)
+		}
+		if record.ContentType != systemdContentType {
+			return nil, fmt.Errorf("unexpected content type %q in systemd log record #%d", record.ContentType, len(events))
+		}
+		event, err := convertSystemdRecord(record, hashAlg, cryptoHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid systemd log record #%d: %v", len(events), err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read systemd log: %v", err)
+	}
+	return events, nil
+}
+
+func convertSystemdRecord(record systemdLogRecord, hashAlg tpm2.Algorithm, cryptoHash crypto.Hash) (*pb.SystemdEvent, error) {
+	var digest []byte
+	for _, d := range record.Digests {
+		alg, ok := systemdHashAlgs[strings.ToLower(d.HashAlg)]
+		if !ok || alg != hashAlg {
+			continue
+		}
+		var err error
+		digest, err = hex.DecodeString(d.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s digest: %v", d.HashAlg, err)
+		}
+		if len(digest) != cryptoHash.Size() {
+			return nil, fmt.Errorf("invalid %s digest length: %d", d.HashAlg, len(digest))
+		}
+	}
+	if digest == nil {
+		return nil, fmt.Errorf("no digest for hash algorithm %v", hashAlg)
+	}
+
+	hasher := cryptoHash.New()
+	hasher.Write([]byte(record.Content.String))
+	return &pb.SystemdEvent{
+		PcrIndex:       record.PCR,
+		UntrustedType:  record.Content.EventType,
+		Data:           record.Content.String,
+		Digest:         digest,
+		DigestVerified: bytes.Equal(hasher.Sum(nil), digest),
+	}, nil
+}
+
+// replaySystemdLog replays the systemd measurement log on top of the TCG
+// firmware event log for every PCR measured by systemd and quoted in pcrs.
+// Firmware events (e.g., the systemd-stub PCR11 events) always come before
+// systemd userspace events. It returns the systemd events verified by the
+// replay, and a copy of pcrs where the values of the replayed PCRs are
+// replaced with the values expected after only the firmware events. Since the
+// complete replay matched, these intermediate values can be trusted when
+// verifying the TCG event log. The systemd log is decoded within the limits.
+//
+// As neither log is authenticated, moving events from one log to the other
+// does not change the replay. The split between the logs is instead derived
+// from the replayed digests: every PCR11 event of the systemd log must be the
+// measurement of a boot phase, no PCR11 event of the TCG event log may be
+// one, and the TCG event log may have no PCR15 event. Otherwise, an event on
+// a systemd PCR is missing from the systemd log, and the logs are rejected.
+func replaySystemdLog(rawEventLog []byte, rawSystemdLog []byte, pcrs *tpmpb.PCRs, limits cel.DecodeLimits) ([]*pb.SystemdEvent, *tpmpb.PCRs, error) {
+	hashAlg := tpm2.Algorithm(pcrs.GetHash())
+	cryptoHash, err := hashAlg.Hash()
+	if err != nil {
+		return nil, nil, fmt.Errorf("received bad PCR proto: %v", err)
+	}
+	systemdEvents, err := parseSystemdLog(rawSystemdLog, hashAlg, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	phaseDigests := make(map[string]bool)
+	for _, phase := range systemdPhases {
+		hasher := cryptoHash.New()
+		hasher.Write([]byte(phase))
+		phaseDigests[string(hasher.Sum(nil))] = true
+	}
+	for idx, event := range systemdEvents {
+		switch event.GetPcrIndex() {
+		case systemdPhasePCR:
+			if !event.GetDigestVerified() || !phaseDigests[string(event.GetDigest())] {
+				return nil, nil, fmt.Errorf("systemd log record #%d is not a boot phase measurement in PCR%d", idx, systemdPhasePCR)
+			}
+		case systemdOwnedPCR:
+		default:
+			return nil, nil, fmt.Errorf("systemd log record #%d measures PCR%d, which systemd does not measure", idx, event.GetPcrIndex())
+		}
+	}
+
+	var firmwareEvents []attest.Event
+	if len(rawEventLog) != 0 {
+		eventLog, err := attest.ParseEventLog(rawEventLog)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse event log: %v", err)
+		}
+		firmwareEvents = eventLog.Events(attest.HashAlg(hashAlg))
+	}
+	for _, event := range firmwareEvents {
+		if uint32(event.Type) == NoAction {
+			continue
+		}
+		switch uint32(event.Index) {
+		case systemdPhasePCR:
+			if phaseDigests[string(event.Digest)] {
+				return nil, nil, fmt.Errorf("TCG event log has a PCR%d boot phase measurement missing from the systemd log", systemdPhasePCR)
+			}
+		case systemdOwnedPCR:
+			return nil, nil, fmt.Errorf("TCG event log has a PCR%d event missing from the systemd log", systemdOwnedPCR)
+		}
+	}
+
+	firmwarePCRs := proto.Clone(pcrs).(*tpmpb.PCRs)
+	verifiedPCRs := make(map[uint32]bool)
+	var failedPCRs []uint32
+	for _, index := range systemdPCRs(systemdEvents) {
+		quoted, ok := pcrs.GetPcrs()[index]
+		if !ok {
+			// Nothing to replay against, so none of the events are verified.
+			continue
+		}
+		replayed := make([]byte, cryptoHash.Size())
+		for _, event := range firmwareEvents {
+			if uint32(event.Index) != index || uint32(event.Type) == NoAction {
+				continue
+			}
+			if len(event.Digest) != cryptoHash.Size() {
+				return nil, nil, fmt.Errorf("TCG event log is missing a %v digest for a PCR%d event", hashAlg, index)
+			}
+			replayed = extendDigest(cryptoHash, replayed, event.Digest)
+		}
+		firmwareValue := replayed
+		for _, event := range systemdEvents {
+			if event.GetPcrIndex() == index {
+				replayed = extendDigest(cryptoHash, replayed, event.GetDigest())
+			}
+		}
+		if !bytes.Equal(replayed, quoted) {
+			failedPCRs = append(failedPCRs, index)
+			continue
+		}
+		firmwarePCRs.Pcrs[index] = firmwareValue
+		verifiedPCRs[index] = true
+	}
+	if len(failedPCRs) != 0 {
+		return nil, nil, fmt.Errorf("systemd log replay failed for PCRs %v", failedPCRs)
+	}
+
+	var verifiedEvents []*pb.SystemdEvent
+	for _, event := range systemdEvents {
+		if verifiedPCRs[event.GetPcrIndex()] {
+			verifiedEvents = append(verifiedEvents, event)
+		}
+	}
+	return verifiedEvents, firmwarePCRs, nil
+}
+
+// systemdPCRs returns the distinct PCR indexes of the events, in order of
+// first appearance.
+func systemdPCRs(events []*pb.SystemdEvent) []uint32 {
+	var indexes []uint32
+	seen := make(map[uint32]bool)
+	for _, event := range events {
+		if !seen[event.GetPcrIndex()] {
+			seen[event.GetPcrIndex()] = true
+			indexes = append(indexes, event.GetPcrIndex())
+		}
+	}
+	return indexes
+}
+
+func extendDigest(hash crypto.Hash, current []byte, digest []byte) []byte {
+	hasher := hash.New()
+	hasher.Write(current)
+	hasher.Write(digest)
+	return hasher.Sum(nil)
+}
+
+// getSystemdState extracts the boot phases, machine ID and file systems from
+// replayed systemd events. Only events whose digest matches their data are
+// trusted. It returns nil if there are no replayed events.
+func getSystemdState(events []*pb.SystemdEvent) (*pb.SystemdState, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	state := &pb.SystemdState{Events: events}
+	for idx, event := range events {
+		if !event.GetDigestVerified() {
+			continue
+		}
+		data := event.GetData()
+		switch {
+		case event.GetPcrIndex() == systemdPhasePCR && event.GetUntrustedType() == SystemdPhaseEvent:
+			state.BootPhases = append(state.BootPhases, data)
+		case event.GetPcrIndex() == systemdPhasePCR && event.GetUntrustedType() == "" && !strings.Contains(data, ":"):
+			// Older systemd versions do not record an event type for phases.
+			state.BootPhases = append(state.BootPhases, data)
+		case event.GetPcrIndex() == systemdOwnedPCR && strings.HasPrefix(data, systemdMachineIDPrefix):
+			if state.GetMachineId() != "" {
+				return nil, fmt.Errorf("found duplicate machine-id event #%d in PCR15", idx)
+			}
+			state.MachineId = strings.TrimPrefix(data, systemdMachineIDPrefix)
+		case event.GetPcrIndex() == systemdOwnedPCR && strings.HasPrefix(data, "file-system:"):
+			state.FileSystems = append(state.FileSystems, data)
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-tpm-tools/cel"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	pb "github.com/google/go-tpm-tools/proto/tpm"
+	"github.com/google/go-tpm/legacy/tpm2"
+	"google.golang.org/protobuf/proto"
+)
+
+type systemdTestEvent struct {
+	pcr       uint32
+	eventType string
+	data      string
+}
+
+var systemdTestEvents = []systemdTestEvent{
+	{11, SystemdPhaseEvent, "enter-initrd"},
+	{15, SystemdMachineIDEvent, "machine-id:3a9d668b4db749398a4a5e78a03bffa5"},
+	{11, SystemdPhaseEvent, "leave-initrd"},
+	{15, SystemdFileSystemEvent, "file-system:/:ext4:8fb0ac7a-3f3a-4b8e-9a42-2e5c1a4d8c1e:root:::"},
+	{11, SystemdPhaseEvent, "sysinit"},
+	{11, SystemdPhaseEvent, "ready"},
+}
+
+// makeSystemdLog returns a systemd measurement log for the events, along
+// with the resulting PCR values starting from zero.
+func makeSystemdLog(hash crypto.Hash, events []systemdTestEvent) ([]byte, map[uint32][]byte) {
+	var log strings.Builder
+	pcrs := make(map[uint32][]byte)
+	for _, event := range events {
+		hasher := hash.New()
+		hasher.Write([]byte(event.data))
+		digest := hasher.Sum(nil)
+		fmt.Fprintf(&log, "\x1e{\"pcr\":%d,\"digests\":[{\"hashAlg\":\"sha256\",\"digest\":\"%s\"}],\"content_type\":\"systemd\",\"content\":{\"string\":%q,\"eventType\":%q}}\n",
+			event.pcr, hex.EncodeToString(digest), event.data, event.eventType)
+		if _, ok := pcrs[event.pcr]; !ok {
+			pcrs[event.pcr] = make([]byte, hash.Size())
+		}
+		pcrs[event.pcr] = extendDigest(hash, pcrs[event.pcr], digest)
+	}
+	return []byte(log.String()), pcrs
+}
+
+func TestParseSystemdEventLog(t *testing.T) {
+	// Use the SHA256 bank.
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	systemdLog, systemdPCRs := makeSystemdLog(crypto.SHA256, systemdTestEvents)
+	for index, value := range systemdPCRs {
+		bank.Pcrs[index] = value
+	}
+	attestation := &attestpb.Attestation{
+		EventLog:        UbuntuAmdSevGCE.RawLog,
+		SystemdEventLog: systemdLog,
+	}
+
+	ms, err := parseMachineStateFromTPM(attestation, bank, VerifyOpts{})
+	if err != nil {
+		t.Fatalf("parseMachineStateFromTPM() got err = %v, want nil", err)
+	}
+	systemd := ms.GetSystemd()
+	if diff := cmp.Diff([]string{"enter-initrd", "leave-initrd", "sysinit", "ready"}, systemd.GetBootPhases()); diff != "" {
+		t.Errorf("unexpected boot phases (-want +got):\n%s", diff)
+	}
+	if systemd.GetMachineId() != "3a9d668b4db749398a4a5e78a03bffa5" {
+		t.Errorf("got machine ID %q, want %q", systemd.GetMachineId(), "3a9d668b4db749398a4a5e78a03bffa5")
+	}
+	if len(systemd.GetFileSystems()) != 1 || !strings.HasPrefix(systemd.GetFileSystems()[0], "file-system:/:ext4:") {
+		t.Errorf("got file systems %v, want the root file system", systemd.GetFileSystems())
+	}
+	if len(systemd.GetEvents()) != len(systemdTestEvents) {
+		t.Errorf("got %d systemd events, want %d", len(systemd.GetEvents()), len(systemdTestEvents))
+	}
+	for _, event := range systemd.GetEvents() {
+		if !event.GetDigestVerified() {
+			t.Errorf("expected systemd event %q to be verified", event.GetData())
+		}
+	}
+	// The firmware event log must still be fully parsed.
+	if ms.GetPlatform() == nil || len(ms.GetRawEvents()) == 0 {
+		t.Error("expected the TCG event log to be parsed")
+	}
+}
+
+func TestParseSystemdEventLogReplayFail(t *testing.T) {
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	systemdLog, systemdPCRs := makeSystemdLog(crypto.SHA256, systemdTestEvents)
+	for index, value := range systemdPCRs {
+		bank.Pcrs[index] = value
+	}
+	// Drop the last phase from the log, as if it was extended outside the log.
+	tampered, _ := makeSystemdLog(crypto.SHA256, systemdTestEvents[:len(systemdTestEvents)-1])
+	attestation := &attestpb.Attestation{
+		EventLog:        UbuntuAmdSevGCE.RawLog,
+		SystemdEventLog: tampered,
+	}
+	if _, err := parseMachineStateFromTPM(attestation, bank, VerifyOpts{}); err == nil {
+		t.Error("parseMachineStateFromTPM() got nil error, want systemd replay error")
+	}
+
+	attestation.SystemdEventLog = systemdLog
+	if _, err := parseMachineStateFromTPM(attestation, bank, VerifyOpts{}); err != nil {
+		t.Errorf("parseMachineStateFromTPM() got err = %v, want nil", err)
+	}
+}
+
+func TestParseSystemdLogLimits(t *testing.T) {
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	systemdLog, systemdPCRs := makeSystemdLog(crypto.SHA256, systemdTestEvents)
+	for index, value := range systemdPCRs {
+		bank.Pcrs[index] = value
+	}
+	attestation := &attestpb.Attestation{
+		EventLog:        UbuntuAmdSevGCE.RawLog,
+		SystemdEventLog: systemdLog,
+	}
+
+	defaults := cel.DefaultDecodeLimits()
+	for _, tc := range []struct {
+		name    string
+		limits  *cel.DecodeLimits
+		wantErr bool
+	}{
+		{"NoLimits", nil, false},
+		{"Defaults", &defaults, false},
+		{"TooManyRecords", &cel.DecodeLimits{MaxRecords: len(systemdTestEvents) - 1}, true},
+		{"RecordTooLarge", &cel.DecodeLimits{MaxRecordSize: 64}, true},
+		{"TooLarge", &cel.DecodeLimits{MaxTotalSize: int64(len(systemdLog) - 1)}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := VerifyOpts{EventLogLimits: tc.limits}
+			_, err := parseMachineStateFromTPM(attestation, bank, opts)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("parseMachineStateFromTPM() got err = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSystemdLogInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{"BadJSON", "\x1e{\"pcr\":11,\n"},
+		{"WrongContentType", "\x1e{\"pcr\":11,\"digests\":[],\"content_type\":\"pcclient_std\",\"content\":{}}\n"},
+		{"MissingDigest", "\x1e{\"pcr\":11,\"digests\":[{\"hashAlg\":\"sha1\",\"digest\":\"00\"}],\"content_type\":\"systemd\",\"content\":{\"string\":\"ready\"}}\n"},
+		{"BadDigestLength", "\x1e{\"pcr\":11,\"digests\":[{\"hashAlg\":\"sha256\",\"digest\":\"00\"}],\"content_type\":\"systemd\",\"content\":{\"string\":\"ready\"}}\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseSystemdLog([]byte(tc.log), tpm2.AlgSHA256, cel.DecodeLimits{}); err == nil {
+				t.Error("parseSystemdLog() got nil error, want error")
+			}
+		})
+	}
+}
+
+// appendTCGEvent appends a crypto agile event measuring measured in the
+// SHA-1 and SHA-256 banks to the TCG event log, and returns the new log.
+func appendTCGEvent(rawLog []byte, index uint32, eventType uint32, measured []byte, data []byte) []byte {
+	buf := bytes.NewBuffer(bytes.Clone(rawLog))
+	binary.Write(buf, binary.LittleEndian, index)
+	binary.Write(buf, binary.LittleEndian, eventType)
+	binary.Write(buf, binary.LittleEndian, uint32(2))
+	for _, alg := range []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256} {
+		hash, _ := alg.Hash()
+		hasher := hash.New()
+		hasher.Write(measured)
+		binary.Write(buf, binary.LittleEndian, uint16(alg))
+		buf.Write(hasher.Sum(nil))
+	}
+	binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestReplaySystemdLogSplit(t *testing.T) {
+	// systemd-stub measures the name and the contents of the UKI sections
+	// into PCR11 before the boot phases.
+	stubEvents := [][]byte{[]byte(".linux\x00"), []byte("kernel")}
+	phaseLog, _ := makeSystemdLog(crypto.SHA256, systemdTestEvents)
+	stubLog := UbuntuAmdSevGCE.RawLog
+	for _, measured := range stubEvents {
+		stubLog = appendTCGEvent(stubLog, 11, IPL, measured, []byte(".linux\x00"))
+	}
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	stubPCR := make([]byte, crypto.SHA256.Size())
+	for _, measured := range stubEvents {
+		digest := crypto.SHA256.New()
+		digest.Write(measured)
+		stubPCR = extendDigest(crypto.SHA256, stubPCR, digest.Sum(nil))
+	}
+	bank.Pcrs[11] = stubPCR
+	bank.Pcrs[15] = make([]byte, crypto.SHA256.Size())
+	for _, event := range systemdTestEvents {
+		digest := crypto.SHA256.New()
+		digest.Write([]byte(event.data))
+		bank.Pcrs[event.pcr] = extendDigest(crypto.SHA256, bank.Pcrs[event.pcr], digest.Sum(nil))
+	}
+
+	events, firmwarePCRs, err := replaySystemdLog(stubLog, phaseLog, bank, cel.DecodeLimits{})
+	if err != nil {
+		t.Fatalf("replaySystemdLog() got err = %v, want nil", err)
+	}
+	if len(events) != len(systemdTestEvents) {
+		t.Errorf("replaySystemdLog() got %d events, want %d", len(events), len(systemdTestEvents))
+	}
+	if !bytes.Equal(firmwarePCRs.GetPcrs()[11], stubPCR) {
+		t.Errorf("replaySystemdLog() got firmware PCR11 %x, want the systemd-stub value %x", firmwarePCRs.GetPcrs()[11], stubPCR)
+	}
+
+	// The same PCR11 and PCR15 chains, split differently between the logs.
+	lastPhase := systemdTestEvents[len(systemdTestEvents)-1]
+	withoutLastPhase, _ := makeSystemdLog(crypto.SHA256, systemdTestEvents[:len(systemdTestEvents)-1])
+	withoutMachineID, _ := makeSystemdLog(crypto.SHA256, append([]systemdTestEvent{systemdTestEvents[0]}, systemdTestEvents[2:]...))
+	withStubContents, _ := makeSystemdLog(crypto.SHA256, append([]systemdTestEvent{{11, "", string(stubEvents[1])}}, systemdTestEvents...))
+	for _, tc := range []struct {
+		name       string
+		eventLog   []byte
+		systemdLog []byte
+	}{
+		{"PhaseInTCGLog", appendTCGEvent(stubLog, 11, IPL, []byte(lastPhase.data), []byte(".osrel\x00")), withoutLastPhase},
+		{"MachineIDInTCGLog", appendTCGEvent(stubLog, 15, IPL, []byte(systemdTestEvents[1].data), nil), withoutMachineID},
+		{"StubEventInSystemdLog", appendTCGEvent(UbuntuAmdSevGCE.RawLog, 11, IPL, stubEvents[0], []byte(".linux\x00")), withStubContents},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := replaySystemdLog(tc.eventLog, tc.systemdLog, bank, cel.DecodeLimits{}); err == nil {
+				t.Error("replaySystemdLog() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestReplaySystemdLogOtherPCR(t *testing.T) {
+	bank := proto.Clone(UbuntuAmdSevGCE.Banks[1]).(*pb.PCRs)
+	systemdLog, systemdPCRs := makeSystemdLog(crypto.SHA256, []systemdTestEvent{{9, "", "ready"}})
+	bank.Pcrs[9] = systemdPCRs[9]
+	if _, _, err := replaySystemdLog(nil, systemdLog, bank, cel.DecodeLimits{}); err == nil {
+		t.Error("replaySystemdLog() with an event in PCR9 got nil error, want error")
+	}
+}
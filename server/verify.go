@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/go-eventlog/proto/state"
 	"github.com/google/go-eventlog/register"
+	"github.com/google/go-tpm-tools/cel"
 	"github.com/google/go-tpm-tools/internal"
 	pb "github.com/google/go-tpm-tools/proto/attest"
 	tpmpb "github.com/google/go-tpm-tools/proto/tpm"
@@ -52,7 +53,7 @@ type VerifyOpts struct {
 	TrustedRootCerts  []*x509.Certificate
 	IntermediateCerts []*x509.Certificate
 	// Which bootloader the instance uses. Pick UNSUPPORTED to skip this
-	// parsing or for unsupported bootloaders (e.g., systemd).
+	// parsing or for unsupported bootloaders.
 	Loader Bootloader
 	// TEEOpts allows customizing the functionality of VerifyTEEAttestation.
 	// Its type can be *VerifySnpOpts if the TEEAttestation is a SevSnpAttestation
@@ -63,6 +64,11 @@ type VerifyOpts struct {
 	// Deprecated: go-tpm-tools no longer verifies SNP or TDX attestation.
 	// Please use go-sev-guest and go-tdx-guest.
 	TEEOpts interface{}
+	// EventLogLimits bounds the number of events, and the size of each event
+	// and of the whole log, accepted in the TCG, Canonical, systemd and IMA
+	// event logs. cel.DefaultDecodeLimits() is suggested when verifying
+	// attestations from untrusted sources. If nil, the logs are not limited.
+	EventLogLimits *cel.DecodeLimits
 }
 
 // Bootloader refers to the second-stage bootloader that loads and transfers
@@ -76,6 +82,10 @@ const (
 	UnsupportedLoader Bootloader = iota
 	// GRUB (https://www.gnu.org/software/grub/).
 	GRUB
+	// SystemdBoot refers to systemd-boot or any other loader starting a
+	// Unified Kernel Image through systemd-stub
+	// (https://www.freedesktop.org/software/systemd/man/latest/systemd-stub.html).
+	SystemdBoot
 )
 
 // TODO: Change int64 fields to uint64 when compatible with ASN1 parsing.
@@ -116,6 +126,11 @@ func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.Machin
 		return nil, fmt.Errorf("failed to parse and validate AK: %w", err)
 	}
 
+	limits := opts.eventLogLimits()
+	if err := checkEventLogLimits(attestation.GetEventLog(), limits); err != nil {
+		return nil, fmt.Errorf("failed to validate the PCClient event log: %w", err)
+	}
+
 	// Attempt to replay the log against our PCRs in order of hash preference
 	var lastErr error
 	for _, quote := range supportedQuotes(attestation.GetQuotes()) {
@@ -133,20 +148,12 @@ func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.Machin
 			continue
 		}
 
-		pcrBank := register.PCRBank{TCGHashAlgo: state.HashAlgo(pcrs.Hash)}
-		digestAlg, err := pcrBank.TCGHashAlgo.CryptoHash()
+		pcrBank, err := toPCRBank(pcrs)
 		if err != nil {
-			return nil, fmt.Errorf("invalid digest algorithm")
+			return nil, err
 		}
 
-		for pcrIndex, digest := range pcrs.GetPcrs() {
-			pcrBank.PCRs = append(pcrBank.PCRs, register.PCR{
-				Index:     int(pcrIndex),
-				Digest:    digest,
-				DigestAlg: digestAlg})
-		}
-
-		celState, err := ParseCosCELPCR(attestation.GetCanonicalEventLog(), pcrBank)
+		celState, err := getCosStateFromCEL(attestation.GetCanonicalEventLog(), pcrBank, cel.PCRTypeValue, limits)
 		if err != nil {
 			lastErr = fmt.Errorf("failed to validate the Canonical event log: %w", err)
 			continue
@@ -173,6 +180,23 @@ func VerifyAttestation(attestation *pb.Attestation, opts VerifyOpts) (*pb.Machin
 	return nil, fmt.Errorf("attestation does not contain a supported quote")
 }
 
+// toPCRBank converts the PCR values into a register.PCRBank, used to replay
+// a Canonical Event Log.
+func toPCRBank(pcrs *tpmpb.PCRs) (register.PCRBank, error) {
+	pcrBank := register.PCRBank{TCGHashAlgo: state.HashAlgo(pcrs.GetHash())}
+	digestAlg, err := pcrBank.TCGHashAlgo.CryptoHash()
+	if err != nil {
+		return register.PCRBank{}, fmt.Errorf("invalid digest algorithm")
+	}
+	for pcrIndex, digest := range pcrs.GetPcrs() {
+		pcrBank.PCRs = append(pcrBank.PCRs, register.PCR{
+			Index:     int(pcrIndex),
+			Digest:    digest,
+			DigestAlg: digestAlg})
+	}
+	return pcrBank, nil
+}
+
 // validateAK validates AK cert in the attestation, and returns AK cert (if exists) and public key.
 // It also pulls out the GCE Instance Info if it exists.
 func validateAK(attestation *pb.Attestation, opts VerifyOpts) (*pb.MachineState, crypto.PublicKey, error) {
@@ -278,6 +302,15 @@ func validateOpts(opts VerifyOpts) error {
 	return nil
 }
 
+// eventLogLimits returns the limits for the event logs, where the zero value
+// means no limits.
+func (opts VerifyOpts) eventLogLimits() cel.DecodeLimits {
+	if opts.EventLogLimits == nil {
+		return cel.DecodeLimits{}
+	}
+	return *opts.EventLogLimits
+}
+
 func validateAKPub(ak crypto.PublicKey, opts VerifyOpts) error {
 	for _, trusted := range opts.TrustedAKs {
 		if internal.PubKeysEqual(ak, trusted) {
@@ -353,9 +386,33 @@ func makePool(certs []*x509.Certificate) *x509.CertPool {
 // 2. verify GceTechnology since the GCE Technology event is directly related to the TPM.
 // 3. populate the machineState TeeAttestatation field with the verified TDX/SNP attestation data.
 func parseMachineStateFromTPM(attestation *pb.Attestation, pcrs *tpmpb.PCRs, opts VerifyOpts) (*pb.MachineState, error) {
+	var imaState *pb.ImaState
+	if len(attestation.GetImaEventLog()) != 0 {
+		var err error
+		if imaState, err = parseImaEventLog(attestation.GetImaEventLog(), pcrs, opts.eventLogLimits()); err != nil {
+			return nil, fmt.Errorf("failed to validate the IMA event log: %w", err)
+		}
+	}
+
+	var systemdState *pb.SystemdState
+	if len(attestation.GetSystemdEventLog()) != 0 {
+		// The systemd log extends PCRs also measured by the firmware, so the
+		// TCG event log is replayed against the values before systemd's events.
+		systemdEvents, firmwarePCRs, err := replaySystemdLog(attestation.GetEventLog(), attestation.GetSystemdEventLog(), pcrs, opts.eventLogLimits())
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate the systemd event log: %w", err)
+		}
+		if systemdState, err = getSystemdState(systemdEvents); err != nil {
+			return nil, fmt.Errorf("failed to parse the systemd event log: %w", err)
+		}
+		pcrs = firmwarePCRs
+	}
+
 	ms, err := parsePCClientEventLog(attestation.GetEventLog(), pcrs, opts.Loader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate the PCClient event log: %w", err)
 	}
+	ms.Systemd = systemdState
+	ms.Ima = imaState
 	return ms, nil
 }
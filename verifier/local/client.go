@@ -0,0 +1,232 @@
+// Package local implements a verifier.Client verifying attestations in
+// process, for deployments without access to a remote verifier (e.g.,
+// air-gapped ones). It mints tokens signed with a key of the deployment.
+package local
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/server"
+	"github.com/google/go-tpm-tools/verifier"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	challengeNamePrefix = "local://"
+	nonceSize           = 32
+	// The lifetime of a challenge, and the default lifetime of a token.
+	challengeLifetime    = time.Hour
+	defaultTokenLifetime = time.Hour
+	// The only token type minted by the local verifier.
+	tokenTypeOIDC = "OIDC"
+)
+
+// Options configures the local verifier.
+type Options struct {
+	// Signer signs the tokens. It must be an *rsa.PrivateKey (RS256) or a P-256
+	// *ecdsa.PrivateKey (ES256).
+	Signer crypto.Signer
+	// KeyID is set as the "kid" header of the tokens, if not empty.
+	KeyID string
+	// Issuer is the "iss" claim of the tokens, and their default audience.
+	Issuer string
+	// Policy, if not nil, is evaluated against the verified MachineState: the
+	// verifier mints no token for a machine state violating it.
+	Policy *attestpb.Policy
+	// TrustedRootCerts verify the AK certificate of the attestations. If both
+	// TrustedRootCerts and TrustedAKs are empty, server.GceEKRoots is used.
+	TrustedRootCerts []*x509.Certificate
+	// TrustedAKs are the public keys trusted as AKs, as in server.VerifyOpts.
+	TrustedAKs []crypto.PublicKey
+	// TokenLifetime is the lifetime of the tokens, an hour if zero.
+	TokenLifetime time.Duration
+}
+
+// Claims are the claims of the tokens minted by the local verifier.
+type Claims struct {
+	jwt.RegisteredClaims
+	// Nonces are the nonces of the token options of the request, if any.
+	Nonces []string `json:"eat_nonce,omitempty"`
+	// MachineState is the JSON encoding of the verified attest.MachineState.
+	MachineState json.RawMessage `json:"machine_state"`
+}
+
+type client struct {
+	opts          Options
+	signingMethod jwt.SigningMethod
+
+	mu sync.Mutex
+	// The expiry of the challenges not yet used, by challenge name.
+	challenges map[string]time.Time
+}
+
+// NewClient creates a local verifier with the given options.
+func NewClient(opts Options) (verifier.Client, error) {
+	var signingMethod jwt.SigningMethod
+	switch key := opts.Signer.(type) {
+	case *rsa.PrivateKey:
+		signingMethod = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %v, expect P-256", key.Curve.Params().Name)
+		}
+		signingMethod = jwt.SigningMethodES256
+	case nil:
+		return nil, errors.New("signing key required to initialize the local verifier")
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T, expect an RSA or ECDSA private key", key)
+	}
+	if opts.Issuer == "" {
+		return nil, errors.New("issuer required to initialize the local verifier")
+	}
+	if len(opts.TrustedRootCerts) == 0 && len(opts.TrustedAKs) == 0 {
+		opts.TrustedRootCerts = server.GceEKRoots
+	}
+	if opts.TokenLifetime == 0 {
+		opts.TokenLifetime = defaultTokenLifetime
+	}
+	return &client{
+		opts:          opts,
+		signingMethod: signingMethod,
+		challenges:    make(map[string]time.Time),
+	}, nil
+}
+
+// Public returns the public key verifying the tokens.
+func (c *client) Public() crypto.PublicKey {
+	return c.opts.Signer.Public()
+}
+
+// CreateChallenge returns a challenge with a random nonce. Each challenge is
+// valid for a single VerifyAttestation call, within an hour.
+func (c *client) CreateChallenge(_ context.Context) (*verifier.Challenge, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate a nonce: %v", err)
+	}
+	name := challengeNamePrefix + hex.EncodeToString(nonce)
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, expiry := range c.challenges {
+		if now.After(expiry) {
+			delete(c.challenges, n)
+		}
+	}
+	c.challenges[name] = now.Add(challengeLifetime)
+	return &verifier.Challenge{Name: name, Nonce: nonce}, nil
+}
+
+// useChallenge checks that the challenge was created by this client and has
+// not expired, and makes sure it cannot be used again.
+func (c *client) useChallenge(challenge *verifier.Challenge) error {
+	if challenge == nil {
+		return errors.New("no challenge in the request")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiry, ok := c.challenges[challenge.Name]
+	if !ok {
+		return fmt.Errorf("unknown or already used challenge %q", challenge.Name)
+	}
+	delete(c.challenges, challenge.Name)
+	if time.Now().After(expiry) {
+		return fmt.Errorf("challenge %q has expired", challenge.Name)
+	}
+	if challenge.Name != challengeNamePrefix+hex.EncodeToString(challenge.Nonce) {
+		return fmt.Errorf("challenge %q does not match its nonce", challenge.Name)
+	}
+	return nil
+}
+
+// VerifyAttestation verifies the TPM attestation of the request with
+// server.VerifyAttestation, evaluates the policy against the resulting
+// MachineState, and returns a token with the MachineState as a claim.
+// The GCP credentials and the container image signatures of the request are
+// ignored.
+func (c *client) VerifyAttestation(_ context.Context, req verifier.VerifyAttestationRequest) (*verifier.VerifyAttestationResponse, error) {
+	if err := c.useChallenge(req.Challenge); err != nil {
+		return nil, err
+	}
+	if req.Attestation == nil {
+		return nil, errors.New("the local verifier only supports TPM attestations")
+	}
+	ms, err := server.VerifyAttestation(req.Attestation, server.VerifyOpts{
+		Nonce:            req.Challenge.Nonce,
+		TrustedRootCerts: c.opts.TrustedRootCerts,
+		TrustedAKs:       c.opts.TrustedAKs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify attestation: %v", err)
+	}
+	if c.opts.Policy != nil {
+		if err := server.EvaluatePolicy(ms, c.opts.Policy); err != nil {
+			return nil, fmt.Errorf("machine state violates the policy: %v", err)
+		}
+	}
+	msJSON, err := protojson.Marshal(ms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert proto object to JSON: %v", err)
+	}
+
+	audience := c.opts.Issuer
+	var nonces []string
+	if opts := req.TokenOptions; opts != nil {
+		if opts.TokenType != "" && opts.TokenType != tokenTypeOIDC {
+			return nil, fmt.Errorf("unsupported token type %q, expect %q", opts.TokenType, tokenTypeOIDC)
+		}
+		if opts.Audience != "" {
+			audience = opts.Audience
+		}
+		nonces = opts.Nonces
+	}
+
+	now := jwt.TimeFunc()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(c.opts.TokenLifetime)),
+			Audience:  []string{audience},
+			Issuer:    c.opts.Issuer,
+			Subject:   subject(ms),
+		},
+		Nonces:       nonces,
+		MachineState: msJSON,
+	}
+	token := jwt.NewWithClaims(c.signingMethod, claims)
+	if c.opts.KeyID != "" {
+		token.Header["kid"] = c.opts.KeyID
+	}
+	signed, err := token.SignedString(c.opts.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the token: %v", err)
+	}
+	return &verifier.VerifyAttestationResponse{ClaimsToken: []byte(signed)}, nil
+}
+
+// subject returns the URL of the GCE instance of the machine state, as in the
+// tokens of the Google Cloud Attestation, or "" if the AK certificate does not
+// carry the instance info.
+func subject(ms *attestpb.MachineState) string {
+	info := ms.GetPlatform().GetInstanceInfo()
+	if info == nil {
+		return ""
+	}
+	return server.GCEInstanceURL(info)
+}
@@ -0,0 +1,211 @@
+package local
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-cmp/cmp"
+	tpmclient "github.com/google/go-tpm-tools/client"
+	"github.com/google/go-tpm-tools/internal/test"
+	attestpb "github.com/google/go-tpm-tools/proto/attest"
+	"github.com/google/go-tpm-tools/verifier"
+	"github.com/google/go-tpm-tools/verifier/models"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const testIssuer = "https://verifier.example.com"
+
+func newTestClient(t *testing.T, ak *tpmclient.Key, policy *attestpb.Policy) (verifier.Client, *ecdsa.PrivateKey) {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(Options{
+		Signer:     signer,
+		KeyID:      "test-key",
+		Issuer:     testIssuer,
+		Policy:     policy,
+		TrustedAKs: []crypto.PublicKey{ak.PublicKey()},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	return c, signer
+}
+
+func attest(t *testing.T, c verifier.Client, ak *tpmclient.Key) verifier.VerifyAttestationRequest {
+	t.Helper()
+	challenge, err := c.CreateChallenge(context.Background())
+	if err != nil {
+		t.Fatalf("CreateChallenge() failed: %v", err)
+	}
+	attestation, err := ak.Attest(tpmclient.AttestOpts{Nonce: challenge.Nonce})
+	if err != nil {
+		t.Fatalf("Attest() failed: %v", err)
+	}
+	return verifier.VerifyAttestationRequest{Challenge: challenge, Attestation: attestation}
+}
+
+func TestVerifyAttestation(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer tpmclient.CheckedClose(t, tpm)
+	ak, err := tpmclient.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	c, signer := newTestClient(t, ak, &attestpb.Policy{})
+
+	for _, tc := range []struct {
+		name         string
+		tokenOptions *models.TokenOptions
+		wantAudience string
+		wantNonces   []string
+	}{
+		{"DefaultToken", nil, testIssuer, nil},
+		{"CustomToken", &models.TokenOptions{Audience: "https://rp.example.com", Nonces: []string{"nonce1", "nonce2"}, TokenType: "OIDC"}, "https://rp.example.com", []string{"nonce1", "nonce2"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := attest(t, c, ak)
+			req.TokenOptions = tc.tokenOptions
+			resp, err := c.VerifyAttestation(context.Background(), req)
+			if err != nil {
+				t.Fatalf("VerifyAttestation() failed: %v", err)
+			}
+
+			claims := &Claims{}
+			token, err := jwt.ParseWithClaims(string(resp.ClaimsToken), claims, func(token *jwt.Token) (any, error) {
+				return signer.Public(), nil
+			})
+			if err != nil {
+				t.Fatalf("failed to parse the token: %v", err)
+			}
+			if kid := token.Header["kid"]; kid != "test-key" {
+				t.Errorf("got kid %v, want %q", kid, "test-key")
+			}
+			if claims.Issuer != testIssuer {
+				t.Errorf("got issuer %q, want %q", claims.Issuer, testIssuer)
+			}
+			if !claims.VerifyAudience(tc.wantAudience, true) {
+				t.Errorf("got audience %v, want %q", claims.Audience, tc.wantAudience)
+			}
+			if diff := cmp.Diff(tc.wantNonces, claims.Nonces); diff != "" {
+				t.Errorf("got unexpected nonces (-want +got):\n%s", diff)
+			}
+			ms := &attestpb.MachineState{}
+			if err := protojson.Unmarshal(claims.MachineState, ms); err != nil {
+				t.Errorf("failed to parse the machine state claim: %v", err)
+			}
+
+			// A challenge cannot be used twice.
+			if _, err := c.VerifyAttestation(context.Background(), req); err == nil {
+				t.Error("VerifyAttestation() with a used challenge got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestVerifyAttestationErrors(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer tpmclient.CheckedClose(t, tpm)
+	ak, err := tpmclient.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	otherAK, err := tpmclient.AttestationKeyRSA(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherAK.Close()
+
+	c, _ := newTestClient(t, ak, nil)
+	for _, tc := range []struct {
+		name   string
+		client verifier.Client
+		modify func(*verifier.VerifyAttestationRequest)
+	}{
+		{"UnknownChallenge", c, func(req *verifier.VerifyAttestationRequest) {
+			req.Challenge = &verifier.Challenge{Name: "local://unknown", Nonce: req.Challenge.Nonce}
+		}},
+		{"NoChallenge", c, func(req *verifier.VerifyAttestationRequest) {
+			req.Challenge = nil
+		}},
+		{"NoAttestation", c, func(req *verifier.VerifyAttestationRequest) {
+			req.Attestation = nil
+		}},
+		{"OtherNonce", c, func(req *verifier.VerifyAttestationRequest) {
+			req.Challenge.Nonce = []byte("other nonce")
+		}},
+		{"UntrustedAK", c, func(req *verifier.VerifyAttestationRequest) {
+			attestation, err := otherAK.Attest(tpmclient.AttestOpts{Nonce: req.Challenge.Nonce})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Attestation = attestation
+		}},
+		{"UnsupportedTokenType", c, func(req *verifier.VerifyAttestationRequest) {
+			req.TokenOptions = &models.TokenOptions{Audience: "aud", TokenType: "PKI"}
+		}},
+		{"PolicyViolation", func() verifier.Client {
+			c, _ := newTestClient(t, ak, &attestpb.Policy{Platform: &attestpb.PlatformPolicy{MinimumTechnology: attestpb.GCEConfidentialTechnology_AMD_SEV_SNP}})
+			return c
+		}(), func(*verifier.VerifyAttestationRequest) {}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := attest(t, tc.client, ak)
+			tc.modify(&req)
+			if _, err := tc.client.VerifyAttestation(context.Background(), req); err == nil {
+				t.Error("VerifyAttestation() got nil error, want error")
+			}
+		})
+	}
+}
+
+func TestPublic(t *testing.T) {
+	tpm := test.GetTPM(t)
+	defer tpmclient.CheckedClose(t, tpm)
+	ak, err := tpmclient.AttestationKeyECC(tpm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ak.Close()
+	c, signer := newTestClient(t, ak, nil)
+
+	pub := c.(interface{ Public() crypto.PublicKey }).Public()
+	if !signer.PublicKey.Equal(pub) {
+		t.Errorf("Public() got %v, want the public key of the signer", pub)
+	}
+}
+
+func TestNewClientErrors(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{"NoSigner", Options{Issuer: testIssuer}},
+		{"P384Signer", Options{Signer: p384Key, Issuer: testIssuer}},
+		{"NoIssuer", Options{Signer: rsaKey}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewClient(tc.opts); err == nil {
+				t.Error("NewClient() got nil error, want error")
+			}
+		})
+	}
+}